@@ -9,8 +9,12 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/redis/go-redis/v9"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/config"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
 	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
 	bidEngine "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/bidEngine/service"
 	bidEngineWeb "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/bidEngine/web"
 
@@ -50,13 +54,103 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	var rates *currency.Rates
+	if cfg.CurrencyRatesPath != "" {
+		rates, err = currency.NewRates(cfg.CurrencyRatesPath)
+		if err != nil {
+			log.Fatalf("Cannot load currency rates: %v", err)
+		}
+		go rates.Watch(ctx, cfg.CurrencyRatesRefreshInterval)
+		log.Printf("Currency rates loaded from %s", cfg.CurrencyRatesPath)
+	}
+
+	var dealRegistry *deals.Registry
+	if cfg.DealsPath != "" {
+		dealRegistry, err = deals.NewRegistry(cfg.DealsPath, rates)
+		if err != nil {
+			log.Fatalf("Cannot load deals: %v", err)
+		}
+		go dealRegistry.Watch(ctx, cfg.DealsRefreshInterval)
+		log.Printf("Deals loaded from %s", cfg.DealsPath)
+	}
+
+	margins, err := bidEngine.NewMarginPolicy(cfg.MarginPolicyPath, cfg.ProfitPercent)
+	if err != nil {
+		log.Fatalf("Cannot load margin policy: %v", err)
+	}
+
+	lossNotifier := bidEngine.NewLossNotifier(
+		cfg.LossNotifyWorkers,
+		cfg.LossNotifyQueueSize,
+		cfg.LossNotifyRetries,
+		cfg.LossNotifyTimeout,
+		cfg.LossNotifyExposePrice,
+	)
+	lossNotifier.Start()
+	defer lossNotifier.Close()
+
+	// Redis нужен шейдингу и частотным ограничениям: billing может прийти в любую реплику
+	var redisClient *redis.Client
+	if cfg.BidShadingEnabled || cfg.FrequencyCapsPath != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		defer redisClient.Close()
+
+		if err := redisClient.Ping(ctx).Err(); err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+	}
+
+	var shader *bidEngine.BidShader
+	if cfg.BidShadingEnabled {
+		shader, err = bidEngine.NewBidShader(bidEngine.ShadingConfig{
+			TestPercent:   cfg.BidShadingTestPercent,
+			MaxShade:      money.RatioFromFloat64(cfg.BidShadingMaxPercent),
+			TargetWinRate: cfg.BidShadingTargetWinRate,
+			MinSamples:    cfg.BidShadingMinSamples,
+			BucketWidth:   money.FromFloat64(cfg.BidShadingBucketWidth),
+			Buckets:       cfg.BidShadingBuckets,
+			MaxSamples:    cfg.BidShadingMaxSamples,
+			PendingTTL:    cfg.BidShadingPendingTTL,
+		}, bidEngine.NewRedisPendingStore(redisClient))
+		if err != nil {
+			log.Fatalf("Cannot create bid shader: %v", err)
+		}
+		log.Printf("Bid shading enabled for %d%% of auctions", cfg.BidShadingTestPercent)
+	}
+
+	var frequencyCaps *bidEngine.FrequencyCapper
+	if cfg.FrequencyCapsPath != "" {
+		frequencyCaps, err = bidEngine.NewFrequencyCapper(
+			cfg.FrequencyCapsPath,
+			bidEngine.NewRedisCapStore(redisClient),
+			cfg.FrequencyCapTimeout,
+			cfg.FrequencyCapPendingTTL,
+		)
+		if err != nil {
+			log.Fatalf("Cannot load frequency caps: %v", err)
+		}
+		log.Printf("Frequency caps loaded from %s", cfg.FrequencyCapsPath)
+	}
+
 	s := grpc.NewServer()
 	bidEngineGrpc.RegisterBidEngineServiceServer(
 		s,
 		bidEngineWeb.NewServer(
-			cfg.ProfitPercent,
+			&bidEngine.AuctionConfig{
+				Margins:         margins,
+				AuctionCurrency: cfg.AuctionCurrency,
+				Rates:           rates,
+				Deals:           dealRegistry,
+				Shader:          shader,
+				FrequencyCaps:   frequencyCaps,
+			},
 			nil,
 			cfg.SystemHostname,
+			lossNotifier,
 			bidEngine.GetWinnerBidInternal_V_2_4,
 			bidEngine.GetWinnerBidInternal_V_2_5,
 		),
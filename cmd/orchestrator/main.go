@@ -52,6 +52,7 @@ func main() {
 		orchestratorWeb.NewServer(
 			clients.BidEngineGrpcClient,
 			clients.DspRouterGrpcClient,
+			orchestratorWeb.NewRouterReplicas(cfg.DspRouterReplicas),
 			nil,
 			cfg.GetBidsTimeout,
 			cfg.AuctionTimeout,
@@ -16,11 +16,15 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/config"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
 	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 	maxproc "gitlab.com/twinbid-exchange/RTB-exchange/internal/mp"
 	dspRouterWeb "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/dspRouter/web"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/taxonomy"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/usersync"
 
 	"google.golang.org/grpc"
 )
@@ -63,20 +67,95 @@ func main() {
 		log.Fatalf("SPP rules are not available: %v", err)
 	}
 
-	if err := fileLoader.LoadDSPRules(); err != nil {
-		log.Printf("Warning: Failed to load dsp filter rules: %v", err)
-	} else {
-		log.Println("Filter rules loaded successfully")
-	}
+	var ruleStore *filter.RedisRuleStore
+	if cfg.RuleStoreEnabled {
+		ruleStoreClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		defer ruleStoreClient.Close()
 
-	if err := fileLoader.LoadSPPRules(); err != nil {
-		log.Printf("Warning: Failed to load spp filter rules: %v", err)
+		ruleStore = filter.NewRedisRuleStore(ruleManager, fileLoader, ruleStoreClient, cfg.RuleStoreReconcileInterval)
+		ruleStore.Start(ctx)
+		log.Printf(
+			"Filter rules served from Redis rule store: dsp revision %d, spp revision %d",
+			ruleManager.Revision(filter.RuleSetDSP),
+			ruleManager.Revision(filter.RuleSetSPP),
+		)
 	} else {
-		log.Println("Filter rules loaded successfully")
+		if err := fileLoader.LoadDSPRules(); err != nil {
+			log.Printf("Warning: Failed to load dsp filter rules: %v", err)
+		} else {
+			log.Println("Filter rules loaded successfully")
+		}
+
+		if err := fileLoader.LoadSPPRules(); err != nil {
+			log.Printf("Warning: Failed to load spp filter rules: %v", err)
+		} else {
+			log.Println("Filter rules loaded successfully")
+		}
 	}
 
 	processor := filter.NewOptimizedFilterProcessor(ruleManager)
 
+	var rates *currency.Rates
+	if cfg.CurrencyRatesPath != "" {
+		rates, err = currency.NewRates(cfg.CurrencyRatesPath)
+		if err != nil {
+			log.Fatalf("Cannot load currency rates: %v", err)
+		}
+		go rates.Watch(ctx, cfg.CurrencyRatesRefreshInterval)
+		log.Printf("Currency rates loaded from %s", cfg.CurrencyRatesPath)
+	}
+
+	var dealRegistry *deals.Registry
+	if cfg.DealsPath != "" {
+		dealRegistry, err = deals.NewRegistry(cfg.DealsPath, rates)
+		if err != nil {
+			log.Fatalf("Cannot load deals: %v", err)
+		}
+		go dealRegistry.Watch(ctx, cfg.DealsRefreshInterval)
+		log.Printf("Deals loaded from %s", cfg.DealsPath)
+	}
+
+	taxonomies, err := taxonomy.NewMapper(cfg.TaxonomyMappingsPath)
+	if err != nil {
+		log.Fatalf("Cannot load taxonomy mappings: %v", err)
+	}
+	go taxonomies.Watch(ctx, cfg.TaxonomyMappingsRefreshInterval)
+	sspTaxonomies, err := taxonomy.ParseEndpoints(taxonomies, cfg.SSPTaxonomies)
+	if err != nil {
+		log.Fatalf("Invalid SSP_TAXONOMIES: %v", err)
+	}
+	dspTaxonomies, err := taxonomy.ParseEndpoints(taxonomies, cfg.DSPTaxonomies)
+	if err != nil {
+		log.Fatalf("Invalid DSP_TAXONOMIES: %v", err)
+	}
+
+	var userSync *usersync.Resolver
+	if cfg.UserSyncPath != "" {
+		userSyncRegistry, err := usersync.NewRegistry(cfg.UserSyncPath)
+		if err != nil {
+			log.Fatalf("Cannot load user sync: %v", err)
+		}
+		go userSyncRegistry.Watch(ctx, cfg.UserSyncRefreshInterval)
+
+		userSyncClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		defer userSyncClient.Close()
+
+		userSync = usersync.NewResolver(
+			userSyncRegistry,
+			usersync.NewRedisStore(userSyncClient, cfg.UserSyncTTL),
+			cfg.UserSyncLookupTimeout,
+		)
+		log.Printf("User sync loaded from %s", cfg.UserSyncPath)
+	}
+
 	name := "DSP1"
 	var price float32 = 0.72
 	BidId := fmt.Sprint(name, name)
@@ -85,20 +164,22 @@ func main() {
 	adid := "ADID"
 	jsonData, err := json.Marshal(&ortb_V2_5.BidResponse{
 		Id: &name,
-		Seatbid: &ortb_V2_5.SeatBid{
-			Bid: []*ortb_V2_5.Bid{
-				{
-					Id:    &BidId,
-					Price: &price,
-					Adid:  &adid,
-					Nurl:  &Nurl,
-					Burl:  &Burl,
+		Seatbid: []*ortb_V2_5.SeatBid{
+			{
+				Bid: []*ortb_V2_5.Bid{
+					{
+						Id:    &BidId,
+						Price: &price,
+						Adid:  &adid,
+						Nurl:  &Nurl,
+						Burl:  &Burl,
+					},
 				},
 			},
 		},
 	})
 	if err != nil {
-		log.Fatalf("Can not marshal in GetBids_V2_5: %w", err)
+		log.Fatalf("Can not marshal in GetBids_V2_5: %v", err)
 	}
 
 	resp := &http.Response{
@@ -109,20 +190,27 @@ func main() {
 	s := grpc.NewServer()
 	dspRouterGrpc.RegisterDspRouterServiceServer(
 		s,
-		dspRouterWeb.NewServer(
-			ruleManager,
-			fileLoader,
-			processor,
-			cfg.DspRulesConfigPath,
-			cfg.SppRulesConfigPath,
-			cfg.DSPEndpoints_v_2_4,
-			cfg.DSPEndpoints_v_2_5,
-			nil,
-			cfg.BidResponsesTimeout,
-			cfg.MaxParallelRequests,
-			cfg.Debug,
-			resp,
-		),
+		dspRouterWeb.NewServer(dspRouterWeb.ServerConfig{
+			RuleManager:         ruleManager,
+			FileLoader:          fileLoader,
+			RuleStore:           ruleStore,
+			Processor:           processor,
+			DspConfigPath:       cfg.DspRulesConfigPath,
+			SppConfigPath:       cfg.SppRulesConfigPath,
+			DspEndpoints_v_2_4:  cfg.DSPEndpoints_v_2_4,
+			DspEndpoints_v_2_5:  cfg.DSPEndpoints_v_2_5,
+			DspCurrencies:       cfg.DSPCurrencies,
+			Rates:               rates,
+			Deals:               dealRegistry,
+			Taxonomies:          taxonomies,
+			SspTaxonomies:       sspTaxonomies,
+			DspTaxonomies:       dspTaxonomies,
+			UserSync:            userSync,
+			Timeout:             cfg.BidResponsesTimeout,
+			MaxParallelRequests: cfg.MaxParallelRequests,
+			Debug:               cfg.Debug,
+			Resp:                resp,
+		}),
 	)
 
 	errChan := make(chan error)
@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
+	"github.com/redis/go-redis/v9"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/config"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/floors"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/geoBadIp"
 	httpServer "gitlab.com/twinbid-exchange/RTB-exchange/internal/http"
 	sppAdapter "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/sspAdapter/service"
 	sppAdapterWeb "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/sspAdapter/web"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/usersync"
 )
 
 func main() {
@@ -44,12 +49,73 @@ func main() {
 
 	badIp, err := geoBadIp.NewBadIPService(cfg.GeoIpDbPath)
 	if err != nil {
-		log.Fatalf("failed to create bad ip service: %w", err)
+		log.Fatalf("failed to create bad ip service: %v", err)
 	}
 
 	geoIp, err := geoBadIp.NewGeoIPService(cfg.GeoIpDbPath)
 	if err != nil {
-		log.Fatalf("failed to create geo ip service: %w", err)
+		log.Fatalf("failed to create geo ip service: %v", err)
+	}
+	if cfg.GeoAsnDbPath != "" {
+		if err := geoIp.OpenASN(cfg.GeoAsnDbPath); err != nil {
+			log.Fatalf("failed to open asn database: %v", err)
+		}
+		log.Printf("GeoIP ASN database loaded: %s", cfg.GeoAsnDbPath)
+	}
+	if cfg.GeoConnectionTypeDbPath != "" {
+		if err := geoIp.OpenConnectionType(cfg.GeoConnectionTypeDbPath); err != nil {
+			log.Fatalf("failed to open connection type database: %v", err)
+		}
+		log.Printf("GeoIP connection type database loaded: %s", cfg.GeoConnectionTypeDbPath)
+	}
+
+	// MaxMind обновляет базы еженедельно, новые файлы подхватываются без перезапуска
+	geoDatabases := append(geoIp.Databases(), badIp.Database())
+	for _, db := range geoDatabases {
+		go db.Watch(ctx, cfg.GeoIpRefreshInterval)
+	}
+
+	var rates *currency.Rates
+	if cfg.CurrencyRatesPath != "" {
+		rates, err = currency.NewRates(cfg.CurrencyRatesPath)
+		if err != nil {
+			log.Fatalf("Cannot load currency rates: %v", err)
+		}
+		go rates.Watch(ctx, cfg.CurrencyRatesRefreshInterval)
+		log.Printf("Currency rates loaded from %s", cfg.CurrencyRatesPath)
+	}
+
+	floorManager, err := floors.NewManager(cfg.FloorRulesPath, rates)
+	if err != nil {
+		log.Fatalf("Cannot load floor rules: %v", err)
+	}
+	log.Printf("Floor rules loaded: %d", len(floorManager.Rules().Rules))
+
+	var userSyncer *usersync.Syncer
+	if cfg.UserSyncPath != "" {
+		userSyncRegistry, err := usersync.NewRegistry(cfg.UserSyncPath)
+		if err != nil {
+			log.Fatalf("Cannot load user sync: %v", err)
+		}
+		go userSyncRegistry.Watch(ctx, cfg.UserSyncRefreshInterval)
+
+		userSyncClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		defer userSyncClient.Close()
+
+		if err := userSyncClient.Ping(ctx).Err(); err != nil {
+			log.Fatalf("Failed to connect to Redis for user sync: %v", err)
+		}
+
+		userSyncer = usersync.NewSyncer(
+			userSyncRegistry,
+			usersync.NewRedisStore(userSyncClient, cfg.UserSyncTTL),
+			fmt.Sprintf("https://%s%s", cfg.UserSyncHostname, sppAdapterWeb.GetSetUidUrl),
+		)
+		log.Printf("User sync loaded from %s", cfg.UserSyncPath)
 	}
 
 	adapter := sppAdapter.NewSspAdapter(
@@ -64,13 +130,31 @@ func main() {
 		router,
 		nil,
 		badIp.IsBad,
-		geoIp.GetCountryISO,
+		geoIp.Lookup,
 		client,
+		floorManager,
+		userSyncer,
+		cfg.UserSyncTTL,
+		cfg.GeoOverwrite,
 		cfg.GetWinnerBidTimeout,
 		cfg.NurlTimeout,
 		cfg.BurlTimeout,
 	)
 	log.Println("HTTP routes initialized")
 
+	// Админ API слушает свой адрес, чтобы не быть доступным SSP вместе с /bid
+	adminRouter := httpServer.InitHttpRouter()
+	sppAdapterWeb.InitAdminRoutes(
+		ctx,
+		adminRouter,
+		client,
+		floorManager,
+		cfg.FloorRulesPath,
+		geoDatabases,
+		cfg.GetWinnerBidTimeout,
+	)
+	go httpServer.RunHttpServer(ctx, adminRouter, cfg.AdminHost, cfg.AdminPort)
+	log.Println("Admin HTTP routes initialized")
+
 	httpServer.RunHttpServer(ctx, router, cfg.Host, cfg.Port)
 }
@@ -763,6 +763,12 @@ deploy_services() {
             kubectl apply -f "$external_service_file"
         fi
 
+        # Headless сервис со всеми подами, для вызовов каждой реплики
+        local replicas_service_file="$K8S_DIR/services/${service}-replicas-service.yaml"
+        if [ -f "$replicas_service_file" ]; then
+            kubectl apply -f "$replicas_service_file"
+        fi
+
         kubectl rollout status "deployment/$deployment_name" -n "$NAMESPACE" --timeout=180s
     done
 
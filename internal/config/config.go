@@ -47,11 +47,41 @@ func (l *ListString) SetValue(value string) error {
 
 type BiddingEngineConfig struct {
 	HttpServer
-	ProfitPercent  float32 `yaml:"PROFIT_PERCENT" env:"PROFIT_PERCENT" env-default:"0.2"`
-	SystemHostname string  `yaml:"SYSTEM_HOSTNAME" env:"SYSTEM_HOSTNAME"`
+	// Маржа по умолчанию, если в политике маржи нет подходящего правила
+	ProfitPercent    float32 `yaml:"PROFIT_PERCENT" env:"PROFIT_PERCENT" env-default:"0.2"`
+	MarginPolicyPath string  `yaml:"MARGIN_POLICY_PATH" env:"MARGIN_POLICY_PATH"`
+	SystemHostname   string  `yaml:"SYSTEM_HOSTNAME" env:"SYSTEM_HOSTNAME"`
+
+	AuctionCurrency string `yaml:"AUCTION_CURRENCY" env:"AUCTION_CURRENCY" env-default:"USD"`
+	CurrencyConfig
+	DealsConfig
+	LossNotifierConfig
+	BidShadingConfig
+	FrequencyCapConfig
 	RedisConfig
 }
 
+type BidShadingConfig struct {
+	BidShadingEnabled bool `yaml:"BID_SHADING_ENABLED" env:"BID_SHADING_ENABLED" env-default:"false"`
+	// Доля аукционов (0-100) в бакете с шейдингом, остальные - контрольная группа
+	BidShadingTestPercent   int           `yaml:"BID_SHADING_TEST_PERCENT" env:"BID_SHADING_TEST_PERCENT" env-default:"50"`
+	BidShadingMaxPercent    float64       `yaml:"BID_SHADING_MAX_PERCENT" env:"BID_SHADING_MAX_PERCENT" env-default:"0.2"`
+	BidShadingTargetWinRate float64       `yaml:"BID_SHADING_TARGET_WIN_RATE" env:"BID_SHADING_TARGET_WIN_RATE" env-default:"0.8"`
+	BidShadingMinSamples    int64         `yaml:"BID_SHADING_MIN_SAMPLES" env:"BID_SHADING_MIN_SAMPLES" env-default:"100"`
+	BidShadingBucketWidth   float64       `yaml:"BID_SHADING_BUCKET_WIDTH" env:"BID_SHADING_BUCKET_WIDTH" env-default:"0.05"`
+	BidShadingBuckets       int           `yaml:"BID_SHADING_BUCKETS" env:"BID_SHADING_BUCKETS" env-default:"400"`
+	BidShadingMaxSamples    int64         `yaml:"BID_SHADING_MAX_SAMPLES" env:"BID_SHADING_MAX_SAMPLES" env-default:"100000"`
+	BidShadingPendingTTL    time.Duration `yaml:"BID_SHADING_PENDING_TTL" env:"BID_SHADING_PENDING_TTL" env-default:"10m"`
+}
+
+type LossNotifierConfig struct {
+	LossNotifyWorkers     int           `yaml:"LOSS_NOTIFY_WORKERS" env:"LOSS_NOTIFY_WORKERS" env-default:"8"`
+	LossNotifyQueueSize   int           `yaml:"LOSS_NOTIFY_QUEUE_SIZE" env:"LOSS_NOTIFY_QUEUE_SIZE" env-default:"4096"`
+	LossNotifyRetries     int           `yaml:"LOSS_NOTIFY_RETRIES" env:"LOSS_NOTIFY_RETRIES" env-default:"2"`
+	LossNotifyTimeout     time.Duration `yaml:"LOSS_NOTIFY_TIMEOUT" env:"LOSS_NOTIFY_TIMEOUT" env-default:"1s"`
+	LossNotifyExposePrice bool          `yaml:"LOSS_NOTIFY_EXPOSE_PRICE" env:"LOSS_NOTIFY_EXPOSE_PRICE" env-default:"false"`
+}
+
 type RouterConfig struct {
 	HttpServer
 	DSPEndpoints_v_2_4 ListString `yaml:"DSP_ENDPOINTS_V_2_4" env:"DSP_ENDPOINTS_V_2_4"`
@@ -64,7 +94,24 @@ type RouterConfig struct {
 	MaxParallelRequests int  `yaml:"MAX_PARALLEL_REQUESTS" env:"MAX_PARALLEL_REQUESTS" env-default:"64"`
 	Debug               bool `yaml:"DEBUG" env:"DEBUG" env-default:"false"`
 
+	// Валюта каждой DSP: endpoint=USD,endpoint2=EUR
+	DSPCurrencies MapStringToString `yaml:"DSP_CURRENCIES" env:"DSP_CURRENCIES"`
+	CurrencyConfig
+	DealsConfig
+
+	// Таксономия категорий SSP и DSP: endpoint=iab2; без настройки - IAB 1.0
+	SSPTaxonomies MapStringToString `yaml:"SSP_TAXONOMIES" env:"SSP_TAXONOMIES"`
+	DSPTaxonomies MapStringToString `yaml:"DSP_TAXONOMIES" env:"DSP_TAXONOMIES"`
+	TaxonomyConfig
+
+	UserSyncConfig
+	// Сколько запрос ждёт uid пользователя в DSP из Redis, после этого уходит без buyeruid
+	UserSyncLookupTimeout time.Duration `yaml:"USER_SYNC_LOOKUP_TIMEOUT" env:"USER_SYNC_LOOKUP_TIMEOUT" env-default:"10ms"`
+
 	RedisConfig
+	// Раздача правил фильтра всем репликам через Redis; выключена - правила только из файлов
+	RuleStoreEnabled           bool          `yaml:"RULE_STORE_ENABLED" env:"RULE_STORE_ENABLED" env-default:"false"`
+	RuleStoreReconcileInterval time.Duration `yaml:"RULE_STORE_RECONCILE_INTERVAL" env:"RULE_STORE_RECONCILE_INTERVAL" env-default:"30s"`
 }
 
 type OrchestratorConfig struct {
@@ -74,16 +121,35 @@ type OrchestratorConfig struct {
 	AuctionTimeout time.Duration `yaml:"AUCTION_TIMEOUT" env:"AUCTION_TIMEOUT"`
 	GetBidsTimeout time.Duration `yaml:"GET_BIDS_TIMEOUT" env:"GET_BIDS_TIMEOUT"`
 
+	// host:port, host которого резолвится во все реплики DSP router (headless service);
+	// пусто - роутер одной репликой за URI_OF_DSP_ROUTER
+	DspRouterReplicas string `yaml:"DSP_ROUTER_REPLICAS" env:"DSP_ROUTER_REPLICAS"`
+
 	RedisConfig
 }
 
 type SppAdapterConfig struct {
 	HttpServer
+	AdminServer
 	UriOfOrchestrator   string        `yaml:"URI_OF_ORCHESTRATOR" env:"URI_OF_ORCHESTRATOR"`
 	NurlTimeout         time.Duration `yaml:"NURL_TIMEOUT" env:"NURL_TIMEOUT"`
 	BurlTimeout         time.Duration `yaml:"BURL_TIMEOUT" env:"BURL_TIMEOUT"`
 	GetWinnerBidTimeout time.Duration `yaml:"GET_WINNER_BID_TIMEOUT" env:"GET_WINNER_BID_TIMEOUT"`
 	GeoIpDbPath         string        `yaml:"GEO_IP_DB_PATH" env:"GEO_IP_DB_PATH"`
+	FloorRulesPath      string        `yaml:"FLOOR_RULES_PATH" env:"FLOOR_RULES_PATH"`
+	// Как часто проверять, не обновились ли файлы mmdb
+	GeoIpRefreshInterval time.Duration `yaml:"GEO_IP_REFRESH_INTERVAL" env:"GEO_IP_REFRESH_INTERVAL" env-default:"1m"`
+	// Необязательные базы MaxMind ASN и Connection Type
+	GeoAsnDbPath            string `yaml:"GEO_ASN_DB_PATH" env:"GEO_ASN_DB_PATH"`
+	GeoConnectionTypeDbPath string `yaml:"GEO_CONNECTION_TYPE_DB_PATH" env:"GEO_CONNECTION_TYPE_DB_PATH"`
+	// Заменять гео, которое передал SSP, данными базы. По умолчанию база только дополняет запрос
+	GeoOverwrite bool `yaml:"GEO_OVERWRITE" env:"GEO_OVERWRITE" env-default:"false"`
+
+	CurrencyConfig
+
+	UserSyncConfig
+	// Внешний хост биржи, на /setuid которого DSP возвращают браузер
+	UserSyncHostname string `yaml:"USER_SYNC_HOSTNAME" env:"USER_SYNC_HOSTNAME"`
 
 	RedisConfig
 }
@@ -117,6 +183,42 @@ type MockDspConfig struct {
 	Adid    string  `env:"ADID"`
 }
 
+type CurrencyConfig struct {
+	CurrencyRatesPath            string        `yaml:"CURRENCY_RATES_PATH" env:"CURRENCY_RATES_PATH"`
+	CurrencyRatesRefreshInterval time.Duration `yaml:"CURRENCY_RATES_REFRESH_INTERVAL" env:"CURRENCY_RATES_REFRESH_INTERVAL" env-default:"1m"`
+}
+
+type DealsConfig struct {
+	// Реестр PMP сделок; пустой путь - сделки не принимаются
+	DealsPath            string        `yaml:"DEALS_PATH" env:"DEALS_PATH"`
+	DealsRefreshInterval time.Duration `yaml:"DEALS_REFRESH_INTERVAL" env:"DEALS_REFRESH_INTERVAL" env-default:"1m"`
+}
+
+type FrequencyCapConfig struct {
+	// Частотные ограничения показов пользователю; пустой путь - ограничений нет.
+	// Счётчики хранятся в Redis.
+	FrequencyCapsPath string `yaml:"FREQUENCY_CAPS_PATH" env:"FREQUENCY_CAPS_PATH"`
+	// Сколько аукцион ждёт счётчики из Redis, после этого ставки не ограничиваются
+	FrequencyCapTimeout time.Duration `yaml:"FREQUENCY_CAP_TIMEOUT" env:"FREQUENCY_CAP_TIMEOUT" env-default:"20ms"`
+	// Сколько ждать billing по выигрышу, чтобы засчитать показ
+	FrequencyCapPendingTTL time.Duration `yaml:"FREQUENCY_CAP_PENDING_TTL" env:"FREQUENCY_CAP_PENDING_TTL" env-default:"1h"`
+}
+
+type UserSyncConfig struct {
+	// DSP для синхронизации пользователей; пустой путь - синхронизация выключена.
+	// Соответствия uid хранятся в Redis.
+	UserSyncPath            string        `yaml:"USER_SYNC_PATH" env:"USER_SYNC_PATH"`
+	UserSyncRefreshInterval time.Duration `yaml:"USER_SYNC_REFRESH_INTERVAL" env:"USER_SYNC_REFRESH_INTERVAL" env-default:"1m"`
+	// Сколько хранится uid пользователя в DSP после последней синхронизации
+	UserSyncTTL time.Duration `yaml:"USER_SYNC_TTL" env:"USER_SYNC_TTL" env-default:"720h"`
+}
+
+type TaxonomyConfig struct {
+	// Собственные коды SSP и DSP и дополнения к встроенным таблицам; пустой путь - только встроенные
+	TaxonomyMappingsPath            string        `yaml:"TAXONOMY_MAPPINGS_PATH" env:"TAXONOMY_MAPPINGS_PATH"`
+	TaxonomyMappingsRefreshInterval time.Duration `yaml:"TAXONOMY_MAPPINGS_REFRESH_INTERVAL" env:"TAXONOMY_MAPPINGS_REFRESH_INTERVAL" env-default:"1m"`
+}
+
 type RedisConfig struct {
 	RedisHost     string `yaml:"REDIS_HOST" env:"REDIS_HOST"`
 	RedisPort     string `yaml:"REDIS_PORT" env:"REDIS_PORT"`
@@ -136,6 +238,13 @@ type HttpServer struct {
 	Port uint16 `yaml:"PORT" env:"PORT"`
 }
 
+// AdminServer - отдельный адрес админ API. Он не публикуется через ingress,
+// доступ к нему - через port-forward или из сети кластера.
+type AdminServer struct {
+	AdminHost string `yaml:"ADMIN_HOSTNAME" env:"ADMIN_HOSTNAME" env-default:"127.0.0.1"`
+	AdminPort uint16 `yaml:"ADMIN_PORT" env:"ADMIN_PORT" env-default:"8096"`
+}
+
 func getEnvFileNames() []string {
 	return []string{".env.local", ".env", "bid-engine.env", "clickhouse-loader.env", "kafka-loader.env", "dsp1.env", "dsp2.env", "dsp3.env", "orchestrator.env", "router.env", "spp-adapter.env"}
 }
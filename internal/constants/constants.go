@@ -12,6 +12,9 @@ const (
 	BID_RESPONSE_WINNER_COLUMN              = "BID_RESPONSE_WINNER"
 	BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN = "BID_RESPONSE_WINNER_BY_DSP_PRICE"
 	RESULT_COLUMN                           = "RESULT"
+	FLOOR_SOURCE_COLUMN                     = "FLOOR_SOURCE"
+	MARGIN_COLUMN                           = "MARGIN"
+	DEALS_COLUMN                            = "DEALS"
 )
 
 const (
@@ -0,0 +1,139 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filewatch"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// DEFAULT_CURRENCY - валюта по умолчанию согласно OpenRTB
+const DEFAULT_CURRENCY = "USD"
+
+// RateTable - неизменяемый снимок курсов.
+// Rates[cur] - сколько единиц cur стоит одна единица Base.
+type RateTable struct {
+	Base  string
+	Rates map[string]money.Ratio
+}
+
+// rateFile - формат файла курсов
+type rateFile struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rates хранит актуальную таблицу курсов и подменяет её атомарно при перечитывании файла
+type Rates struct {
+	path    string
+	table   atomic.Pointer[RateTable]
+	modTime atomic.Int64
+}
+
+func NewRates(path string) (*Rates, error) {
+	r := &Rates{path: path}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func Normalize(cur string) string {
+	cur = strings.ToUpper(strings.TrimSpace(cur))
+	if cur == "" {
+		return DEFAULT_CURRENCY
+	}
+	return cur
+}
+
+func (r *Rates) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	table, err := parseRateTable(data)
+	if err != nil {
+		return fmt.Errorf("invalid currency rates file %s: %w", r.path, err)
+	}
+
+	r.table.Store(table)
+	r.modTime.Store(info.ModTime().UnixNano())
+	return nil
+}
+
+// Watch перечитывает файл курсов при изменении времени модификации
+func (r *Rates) Watch(ctx context.Context, interval time.Duration) {
+	filewatch.Watch(ctx, r.path, interval, &r.modTime, "currency rates", r.Reload)
+}
+
+// Convert переводит amount из валюты from в валюту to с заданным округлением.
+// Без загруженной таблицы допускается только конвертация в ту же валюту.
+func (r *Rates) Convert(amount money.Micros, from, to string, rounding money.Rounding) (money.Micros, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return amount, nil
+	}
+	if r == nil {
+		return 0, fmt.Errorf("no currency rates loaded to convert %s to %s", from, to)
+	}
+	return r.table.Load().Convert(amount, from, to, rounding)
+}
+
+// Has сообщает, известен ли курс валюты
+func (r *Rates) Has(cur string) bool {
+	cur = Normalize(cur)
+	if r == nil {
+		return cur == DEFAULT_CURRENCY
+	}
+	_, ok := r.table.Load().Rates[cur]
+	return ok
+}
+
+func (t *RateTable) Convert(amount money.Micros, from, to string, rounding money.Rounding) (money.Micros, error) {
+	fromRate, ok := t.Rates[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %s", from)
+	}
+	toRate, ok := t.Rates[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %s", to)
+	}
+	return amount.Convert(fromRate, toRate, rounding), nil
+}
+
+func parseRateTable(data []byte) (*RateTable, error) {
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	table := RateTable{
+		Base:  Normalize(file.Base),
+		Rates: make(map[string]money.Ratio, len(file.Rates)+1),
+	}
+	for cur, rate := range file.Rates {
+		ratio := money.RatioFromFloat64(rate)
+		if ratio <= 0 {
+			return nil, fmt.Errorf("rate for %s must be at least 0.000001, got %v", cur, rate)
+		}
+		table.Rates[Normalize(cur)] = ratio
+	}
+	if rate, ok := table.Rates[table.Base]; ok && rate != money.RATIO_ONE {
+		return nil, fmt.Errorf("rate for base currency %s must be 1, got %v", table.Base, rate.Float64())
+	}
+	table.Rates[table.Base] = money.RATIO_ONE
+
+	return &table, nil
+}
@@ -0,0 +1,167 @@
+package currency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+const testRates = `{"base": "usd", "rates": {"EUR": 0.9, "jpy": 150, "GBP": 0.5}}`
+
+// writeRates пишет файл курсов и сдвигает время модификации, чтобы Watch увидел изменение
+// даже на файловых системах с грубым разрешением mtime
+func writeRates(t *testing.T, path string, data string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func newTestRates(t *testing.T, data string) *Rates {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rates.json")
+	writeRates(t, path, data, time.Now())
+	rates, err := NewRates(path)
+	require.NoError(t, err)
+	return rates
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		cur      string
+		expected string
+	}{
+		{cur: "EUR", expected: "EUR"},
+		{cur: " eur ", expected: "EUR"},
+		{cur: "", expected: DEFAULT_CURRENCY},
+		{cur: "  ", expected: DEFAULT_CURRENCY},
+	} {
+		assert.Equal(t, tt.expected, Normalize(tt.cur), "Normalize(%q)", tt.cur)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rates := newTestRates(t, testRates)
+
+	for _, tt := range []struct {
+		name     string
+		amount   money.Micros
+		from, to string
+		rounding money.Rounding
+		expected money.Micros
+	}{
+		{name: "Same currency", amount: 1_234_567, from: "eur", to: "EUR", rounding: money.ROUND_DOWN, expected: 1_234_567},
+		{name: "Empty is USD", amount: 1_000_000, from: "", to: "EUR", rounding: money.ROUND_DOWN, expected: 900_000},
+		{name: "Exact", amount: 2_000_000, from: "USD", to: "JPY", rounding: money.ROUND_DOWN, expected: 300_000_000},
+
+		// 1 EUR = 1.1111111... USD
+		{name: "Down", amount: 1_000_000, from: "EUR", to: "USD", rounding: money.ROUND_DOWN, expected: 1_111_111},
+		{name: "Up", amount: 1_000_000, from: "EUR", to: "USD", rounding: money.ROUND_UP, expected: 1_111_112},
+		{name: "Half up below half", amount: 1_000_000, from: "EUR", to: "USD", rounding: money.ROUND_HALF_UP, expected: 1_111_111},
+		{name: "Half even below half", amount: 1_000_000, from: "EUR", to: "USD", rounding: money.ROUND_HALF_EVEN, expected: 1_111_111},
+
+		// 1 EUR = 166.6666666... JPY, через базовую валюту
+		{name: "Cross rate down", amount: 1_000_000, from: "EUR", to: "JPY", rounding: money.ROUND_DOWN, expected: 166_666_666},
+		{name: "Cross rate half even", amount: 1_000_000, from: "EUR", to: "JPY", rounding: money.ROUND_HALF_EVEN, expected: 166_666_667},
+
+		// Ровно половина micros
+		{name: "Half up on half", amount: 1, from: "USD", to: "GBP", rounding: money.ROUND_HALF_UP, expected: 1},
+		{name: "Half even on half to even", amount: 1, from: "USD", to: "GBP", rounding: money.ROUND_HALF_EVEN, expected: 0},
+		{name: "Half even on half from odd", amount: 3, from: "USD", to: "GBP", rounding: money.ROUND_HALF_EVEN, expected: 2},
+		{name: "Down on half", amount: 3, from: "USD", to: "GBP", rounding: money.ROUND_DOWN, expected: 1},
+		{name: "Up on half", amount: 3, from: "USD", to: "GBP", rounding: money.ROUND_UP, expected: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, err := rates.Convert(tt.amount, tt.from, tt.to, tt.rounding)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, converted)
+		})
+	}
+}
+
+func TestConvertUnknownCurrency(t *testing.T) {
+	rates := newTestRates(t, testRates)
+
+	_, err := rates.Convert(1_000_000, "USD", "RUB", money.ROUND_DOWN)
+	assert.ErrorContains(t, err, "unknown currency RUB")
+	_, err = rates.Convert(1_000_000, "rub", "USD", money.ROUND_DOWN)
+	assert.ErrorContains(t, err, "unknown currency RUB")
+
+	assert.True(t, rates.Has("usd"))
+	assert.True(t, rates.Has("JPY"))
+	assert.False(t, rates.Has("RUB"))
+
+	// Без таблицы курсов работает только конвертация в ту же валюту
+	var noRates *Rates
+	converted, err := noRates.Convert(1_000_000, "", "usd", money.ROUND_DOWN)
+	require.NoError(t, err)
+	assert.Equal(t, money.Micros(1_000_000), converted)
+	_, err = noRates.Convert(1_000_000, "USD", "EUR", money.ROUND_DOWN)
+	assert.Error(t, err)
+	assert.True(t, noRates.Has(""))
+	assert.False(t, noRates.Has("EUR"))
+}
+
+func TestParseRateTable(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "Zero rate", data: `{"base": "USD", "rates": {"EUR": 0}}`, err: "rate for EUR"},
+		{name: "Rate rounds to zero", data: `{"base": "USD", "rates": {"EUR": 0.0000001}}`, err: "rate for EUR"},
+		{name: "Negative rate", data: `{"base": "USD", "rates": {"EUR": -1}}`, err: "rate for EUR"},
+		{name: "Base rate is not one", data: `{"base": "USD", "rates": {"usd": 2}}`, err: "base currency USD"},
+		{name: "Broken json", data: `{"base": `, err: "unexpected end of JSON input"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRateTable([]byte(tt.data))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// Базовая валюта по умолчанию USD и добавляется в таблицу с курсом 1
+	table, err := parseRateTable([]byte(`{"rates": {"eur": 0.9}}`))
+	require.NoError(t, err)
+	assert.Equal(t, DEFAULT_CURRENCY, table.Base)
+	assert.Equal(t, map[string]money.Ratio{"USD": money.RATIO_ONE, "EUR": 900_000}, table.Rates)
+}
+
+func TestWatchReloadsRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeRates(t, path, testRates, modTime)
+	rates, err := NewRates(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		rates.Watch(ctx, time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Eventually вызывает условие не из горутины теста, поэтому без require
+	toEUR := func() money.Micros {
+		converted, _ := rates.Convert(1_000_000, "USD", "EUR", money.ROUND_DOWN)
+		return converted
+	}
+
+	writeRates(t, path, `{"base": "USD", "rates": {"EUR": 0.8}}`, modTime.Add(time.Minute))
+	assert.Eventually(t, func() bool { return toEUR() == 800_000 }, time.Second, time.Millisecond)
+
+	// Битый файл не заменяет загруженные курсы
+	writeRates(t, path, `{"base": "USD", "rates": {"EUR": 0}}`, modTime.Add(2*time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, money.Micros(800_000), toEUR())
+	assert.False(t, rates.Has("JPY"))
+}
@@ -0,0 +1,140 @@
+package deals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filewatch"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Registry - реестр сделок биржи. Чтение lock-free, файл перечитывается при изменении.
+// nil *Registry означает, что сделки не настроены: ни одна сделка не допускается.
+type Registry struct {
+	path    string
+	rates   *currency.Rates
+	deals   atomic.Pointer[map[string]*Deal]
+	modTime atomic.Int64
+}
+
+func NewRegistry(path string, rates *currency.Rates) (*Registry, error) {
+	r := &Registry{
+		path:  path,
+		rates: rates,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Registry) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	var config DealConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("invalid deals file %s: %v", r.path, err)
+	}
+	deals, err := compileDeals(config)
+	if err != nil {
+		return fmt.Errorf("deals validation failed: %v", err)
+	}
+
+	r.deals.Store(&deals)
+	r.modTime.Store(info.ModTime().UnixNano())
+	return nil
+}
+
+// Watch перечитывает файл сделок при изменении времени модификации
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	filewatch.Watch(ctx, r.path, interval, &r.modTime, "deals", r.Reload)
+}
+
+func (r *Registry) Get(id string) (*Deal, bool) {
+	if r == nil || id == "" {
+		return nil, false
+	}
+	deal, ok := (*r.deals.Load())[id]
+	return deal, ok
+}
+
+// Allowed возвращает сделку, если она есть в реестре и DSP к ней допущена биржей.
+// Места покупателей (wseat) проверяются уже по ответу DSP.
+func (r *Registry) Allowed(id string, dsp string) (*Deal, bool) {
+	deal, ok := r.Get(id)
+	if !ok || !deal.AllowsDsp(dsp) {
+		return nil, false
+	}
+	return deal, true
+}
+
+// Floor - флор сделки в валюте cur: максимум из флора реестра и флора сделки от SSP.
+// Округление вверх не даёт флору опуститься при конвертации.
+func (r *Registry) Floor(deal *Deal, sspFloor money.Micros, sspFloorCur string, cur string) (money.Micros, error) {
+	floor, err := r.rates.Convert(deal.floor, deal.Cur, cur, money.ROUND_UP)
+	if err != nil {
+		return 0, err
+	}
+	if sspFloor <= 0 {
+		return floor, nil
+	}
+
+	converted, err := r.rates.Convert(sspFloor, sspFloorCur, cur, money.ROUND_UP)
+	if err != nil {
+		return 0, err
+	}
+	return max(floor, converted), nil
+}
+
+func compileDeals(config DealConfig) (map[string]*Deal, error) {
+	deals := make(map[string]*Deal, len(config.Deals))
+	for i := range config.Deals {
+		deal := config.Deals[i]
+		deal.ID = strings.TrimSpace(deal.ID)
+		if deal.ID == "" {
+			return nil, fmt.Errorf("deal #%d has no id", i)
+		}
+		if _, ok := deals[deal.ID]; ok {
+			return nil, fmt.Errorf("duplicate deal id %s", deal.ID)
+		}
+		if deal.Floor < 0 {
+			return nil, fmt.Errorf("deal %s: floor must not be negative", deal.ID)
+		}
+
+		switch deal.At {
+		case 0:
+			deal.At = AUCTION_TYPE_FIRST_PRICE
+		case AUCTION_TYPE_FIRST_PRICE, AUCTION_TYPE_SECOND_PRICE:
+		case AUCTION_TYPE_FIXED_PRICE:
+			if deal.Floor <= 0 {
+				return nil, fmt.Errorf("deal %s: fixed price deal needs a floor", deal.ID)
+			}
+		default:
+			return nil, fmt.Errorf("deal %s: unknown auction type %d", deal.ID, deal.At)
+		}
+
+		deal.Cur = currency.Normalize(deal.Cur)
+		deal.floor = money.FromFloat64(deal.Floor)
+		deal.seats = make(map[string]struct{}, len(deal.Seats))
+		for _, seat := range deal.Seats {
+			deal.seats[seat] = struct{}{}
+		}
+
+		deals[deal.ID] = &deal
+	}
+	return deals, nil
+}
@@ -0,0 +1,113 @@
+package deals
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+func newTestRegistry(t *testing.T, data string) (*Registry, string) {
+	path := filepath.Join(t.TempDir(), "deals.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	r, err := NewRegistry(path, nil)
+	require.NoError(t, err)
+	return r, path
+}
+
+func TestRegistryAllowed(t *testing.T) {
+	r, _ := newTestRegistry(t, `{
+		"version": "1.0",
+		"deals": [
+			{"id": "open", "floor": 1},
+			{"id": "dsp1_only", "floor": 2, "seats": ["dsp1"], "at": 3}
+		]
+	}`)
+
+	tests := []struct {
+		name    string
+		id      string
+		dsp     string
+		seat    string
+		wseat   []string
+		allowed bool
+	}{
+		{name: "Deal without seats", id: "open", dsp: "dsp2", allowed: true},
+		{name: "DSP allowed by exchange", id: "dsp1_only", dsp: "dsp1", allowed: true},
+		{name: "DSP not allowed by exchange", id: "dsp1_only", dsp: "dsp2"},
+		{name: "Seat not allowed by SSP", id: "open", dsp: "dsp2", seat: "seat2", wseat: []string{"seat1"}},
+		{name: "Seat allowed by SSP", id: "open", dsp: "dsp2", seat: "seat1", wseat: []string{"seat1"}, allowed: true},
+		{name: "DSP without seat is its own seat", id: "open", dsp: "dsp1", wseat: []string{"dsp1"}, allowed: true},
+		{name: "Unknown deal", id: "unknown", dsp: "dsp1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deal, ok := r.Get(tt.id)
+			assert.Equal(t, tt.allowed, ok && deal.AllowsSeat(tt.dsp, tt.seat, tt.wseat))
+		})
+	}
+
+	_, ok := r.Allowed("dsp1_only", "dsp2")
+	assert.False(t, ok, "router must not send deal to DSP not allowed by exchange")
+
+	deal, ok := r.Get("open")
+	require.True(t, ok)
+	assert.Equal(t, int32(AUCTION_TYPE_FIRST_PRICE), deal.At, "default auction type")
+	assert.Equal(t, "USD", deal.Cur)
+}
+
+func TestRegistryFloor(t *testing.T) {
+	r, _ := newTestRegistry(t, `{"deals": [{"id": "deal1", "floor": 1.5}]}`)
+	deal, ok := r.Get("deal1")
+	require.True(t, ok)
+
+	floor, err := r.Floor(deal, 0, "", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.Micros(1_500_000), floor)
+
+	floor, err = r.Floor(deal, 2_000_000, "usd", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.Micros(2_000_000), floor, "SSP floor is higher")
+
+	_, err = r.Floor(deal, 0, "", "EUR")
+	assert.Error(t, err, "no rates to convert")
+}
+
+func TestRegistryValidation(t *testing.T) {
+	for name, data := range map[string]string{
+		"Missing id":              `{"deals": [{"floor": 1}]}`,
+		"Duplicate id":            `{"deals": [{"id": "a"}, {"id": "a"}]}`,
+		"Negative floor":          `{"deals": [{"id": "a", "floor": -1}]}`,
+		"Unknown auction type":    `{"deals": [{"id": "a", "at": 5}]}`,
+		"Fixed price needs floor": `{"deals": [{"id": "a", "at": 3}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "deals.json")
+			require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+			_, err := NewRegistry(path, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistryReloadKeepsDealsOnError(t *testing.T) {
+	r, path := newTestRegistry(t, `{"deals": [{"id": "deal1"}]}`)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"deals": [{"id": ""}]}`), 0o644))
+	assert.Error(t, r.Reload())
+
+	_, ok := r.Get("deal1")
+	assert.True(t, ok)
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	_, ok := r.Allowed("deal1", "dsp1")
+	assert.False(t, ok)
+}
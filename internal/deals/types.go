@@ -0,0 +1,81 @@
+package deals
+
+import (
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Тип аукциона сделки, значения совпадают с deal.at из OpenRTB
+const (
+	AUCTION_TYPE_FIRST_PRICE  = 1
+	AUCTION_TYPE_SECOND_PRICE = 2
+	// Победитель платит флор сделки независимо от ставки
+	AUCTION_TYPE_FIXED_PRICE = 3
+)
+
+// Deal - условия приватной сделки, согласованные с биржей
+type Deal struct {
+	ID    string  `json:"id"`
+	Floor float64 `json:"floor"`
+	Cur   string  `json:"cur,omitempty"`
+	// Endpoint'ы DSP, допущенные к сделке; пусто - все DSP
+	Seats []string `json:"seats,omitempty"`
+	At    int32    `json:"at,omitempty"`
+
+	floor money.Micros
+	seats map[string]struct{}
+}
+
+type DealConfig struct {
+	Version string `json:"version"`
+	Deals   []Deal `json:"deals"`
+}
+
+// Report - итог сделки по импрессии для статистики
+type Report struct {
+	ImpID  string `json:"impId"`
+	DealID string `json:"dealId"`
+	// Все ставки по сделке и прошедшие проверку флора и мест
+	Bids      int  `json:"bids"`
+	ValidBids int  `json:"validBids"`
+	Won       bool `json:"won"`
+	// Цена DSP победившей ставки в валюте аукциона
+	Price *money.Micros `json:"priceMicros,omitempty"`
+	Cur   string        `json:"cur,omitempty"`
+}
+
+func (d *Deal) FloorMicros() money.Micros {
+	return d.floor
+}
+
+// AllowsDsp проверяет, что DSP допущена к сделке биржей
+func (d *Deal) AllowsDsp(dsp string) bool {
+	if len(d.seats) == 0 {
+		return true
+	}
+	_, ok := d.seats[dsp]
+	return ok
+}
+
+// AllowsSeat проверяет допуск DSP биржей и места покупателя SSP (wseat из запроса).
+// Если DSP не указала место, местом считается сама DSP.
+func (d *Deal) AllowsSeat(dsp string, seat string, wseat []string) bool {
+	if !d.AllowsDsp(dsp) {
+		return false
+	}
+	if len(wseat) == 0 {
+		return true
+	}
+	if seat == "" {
+		seat = dsp
+	}
+	for _, allowed := range wseat {
+		if allowed == seat {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Deal) IsFixedPrice() bool {
+	return d.At == AUCTION_TYPE_FIXED_PRICE
+}
@@ -0,0 +1,50 @@
+package filewatch
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Watch раз в interval сравнивает время модификации файла path с modTime -
+// UnixNano файла, загруженного последним, - и при отличии вызывает reload.
+// reload сам обновляет modTime; при ошибке остаются прежние данные.
+// what - что лежит в файле, для логов. Возвращается после отмены ctx.
+func Watch(
+	ctx context.Context,
+	path string,
+	interval time.Duration,
+	modTime *atomic.Int64,
+	what string,
+	reload func() error,
+) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				log.Printf("Cannot stat %s file %s: %v", what, path, err)
+				continue
+			}
+			if info.ModTime().UnixNano() == modTime.Load() {
+				continue
+			}
+			if err := reload(); err != nil {
+				log.Printf("Cannot reload %s: %v", what, err)
+				continue
+			}
+			log.Printf("%s reloaded from %s", what, path)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchReloadsOnModTimeChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o644))
+
+	var modTime atomic.Int64
+	var reloads atomic.Int64
+	fail := atomic.Bool{}
+	reload := func() error {
+		reloads.Add(1)
+		if fail.Load() {
+			return errors.New("broken file")
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTime.Store(info.ModTime().UnixNano())
+		return nil
+	}
+	require.NoError(t, reload())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, path, time.Millisecond, &modTime, "test data", reload)
+		close(done)
+	}()
+
+	// Файл не менялся - перечитывать нечего
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(1), reloads.Load())
+
+	touch := func(at time.Time) {
+		require.NoError(t, os.Chtimes(path, at, at))
+	}
+	touch(time.Now().Add(time.Hour))
+	assert.Eventually(t, func() bool { return reloads.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Неудачная загрузка не обновляет modTime, поэтому повторяется на следующем тике
+	fail.Store(true)
+	touch(time.Now().Add(2 * time.Hour))
+	assert.Eventually(t, func() bool { return reloads.Load() >= 4 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not stop after ctx cancel")
+	}
+}
@@ -0,0 +1,377 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// Коды причин отказа OpenRTB для ставок, нарушивших запреты
+const (
+	BlockReasonAdvertiser int32 = 205
+	BlockReasonAppBundle  int32 = 206
+	BlockReasonCategory   int32 = 208
+)
+
+// Blocklist - запреты SSP, которые действуют на все её запросы вместе с bcat,
+// badv и bapp самого запроса. Категория запрещает и свои подкатегории (IAB1 -
+// IAB1-2), домен - свои поддомены. Списки из file дополняют заданные в правилах
+// и перечитываются RuleManager.ReloadBlocklists.
+type Blocklist struct {
+	Bcat []string `json:"bcat,omitempty"`
+	Badv []string `json:"badv,omitempty"`
+	Bapp []string `json:"bapp,omitempty"`
+	File string   `json:"file,omitempty"`
+}
+
+// BlocklistStatus - загруженный blocklist SPP
+type BlocklistStatus struct {
+	SPP      string    `json:"spp"`
+	File     string    `json:"file,omitempty"`
+	Bcat     int       `json:"bcat"`
+	Badv     int       `json:"badv"`
+	Bapp     int       `json:"bapp"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+func validateBlocklist(blocklist *Blocklist) error {
+	for name, list := range map[string][]string{"bcat": blocklist.Bcat, "badv": blocklist.Badv, "bapp": blocklist.Bapp} {
+		for _, value := range list {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("blocklist %s contains an empty value", name)
+			}
+		}
+	}
+	return nil
+}
+
+// blockSet - blocklist SPP вместе со списками из файла. Значения в нижнем регистре.
+type blockSet struct {
+	cat      map[string]struct{}
+	adv      map[string]struct{}
+	app      map[string]struct{}
+	loadedAt time.Time
+}
+
+// loadBlockSet собирает blocklist; файл читается при каждом вызове
+func loadBlockSet(blocklist *Blocklist) (*blockSet, error) {
+	lists := []*Blocklist{blocklist}
+	if blocklist.File != "" {
+		data, err := os.ReadFile(blocklist.File)
+		if err != nil {
+			return nil, fmt.Errorf("read blocklist file: %w", err)
+		}
+		var fromFile Blocklist
+		if err := json.Unmarshal(data, &fromFile); err != nil {
+			return nil, fmt.Errorf("parse blocklist file %s: %w", blocklist.File, err)
+		}
+		if fromFile.File != "" {
+			return nil, fmt.Errorf("blocklist file %s cannot reference another file", blocklist.File)
+		}
+		if err := validateBlocklist(&fromFile); err != nil {
+			return nil, fmt.Errorf("blocklist file %s: %w", blocklist.File, err)
+		}
+		lists = append(lists, &fromFile)
+	}
+
+	set := &blockSet{
+		cat:      make(map[string]struct{}),
+		adv:      make(map[string]struct{}),
+		app:      make(map[string]struct{}),
+		loadedAt: time.Now().UTC(),
+	}
+	for _, list := range lists {
+		for _, cat := range list.Bcat {
+			set.cat[strings.ToLower(strings.TrimSpace(cat))] = struct{}{}
+		}
+		for _, domain := range list.Badv {
+			set.adv[normalizeDomain(domain)] = struct{}{}
+		}
+		for _, bundle := range list.Bapp {
+			set.app[strings.ToLower(strings.TrimSpace(bundle))] = struct{}{}
+		}
+	}
+	return set, nil
+}
+
+// setBlocklist загружает blocklist набора правил SPP
+func (rs *CompiledRuleSet) setBlocklist(blocklist *Blocklist) error {
+	rs.blocklist = blocklist
+	if blocklist == nil {
+		return nil
+	}
+	set, err := loadBlockSet(blocklist)
+	if err != nil {
+		return err
+	}
+	rs.blocks.Store(set)
+	return nil
+}
+
+func (rs *CompiledRuleSet) blocklistStatus(sppID string) BlocklistStatus {
+	status := BlocklistStatus{SPP: sppID, File: rs.blocklist.File}
+	if set := rs.blocks.Load(); set != nil {
+		status.Bcat = len(set.cat)
+		status.Badv = len(set.adv)
+		status.Bapp = len(set.app)
+		status.LoadedAt = set.loadedAt
+	}
+	return status
+}
+
+// ReloadBlocklists перечитывает файлы blocklist активных правил SPP. Если файл не
+// читается, у SPP остаётся прежний список; ошибки возвращаются все вместе.
+func (rm *RuleManager) ReloadBlocklists() ([]BlocklistStatus, error) {
+	_, sppRules := rm.ruleSets()
+
+	var errs []error
+	for sppID, ruleSet := range sppRules {
+		if ruleSet.blocklist == nil || ruleSet.blocklist.File == "" {
+			continue
+		}
+		set, err := loadBlockSet(ruleSet.blocklist)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SPP %s: %w", sppID, err))
+			continue
+		}
+		ruleSet.blocks.Store(set)
+	}
+
+	return blocklistStatuses(sppRules), errors.Join(errs...)
+}
+
+// Blocklists возвращает загруженные blocklist активных правил SPP
+func (rm *RuleManager) Blocklists() []BlocklistStatus {
+	_, sppRules := rm.ruleSets()
+	return blocklistStatuses(sppRules)
+}
+
+func blocklistStatuses(sppRules map[string]*CompiledRuleSet) []BlocklistStatus {
+	statuses := make([]BlocklistStatus, 0)
+	for sppID, ruleSet := range sppRules {
+		if ruleSet.blocklist != nil {
+			statuses = append(statuses, ruleSet.blocklistStatus(sppID))
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].SPP < statuses[j].SPP })
+	return statuses
+}
+
+// blockMatcher - запреты запроса вместе с запретами SPP
+type blockMatcher struct {
+	list []string
+	set  map[string]struct{}
+}
+
+func (m blockMatcher) has(value string) bool {
+	for _, blocked := range m.list {
+		if strings.EqualFold(strings.TrimSpace(blocked), value) {
+			return true
+		}
+	}
+	_, ok := m.set[strings.ToLower(value)]
+	return ok
+}
+
+func (m blockMatcher) empty() bool {
+	return len(m.list) == 0 && len(m.set) == 0
+}
+
+// hasDomain проверяет домен и все его родительские домены
+func (m blockMatcher) hasDomain(domain string) bool {
+	domain = normalizeDomain(domain)
+	for domain != "" {
+		if _, ok := m.set[domain]; ok || m.listHasDomain(domain) {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+// listHasDomain сравнивает с badv запроса, где домены могут быть записаны с www или схемой
+func (m blockMatcher) listHasDomain(domain string) bool {
+	for _, blocked := range m.list {
+		if normalizeDomain(blocked) == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCategory проверяет категорию и её родителя: IAB1-2 запрещена и через IAB1
+func (m blockMatcher) hasCategory(cat string) bool {
+	cat = strings.TrimSpace(cat)
+	if m.has(cat) {
+		return true
+	}
+	if dash := strings.IndexByte(cat, '-'); dash > 0 {
+		return m.has(cat[:dash])
+	}
+	return false
+}
+
+// normalizeDomain приводит adomain к виду example.com: без схемы, пути и www
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#:"); i >= 0 {
+		domain = domain[:i]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(domain, "www."), ".")
+}
+
+// bidBlocks - запреты, по которым проверяются ставки одного ответа
+type bidBlocks struct {
+	cat, adv, app blockMatcher
+}
+
+func (fp *OptimizedFilterProcessor) bidBlocks(sppURL string, bcat, badv, bapp []string) (bidBlocks, bool) {
+	blocks := bidBlocks{
+		cat: blockMatcher{list: bcat},
+		adv: blockMatcher{list: badv},
+		app: blockMatcher{list: bapp},
+	}
+	if ruleSet := fp.ruleManager.GetCompiledRulesForSPP(sppURL); ruleSet != nil {
+		if set := ruleSet.blocks.Load(); set != nil {
+			blocks.cat.set = set.cat
+			blocks.adv.set = set.adv
+			blocks.app.set = set.app
+		}
+	}
+	return blocks, !blocks.cat.empty() || !blocks.adv.empty() || !blocks.app.empty()
+}
+
+// reason возвращает причину отказа ставки, 0 - ставка ничего не нарушает
+func (b bidBlocks) reason(cats, adomains []string, bundle string) int32 {
+	for _, domain := range adomains {
+		if b.adv.hasDomain(domain) {
+			return BlockReasonAdvertiser
+		}
+	}
+	if bundle != "" && b.app.has(strings.TrimSpace(bundle)) {
+		return BlockReasonAppBundle
+	}
+	for _, cat := range cats {
+		if b.cat.hasCategory(cat) {
+			return BlockReasonCategory
+		}
+	}
+	return 0
+}
+
+// BlockedBidsV24 - ставки ответа DSP, убранные по одной причине
+type BlockedBidsV24 struct {
+	Reason   int32
+	Response *ortb_V2_4.BidResponse
+}
+
+// BlockedBidsV25 - то же, что BlockedBidsV24, для OpenRTB 2.5
+type BlockedBidsV25 struct {
+	Reason   int32
+	Response *ortb_V2_5.BidResponse
+}
+
+// BlockBidsV24 убирает из ответа ставки, нарушающие bcat, badv и bapp запроса
+// или blocklist SPP, и возвращает их отдельными ответами по причинам отказа.
+// nil - ни одна ставка не убрана.
+func (fp *OptimizedFilterProcessor) BlockBidsV24(
+	sppURL string,
+	req *ortb_V2_4.BidRequest,
+	resp *ortb_V2_4.BidResponse,
+) []BlockedBidsV24 {
+	blocks, ok := fp.bidBlocks(sppURL, req.GetBcat(), req.GetBadv(), req.GetBapp())
+	if !ok {
+		return nil
+	}
+
+	var blocked []BlockedBidsV24
+	// Номер seatbid ответа, для которого создан последний seatbid каждого blocked
+	var lastSeat []int
+	for s, seatBid := range resp.GetSeatbid() {
+		bids := seatBid.Bid[:0]
+		for _, bid := range seatBid.Bid {
+			reason := blocks.reason(bid.GetCat(), bid.GetAdomain(), bid.GetBundle())
+			if reason == 0 {
+				bids = append(bids, bid)
+				continue
+			}
+			i := slices.IndexFunc(blocked, func(b BlockedBidsV24) bool { return b.Reason == reason })
+			if i < 0 {
+				i = len(blocked)
+				blocked = append(blocked, BlockedBidsV24{
+					Reason:   reason,
+					Response: &ortb_V2_4.BidResponse{Id: resp.Id, Cur: resp.Cur},
+				})
+				lastSeat = append(lastSeat, -1)
+			}
+			response := blocked[i].Response
+			if lastSeat[i] != s {
+				response.Seatbid = append(response.Seatbid, &ortb_V2_4.SeatBid{Seat: seatBid.Seat, Group: seatBid.Group})
+				lastSeat[i] = s
+			}
+			blockedSeat := response.Seatbid[len(response.Seatbid)-1]
+			blockedSeat.Bid = append(blockedSeat.Bid, bid)
+		}
+		seatBid.Bid = bids
+	}
+	return blocked
+}
+
+// BlockBidsV25 - то же, что BlockBidsV24, для OpenRTB 2.5
+func (fp *OptimizedFilterProcessor) BlockBidsV25(
+	sppURL string,
+	req *ortb_V2_5.BidRequest,
+	resp *ortb_V2_5.BidResponse,
+) []BlockedBidsV25 {
+	blocks, ok := fp.bidBlocks(sppURL, req.GetBcat(), req.GetBadv(), req.GetBapp())
+	if !ok {
+		return nil
+	}
+
+	var blocked []BlockedBidsV25
+	// Номер seatbid ответа, для которого создан последний seatbid каждого blocked
+	var lastSeat []int
+	for s, seatBid := range resp.GetSeatbid() {
+		bids := seatBid.Bid[:0]
+		for _, bid := range seatBid.Bid {
+			reason := blocks.reason(bid.GetCat(), bid.GetAdomain(), bid.GetBundle())
+			if reason == 0 {
+				bids = append(bids, bid)
+				continue
+			}
+			i := slices.IndexFunc(blocked, func(b BlockedBidsV25) bool { return b.Reason == reason })
+			if i < 0 {
+				i = len(blocked)
+				blocked = append(blocked, BlockedBidsV25{
+					Reason:   reason,
+					Response: &ortb_V2_5.BidResponse{Id: resp.Id, Cur: resp.Cur},
+				})
+				lastSeat = append(lastSeat, -1)
+			}
+			response := blocked[i].Response
+			if lastSeat[i] != s {
+				response.Seatbid = append(response.Seatbid, &ortb_V2_5.SeatBid{Seat: seatBid.Seat, Group: seatBid.Group})
+				lastSeat[i] = s
+			}
+			blockedSeat := response.Seatbid[len(response.Seatbid)-1]
+			blockedSeat.Bid = append(blockedSeat.Bid, bid)
+		}
+		seatBid.Bid = bids
+	}
+	return blocked
+}
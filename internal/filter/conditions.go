@@ -1,9 +1,21 @@
 package filter
 
+import (
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
 type IntCondition struct {
 	values [2]int // использование array вместо slice
 	cond   ConditionType
 	// Убрать hasValues - оно не используется
+	// Для in/not_in
+	set map[int]struct{}
 }
 
 func (ic IntCondition) Type() ValueType { return ValueTypeInt }
@@ -30,6 +42,12 @@ func (ic IntCondition) Compare(fieldValue FieldValue) bool {
 		return fieldInt >= ic.values[0] && fieldInt <= ic.values[1]
 	case ConditionNotBetween:
 		return fieldInt < ic.values[0] || fieldInt > ic.values[1]
+	case ConditionIn:
+		_, ok := ic.set[fieldInt]
+		return ok
+	case ConditionNotIn:
+		_, ok := ic.set[fieldInt]
+		return !ok
 	case ConditionExists:
 		return true
 	default:
@@ -40,6 +58,10 @@ func (ic IntCondition) Compare(fieldValue FieldValue) bool {
 type StringCondition struct {
 	value string // одно значение вместо slice
 	cond  ConditionType
+	// Для in/not_in
+	set map[string]struct{}
+	// Для regex
+	re *regexp.Regexp
 }
 
 func (sc StringCondition) Type() ValueType { return ValueTypeString }
@@ -53,6 +75,20 @@ func (sc StringCondition) Compare(fieldValue FieldValue) bool {
 		return fieldValue.String == sc.value
 	case ConditionNotEqual:
 		return fieldValue.String != sc.value
+	case ConditionIn:
+		_, ok := sc.set[fieldValue.String]
+		return ok
+	case ConditionNotIn:
+		_, ok := sc.set[fieldValue.String]
+		return !ok
+	case ConditionPrefix:
+		return strings.HasPrefix(fieldValue.String, sc.value)
+	case ConditionSuffix:
+		return strings.HasSuffix(fieldValue.String, sc.value)
+	case ConditionContains:
+		return strings.Contains(fieldValue.String, sc.value)
+	case ConditionRegex:
+		return sc.re.MatchString(fieldValue.String)
 	case ConditionExists:
 		return fieldValue.String != ""
 	default:
@@ -61,8 +97,10 @@ func (sc StringCondition) Compare(fieldValue FieldValue) bool {
 }
 
 type FloatCondition struct {
-	values [2]float64 // использование array вместо slice
+	values [2]money.Micros // использование array вместо slice
 	cond   ConditionType
+	// Для in/not_in
+	set map[money.Micros]struct{}
 }
 
 func (fc FloatCondition) Type() ValueType { return ValueTypeFloat }
@@ -71,7 +109,7 @@ func (fc FloatCondition) Compare(fieldValue FieldValue) bool {
 		return false
 	}
 
-	fieldFloat := fieldValue.Float
+	fieldFloat := fieldValue.Micros
 	switch fc.cond {
 	case ConditionEqual:
 		return fieldFloat == fc.values[0]
@@ -89,9 +127,132 @@ func (fc FloatCondition) Compare(fieldValue FieldValue) bool {
 		return fieldFloat >= fc.values[0] && fieldFloat <= fc.values[1]
 	case ConditionNotBetween:
 		return fieldFloat < fc.values[0] || fieldFloat > fc.values[1]
+	case ConditionIn:
+		_, ok := fc.set[fieldFloat]
+		return ok
+	case ConditionNotIn:
+		_, ok := fc.set[fieldFloat]
+		return !ok
 	case ConditionExists:
 		return true
 	default:
 		return false
 	}
 }
+
+// CIDRCondition проверяет, входит ли IP в одну из подсетей. Подсети сгруппированы
+// по длине префикса: проверка - один поиск в map на каждую длину, а не на каждую подсеть.
+type CIDRCondition struct {
+	prefixes map[netip.Prefix]struct{}
+	// Различные длины префиксов из prefixes
+	bits []int
+}
+
+func (cc CIDRCondition) Type() ValueType { return ValueTypeString }
+func (cc CIDRCondition) Compare(fieldValue FieldValue) bool {
+	if fieldValue.Type != ValueTypeString {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(fieldValue.String)
+	if err != nil {
+		return false
+	}
+	// ::ffff:1.2.3.4 сравнивается с подсетями IPv4
+	addr = addr.Unmap().WithZone("")
+
+	for _, bits := range cc.bits {
+		// Подсеть другого семейства; ошибка Addr.Prefix аллоцирует, поэтому проверяем заранее
+		if bits > addr.BitLen() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if _, ok := cc.prefixes[prefix]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// negativeCondition сообщает, что условие выполняется при отсутствии значения:
+// для repeated полей такое условие должно выполниться на всех значениях
+func negativeCondition(condition ConditionType) bool {
+	switch condition {
+	case ConditionNotEqual, ConditionNotIn, ConditionNotBetween:
+		return true
+	default:
+		return false
+	}
+}
+
+// conditionSource записывает значение условия в формате SimpleRule.Value,
+// для exists и неизвестных условий - nil
+func conditionSource(value ConditionValue) json.RawMessage {
+	var source any
+	switch v := value.(type) {
+	case IntCondition:
+		switch v.cond {
+		case ConditionExists:
+			return nil
+		case ConditionBetween, ConditionNotBetween:
+			source = v.values
+		case ConditionIn, ConditionNotIn:
+			values := make([]int, 0, len(v.set))
+			for value := range v.set {
+				values = append(values, value)
+			}
+			sort.Ints(values)
+			source = values
+		default:
+			source = v.values[0]
+		}
+	case StringCondition:
+		switch v.cond {
+		case ConditionExists:
+			return nil
+		case ConditionIn, ConditionNotIn:
+			values := make([]string, 0, len(v.set))
+			for value := range v.set {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+			source = values
+		default:
+			source = v.value
+		}
+	case FloatCondition:
+		switch v.cond {
+		case ConditionExists:
+			return nil
+		case ConditionBetween, ConditionNotBetween:
+			source = [2]float64{v.values[0].Float64(), v.values[1].Float64()}
+		case ConditionIn, ConditionNotIn:
+			values := make([]float64, 0, len(v.set))
+			for value := range v.set {
+				values = append(values, value.Float64())
+			}
+			sort.Float64s(values)
+			source = values
+		default:
+			source = v.values[0].Float64()
+		}
+	case CIDRCondition:
+		values := make([]string, 0, len(v.prefixes))
+		for prefix := range v.prefixes {
+			values = append(values, prefix.String())
+		}
+		sort.Strings(values)
+		source = values
+	default:
+		return nil
+	}
+
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"fmt"
+	"time"
+	// Часовые пояса для clock встроены в бинарник: в образе может не быть zoneinfo
+	_ "time/tzdata"
+)
+
+// Clock задаёт, по какому времени проверяются поля time.*: exchange - UTC биржи,
+// user - местное время пользователя по device.geo.utcoffset, иначе имя часового
+// пояса IANA, например Europe/Moscow. Время пользователя известно только для
+// запросов; если смещение не задано, условие не выполняется.
+type Clock string
+
+const (
+	ClockExchange Clock = "exchange"
+	ClockUser     Clock = "user"
+)
+
+type timeField uint8
+
+const (
+	timeNone timeField = iota
+	timeHour
+	timeMinute
+	timeWeekday
+)
+
+var timeFields = map[FieldType]timeField{
+	FieldTimeHour:    timeHour,
+	FieldTimeMinute:  timeMinute,
+	FieldTimeWeekday: timeWeekday,
+}
+
+func isTimeField(field FieldType) bool {
+	_, ok := timeFields[field]
+	return ok
+}
+
+// ruleClock - скомпилированный Clock правила
+type ruleClock struct {
+	user     bool
+	location *time.Location
+}
+
+func parseClock(clock Clock) (ruleClock, error) {
+	switch clock {
+	case "", ClockExchange:
+		return ruleClock{location: time.UTC}, nil
+	case ClockUser:
+		return ruleClock{user: true}, nil
+	}
+	location, err := time.LoadLocation(string(clock))
+	if err != nil {
+		return ruleClock{}, fmt.Errorf("unknown clock %s: %w", clock, err)
+	}
+	return ruleClock{location: location}, nil
+}
+
+// source возвращает Clock, из которого получен ruleClock
+func (c ruleClock) source() Clock {
+	switch {
+	case c.user:
+		return ClockUser
+	case c.location == nil || c.location == time.UTC:
+		return ClockExchange
+	default:
+		return Clock(c.location.String())
+	}
+}
+
+// bindTime готовит правило по полю time.*
+func (r *FilterRule) bindTime(clock Clock) error {
+	r.timeField = timeFields[r.Field]
+	var err error
+	r.clock, err = parseClock(clock)
+	return err
+}
+
+// now возвращает время запроса, одно для всех условий дерева
+func (ctx *evalContext) now() time.Time {
+	if ctx.at.IsZero() {
+		ctx.at = ctx.clock()
+	}
+	return ctx.at
+}
+
+// timeValue возвращает значение поля time.* по часам правила
+func (ctx *evalContext) timeValue(r *FilterRule) FieldValue {
+	at := ctx.now()
+	if r.clock.user {
+		if ctx.impExtractor == nil {
+			return FieldValue{}
+		}
+		offset, ok := ctx.impExtractor.UserUTCOffset(ctx.data)
+		if !ok {
+			return FieldValue{}
+		}
+		// Сдвиг вместо time.FixedZone, чтобы не аллоцировать зону на каждый запрос
+		at = at.UTC().Add(time.Duration(offset) * time.Minute)
+	} else {
+		at = at.In(r.clock.location)
+	}
+
+	switch r.timeField {
+	case timeHour:
+		return NewIntValue(at.Hour())
+	case timeMinute:
+		return NewIntValue(at.Hour()*60 + at.Minute())
+	default:
+		weekday := int(at.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		return NewIntValue(weekday)
+	}
+}
@@ -0,0 +1,244 @@
+package filter
+
+import (
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// Explanation - разбор решения фильтра для одной DSP или SPP. Строится отдельным
+// обходом дерева без короткого замыкания, горячий путь Process* его не использует.
+type Explanation struct {
+	Target string `json:"target"`
+	// false - для DSP/SPP нет правил, проходит всё
+	HasRules bool         `json:"has_rules"`
+	Result   FilterResult `json:"result"`
+	// Авто-правила, которые применяются к ответам для любой SPP
+	AutoRules *RuleTrace `json:"auto_rules,omitempty"`
+	// Одно вычисление дерева, либо по одному на imp, если в дереве есть правила each
+	Evaluations []Evaluation `json:"evaluations,omitempty"`
+}
+
+type Evaluation struct {
+	// Индекс imp, для которого вычислялось дерево; nil - для всего запроса
+	Imp   *int      `json:"imp,omitempty"`
+	Rules RuleTrace `json:"rules"`
+}
+
+// RuleTrace - результат условия или группы условий
+type RuleTrace struct {
+	Op        string        `json:"op"`
+	ID        string        `json:"id,omitempty"`
+	Field     FieldType     `json:"field,omitempty"`
+	Condition ConditionType `json:"condition,omitempty"`
+	ImpMatch  ImpMatch      `json:"imp_match,omitempty"`
+	// Извлечённые значения поля: одно, либо по одному на imp для any/all
+	Values   []TracedValue `json:"values,omitempty"`
+	Passed   bool          `json:"passed"`
+	Children []RuleTrace   `json:"children,omitempty"`
+}
+
+type TracedValue struct {
+	Imp *int `json:"imp,omitempty"`
+	// nil - поля нет в запросе или ответе
+	Value  interface{} `json:"value"`
+	Passed bool        `json:"passed"`
+}
+
+var nodeOpNames = map[nodeOp]string{
+	nodeRule: "rule",
+	nodeAll:  "all",
+	nodeAny:  "any",
+	nodeNot:  "not",
+}
+
+// ExplainRequestForDSPV24 разбирает решение фильтра DSP для BidRequest v2.4
+func (fp *OptimizedFilterProcessor) ExplainRequestForDSPV24(dspURL, globalId string, req *ortb_V2_4.BidRequest) Explanation {
+	if req == nil {
+		return Explanation{Target: dspURL}
+	}
+	return fp.explainRequestForDSP(dspURL, globalId, fp.v24ReqExtractor, req)
+}
+
+// ExplainRequestForDSPV25 разбирает решение фильтра DSP для BidRequest v2.5
+func (fp *OptimizedFilterProcessor) ExplainRequestForDSPV25(dspURL, globalId string, req *ortb_V2_5.BidRequest) Explanation {
+	if req == nil {
+		return Explanation{Target: dspURL}
+	}
+	return fp.explainRequestForDSP(dspURL, globalId, fp.v25ReqExtractor, req)
+}
+
+// ExplainResponseForSPPV24 разбирает решение фильтра SPP для BidResponse v2.4
+func (fp *OptimizedFilterProcessor) ExplainResponseForSPPV24(sppURL string, resp *ortb_V2_4.BidResponse) Explanation {
+	if resp == nil {
+		return Explanation{Target: sppURL}
+	}
+	return fp.explainResponseForSPP(sppURL, fp.v24RespExtractor, resp)
+}
+
+// ExplainResponseForSPPV25 разбирает решение фильтра SPP для BidResponse v2.5
+func (fp *OptimizedFilterProcessor) ExplainResponseForSPPV25(sppURL string, resp *ortb_V2_5.BidResponse) Explanation {
+	if resp == nil {
+		return Explanation{Target: sppURL}
+	}
+	return fp.explainResponseForSPP(sppURL, fp.v25RespExtractor, resp)
+}
+
+// explainRequestForDSP повторяет обход и итог processRequestForDSPOptimized
+func (fp *OptimizedFilterProcessor) explainRequestForDSP(
+	dspURL string,
+	globalId string,
+	extractor BidRequestExtractor,
+	req interface{},
+) Explanation {
+	explanation := Explanation{Target: dspURL, Result: FilterResult{Allowed: true}}
+
+	ruleSet := fp.ruleManager.GetCompiledRulesForDSP(dspURL)
+	if ruleSet == nil || ruleSet.root == nil {
+		return explanation
+	}
+	explanation.HasRules = true
+
+	ctx := fp.requestContext(extractor, req, globalId)
+	if !ruleSet.root.perImp {
+		rules := ruleSet.root.explain(&ctx)
+		explanation.Result.Allowed = rules.Passed
+		explanation.Result.SampleRate = ctx.appliedSampleRate(rules.Passed)
+		explanation.Evaluations = []Evaluation{{Rules: rules}}
+		return explanation
+	}
+
+	impCount := ctx.imps()
+	selected := make([]int, 0, impCount)
+	explanation.Evaluations = make([]Evaluation, 0, impCount)
+	for i := 0; i < impCount; i++ {
+		ctx.imp = i
+		ctx.sampleRate = 0
+		imp := i
+		rules := ruleSet.root.explain(&ctx)
+		if rules.Passed {
+			selected = append(selected, i)
+			explanation.Result.SampleRate = minSampleRate(explanation.Result.SampleRate, ctx.sampleRate)
+		}
+		explanation.Evaluations = append(explanation.Evaluations, Evaluation{Imp: &imp, Rules: rules})
+	}
+
+	explanation.Result.Allowed = len(selected) > 0
+	if len(selected) > 0 && len(selected) < impCount {
+		explanation.Result.Imps = selected
+	}
+
+	return explanation
+}
+
+func (fp *OptimizedFilterProcessor) explainResponseForSPP(
+	sppURL string,
+	extractor BidResponseExtractor,
+	resp interface{},
+) Explanation {
+	explanation := Explanation{Target: sppURL}
+
+	ctx := newEvalContext(extractor, nil, resp, fp.now)
+	autoRules := autoRulesForSPP.root.explain(&ctx)
+	explanation.AutoRules = &autoRules
+	explanation.Result.Allowed = autoRules.Passed
+
+	ruleSet := fp.ruleManager.GetCompiledRulesForSPP(sppURL)
+	if ruleSet == nil || ruleSet.root == nil {
+		return explanation
+	}
+	explanation.HasRules = true
+
+	rules := ruleSet.root.explain(&ctx)
+	explanation.Result.Allowed = explanation.Result.Allowed && rules.Passed
+	explanation.Evaluations = []Evaluation{{Rules: rules}}
+
+	return explanation
+}
+
+// explain вычисляет все условия дерева, в отличие от eval, который останавливается
+// на первом решающем условии группы. Итог каждой группы совпадает с eval.
+// Счётчики статистики explain не трогает.
+func (n *ruleNode) explain(ctx *evalContext) RuleTrace {
+	if n.op == nodeRule {
+		return n.rule.explain(ctx)
+	}
+
+	trace := RuleTrace{
+		Op:       nodeOpNames[n.op],
+		Passed:   n.op != nodeAny,
+		Children: make([]RuleTrace, 0, len(n.children)),
+	}
+	// Как в eval: доля выборки any - из первой прошедшей ветки, not её не меняет
+	sampleRate, appliedRate := ctx.sampleRate, ctx.sampleRate
+	for _, child := range n.children {
+		childTrace := child.explain(ctx)
+		switch n.op {
+		case nodeAll:
+			trace.Passed = trace.Passed && childTrace.Passed
+		case nodeAny:
+			if childTrace.Passed && !trace.Passed {
+				appliedRate = ctx.sampleRate
+			}
+			trace.Passed = trace.Passed || childTrace.Passed
+			ctx.sampleRate = sampleRate
+		case nodeNot:
+			trace.Passed = !childTrace.Passed
+			ctx.sampleRate = sampleRate
+		}
+		trace.Children = append(trace.Children, childTrace)
+	}
+	if n.op == nodeAny {
+		ctx.sampleRate = appliedRate
+	}
+
+	return trace
+}
+
+func (r *FilterRule) explain(ctx *evalContext) RuleTrace {
+	trace := RuleTrace{
+		Op:        nodeOpNames[nodeRule],
+		ID:        r.ID,
+		Field:     r.Field,
+		Condition: r.Condition,
+		ImpMatch:  r.ImpMatch,
+		Passed:    r.match(ctx),
+	}
+
+	switch {
+	case r.timeField != timeNone:
+		trace.Values = []TracedValue{r.traceValue(ctx.timeValue(r), -1)}
+	case r.sample != nil:
+		trace.Values = []TracedValue{r.traceValue(ctx.sampleValue(r), -1)}
+	case ctx.impExtractor == nil || !r.impField:
+		trace.Values = []TracedValue{r.traceValue(ctx.extractor.ExtractFieldValue(r, ctx.data), -1)}
+	case r.ImpMatch == ImpMatchAny || r.ImpMatch == ImpMatchAll:
+		for i := 0; i < ctx.imps(); i++ {
+			trace.Values = append(trace.Values, r.traceValue(ctx.impExtractor.ExtractImpFieldValue(r, ctx.data, i), i))
+		}
+	case ctx.imp >= 0:
+		trace.Values = []TracedValue{r.traceValue(ctx.impExtractor.ExtractImpFieldValue(r, ctx.data, ctx.imp), ctx.imp)}
+	}
+
+	return trace
+}
+
+func (r *FilterRule) traceValue(value FieldValue, imp int) TracedValue {
+	traced := TracedValue{Value: value.explainValue(), Passed: r.Value.Compare(value)}
+	if imp >= 0 {
+		traced.Imp = &imp
+	}
+	return traced
+}
+
+func (v FieldValue) explainValue() interface{} {
+	switch v.Type {
+	case ValueTypeInt:
+		return v.Int
+	case ValueTypeFloat:
+		return v.Float
+	case ValueTypeString:
+		return v.String
+	default:
+		return nil
+	}
+}
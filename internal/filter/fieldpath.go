@@ -0,0 +1,467 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// Поле правила - путь через точку по сообщениям ORTB: device.geo.country,
+// imp.banner.w, user.ext.segments. Путь разрешается в цепочку дескрипторов
+// protobuf один раз при компиляции правила. При проверке запроса поля читаются
+// через reflect по индексам полей сгенерированных структур: protoreflect.Message
+// создаёт обёртку на каждое чтение repeated поля, и на
+// BenchmarkProcessRequestForDSPV25LargeLists это 5 аллокаций и втрое дольше.
+
+// ortbRoot - корневое сообщение, от которого разрешается путь поля
+type ortbRoot uint8
+
+const (
+	rootRequestV24 ortbRoot = iota
+	rootRequestV25
+	rootResponseV24
+	rootResponseV25
+	rootCount
+)
+
+var ortbRootMessages = [rootCount]protoreflect.ProtoMessage{
+	rootRequestV24:  &ortb_V2_4.BidRequest{},
+	rootRequestV25:  &ortb_V2_5.BidRequest{},
+	rootResponseV24: &ortb_V2_4.BidResponse{},
+	rootResponseV25: &ortb_V2_5.BidResponse{},
+}
+
+// Прежние короткие имена полей, которые не совпадают с путём в сообщении
+var fieldAliases = map[FieldType]string{
+	FieldBidFloor:     "imp.bidfloor",
+	FieldBannerWidth:  "imp.banner.w",
+	FieldBannerHeight: "imp.banner.h",
+	FieldBidPrice:     "seatbid.bid.price",
+	FieldBidID:        "seatbid.bid.id",
+	FieldBidAdID:      "seatbid.bid.adid",
+	FieldBidImpID:     "seatbid.bid.impid",
+	FieldBidNurl:      "seatbid.bid.nurl",
+	FieldBidBurl:      "seatbid.bid.burl",
+	FieldBidArray:     "seatbid.bid",
+	FieldBidCat:       "seatbid.bid.cat",
+}
+
+// fieldPathName возвращает путь поля в сообщении ORTB
+func fieldPathName(field FieldType) string {
+	if path, ok := fieldAliases[field]; ok {
+		return path
+	}
+	return string(field)
+}
+
+// IsImpField сообщает, что поле берётся из каждого imp запроса отдельно
+func IsImpField(field FieldType) bool {
+	name, rest, ok := strings.Cut(fieldPathName(field), ".")
+	return ok && rest != "" && strings.EqualFold(name, "imp")
+}
+
+type pathStep struct {
+	field protoreflect.FieldDescriptor
+	// Индекс поля в структуре сообщения
+	index int
+}
+
+// leafMode - как хранится последнее поле пути
+type leafMode uint8
+
+const (
+	leafValue leafMode = iota
+	leafPointer
+	leafList
+	leafMessage
+	leafMessageList
+)
+
+// fieldPath - путь поля, скомпилированный для одного корневого сообщения
+type fieldPath struct {
+	steps []pathStep
+	mode  leafMode
+	// Тип Go скалярного значения поля
+	scalar reflect.Kind
+	// Тип значения, которое возвращает путь
+	valueType ValueType
+	// Первый шаг пути - imp запроса
+	imp bool
+}
+
+// compileFieldPath разрешает путь от корневого сообщения. Имя поля можно
+// указать как в proto, как в JSON или без учёта регистра.
+func compileFieldPath(root ortbRoot, field FieldType) (*fieldPath, error) {
+	msg := ortbRootMessages[root]
+	desc := msg.ProtoReflect().Descriptor()
+	structType := reflect.TypeOf(msg).Elem()
+
+	names := strings.Split(fieldPathName(field), ".")
+	path := &fieldPath{steps: make([]pathStep, 0, len(names))}
+	var first protoreflect.Name
+	var leaf protoreflect.FieldDescriptor
+	var leafType reflect.Type
+	for i, name := range names {
+		if desc == nil {
+			return nil, fmt.Errorf("unknown field %s: %s is not a message", field, strings.Join(names[:i], "."))
+		}
+		fd := findFieldDescriptor(desc, name)
+		if fd == nil {
+			return nil, fmt.Errorf("unknown field %s: no %s in %s", field, name, desc.Name())
+		}
+		if fd.IsMap() || fd.Kind() == protoreflect.BytesKind {
+			return nil, fmt.Errorf("field %s: %s fields are not supported", field, fd.Kind())
+		}
+		structField, ok := structFieldByNumber(structType, fd.Number())
+		if !ok {
+			return nil, fmt.Errorf("field %s: no struct field for %s", field, fd.FullName())
+		}
+		if i == 0 {
+			first = fd.Name()
+		}
+		path.steps = append(path.steps, pathStep{field: fd, index: structField.Index[0]})
+		leaf, leafType = fd, structField.Type
+
+		desc = fd.Message()
+		if desc != nil {
+			goType := structField.Type
+			if fd.IsList() {
+				goType = goType.Elem()
+			}
+			structType = goType.Elem()
+		}
+	}
+
+	switch {
+	case leaf.Message() != nil && leaf.IsList():
+		path.mode = leafMessageList
+	case leaf.Message() != nil:
+		path.mode = leafMessage
+	case leafType.Kind() == reflect.Slice:
+		path.mode, path.scalar = leafList, leafType.Elem().Kind()
+	case leafType.Kind() == reflect.Ptr:
+		path.mode, path.scalar = leafPointer, leafType.Elem().Kind()
+	default:
+		path.mode, path.scalar = leafValue, leafType.Kind()
+	}
+	path.valueType = kindValueType(leaf.Kind())
+	path.imp = len(path.steps) > 1 && path.steps[0].field.IsList() && root <= rootRequestV25 && first == "imp"
+	return path, nil
+}
+
+func findFieldDescriptor(desc protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := desc.Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	if fd := fields.ByJSONName(name); fd != nil {
+		return fd
+	}
+	for i := 0; i < fields.Len(); i++ {
+		if strings.EqualFold(string(fields.Get(i).Name()), name) {
+			return fields.Get(i)
+		}
+	}
+	return nil
+}
+
+// structFieldByNumber находит поле структуры по номеру поля из тега protobuf
+func structFieldByNumber(structType reflect.Type, number protoreflect.FieldNumber) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		tag := strings.Split(structType.Field(i).Tag.Get("protobuf"), ",")
+		if len(tag) < 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(tag[1]); err == nil && protoreflect.FieldNumber(n) == number {
+			return structType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func kindValueType(kind protoreflect.Kind) ValueType {
+	switch kind {
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return ValueTypeFloat
+	case protoreflect.StringKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return ValueTypeString
+	default:
+		return ValueTypeInt
+	}
+}
+
+// value возвращает первое заданное значение поля: repeated сообщения на пути
+// просматриваются по порядку, у repeated поля берётся первый элемент,
+// для сообщения - "exists". Если поле не задано, возвращается ноль его типа.
+func (p *fieldPath) value(msg reflect.Value) FieldValue {
+	if value, ok := p.lookup(msg); ok {
+		return value
+	}
+	return p.zero()
+}
+
+// lookup возвращает значение поля и false, если поле не задано
+func (p *fieldPath) lookup(msg reflect.Value) (FieldValue, bool) {
+	if leaf, ok := p.find(msg, 0); ok {
+		return p.read(leaf), true
+	}
+	return FieldValue{}, false
+}
+
+// impValue возвращает значение поля из imp с индексом imp
+func (p *fieldPath) impValue(msg reflect.Value, imp int) FieldValue {
+	imps := msg.Elem().Field(p.steps[0].index)
+	if leaf, ok := p.find(imps.Index(imp), 1); ok {
+		return p.read(leaf)
+	}
+	return p.zero()
+}
+
+// stringList сообщает, что поле - repeated string, как site.cat или bid.adomain
+func (p *fieldPath) stringList() bool {
+	return p.mode == leafList && p.scalar == reflect.String
+}
+
+// match проверяет условие по всем значениям repeated string поля, включая
+// repeated сообщения на пути: для отрицающих условий (not_equal, not_in,
+// not_between) должны подойти все значения, для остальных - хотя бы одно. Если
+// значений нет, проверяется пустая строка.
+func (p *fieldPath) match(msg reflect.Value, cond ConditionValue, all bool) bool {
+	matched, found := p.matchEach(msg, 0, cond, all)
+	if !found {
+		return cond.Compare(p.zero())
+	}
+	return matched
+}
+
+// matchEach возвращает результат проверки и то, нашлось ли хотя бы одно значение
+func (p *fieldPath) matchEach(msg reflect.Value, step int, cond ConditionValue, all bool) (bool, bool) {
+	if !msg.IsValid() || msg.IsNil() {
+		return all, false
+	}
+	field := msg.Elem().Field(p.steps[step].index)
+	if step == len(p.steps)-1 {
+		for i := 0; i < field.Len(); i++ {
+			if cond.Compare(NewStringValue(field.Index(i).String())) != all {
+				return !all, true
+			}
+		}
+		return all, field.Len() > 0
+	}
+	if !p.steps[step].field.IsList() {
+		return p.matchEach(field, step+1, cond, all)
+	}
+	found := false
+	for i := 0; i < field.Len(); i++ {
+		matched, ok := p.matchEach(field.Index(i), step+1, cond, all)
+		if !ok {
+			continue
+		}
+		if matched != all {
+			return matched, true
+		}
+		found = true
+	}
+	return all, found
+}
+
+// find возвращает значение последнего поля пути
+func (p *fieldPath) find(msg reflect.Value, step int) (reflect.Value, bool) {
+	if !msg.IsValid() || msg.IsNil() {
+		return reflect.Value{}, false
+	}
+	field := msg.Elem().Field(p.steps[step].index)
+	if step == len(p.steps)-1 {
+		return p.leaf(field)
+	}
+	if !p.steps[step].field.IsList() {
+		return p.find(field, step+1)
+	}
+	for i := 0; i < field.Len(); i++ {
+		if leaf, ok := p.find(field.Index(i), step+1); ok {
+			return leaf, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func (p *fieldPath) leaf(field reflect.Value) (reflect.Value, bool) {
+	switch p.mode {
+	case leafMessage, leafMessageList:
+		return field, !field.IsNil()
+	case leafPointer:
+		return field.Elem(), !field.IsNil()
+	case leafList:
+		if field.Len() == 0 {
+			return reflect.Value{}, false
+		}
+		return field.Index(0), true
+	default:
+		return field, true
+	}
+}
+
+func (p *fieldPath) read(value reflect.Value) FieldValue {
+	if p.mode == leafMessage || p.mode == leafMessageList {
+		return NewStringValue("exists")
+	}
+	switch p.scalar {
+	case reflect.String:
+		return NewStringValue(value.String())
+	case reflect.Float32:
+		return NewMoneyValue(float32(value.Float()))
+	case reflect.Float64:
+		return NewFloatValue(value.Float())
+	case reflect.Bool:
+		if value.Bool() {
+			return NewIntValue(1)
+		}
+		return NewIntValue(0)
+	case reflect.Int32, reflect.Int64:
+		return NewIntValue(int(value.Int()))
+	case reflect.Uint32, reflect.Uint64:
+		return NewIntValue(int(value.Uint()))
+	default:
+		return FieldValue{}
+	}
+}
+
+func (p *fieldPath) zero() FieldValue {
+	switch p.valueType {
+	case ValueTypeFloat:
+		return NewFloatValue(0)
+	case ValueTypeString:
+		return NewStringValue("")
+	default:
+		return NewIntValue(0)
+	}
+}
+
+// bindPaths компилирует путь поля правила для всех версий ORTB. Правило
+// допустимо, если поле есть хотя бы в одном сообщении; в остальных оно пустое.
+func (r *FilterRule) bindPaths() error {
+	if isTimeField(r.Field) {
+		return r.bindTime(ClockExchange)
+	}
+	var firstErr error
+	bound := false
+	for root := ortbRoot(0); root < rootCount; root++ {
+		path, err := compileFieldPath(root, r.Field)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		r.paths[root] = path
+		bound = true
+		if path.imp {
+			r.impField = true
+		} else if path.stringList() {
+			r.listField = true
+		}
+	}
+	if !bound {
+		return firstErr
+	}
+	return nil
+}
+
+// fieldValueType возвращает тип значения поля в первом сообщении, где оно есть
+func fieldValueType(field FieldType) (ValueType, error) {
+	if isTimeField(field) {
+		return ValueTypeInt, nil
+	}
+	var firstErr error
+	for root := ortbRoot(0); root < rootCount; root++ {
+		path, err := compileFieldPath(root, field)
+		if err == nil {
+			return path.valueType, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
+
+// protoExtractor читает поля правил из запроса или ответа одной версии ORTB
+type protoExtractor struct {
+	root ortbRoot
+	// Тип сообщения: другой тип вернёт пустое значение
+	msgType reflect.Type
+	// Индекс поля imp в структуре запроса, -1 для ответов
+	impIndex int
+	// device.geo.utcoffset, nil для ответов
+	utcOffset *fieldPath
+}
+
+func newProtoExtractor(root ortbRoot) *protoExtractor {
+	e := &protoExtractor{root: root, msgType: reflect.TypeOf(ortbRootMessages[root]), impIndex: -1}
+	if root <= rootRequestV25 {
+		if path, err := compileFieldPath(root, "imp"); err == nil {
+			e.impIndex = path.steps[0].index
+		}
+		// Без поля в сообщении дневные окна по времени пользователя не действуют
+		e.utcOffset, _ = compileFieldPath(root, "device.geo.utcoffset")
+	}
+	return e
+}
+
+// message возвращает сообщение, пустое значение - пустое сообщение или чужой тип
+func (e *protoExtractor) message(data interface{}) reflect.Value {
+	msg := reflect.ValueOf(data)
+	if !msg.IsValid() || msg.Type() != e.msgType || msg.IsNil() {
+		return reflect.Value{}
+	}
+	return msg
+}
+
+func (e *protoExtractor) ExtractFieldValue(rule *FilterRule, data interface{}) FieldValue {
+	path := rule.paths[e.root]
+	if path == nil {
+		return FieldValue{}
+	}
+	return path.value(e.message(data))
+}
+
+// MatchFieldValues проверяет условие правила по всем значениям repeated поля
+func (e *protoExtractor) MatchFieldValues(rule *FilterRule, data interface{}) bool {
+	path := rule.paths[e.root]
+	if path == nil || !path.stringList() {
+		return rule.Value.Compare(e.ExtractFieldValue(rule, data))
+	}
+	return path.match(e.message(data), rule.Value, negativeCondition(rule.Condition))
+}
+
+func (e *protoExtractor) ImpCount(data interface{}) int {
+	msg := e.message(data)
+	if e.impIndex < 0 || !msg.IsValid() {
+		return 0
+	}
+	return msg.Elem().Field(e.impIndex).Len()
+}
+
+func (e *protoExtractor) ExtractImpFieldValue(rule *FilterRule, data interface{}, imp int) FieldValue {
+	path := rule.paths[e.root]
+	msg := e.message(data)
+	if path == nil || !path.imp || !msg.IsValid() {
+		return FieldValue{}
+	}
+	return path.impValue(msg, imp)
+}
+
+func (e *protoExtractor) UserUTCOffset(data interface{}) (int, bool) {
+	msg := e.message(data)
+	if e.utcOffset == nil || !msg.IsValid() {
+		return 0, false
+	}
+	value, ok := e.utcOffset.lookup(msg)
+	return value.Int, ok
+}
@@ -1,8 +1,16 @@
 package filter
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
@@ -73,7 +81,7 @@ func (suite *FilterTestSuite) TestDSPFilteringV24() {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := suite.createTestBidRequestV24(tt.country, tt.bidFloor, tt.appID, tt.bannerWidth, 90, tt.deviceIP)
-			result := suite.processor.ProcessRequestForDSPV24(tt.dspID, req)
+			result := suite.processor.ProcessRequestForDSPV24(tt.dspID, "", req)
 
 			assert.Equal(t, tt.expectedAllowed, result.Allowed,
 				"Test case: %s, Country: %s, BidFloor: %.2f, AppID: %s, BannerWidth: %d, DeviceIP: %s",
@@ -119,7 +127,7 @@ func (suite *FilterTestSuite) TestDSPFilteringV25() {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := suite.createTestBidRequestV25(tt.country, tt.bidFloor, tt.bannerWidth, 90, tt.deviceIP)
-			result := suite.processor.ProcessRequestForDSPV25(tt.dspID, req)
+			result := suite.processor.ProcessRequestForDSPV25(tt.dspID, "", req)
 
 			assert.Equal(t, tt.expectedAllowed, result.Allowed,
 				"Test case: %s, Country: %s, BidFloor: %.2f, BannerWidth: %d, DeviceIP: %s",
@@ -275,12 +283,12 @@ func (suite *FilterTestSuite) createTestBidRequestV25(country string, bidFloor f
 }
 
 func (suite *FilterTestSuite) createTestBidResponseV24(price float32, bidID, adID, impID string, hasBids bool) *ortb_V2_4.BidResponse {
-	var seatbid *ortb_V2_4.SeatBid
+	var seatbid []*ortb_V2_4.SeatBid
 	nurl := "http://example.com/win"
 	burl := "http://example.com/billing"
 
 	if hasBids {
-		seatbid = &ortb_V2_4.SeatBid{
+		seatbid = []*ortb_V2_4.SeatBid{{
 			Bid: []*ortb_V2_4.Bid{
 				{
 					Price: &price,
@@ -291,7 +299,7 @@ func (suite *FilterTestSuite) createTestBidResponseV24(price float32, bidID, adI
 					Burl:  &burl,
 				},
 			},
-		}
+		}}
 	}
 
 	return &ortb_V2_4.BidResponse{
@@ -300,12 +308,12 @@ func (suite *FilterTestSuite) createTestBidResponseV24(price float32, bidID, adI
 }
 
 func (suite *FilterTestSuite) createTestBidResponseV25(price float32, bidID, adID, impID string, hasBids bool) *ortb_V2_5.BidResponse {
-	var seatbid *ortb_V2_5.SeatBid
+	var seatbid []*ortb_V2_5.SeatBid
 	nurl := "http://example.com/win"
 	burl := "http://example.com/billing"
 
 	if hasBids {
-		seatbid = &ortb_V2_5.SeatBid{
+		seatbid = []*ortb_V2_5.SeatBid{{
 			Bid: []*ortb_V2_5.Bid{
 				{
 					Price: &price,
@@ -316,7 +324,7 @@ func (suite *FilterTestSuite) createTestBidResponseV25(price float32, bidID, adI
 					Burl:  &burl,
 				},
 			},
-		}
+		}}
 	}
 
 	return &ortb_V2_5.BidResponse{
@@ -393,7 +401,7 @@ func (suite *FilterTestSuite) TestSPPAutoRules() {
 
 // Вспомогательный метод для создания BidResponse с контролем nurl и burl
 func (suite *FilterTestSuite) createTestBidResponseWithNurlBurl(price float32, bidID, adID, impID string, hasBids, hasNurl, hasBurl bool) *ortb_V2_4.BidResponse {
-	var seatbid *ortb_V2_4.SeatBid
+	var seatbid []*ortb_V2_4.SeatBid
 
 	var nurlPtr, burlPtr *string
 	if hasNurl {
@@ -406,7 +414,7 @@ func (suite *FilterTestSuite) createTestBidResponseWithNurlBurl(price float32, b
 	}
 
 	if hasBids {
-		seatbid = &ortb_V2_4.SeatBid{
+		seatbid = []*ortb_V2_4.SeatBid{{
 			Bid: []*ortb_V2_4.Bid{
 				{
 					Price: &price,
@@ -417,10 +425,1316 @@ func (suite *FilterTestSuite) createTestBidResponseWithNurlBurl(price float32, b
 					Burl:  burlPtr,
 				},
 			},
-		}
+		}}
 	}
 
 	return &ortb_V2_4.BidResponse{
 		Seatbid: seatbid,
 	}
 }
+
+func (suite *FilterTestSuite) TestFloatConditionComparesMicros() {
+	t := suite.T()
+
+	rule, err := parseSimpleRule(SimpleRule{
+		Field:     FieldBidPrice,
+		Condition: ConditionGreaterThan,
+		ValueType: ValueTypeFloat,
+		Value:     []byte("1.1"),
+	})
+	assert.NoError(t, err)
+
+	// float32(1.1) в float64 равно 1.100000023841858 и раньше проходило "greater_than 1.1"
+	assert.False(t, rule.Value.Compare(NewMoneyValue(1.1)))
+	assert.True(t, rule.Value.Compare(NewMoneyValue(1.100001)))
+}
+
+func (suite *FilterTestSuite) TestExtractorsReadAllSeatBids() {
+	t := suite.T()
+
+	seat := "seat2"
+	price := float32(1.5)
+	resp := &ortb_V2_5.BidResponse{
+		Seatbid: []*ortb_V2_5.SeatBid{
+			{},
+			{Seat: &seat, Bid: []*ortb_V2_5.Bid{{Price: &price}}},
+		},
+	}
+
+	extractor := newProtoExtractor(rootResponseV25)
+	seatRule := &FilterRule{Field: FieldSeatBidSeat}
+	priceRule := &FilterRule{Field: FieldBidPrice}
+	assert.NoError(t, seatRule.bindPaths())
+	assert.NoError(t, priceRule.bindPaths())
+	assert.Equal(t, NewStringValue("seat2"), extractor.ExtractFieldValue(seatRule, resp))
+	assert.Equal(t, NewMoneyValue(1.5), extractor.ExtractFieldValue(priceRule, resp))
+}
+
+func (suite *FilterTestSuite) TestFieldPaths() {
+	t := suite.T()
+
+	w := int32(300)
+	appID := "app-1"
+	req := &ortb_V2_5.BidRequest{
+		Imp:  []*ortb_V2_5.Imp{{}, {Banner: &ortb_V2_5.Banner{W: &w}}},
+		App:  &ortb_V2_5.App{Id: &appID},
+		User: &ortb_V2_5.User{Ext: &ortb_V2_5.UserExt{Segments: []string{"sports", "auto"}}},
+	}
+	extractor := newProtoExtractor(rootRequestV25)
+
+	tests := []struct {
+		field FieldType
+		want  FieldValue
+	}{
+		{"imp.banner.w", NewIntValue(300)},
+		{"imp.Banner.W", NewIntValue(300)},
+		{FieldBannerWidth, NewIntValue(300)},
+		{"imp.bidFloor", NewFloatValue(0)},
+		{FieldAppID, NewStringValue("app-1")},
+		{FieldSiteID, NewStringValue("")},
+		{"user.ext.segments", NewStringValue("sports")},
+		{"user.ext", NewStringValue("exists")},
+		{"device.geo.country", NewStringValue("")},
+	}
+	for _, tt := range tests {
+		rule := &FilterRule{Field: tt.field}
+		assert.NoError(t, rule.bindPaths(), tt.field)
+		assert.Equal(t, tt.want, extractor.ExtractFieldValue(rule, req), tt.field)
+	}
+
+	rule := &FilterRule{Field: "imp.banner.w"}
+	assert.NoError(t, rule.bindPaths())
+	assert.True(t, rule.impField)
+	assert.Equal(t, 2, extractor.ImpCount(req))
+	assert.Equal(t, NewIntValue(0), extractor.ExtractImpFieldValue(rule, req, 0))
+	assert.Equal(t, NewIntValue(300), extractor.ExtractImpFieldValue(rule, req, 1))
+
+	// Поле из одной версии ORTB: в другой версии значение пустое
+	rule = &FilterRule{Field: "seatbid.seat"}
+	assert.NoError(t, rule.bindPaths())
+	assert.Equal(t, FieldValue{}, extractor.ExtractFieldValue(rule, req))
+
+	// Пустое сообщение или сообщение другой версии читается как пустое
+	rule = &FilterRule{Field: "device.geo.country"}
+	assert.NoError(t, rule.bindPaths())
+	assert.Equal(t, NewStringValue(""), extractor.ExtractFieldValue(rule, nil))
+	assert.Equal(t, NewStringValue(""), extractor.ExtractFieldValue(rule, (*ortb_V2_5.BidRequest)(nil)))
+	assert.Equal(t, NewStringValue(""), extractor.ExtractFieldValue(rule, &ortb_V2_4.BidRequest{}))
+	assert.Equal(t, 0, extractor.ImpCount(&ortb_V2_4.BidRequest{Imp: []*ortb_V2_4.Imp{{}}}))
+
+	err := ValidateSimpleRule(SimpleRule{
+		Field:     "device.geo.planet",
+		Condition: ConditionEqual,
+		ValueType: ValueTypeString,
+		Value:     []byte(`"mars"`),
+	})
+	assert.ErrorContains(t, err, "unknown field")
+
+	err = ValidateSimpleRule(SimpleRule{
+		Field:     "imp.banner.w",
+		Condition: ConditionEqual,
+		ValueType: ValueTypeString,
+		Value:     []byte(`"300"`),
+	})
+	assert.ErrorContains(t, err, "value_type")
+
+	err = ValidateSimpleRule(SimpleRule{
+		Field:     "user.ext.segments",
+		Condition: ConditionIn,
+		ValueType: ValueTypeString,
+		Value:     []byte(`["sports"]`),
+	})
+	assert.NoError(t, err)
+}
+
+func (suite *FilterTestSuite) TestDSPFilteringPerImp() {
+	t := suite.T()
+
+	width := func(w int32) *ortb_V2_5.Imp {
+		return &ortb_V2_5.Imp{Banner: &ortb_V2_5.Banner{W: &w}}
+	}
+	req := &ortb_V2_5.BidRequest{
+		Imp: []*ortb_V2_5.Imp{width(200), width(300), width(728)},
+	}
+
+	tests := []struct {
+		name            string
+		impMatch        ImpMatch
+		expectedAllowed bool
+		expectedImps    []int
+	}{
+		{name: "each sends only matching imps", impMatch: "", expectedAllowed: true, expectedImps: []int{1, 2}},
+		{name: "any keeps all imps", impMatch: ImpMatchAny, expectedAllowed: true},
+		{name: "all rejects request", impMatch: ImpMatchAll, expectedAllowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseSimpleRule(SimpleRule{
+				Field:     FieldBannerWidth,
+				Condition: ConditionBetween,
+				ValueType: ValueTypeInt,
+				Value:     []byte("[300, 800]"),
+				ImpMatch:  tt.impMatch,
+			})
+			assert.NoError(t, err)
+			suite.ruleManager.SetDSPRules("dsp3", map[string]*FilterRule{rule.ID: rule})
+
+			result := suite.processor.ProcessRequestForDSPV25("dsp3", "", req)
+			assert.Equal(t, tt.expectedAllowed, result.Allowed)
+			assert.Equal(t, tt.expectedImps, result.Imps)
+		})
+	}
+
+	_, err := parseSimpleRule(SimpleRule{
+		Field:     FieldDeviceIP,
+		Condition: ConditionExists,
+		ValueType: ValueTypeString,
+		ImpMatch:  ImpMatchAll,
+	})
+	assert.Error(t, err, "imp_match is not allowed for request fields")
+}
+
+func (suite *FilterTestSuite) TestRicherConditions() {
+	t := suite.T()
+
+	tests := []struct {
+		name      string
+		field     FieldType
+		condition ConditionType
+		valueType ValueType
+		value     string
+		fieldVal  FieldValue
+		expected  bool
+	}{
+		{"string in", FieldAppID, ConditionIn, ValueTypeString, `["a", "b"]`, NewStringValue("b"), true},
+		{"string not in", FieldAppID, ConditionNotIn, ValueTypeString, `["a", "b"]`, NewStringValue("b"), false},
+		{"int in", FieldBannerWidth, ConditionIn, ValueTypeInt, `[300, 728]`, NewIntValue(728), true},
+		{"int not in", FieldBannerWidth, ConditionNotIn, ValueTypeInt, `[300, 728]`, NewIntValue(320), true},
+		{"float in", FieldBidFloor, ConditionIn, ValueTypeFloat, `[0.5, 1.1]`, NewMoneyValue(1.1), true},
+		{"prefix", FieldAppID, ConditionPrefix, ValueTypeString, `"com.game."`, NewStringValue("com.game.puzzle"), true},
+		{"suffix", FieldSiteID, ConditionSuffix, ValueTypeString, `".ru"`, NewStringValue("news.com"), false},
+		{"contains", FieldAppID, ConditionContains, ValueTypeString, `"game"`, NewStringValue("com.game.puzzle"), true},
+		{"regex", FieldAppID, ConditionRegex, ValueTypeString, `"^com\\.(game|news)\\."`, NewStringValue("com.news.daily"), true},
+		{"regex no match", FieldAppID, ConditionRegex, ValueTypeString, `"^com\\.(game|news)\\."`, NewStringValue("org.news.daily"), false},
+		{"cidr ipv4", FieldDeviceIP, ConditionCIDR, ValueTypeString, `["10.0.0.0/8", "192.168.1.0/24"]`, NewStringValue("192.168.1.77"), true},
+		{"cidr ipv4 outside", FieldDeviceIP, ConditionCIDR, ValueTypeString, `["10.0.0.0/8", "192.168.1.0/24"]`, NewStringValue("192.168.2.1"), false},
+		{"cidr ipv6", FieldDeviceIP, ConditionCIDR, ValueTypeString, `"2001:db8::/32"`, NewStringValue("2001:db8:1::1"), true},
+		{"cidr ipv4-mapped ipv6", FieldDeviceIP, ConditionCIDR, ValueTypeString, `["10.0.0.0/8"]`, NewStringValue("::ffff:10.1.2.3"), true},
+		{"cidr single address", FieldDeviceIP, ConditionCIDR, ValueTypeString, `["1.2.3.4"]`, NewStringValue("1.2.3.4"), true},
+		{"cidr invalid ip", FieldDeviceIP, ConditionCIDR, ValueTypeString, `["10.0.0.0/8"]`, NewStringValue("not-an-ip"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseSimpleRule(SimpleRule{
+				Field:     tt.field,
+				Condition: tt.condition,
+				ValueType: tt.valueType,
+				Value:     []byte(tt.value),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rule.Value.Compare(tt.fieldVal))
+		})
+	}
+}
+
+func (suite *FilterTestSuite) TestRicherConditionsValidation() {
+	t := suite.T()
+
+	invalid := []SimpleRule{
+		{Field: FieldAppID, Condition: ConditionIn, ValueType: ValueTypeString, Value: []byte(`[]`)},
+		{Field: FieldAppID, Condition: ConditionIn, ValueType: ValueTypeString, Value: []byte(`["a", ""]`)},
+		{Field: FieldAppID, Condition: ConditionRegex, ValueType: ValueTypeString, Value: []byte(`"(unclosed"`)},
+		{Field: FieldDeviceIP, Condition: ConditionCIDR, ValueType: ValueTypeString, Value: []byte(`["10.0.0.0/33"]`)},
+		{Field: FieldAppID, Condition: ConditionCIDR, ValueType: ValueTypeString, Value: []byte(`["10.0.0.0/8"]`)},
+		{Field: FieldBannerWidth, Condition: ConditionPrefix, ValueType: ValueTypeInt, Value: []byte(`3`)},
+		{Field: FieldAppID, Condition: ConditionBetween, ValueType: ValueTypeString, Value: []byte(`"a"`)},
+	}
+
+	for _, rule := range invalid {
+		assert.Error(t, ValidateSimpleRule(rule), "rule %s %s %s", rule.Field, rule.Condition, rule.Value)
+	}
+}
+
+// largeListRules - правила со списками на тысячи значений
+func largeListRules(tb testing.TB) map[string]*FilterRule {
+	countries := make([]string, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		countries = append(countries, fmt.Sprintf("C%04d", i))
+	}
+	widths := make([]int, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		widths = append(widths, 100+i)
+	}
+	subnets := make([]string, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		subnets = append(subnets, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+		subnets = append(subnets, fmt.Sprintf("2001:db8:%x::/48", i))
+	}
+
+	rules := make(map[string]*FilterRule)
+	for _, simpleRule := range []SimpleRule{
+		{Field: FieldDeviceCountry, Condition: ConditionNotIn, ValueType: ValueTypeString, Value: mustJSON(tb, countries)},
+		{Field: FieldBannerWidth, Condition: ConditionIn, ValueType: ValueTypeInt, Value: mustJSON(tb, widths)},
+		{Field: FieldDeviceIP, Condition: ConditionCIDR, ValueType: ValueTypeString, Value: mustJSON(tb, subnets)},
+		{Field: FieldBidFloor, Condition: ConditionGreaterThan, ValueType: ValueTypeFloat, Value: []byte("0.1")},
+	} {
+		rule, err := parseSimpleRule(simpleRule)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		rules[rule.ID] = rule
+	}
+	return rules
+}
+
+func mustJSON(tb testing.TB, value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return data
+}
+
+func largeListProcessor(tb testing.TB) *OptimizedFilterProcessor {
+	ruleManager := NewRuleManager()
+	ruleManager.SetDSPRules("dsp", largeListRules(tb))
+	return NewOptimizedFilterProcessor(ruleManager)
+}
+
+func largeListRequest() *ortb_V2_5.BidRequest {
+	country := "US"
+	ip := "10.3.231.17"
+	bidFloor := float32(0.5)
+	w, h := int32(728), int32(90)
+	return &ortb_V2_5.BidRequest{
+		Imp: []*ortb_V2_5.Imp{
+			{BidFloor: &bidFloor, Banner: &ortb_V2_5.Banner{W: &w, H: &h}},
+			{BidFloor: &bidFloor, Banner: &ortb_V2_5.Banner{W: &w, H: &h}},
+		},
+		Device: &ortb_V2_5.Device{Ip: &ip, Geo: &ortb_V2_5.Geo{Country: &country}},
+	}
+}
+
+func TestProcessRequestForDSPDoesNotAllocate(t *testing.T) {
+	processor := largeListProcessor(t)
+	req := largeListRequest()
+
+	assert.True(t, processor.ProcessRequestForDSPV25("dsp", "", req).Allowed)
+	allocs := testing.AllocsPerRun(100, func() {
+		processor.ProcessRequestForDSPV25("dsp", "", req)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkProcessRequestForDSPV25LargeLists(b *testing.B) {
+	processor := largeListProcessor(b)
+	req := largeListRequest()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !processor.ProcessRequestForDSPV25("dsp", "", req).Allowed {
+			b.Fatal("request must pass")
+		}
+	}
+}
+
+func loadTestRules(t *testing.T, dspRules string) *OptimizedFilterProcessor {
+	path := filepath.Join(t.TempDir(), "dsp.json")
+	assert.NoError(t, os.WriteFile(path, []byte(dspRules), 0o644))
+
+	ruleManager := NewRuleManager()
+	assert.NoError(t, NewFileRuleLoader(ruleManager, path, "").LoadDSPRules())
+	return NewOptimizedFilterProcessor(ruleManager)
+}
+
+func (suite *FilterTestSuite) TestRuleGroups() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"},
+				{"not": {"field": "app.id", "condition": "exists", "value_type": "string"}},
+				{"any": [
+					{"all": [
+						{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300},
+						{"field": "banner.h", "condition": "equal", "value_type": "int", "value": 250}
+					]},
+					{"all": [
+						{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 728},
+						{"field": "banner.h", "condition": "equal", "value_type": "int", "value": 90}
+					]}
+				]}
+			]}
+		}
+	}`)
+
+	imp := func(w, h int32) *ortb_V2_4.Imp {
+		return &ortb_V2_4.Imp{Banner: &ortb_V2_4.Banner{W: &w, H: &h}}
+	}
+	request := func(country, appID string, imps ...*ortb_V2_4.Imp) *ortb_V2_4.BidRequest {
+		req := &ortb_V2_4.BidRequest{
+			Imp:    imps,
+			Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}},
+		}
+		if appID != "" {
+			req.App = &ortb_V2_4.App{Id: &appID}
+		}
+		return req
+	}
+
+	result := processor.ProcessRequestForDSPV24("dsp", "", request("US", "", imp(300, 250), imp(320, 50), imp(728, 90)))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, []int{0, 2}, result.Imps)
+
+	result = processor.ProcessRequestForDSPV24("dsp", "", request("US", "", imp(728, 90)))
+	assert.True(t, result.Allowed)
+	assert.Nil(t, result.Imps)
+
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("US", "", imp(300, 90))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("US", "app1", imp(300, 250))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("CA", "", imp(300, 250))).Allowed)
+}
+
+func (suite *FilterTestSuite) TestDayparting() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"local": {"rules": [
+				{"field": "time.minute", "condition": "between", "value_type": "int", "value": [480, 1379], "clock": "user"},
+				{"field": "time.weekday", "condition": "between", "value_type": "int", "value": [1, 5], "clock": "user"}
+			]},
+			"exchange": {"rules": [
+				{"field": "time.hour", "condition": "greater_equal", "value_type": "int", "value": 8}
+			]},
+			"moscow": {"rules": [
+				{"field": "time.hour", "condition": "greater_equal", "value_type": "int", "value": 8, "clock": "Europe/Moscow"}
+			]}
+		}
+	}`)
+
+	// Пятница, 06:30 UTC
+	now := time.Date(2025, 1, 3, 6, 30, 0, 0, time.UTC)
+	processor.now = func() time.Time { return now }
+
+	request := func(utcOffset *int32) *ortb_V2_5.BidRequest {
+		return &ortb_V2_5.BidRequest{Device: &ortb_V2_5.Device{Geo: &ortb_V2_5.Geo{Utcoffset: utcOffset}}}
+	}
+	offset := func(minutes int32) *int32 { return &minutes }
+
+	// Москва, 09:30 пятницы
+	assert.True(t, processor.ProcessRequestForDSPV25("local", "", request(offset(180))).Allowed)
+	// Нью-Йорк, 01:30 пятницы
+	assert.False(t, processor.ProcessRequestForDSPV25("local", "", request(offset(-300))).Allowed)
+	// Местное время неизвестно
+	assert.False(t, processor.ProcessRequestForDSPV25("local", "", request(nil)).Allowed)
+
+	assert.False(t, processor.ProcessRequestForDSPV25("exchange", "", request(nil)).Allowed)
+	assert.True(t, processor.ProcessRequestForDSPV25("moscow", "", request(nil)).Allowed)
+
+	explanation := processor.ExplainRequestForDSPV25("local", "", request(offset(180)))
+	assert.Equal(t, 570, explanation.Evaluations[0].Rules.Children[0].Values[0].Value)
+
+	// Пятница, 22:00 UTC
+	now = time.Date(2025, 1, 3, 22, 0, 0, 0, time.UTC)
+	assert.True(t, processor.ProcessRequestForDSPV25("exchange", "", request(nil)).Allowed)
+	// Нью-Йорк, 17:00 пятницы
+	assert.True(t, processor.ProcessRequestForDSPV25("local", "", request(offset(-300))).Allowed)
+	// Окленд, 11:00 субботы
+	assert.False(t, processor.ProcessRequestForDSPV25("local", "", request(offset(780))).Allowed)
+
+	for _, rule := range []SimpleRule{
+		{Field: FieldTimeHour, Condition: ConditionEqual, ValueType: ValueTypeInt, Value: []byte("8"), Clock: "Mars/Olympus"},
+		{Field: FieldDeviceCountry, Condition: ConditionEqual, ValueType: ValueTypeString, Value: []byte(`"US"`), Clock: ClockUser},
+		{Field: FieldTimeHour, Condition: ConditionEqual, ValueType: ValueTypeString, Value: []byte(`"8"`)},
+	} {
+		assert.Error(t, ValidateSimpleRule(rule), rule.Field)
+	}
+}
+
+func (suite *FilterTestSuite) TestSampleRules() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"},
+				{"sample": {"rate": 0.25}}
+			]},
+			"global": {"rules": [
+				{"sample": {"rate": 0.25, "key": "global_id", "salt": "global"}}
+			]}
+		}
+	}`)
+
+	request := func(country, userID string) *ortb_V2_5.BidRequest {
+		req := &ortb_V2_5.BidRequest{Device: &ortb_V2_5.Device{Geo: &ortb_V2_5.Geo{Country: &country}}}
+		if userID != "" {
+			req.User = &ortb_V2_5.User{Id: &userID}
+		}
+		return req
+	}
+
+	sampled := 0
+	for i := 0; i < 4000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		result := processor.ProcessRequestForDSPV25("dsp", fmt.Sprintf("global-%d", i), request("US", userID))
+		// Пользователь в выборке независимо от globalId
+		again := processor.ProcessRequestForDSPV25("dsp", "other", request("US", userID))
+		assert.Equal(t, result.Allowed, again.Allowed, userID)
+		if result.Allowed {
+			sampled++
+			assert.Equal(t, 0.25, result.SampleRate)
+		}
+	}
+	assert.InDelta(t, 1000, sampled, 100)
+
+	// Без user.id выборка идёт по globalId, без обоих ключей запрос не проходит
+	for _, globalId := range []string{"global-1", "global-2", "global-3", "global-4"} {
+		assert.Equal(t, sampleHash("", globalId)%sampleBuckets < 2500,
+			processor.ProcessRequestForDSPV25("dsp", globalId, request("US", "")).Allowed, globalId)
+	}
+	assert.False(t, processor.ProcessRequestForDSPV25("dsp", "", request("US", "")).Allowed)
+
+	// Условия вычисляются вместе с выборкой
+	assert.False(t, processor.ProcessRequestForDSPV25("dsp", "", request("CA", "user-1")).Allowed)
+
+	explanation := processor.ExplainRequestForDSPV25("global", "global-7", request("US", ""))
+	bucket := int(sampleHash("global", "global-7") % sampleBuckets)
+	assert.Equal(t, bucket, explanation.Evaluations[0].Rules.Children[0].Values[0].Value)
+	assert.Equal(t, bucket < 2500, explanation.Result.Allowed)
+
+	// Доля выборки берётся из прошедшей ветки any, а не из всех проверенных.
+	// Imp прошли по разным выборкам: запрос считается по самой узкой, в любом порядке imp.
+	processor = loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"any": [
+					{"all": [
+						{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300},
+						{"sample": {"rate": 0.5}}
+					]},
+					{"all": [
+						{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 728},
+						{"sample": {"rate": 0.25}}
+					]}
+				]}
+			]}
+		}
+	}`)
+	userID := "user-0"
+	for i := 1; sampleHash("", userID)%sampleBuckets >= 2500; i++ {
+		userID = fmt.Sprintf("user-%d", i)
+	}
+	for _, widths := range [][]int32{{728}, {300, 728}, {728, 300}} {
+		req := request("US", userID)
+		for _, width := range widths {
+			req.Imp = append(req.Imp, &ortb_V2_5.Imp{Banner: &ortb_V2_5.Banner{W: &width}})
+		}
+		result := processor.ProcessRequestForDSPV25("dsp", "", req)
+		assert.True(t, result.Allowed, widths)
+		assert.Nil(t, result.Imps, widths)
+		assert.Equal(t, 0.25, result.SampleRate, widths)
+		assert.Equal(t, 0.25, processor.ExplainRequestForDSPV25("dsp", "", req).Result.SampleRate, widths)
+	}
+
+	var spp SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{"format": "2.0", "spps": {"spp": {"rules": [{"sample": {"rate": 0.5}}]}}}`), &spp))
+	assert.ErrorContains(t, ValidateSPPConfig(&spp), "sample")
+
+	for _, sample := range []SampleRule{{Rate: 0}, {Rate: 1.5}, {Rate: 0.5, Key: "device"}} {
+		assert.Error(t, ValidateSampleRule(sample))
+	}
+}
+
+func (suite *FilterTestSuite) TestCategoryRules() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"sports": {"rules": [
+				{"field": "site.cat", "condition": "in", "value_type": "string", "value": ["IAB17", "IAB17-12"]}
+			]},
+			"safe": {"rules": [
+				{"field": "site.cat", "condition": "not_in", "value_type": "string", "value": ["IAB25", "IAB26"]}
+			]}
+		}
+	}`)
+
+	request := func(cats ...string) *ortb_V2_5.BidRequest {
+		return &ortb_V2_5.BidRequest{Site: &ortb_V2_5.Site{Cat: cats}}
+	}
+
+	// Положительное условие - хотя бы одна категория, отрицающее - все
+	assert.True(t, processor.ProcessRequestForDSPV25("sports", "", request("IAB1", "IAB17-12")).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV25("sports", "", request("IAB1")).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV25("sports", "", request()).Allowed)
+	assert.True(t, processor.ProcessRequestForDSPV25("safe", "", request("IAB1", "IAB17")).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV25("safe", "", request("IAB1", "IAB26")).Allowed)
+	assert.True(t, processor.ProcessRequestForDSPV25("safe", "", request()).Allowed)
+
+	var config SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"format": "2.0",
+		"spps": {"spp": {"rules": [
+			{"field": "bid.cat", "condition": "not_equal", "value_type": "string", "value": "IAB7-3"}
+		]}}
+	}`), &config))
+	_, err := processor.ruleManager.UpdateRules(RuleSetSPP, &config, RuleChange{})
+	assert.NoError(t, err)
+
+	response := func(cats ...[]string) *ortb_V2_5.BidResponse {
+		url := "https://dsp/win"
+		seatBid := &ortb_V2_5.SeatBid{}
+		for _, cat := range cats {
+			seatBid.Bid = append(seatBid.Bid, &ortb_V2_5.Bid{Nurl: &url, Burl: &url, Cat: cat})
+		}
+		return &ortb_V2_5.BidResponse{Seatbid: []*ortb_V2_5.SeatBid{seatBid}}
+	}
+	assert.True(t, processor.ProcessResponseForSPPV25("spp", response([]string{"IAB7"}, []string{"IAB1"})).Allowed)
+	assert.False(t, processor.ProcessResponseForSPPV25("spp", response([]string{"IAB1"}, []string{"IAB2", "IAB7-3"})).Allowed)
+}
+
+func (suite *FilterTestSuite) TestGeoRules() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"california": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"},
+				{"field": "device.geo.region", "condition": "in", "value_type": "string", "value": ["CA", "NV"]},
+				{"field": "device.geo.city", "condition": "not_equal", "value_type": "string", "value": "Los Angeles"}
+			]}
+		}
+	}`)
+
+	geo := func(country, region, city string) *ortb_V2_4.Geo {
+		g := &ortb_V2_4.Geo{Country: &country}
+		if region != "" {
+			g.Region = &region
+		}
+		if city != "" {
+			g.City = &city
+		}
+		return g
+	}
+	request := func(g *ortb_V2_4.Geo) *ortb_V2_4.BidRequest {
+		return &ortb_V2_4.BidRequest{Device: &ortb_V2_4.Device{Geo: g}}
+	}
+
+	assert.True(t, processor.ProcessRequestForDSPV24("california", "", request(geo("US", "CA", "San Francisco"))).Allowed)
+	assert.True(t, processor.ProcessRequestForDSPV24("california", "", request(geo("US", "NV", ""))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("california", "", request(geo("US", "CA", "Los Angeles"))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("california", "", request(geo("US", "TX", "Austin"))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("california", "", request(geo("US", "", ""))).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("california", "", request(geo("CA", "ON", "Toronto"))).Allowed)
+}
+
+func (suite *FilterTestSuite) TestBidBlocklists() {
+	t := suite.T()
+
+	blocklistPath := filepath.Join(t.TempDir(), "blocklist.json")
+	assert.NoError(t, os.WriteFile(blocklistPath, []byte(`{"bapp": ["com.blocked.game"]}`), 0o644))
+
+	var config SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+		"format": "2.0",
+		"spps": {"spp": {"rules": [], "blocklist": {"bcat": ["IAB7"], "file": %q}}}
+	}`, blocklistPath)), &config))
+	ruleManager := NewRuleManager()
+	_, err := ruleManager.UpdateRules(RuleSetSPP, &config, RuleChange{})
+	assert.NoError(t, err)
+	processor := NewOptimizedFilterProcessor(ruleManager)
+
+	bid := func(id string, cat []string, adomain []string, bundle string) *ortb_V2_5.Bid {
+		return &ortb_V2_5.Bid{Id: &id, Cat: cat, Adomain: adomain, Bundle: &bundle}
+	}
+	response := func() *ortb_V2_5.BidResponse {
+		return &ortb_V2_5.BidResponse{Seatbid: []*ortb_V2_5.SeatBid{{Bid: []*ortb_V2_5.Bid{
+			bid("clean", []string{"IAB1"}, []string{"good.com"}, "com.good.app"),
+			bid("adv", nil, []string{"https://www.shop.bad.com/landing"}, ""),
+			bid("cat", []string{"IAB7-3"}, nil, ""),
+			bid("app", nil, nil, "com.blocked.game"),
+			bid("reqcat", []string{"iab25-1"}, nil, ""),
+		}}}}
+	}
+	request := &ortb_V2_5.BidRequest{Bcat: []string{"IAB25-1"}, Badv: []string{"bad.com"}}
+
+	resp := response()
+	blocked := processor.BlockBidsV25("spp", request, resp)
+	assert.Len(t, resp.Seatbid[0].Bid, 1)
+	assert.Equal(t, "clean", resp.Seatbid[0].Bid[0].GetId())
+
+	// Убранные ставки разложены по ответам с одной причиной отказа
+	assert.Len(t, blocked, 3)
+	reasons := make(map[string]int32)
+	for _, entry := range blocked {
+		assert.Len(t, entry.Response.Seatbid, 1)
+		for _, b := range entry.Response.Seatbid[0].Bid {
+			reasons[b.GetId()] = entry.Reason
+		}
+	}
+	assert.Equal(t, map[string]int32{
+		"adv":    BlockReasonAdvertiser,
+		"cat":    BlockReasonCategory,
+		"app":    BlockReasonAppBundle,
+		"reqcat": BlockReasonCategory,
+	}, reasons)
+
+	// Без запретов запроса и SPP ответ не меняется
+	resp = response()
+	assert.Nil(t, processor.BlockBidsV25("other", &ortb_V2_5.BidRequest{}, resp))
+	assert.Len(t, resp.Seatbid[0].Bid, 5)
+
+	// Файл перечитывается без обновления правил, битый файл оставляет прежний список
+	assert.NoError(t, os.WriteFile(blocklistPath, []byte(`{"bapp": ["com.good.app"]}`), 0o644))
+	statuses, err := ruleManager.ReloadBlocklists()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, statuses[0].Bapp)
+	resp = response()
+	processor.BlockBidsV25("spp", &ortb_V2_5.BidRequest{}, resp)
+	ids := []string{}
+	for _, b := range resp.Seatbid[0].Bid {
+		ids = append(ids, b.GetId())
+	}
+	assert.Equal(t, []string{"adv", "app", "reqcat"}, ids)
+
+	assert.NoError(t, os.WriteFile(blocklistPath, []byte(`{"bapp": [`), 0o644))
+	_, err = ruleManager.ReloadBlocklists()
+	assert.ErrorContains(t, err, "SPP spp")
+	resp = response()
+	processor.BlockBidsV25("spp", &ortb_V2_5.BidRequest{}, resp)
+	assert.Len(t, resp.Seatbid[0].Bid, 3)
+
+	// Blocklist сохраняется в правилах SPP, а файл проверяется при загрузке
+	assert.Equal(t, blocklistPath, ruleManager.Rules(RuleSetSPP).SPPs["spp"].Blocklist.File)
+	config.SPPs["spp"].Blocklist.File = filepath.Join(t.TempDir(), "missing.json")
+	_, err = ruleManager.UpdateRules(RuleSetSPP, &config, RuleChange{})
+	assert.ErrorContains(t, err, "blocklist")
+}
+
+func (suite *FilterTestSuite) TestRuleGroupsValidation() {
+	t := suite.T()
+
+	config := func(version, rules string) *SimpleRuleConfig {
+		var config SimpleRuleConfig
+		assert.NoError(t, json.Unmarshal([]byte(`{"version": "`+version+`", "dsps": {"dsp": {"rules": `+rules+`}}}`), &config))
+		return &config
+	}
+	width := `{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300}`
+
+	// Одинаковые условия допустимы только в дереве
+	assert.Error(t, ValidateDSPConfig(config("1.0", `[`+width+`, `+width+`]`)))
+	assert.NoError(t, ValidateDSPConfig(config("2.0", `[`+width+`, `+width+`]`)))
+
+	assert.Error(t, ValidateDSPConfig(config("1.0", `[{"not": `+width+`}]`)))
+	assert.Error(t, ValidateDSPConfig(config("2.0", `[{"any": []}]`)))
+	assert.Error(t, ValidateDSPConfig(config("2.0", `[{"all": [`+width+`], "field": "banner.h"}]`)))
+	assert.Error(t, ValidateDSPConfig(config("3.0", `[`+width+`]`)))
+}
+
+func (suite *FilterTestSuite) TestExplainRequestForDSP() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "in", "value_type": "string", "value": ["US", "CA"]},
+				{"any": [
+					{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300},
+					{"field": "bidfloor", "condition": "less_than", "value_type": "float", "value": 1.0, "imp_match": "all"}
+				]}
+			]}
+		}
+	}`)
+
+	country := "US"
+	w300, w728 := int32(300), int32(728)
+	floor := float32(2)
+	req := &ortb_V2_4.BidRequest{
+		Imp: []*ortb_V2_4.Imp{
+			{Banner: &ortb_V2_4.Banner{W: &w300}, BidFloor: &floor},
+			{Banner: &ortb_V2_4.Banner{W: &w728}},
+		},
+		Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}},
+	}
+
+	explanation := processor.ExplainRequestForDSPV24("dsp", "", req)
+	assert.True(t, explanation.HasRules)
+	assert.Equal(t, processor.ProcessRequestForDSPV24("dsp", "", req), explanation.Result)
+	assert.Equal(t, []int{0}, explanation.Result.Imps)
+	assert.Len(t, explanation.Evaluations, 2)
+
+	rejected := explanation.Evaluations[1]
+	assert.Equal(t, 1, *rejected.Imp)
+	assert.False(t, rejected.Rules.Passed)
+
+	countryRule := rejected.Rules.Children[0]
+	assert.Equal(t, FieldDeviceCountry, countryRule.Field)
+	assert.Equal(t, []TracedValue{{Value: "US", Passed: true}}, countryRule.Values)
+	assert.True(t, countryRule.Passed)
+
+	// В отличие от горячего пути, any вычисляет все условия
+	anyGroup := rejected.Rules.Children[1]
+	assert.Equal(t, "any", anyGroup.Op)
+	assert.Len(t, anyGroup.Children, 2)
+	assert.Equal(t, 728, anyGroup.Children[0].Values[0].Value)
+	assert.Equal(t, 1, *anyGroup.Children[0].Values[0].Imp)
+
+	floorRule := anyGroup.Children[1]
+	assert.Equal(t, ImpMatchAll, floorRule.ImpMatch)
+	assert.Len(t, floorRule.Values, 2)
+	assert.Equal(t, float64(2), floorRule.Values[0].Value)
+	assert.False(t, floorRule.Values[0].Passed)
+	assert.False(t, floorRule.Passed)
+
+	unknown := processor.ExplainRequestForDSPV24("unknown", "", req)
+	assert.False(t, unknown.HasRules)
+	assert.True(t, unknown.Result.Allowed)
+	assert.Empty(t, unknown.Evaluations)
+}
+
+func (suite *FilterTestSuite) TestExplainResponseForSPP() {
+	t := suite.T()
+
+	nurl, burl, price := "nurl", "burl", float32(0.5)
+	resp := &ortb_V2_5.BidResponse{
+		Seatbid: []*ortb_V2_5.SeatBid{{Bid: []*ortb_V2_5.Bid{{Nurl: &nurl, Burl: &burl, Price: &price}}}},
+	}
+
+	explanation := suite.processor.ExplainResponseForSPPV25("spp1", resp)
+	assert.Equal(t, suite.processor.ProcessResponseForSPPV25("spp1", resp), explanation.Result)
+	assert.False(t, explanation.Result.Allowed)
+	assert.True(t, explanation.AutoRules.Passed)
+	assert.True(t, explanation.HasRules)
+
+	priceRule := explanation.Evaluations[0].Rules.Children[0]
+	assert.Equal(t, FieldBidPrice, priceRule.Field)
+	assert.Equal(t, float64(price), priceRule.Values[0].Value)
+	assert.False(t, priceRule.Passed)
+}
+
+func (suite *FilterTestSuite) TestStats() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "1.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"},
+				{"field": "banner.w", "condition": "between", "value_type": "int", "value": [300, 728]}
+			]}
+		}
+	}`)
+	processor.ResetStats()
+
+	request := func(country string, w int32) *ortb_V2_4.BidRequest {
+		return &ortb_V2_4.BidRequest{
+			Imp:    []*ortb_V2_4.Imp{{Banner: &ortb_V2_4.Banner{W: &w}}},
+			Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}},
+		}
+	}
+	processor.ProcessRequestForDSPV24("dsp", "", request("US", 300))
+	processor.ProcessRequestForDSPV24("dsp", "", request("US", 100))
+	processor.ProcessRequestForDSPV24("dsp", "", request("CA", 300))
+	processor.ProcessRequestForDSPV24("unknown", "", request("CA", 300))
+	// Разбор решения не должен попадать в статистику
+	processor.ExplainRequestForDSPV24("dsp", "", request("CA", 100))
+
+	nurl, burl, price := "nurl", "burl", float32(1)
+	processor.ProcessResponseForSPPV24("spp", &ortb_V2_4.BidResponse{
+		Seatbid: []*ortb_V2_4.SeatBid{{Bid: []*ortb_V2_4.Bid{{Nurl: &nurl, Burl: &burl, Price: &price}}}},
+	})
+	processor.ProcessResponseForSPPV24("spp", &ortb_V2_4.BidResponse{
+		Seatbid: []*ortb_V2_4.SeatBid{{Bid: []*ortb_V2_4.Bid{{Burl: &burl, Price: &price}}}},
+	})
+
+	stats := processor.Stats()
+	assert.Equal(t, RuleSetStats{
+		Evaluated: 3,
+		Rejected:  2,
+		Rules: []RuleStats{
+			{ID: "device.geo.country_equal", Field: FieldDeviceCountry, Condition: ConditionEqual, Evaluated: 3, Rejected: 1},
+			{ID: "banner.w_between", Field: FieldBannerWidth, Condition: ConditionBetween, Evaluated: 2, Rejected: 1},
+		},
+	}, stats.DSPs["dsp"])
+	assert.NotContains(t, stats.DSPs, "unknown")
+
+	assert.Equal(t, uint64(2), stats.AutoRulesSPP.Evaluated)
+	assert.Equal(t, uint64(1), stats.AutoRulesSPP.Rejected)
+	assert.Equal(t, uint64(1), stats.AutoRulesSPP.Rules[0].Rejected)
+	assert.Equal(t, uint64(1), stats.AutoRulesSPP.Rules[1].Evaluated)
+
+	processor.ResetStats()
+	stats = processor.Stats()
+	assert.Zero(t, stats.DSPs["dsp"].Evaluated)
+	assert.Zero(t, stats.DSPs["dsp"].Rules[0].Evaluated)
+	assert.Zero(t, stats.AutoRulesSPP.Evaluated)
+}
+
+func (suite *FilterTestSuite) TestShadowRules() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "1.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"}
+			]}
+		}
+	}`)
+	ruleManager := processor.ruleManager
+
+	_, loaded := ruleManager.ShadowReport()
+	assert.False(t, loaded)
+	assert.Error(t, ruleManager.PromoteShadowRules(RuleChange{}))
+
+	var candidate SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"version": "1.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "in", "value_type": "string", "value": ["US", "CA"]},
+				{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300}
+			]}
+		}
+	}`), &candidate))
+	assert.NoError(t, ruleManager.SetShadowRules(&candidate))
+
+	request := func(country string, w int32) *ortb_V2_4.BidRequest {
+		return &ortb_V2_4.BidRequest{
+			Imp:    []*ortb_V2_4.Imp{{Banner: &ortb_V2_4.Banner{W: &w}}},
+			Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}},
+		}
+	}
+
+	// Решает только активный набор
+	assert.True(t, processor.ProcessRequestForDSPV24("dsp", "", request("US", 728)).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("CA", 300)).Allowed)
+	assert.True(t, processor.ProcessRequestForDSPV24("dsp", "", request("US", 300)).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("DE", 300)).Allowed)
+
+	report, loaded := ruleManager.ShadowReport()
+	assert.True(t, loaded)
+	diff := report.DSPs["dsp"]
+	assert.Equal(t, uint64(4), diff.Compared)
+	assert.Equal(t, uint64(1), diff.WouldBlock)
+	assert.Equal(t, uint64(1), diff.WouldAllow)
+	assert.Equal(t, []ShadowRuleDiff{
+		{ID: "device.geo.country_in", Field: FieldDeviceCountry, Condition: ConditionIn},
+		{ID: "banner.w_equal", Field: FieldBannerWidth, Condition: ConditionEqual, Diverged: 1},
+	}, diff.CandidateRules)
+	assert.Equal(t, []ShadowRuleDiff{
+		{ID: "device.geo.country_equal", Field: FieldDeviceCountry, Condition: ConditionEqual, Diverged: 1},
+	}, diff.ActiveRules)
+
+	processor.ResetStats()
+	report, _ = ruleManager.ShadowReport()
+	assert.Zero(t, report.DSPs["dsp"].Compared)
+	assert.Zero(t, report.DSPs["dsp"].ActiveRules[0].Diverged)
+
+	assert.NoError(t, ruleManager.PromoteShadowRules(RuleChange{}))
+	_, loaded = ruleManager.ShadowReport()
+	assert.False(t, loaded)
+	assert.True(t, processor.ProcessRequestForDSPV24("dsp", "", request("CA", 300)).Allowed)
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request("US", 728)).Allowed)
+}
+
+func (suite *FilterTestSuite) TestMergeReplicaStats() {
+	t := suite.T()
+
+	country := RuleStats{ID: "country", Field: FieldDeviceCountry, Condition: ConditionEqual}
+	width := RuleStats{ID: "width", Field: FieldBannerWidth, Condition: ConditionBetween}
+	withCounts := func(rule RuleStats, evaluated, rejected uint64) RuleStats {
+		rule.Evaluated, rule.Rejected = evaluated, rejected
+		return rule
+	}
+
+	merged := MergeFilterStats([]FilterStats{
+		{
+			DSPs: map[string]RuleSetStats{"dsp": {Evaluated: 3, Rejected: 1, Rules: []RuleStats{
+				withCounts(country, 3, 1),
+			}}},
+			AutoRulesSPP: RuleSetStats{Evaluated: 1, Rules: []RuleStats{}},
+		},
+		{
+			// Реплика уже на новой ревизии с добавленным условием
+			DSPs: map[string]RuleSetStats{"dsp": {Evaluated: 2, Rejected: 2, Rules: []RuleStats{
+				withCounts(country, 2, 1),
+				withCounts(width, 1, 1),
+			}}},
+			SPPs:         map[string]RuleSetStats{"spp": {Evaluated: 5, Rules: []RuleStats{}}},
+			AutoRulesSPP: RuleSetStats{Evaluated: 4, Rules: []RuleStats{}},
+		},
+	})
+
+	assert.Equal(t, 2, merged.Replicas)
+	assert.Equal(t, RuleSetStats{Evaluated: 5, Rejected: 3, Rules: []RuleStats{
+		withCounts(country, 5, 2),
+		withCounts(width, 1, 1),
+	}}, merged.DSPs["dsp"])
+	assert.Equal(t, uint64(5), merged.SPPs["spp"].Evaluated)
+	assert.Equal(t, uint64(5), merged.AutoRulesSPP.Evaluated)
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	diverged := func(diverged uint64) []ShadowRuleDiff {
+		return []ShadowRuleDiff{{ID: "country", Field: FieldDeviceCountry, Condition: ConditionIn, Diverged: diverged}}
+	}
+	report := MergeShadowReports([]ShadowReport{
+		{Since: since.Add(time.Hour), DSPs: map[string]ShadowDiff{"dsp": {Compared: 4, WouldBlock: 1, CandidateRules: diverged(1), ActiveRules: []ShadowRuleDiff{}}}},
+		{Since: since, DSPs: map[string]ShadowDiff{"dsp": {Compared: 6, WouldAllow: 2, CandidateRules: diverged(3), ActiveRules: []ShadowRuleDiff{}}}},
+	})
+
+	assert.Equal(t, 2, report.Replicas)
+	assert.Equal(t, since, report.Since)
+	assert.Equal(t, ShadowDiff{
+		Compared:       10,
+		WouldBlock:     1,
+		WouldAllow:     2,
+		CandidateRules: diverged(4),
+		ActiveRules:    []ShadowRuleDiff{},
+	}, report.DSPs["dsp"])
+}
+
+func (suite *FilterTestSuite) TestRuleVersions() {
+	t := suite.T()
+
+	processor := loadTestRules(t, `{
+		"version": "1.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"}
+			]}
+		}
+	}`)
+	ruleManager := processor.ruleManager
+	assert.Equal(t, uint64(1), ruleManager.Rules(RuleSetDSP).Version)
+
+	var update SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"version": 1,
+		"format": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"},
+				{"field": "banner.w", "condition": "equal", "value_type": "int", "value": 300}
+			]},
+			"other": {"rules": [
+				{"field": "app.id", "condition": "exists", "value_type": "string"}
+			]}
+		}
+	}`), &update))
+	revision, err := ruleManager.UpdateRules(RuleSetDSP, &update, RuleChange{Author: "alice", Comment: "banner size"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), revision)
+
+	// Конфиг, основанный на устаревших правилах, не применяется
+	_, err = ruleManager.UpdateRules(RuleSetDSP, &update, RuleChange{})
+	assert.ErrorIs(t, err, ErrStaleRevision)
+	update.Version = 3
+	_, err = ruleManager.UpdateRules(RuleSetDSP, &update, RuleChange{})
+	assert.ErrorIs(t, err, ErrStaleRevision)
+	assert.Equal(t, uint64(2), ruleManager.Revision(RuleSetDSP))
+
+	width, country := int32(728), "US"
+	request := &ortb_V2_4.BidRequest{
+		Imp:    []*ortb_V2_4.Imp{{Banner: &ortb_V2_4.Banner{W: &width}}},
+		Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}},
+	}
+	assert.False(t, processor.ProcessRequestForDSPV24("dsp", "", request).Allowed)
+
+	diff, err := ruleManager.DiffVersions(RuleSetDSP, 1, 2)
+	assert.NoError(t, err)
+	assert.Contains(t, diff.Added, "other")
+	assert.Empty(t, diff.Removed)
+	assert.Len(t, diff.Changed["dsp"].Added, 1)
+	assert.Equal(t, FieldBannerWidth, diff.Changed["dsp"].Added[0].Field)
+	assert.Empty(t, diff.Changed["dsp"].Removed)
+
+	_, err = ruleManager.DiffVersions(RuleSetDSP, 1, 4)
+	assert.ErrorIs(t, err, ErrUnknownRevision)
+
+	// Конфиг заменяет набор целиком: DSP, которой в нём нет, удаляется
+	delete(update.DSPs, "other")
+	update.Version = 2
+	revision, err = ruleManager.UpdateRules(RuleSetDSP, &update, RuleChange{Author: "alice", Comment: "drop other"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), revision)
+	assert.Nil(t, ruleManager.GetCompiledRulesForDSP("other"))
+	assert.NotNil(t, ruleManager.GetCompiledRulesForDSP("dsp"))
+	diff, err = ruleManager.DiffVersions(RuleSetDSP, 2, 3)
+	assert.NoError(t, err)
+	assert.Contains(t, diff.Removed, "other")
+
+	revision, err = ruleManager.Rollback(RuleSetDSP, 1, RuleChange{Author: "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), revision)
+	assert.True(t, processor.ProcessRequestForDSPV24("dsp", "", request).Allowed)
+	assert.Nil(t, ruleManager.GetCompiledRulesForDSP("other"))
+
+	versions := ruleManager.Versions(RuleSetDSP)
+	assert.Len(t, versions, 4)
+	assert.Equal(t, RuleChange{Author: "alice", Comment: "banner size"}, versions[1].RuleChange)
+	assert.Equal(t, uint64(4), versions[3].Revision)
+	assert.Equal(t, uint64(1), versions[3].RolledBackTo)
+
+	// История SPP ведётся отдельно
+	assert.Empty(t, ruleManager.Versions(RuleSetSPP))
+}
+
+func (suite *FilterTestSuite) TestRuleSourceFromCompiledRules() {
+	t := suite.T()
+
+	// Правила, заданные через SetDSPRules, восстанавливаются в конфиг со значениями
+	for _, data := range []string{
+		`{"field": "device.geo.country", "condition": "in", "value_type": "string", "value": ["US", "DE"]}`,
+		`{"field": "app.id", "condition": "regex", "value_type": "string", "value": "^com\\."}`,
+		`{"field": "app.id", "condition": "exists", "value_type": "string"}`,
+		`{"field": "banner.w", "condition": "between", "value_type": "int", "value": [300, 800], "imp_match": "any"}`,
+		`{"field": "banner.h", "condition": "not_in", "value_type": "int", "value": [90, 50]}`,
+		`{"field": "bidfloor", "condition": "greater_than", "value_type": "float", "value": 0.35}`,
+		`{"field": "bidfloor", "condition": "in", "value_type": "float", "value": [1.5, 0.25]}`,
+		`{"field": "device.ip", "condition": "cidr", "value_type": "string", "value": ["10.0.0.0/8", "192.168.1.1"]}`,
+		`{"field": "time.hour", "condition": "between", "value_type": "int", "value": [9, 18], "clock": "Europe/Berlin"}`,
+		`{"field": "time.weekday", "condition": "in", "value_type": "int", "value": [0, 6], "clock": "user"}`,
+		`{"sample": {"rate": 0.25, "salt": "dsp"}}`,
+	} {
+		var node RuleNode
+		assert.NoError(t, json.Unmarshal([]byte(data), &node), data)
+		root, err := parseRuleNodes([]RuleNode{node})
+		assert.NoError(t, err, data)
+		rules := root.leaves(nil)
+		assert.Len(t, rules, 1, data)
+
+		source := ruleSource(rules)
+		restored, err := parseRuleNodes(source)
+		assert.NoError(t, err, data)
+		assert.Equal(t, rules, restored.leaves(nil), data)
+	}
+
+	ruleManager := suite.ruleManager
+	rule, err := parseSimpleRule(SimpleRule{
+		Field:     FieldDeviceCountry,
+		Condition: ConditionEqual,
+		ValueType: ValueTypeString,
+		Value:     []byte(`"US"`),
+	})
+	assert.NoError(t, err)
+	ruleManager.SetDSPRules("dsp", map[string]*FilterRule{rule.ID: rule})
+	assert.JSONEq(t, `"US"`, string(ruleManager.Rules(RuleSetDSP).DSPs["dsp"].Rules[0].Value))
+
+	// К версии из SetDSPRules можно откатиться, правило работает с тем же значением
+	revision := ruleManager.Revision(RuleSetDSP)
+	ruleManager.SetDSPRules("dsp", nil)
+	_, err = ruleManager.Rollback(RuleSetDSP, revision, RuleChange{})
+	assert.NoError(t, err)
+	country := "DE"
+	request := &ortb_V2_4.BidRequest{Device: &ortb_V2_4.Device{Geo: &ortb_V2_4.Geo{Country: &country}}}
+	assert.False(t, suite.processor.ProcessRequestForDSPV24("dsp", "", request).Allowed)
+	country = "US"
+	assert.True(t, suite.processor.ProcessRequestForDSPV24("dsp", "", request).Allowed)
+}
+
+func (suite *FilterTestSuite) TestRedisRuleStorePropagation() {
+	t := suite.T()
+
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Реплика со своими файлами правил; сверка редкая, изменения приходят по pub/sub
+	startReplica := func() (*RedisRuleStore, *RuleManager, string) {
+		dir := t.TempDir()
+		dspPath, sppPath := filepath.Join(dir, "dsp.json"), filepath.Join(dir, "spp.json")
+		assert.NoError(t, os.WriteFile(dspPath, []byte(`{
+			"version": "1.0",
+			"dsps": {
+				"dsp": {"rules": [
+					{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"}
+				]}
+			}
+		}`), 0o644))
+		assert.NoError(t, os.WriteFile(sppPath, []byte(`{"version": "1.0", "spps": {}}`), 0o644))
+
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		ruleManager := NewRuleManager()
+		store := NewRedisRuleStore(ruleManager, NewFileRuleLoader(ruleManager, dspPath, sppPath), client, time.Hour)
+		store.Start(ctx)
+		return store, ruleManager, dspPath
+	}
+	rules := func(dspID string) *SimpleRuleConfig {
+		var config SimpleRuleConfig
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+			"format": "2.0",
+			"dsps": {%q: {"rules": [{"field": "app.id", "condition": "exists", "value_type": "string"}]}}
+		}`, dspID)), &config))
+		return &config
+	}
+
+	store1, ruleManager1, _ := startReplica()
+	store2, ruleManager2, dspPath2 := startReplica()
+	assert.Eventually(t, func() bool {
+		return server.PubSubNumSub(ruleStoreChannel)[ruleStoreChannel] == 2
+	}, time.Second, time.Millisecond)
+
+	// Правила из файлов в Redis не попадают
+	assert.Equal(t, uint64(1), ruleManager1.Revision(RuleSetDSP))
+	assert.False(t, server.Exists(ruleStoreKey(RuleSetDSP)))
+	assert.Equal(t, RuleSourceRedis, store1.Status().Source)
+	assert.Empty(t, store1.Status().Error)
+
+	// Изменение через одну реплику доходит до другой по уведомлению
+	update := rules("other")
+	update.Version = 1
+	revision, err := store1.UpdateRules(ctx, RuleSetDSP, update, RuleChange{Author: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), revision)
+	assert.True(t, server.Exists(ruleStoreKey(RuleSetDSP)))
+	assert.Eventually(t, func() bool { return ruleManager2.Revision(RuleSetDSP) == 2 }, time.Second, time.Millisecond)
+	assert.Nil(t, ruleManager2.GetCompiledRulesForDSP("dsp"))
+	assert.NotNil(t, ruleManager2.GetCompiledRulesForDSP("other"))
+	assert.Equal(t, uint64(2), store2.Status().DSP.StoredRevision)
+	saved, err := os.ReadFile(dspPath2)
+	assert.NoError(t, err)
+	assert.Contains(t, string(saved), `"other"`)
+
+	// Другая реплика сохраняет ревизию между сверкой и записью: скрипт отклоняет
+	// устаревшее изменение, и реплика догоняет правила из Redis
+	store1.mu.Lock()
+	assert.NoError(t, store1.syncLocked(ctx, RuleSetDSP))
+	stale, err := ruleManager1.prepareUpdate(RuleSetDSP, rules("stale"), RuleChange{})
+	assert.NoError(t, err)
+	revision, err = store2.UpdateRules(ctx, RuleSetDSP, rules("bob"), RuleChange{Author: "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), revision)
+	_, err = store1.commitLocked(ctx, stale)
+	store1.mu.Unlock()
+	assert.ErrorIs(t, err, ErrStaleRevision)
+
+	assert.Eventually(t, func() bool { return ruleManager1.Revision(RuleSetDSP) == 3 }, time.Second, time.Millisecond)
+	assert.Nil(t, ruleManager1.GetCompiledRulesForDSP("stale"))
+	assert.NotNil(t, ruleManager1.GetCompiledRulesForDSP("bob"))
+
+	// Новая реплика берёт правила из Redis, а не из своего файла
+	_, ruleManager3, _ := startReplica()
+	assert.Equal(t, uint64(3), ruleManager3.Revision(RuleSetDSP))
+	assert.NotNil(t, ruleManager3.GetCompiledRulesForDSP("bob"))
+	assert.Nil(t, ruleManager3.GetCompiledRulesForDSP("dsp"))
+
+	// Набор реплики изменили в обход хранилища после подготовки: ревизия уже в Redis,
+	// и реплика применяет её поверх своего набора, а не расходится с другими
+	store1.mu.Lock()
+	assert.NoError(t, store1.syncLocked(ctx, RuleSetDSP))
+	carol, err := ruleManager1.prepareUpdate(RuleSetDSP, rules("carol"), RuleChange{Author: "carol"})
+	assert.NoError(t, err)
+	ruleManager1.SetDSPRules("local", nil)
+	revision, err = store1.commitLocked(ctx, carol)
+	store1.mu.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), revision)
+	assert.Equal(t, uint64(4), ruleManager1.Revision(RuleSetDSP))
+	assert.NotNil(t, ruleManager1.GetCompiledRulesForDSP("carol"))
+	assert.Nil(t, ruleManager1.GetCompiledRulesForDSP("local"))
+	assert.Equal(t, RuleChange{Author: "carol"}, ruleManager1.Versions(RuleSetDSP)[len(ruleManager1.Versions(RuleSetDSP))-1].RuleChange)
+	assert.Eventually(t, func() bool { return ruleManager2.GetCompiledRulesForDSP("carol") != nil }, time.Second, time.Millisecond)
+}
+
+func (suite *FilterTestSuite) TestRedisRuleStoreWithoutRedis() {
+	t := suite.T()
+
+	dir := t.TempDir()
+	dspPath, sppPath := filepath.Join(dir, "dsp.json"), filepath.Join(dir, "spp.json")
+	assert.NoError(t, os.WriteFile(dspPath, []byte(`{
+		"version": 3,
+		"format": "2.0",
+		"dsps": {
+			"dsp": {"rules": [
+				{"field": "device.geo.country", "condition": "equal", "value_type": "string", "value": "US"}
+			]}
+		}
+	}`), 0o644))
+	assert.NoError(t, os.WriteFile(sppPath, []byte(`{
+		"version": "1.0",
+		"spps": {
+			"spp": {"rules": [
+				{"field": "bid.price", "condition": "greater_than", "value_type": "float", "value": 0}
+			]}
+		}
+	}`), 0o644))
+
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Без Redis реплика стартует с локальных файлов
+	ruleManager := NewRuleManager()
+	fileLoader := NewFileRuleLoader(ruleManager, dspPath, sppPath)
+	store := NewRedisRuleStore(ruleManager, fileLoader, client, time.Hour)
+	store.Start(ctx)
+
+	status := store.Status()
+	assert.Equal(t, RuleSourceRedis, status.Source)
+	assert.Equal(t, uint64(3), status.DSP.Revision)
+	assert.Equal(t, uint64(1), status.SPP.Revision)
+	assert.Nil(t, status.SyncedAt)
+	assert.NotEmpty(t, status.Error)
+
+	// Без Redis изменения отклоняются, чтобы реплики не разошлись
+	var update SimpleRuleConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{"format": "2.0", "dsps": {"other": {"rules": []}}}`), &update))
+	_, err := store.UpdateRules(ctx, RuleSetDSP, &update, RuleChange{})
+	assert.ErrorIs(t, err, ErrRuleStoreUnavailable)
+	assert.Equal(t, uint64(3), ruleManager.Revision(RuleSetDSP))
+
+	// Ревизия из Redis заменяет набор целиком и сохраняется в файл
+	var stored storedRules
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"revision": 7,
+		"author": "alice",
+		"config": {
+			"format": "2.0",
+			"dsps": {
+				"other": {"rules": [
+					{"field": "app.id", "condition": "exists", "value_type": "string"}
+				]}
+			}
+		}
+	}`), &stored))
+	assert.NoError(t, store.loadLocked(RuleSetDSP, &stored))
+	assert.Equal(t, uint64(7), ruleManager.Revision(RuleSetDSP))
+	assert.Nil(t, ruleManager.GetCompiledRulesForDSP("dsp"))
+	assert.NotNil(t, ruleManager.GetCompiledRulesForDSP("other"))
+	versions := ruleManager.Versions(RuleSetDSP)
+	assert.Equal(t, "alice", versions[len(versions)-1].Author)
+
+	restarted := NewRuleManager()
+	assert.NoError(t, NewFileRuleLoader(restarted, dspPath, sppPath).LoadDSPRules())
+	assert.Equal(t, uint64(7), restarted.Revision(RuleSetDSP))
+	saved, err := json.Marshal(ruleManager.Rules(RuleSetDSP))
+	assert.NoError(t, err)
+	reloaded, err := json.Marshal(restarted.Rules(RuleSetDSP))
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(saved), string(reloaded))
+
+	kind, revision, err := parseRuleNotification(ruleNotification(RuleSetSPP, 12))
+	assert.NoError(t, err)
+	assert.Equal(t, RuleSetSPP, kind)
+	assert.Equal(t, uint64(12), revision)
+	_, _, err = parseRuleNotification("bidder:1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,472 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RuleSetKind - набор правил со своей ревизией и историей
+type RuleSetKind string
+
+const (
+	RuleSetDSP RuleSetKind = "dsp"
+	RuleSetSPP RuleSetKind = "spp"
+)
+
+var ruleSetKinds = []RuleSetKind{RuleSetDSP, RuleSetSPP}
+
+// Сколько последних версий каждого набора хранится для diff и отката
+const ruleHistoryLimit = 100
+
+var (
+	ErrStaleRevision   = errors.New("stale rule revision")
+	ErrUnknownRevision = errors.New("unknown rule revision")
+)
+
+// RuleChange - кто и зачем меняет правила
+type RuleChange struct {
+	Author  string `json:"author"`
+	Comment string `json:"comment"`
+}
+
+// RuleVersion - описание версии набора правил
+type RuleVersion struct {
+	Revision uint64 `json:"revision"`
+	RuleChange
+	CreatedAt time.Time `json:"created_at"`
+	// Ревизия, к которой откатились; 0 - обычное изменение
+	RolledBackTo uint64 `json:"rolled_back_to,omitempty"`
+}
+
+// ruleHistory - ревизия и снимки набора правил. Снимок хранит map правил целиком,
+// неизменные DSP и SPP разделяют скомпилированные правила с соседними снимками.
+type ruleHistory struct {
+	revision uint64
+	versions []ruleSnapshot
+}
+
+type ruleSnapshot struct {
+	RuleVersion
+	rules map[string]*CompiledRuleSet
+}
+
+// RuleVersionDiff - изменения правил между двумя ревизиями. Правила сравниваются
+// по верхнему уровню списка: изменённое условие - это удалённое и добавленное.
+type RuleVersionDiff struct {
+	From    uint64                   `json:"from"`
+	To      uint64                   `json:"to"`
+	Added   map[string][]RuleNode    `json:"added"`
+	Removed map[string][]RuleNode    `json:"removed"`
+	Changed map[string]RuleSetChange `json:"changed"`
+}
+
+type RuleSetChange struct {
+	Added   []RuleNode `json:"added"`
+	Removed []RuleNode `json:"removed"`
+}
+
+// UpdateRules заменяет правила DSP или SPP конфигом целиком как следующую ревизию:
+// DSP (SPP), которых нет в конфиге, удаляются. Ревизия в конфиге - та, на основе
+// которой он составлен (её возвращает Rules); если с тех пор правила изменились,
+// конфиг отклоняется с ErrStaleRevision. Без ревизии конфиг применяется без проверки.
+func (rm *RuleManager) UpdateRules(kind RuleSetKind, config *SimpleRuleConfig, change RuleChange) (uint64, error) {
+	update, err := rm.prepareUpdate(kind, config, change)
+	if err != nil {
+		return 0, err
+	}
+	return rm.apply(update)
+}
+
+// replaceRules заменяет правила набора сохранённым конфигом под его ревизией,
+// без проверки, на чём он основан
+func (rm *RuleManager) replaceRules(kind RuleSetKind, config *SimpleRuleConfig, change RuleChange) (uint64, error) {
+	update, err := rm.prepareReplace(kind, config, change, 0)
+	if err != nil {
+		return 0, err
+	}
+	return rm.apply(update)
+}
+
+// Revision возвращает ревизию активных правил набора
+func (rm *RuleManager) Revision(kind RuleSetKind) uint64 {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return rm.history(kind).revision
+}
+
+// Rules возвращает текущие правила набора в формате конфига, с текущей ревизией
+func (rm *RuleManager) Rules(kind RuleSetKind) SimpleRuleConfig {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	return ruleConfig(kind, rm.history(kind).revision, rm.rulesLocked(kind))
+}
+
+func ruleConfig(kind RuleSetKind, revision uint64, rules map[string]*CompiledRuleSet) SimpleRuleConfig {
+	config := SimpleRuleConfig{Version: revision, Format: ConfigVersionTree}
+	switch kind {
+	case RuleSetDSP:
+		config.DSPs = make(map[string]DSPSettings, len(rules))
+		for dspID, ruleSet := range rules {
+			config.DSPs[dspID] = DSPSettings{Rules: ruleSet.source}
+		}
+	case RuleSetSPP:
+		config.SPPs = make(map[string]SPPSettings, len(rules))
+		for sppID, ruleSet := range rules {
+			config.SPPs[sppID] = SPPSettings{Rules: ruleSet.source, Blocklist: ruleSet.blocklist}
+		}
+	}
+
+	return config
+}
+
+// Versions возвращает сохранённые версии набора, от старых к новым
+func (rm *RuleManager) Versions(kind RuleSetKind) []RuleVersion {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	history := rm.history(kind)
+	versions := make([]RuleVersion, 0, len(history.versions))
+	for _, snapshot := range history.versions {
+		versions = append(versions, snapshot.RuleVersion)
+	}
+	return versions
+}
+
+// DiffVersions сравнивает правила двух сохранённых ревизий
+func (rm *RuleManager) DiffVersions(kind RuleSetKind, from, to uint64) (RuleVersionDiff, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	fromSnapshot, err := rm.history(kind).find(from)
+	if err != nil {
+		return RuleVersionDiff{}, err
+	}
+	toSnapshot, err := rm.history(kind).find(to)
+	if err != nil {
+		return RuleVersionDiff{}, err
+	}
+
+	diff := RuleVersionDiff{
+		From:    from,
+		To:      to,
+		Added:   make(map[string][]RuleNode),
+		Removed: make(map[string][]RuleNode),
+		Changed: make(map[string]RuleSetChange),
+	}
+	for id, fromRules := range fromSnapshot.rules {
+		toRules, ok := toSnapshot.rules[id]
+		if !ok {
+			diff.Removed[id] = fromRules.source
+			continue
+		}
+		if change := diffRuleSources(fromRules.source, toRules.source); len(change.Added)+len(change.Removed) > 0 {
+			diff.Changed[id] = change
+		}
+	}
+	for id, toRules := range toSnapshot.rules {
+		if _, ok := fromSnapshot.rules[id]; !ok {
+			diff.Added[id] = toRules.source
+		}
+	}
+
+	return diff, nil
+}
+
+// Rollback атомарно возвращает правила сохранённой ревизии. Откат записывается
+// новой ревизией, история не переписывается.
+func (rm *RuleManager) Rollback(kind RuleSetKind, revision uint64, change RuleChange) (uint64, error) {
+	update, err := rm.prepareRollback(kind, revision, change)
+	if err != nil {
+		return 0, err
+	}
+	return rm.apply(update)
+}
+
+// ruleUpdate - новая ревизия набора правил, ещё не ставшая активной. Её можно
+// сохранить в общем хранилище до того, как применить.
+type ruleUpdate struct {
+	kind RuleSetKind
+	// Ревизия, от которой построено изменение
+	base         uint64
+	revision     uint64
+	rules        map[string]*CompiledRuleSet
+	change       RuleChange
+	rolledBackTo uint64
+}
+
+// config возвращает правила изменения в формате конфига
+func (u *ruleUpdate) config() SimpleRuleConfig {
+	return ruleConfig(u.kind, u.revision, u.rules)
+}
+
+// prepareUpdate компилирует конфиг, который целиком заменит правила набора
+// следующей ревизией, если конфиг основан на текущей
+func (rm *RuleManager) prepareUpdate(kind RuleSetKind, config *SimpleRuleConfig, change RuleChange) (*ruleUpdate, error) {
+	rules, err := rm.compileConfig(kind, config)
+	if err != nil {
+		return nil, err
+	}
+
+	base := rm.Revision(kind)
+	if config.Version != 0 && config.Version != base {
+		return nil, fmt.Errorf("%w: config is based on revision %d, current revision of %s rules is %d", ErrStaleRevision, config.Version, kind, base)
+	}
+
+	return &ruleUpdate{kind: kind, base: base, revision: base + 1, rules: rules, change: change}, nil
+}
+
+// prepareReplace компилирует сохранённый конфиг (из Redis или файла), который
+// целиком заменит правила набора под ревизией конфига; 0 - следующая после текущей
+func (rm *RuleManager) prepareReplace(
+	kind RuleSetKind,
+	config *SimpleRuleConfig,
+	change RuleChange,
+	rolledBackTo uint64,
+) (*ruleUpdate, error) {
+	rules, err := rm.compileConfig(kind, config)
+	if err != nil {
+		return nil, err
+	}
+
+	base := rm.Revision(kind)
+	revision := config.Version
+	if revision == 0 {
+		revision = base + 1
+	}
+
+	return &ruleUpdate{
+		kind:         kind,
+		base:         base,
+		revision:     revision,
+		rules:        rules,
+		change:       change,
+		rolledBackTo: rolledBackTo,
+	}, nil
+}
+
+func (rm *RuleManager) prepareRollback(kind RuleSetKind, revision uint64, change RuleChange) (*ruleUpdate, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	snapshot, err := rm.history(kind).find(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	current := rm.rulesLocked(kind)
+	rules := make(map[string]*CompiledRuleSet, len(snapshot.rules))
+	for id, ruleSet := range snapshot.rules {
+		// Неактивные с тех пор правила возвращаются с чистой статистикой
+		if current[id] != ruleSet {
+			ruleSet.resetStats()
+			ruleSet.resetDiverged()
+		}
+		rules[id] = ruleSet
+	}
+
+	base := rm.history(kind).revision
+	return &ruleUpdate{
+		kind:         kind,
+		base:         base,
+		revision:     base + 1,
+		rules:        rules,
+		change:       change,
+		rolledBackTo: revision,
+	}, nil
+}
+
+// compileConfig проверяет и компилирует правила DSP или SPP из конфига
+func (rm *RuleManager) compileConfig(kind RuleSetKind, config *SimpleRuleConfig) (map[string]*CompiledRuleSet, error) {
+	var sources map[string][]RuleNode
+	var blocklists map[string]*Blocklist
+	switch kind {
+	case RuleSetDSP:
+		if err := ValidateDSPConfig(config); err != nil {
+			return nil, fmt.Errorf("DSP config validation failed: %v", err)
+		}
+		sources = make(map[string][]RuleNode, len(config.DSPs))
+		for dspID, dspSettings := range config.DSPs {
+			sources[dspID] = dspSettings.Rules
+		}
+	case RuleSetSPP:
+		if err := ValidateSPPConfig(config); err != nil {
+			return nil, fmt.Errorf("SPP config validation failed: %v", err)
+		}
+		sources = make(map[string][]RuleNode, len(config.SPPs))
+		blocklists = make(map[string]*Blocklist, len(config.SPPs))
+		for sppID, sppSettings := range config.SPPs {
+			sources[sppID] = sppSettings.Rules
+			blocklists[sppID] = sppSettings.Blocklist
+		}
+	default:
+		return nil, fmt.Errorf("unknown rule set %q", kind)
+	}
+
+	ruleSets := make(map[string]*CompiledRuleSet, len(sources))
+	for id, nodes := range sources {
+		ruleSet, err := rm.compileSource(nodes)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing rule for %s %s: %v", kind, id, err)
+		}
+		if err := ruleSet.setBlocklist(blocklists[id]); err != nil {
+			return nil, fmt.Errorf("Error loading blocklist for %s %s: %v", kind, id, err)
+		}
+		ruleSets[id] = ruleSet
+	}
+
+	return ruleSets, nil
+}
+
+// apply делает изменение активным, если с его подготовки правила набора не менялись
+func (rm *RuleManager) apply(update *ruleUpdate) (uint64, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if err := rm.applyLocked(update); err != nil {
+		return 0, err
+	}
+	return update.revision, nil
+}
+
+// applyLocked применяет изменения всех наборов, либо ни одного
+func (rm *RuleManager) applyLocked(updates ...*ruleUpdate) error {
+	for _, update := range updates {
+		if current := rm.history(update.kind).revision; current != update.base {
+			return fmt.Errorf("%w: %s rules changed to revision %d concurrently", ErrStaleRevision, update.kind, current)
+		}
+	}
+	for _, update := range updates {
+		rm.commitLocked(update.kind, update.rules, update.revision, update.change, update.rolledBackTo)
+	}
+	return nil
+}
+
+// mergeLocked добавляет ruleSets к текущим правилам набора как новую ревизию
+func (rm *RuleManager) mergeLocked(kind RuleSetKind, ruleSets map[string]*CompiledRuleSet, change RuleChange) {
+	rm.commitLocked(kind, mergeRuleSets(rm.rulesLocked(kind), ruleSets), 0, change, 0)
+}
+
+// mergeRuleSets возвращает новую map: текущие правила, заменённые ruleSets
+func mergeRuleSets(current, ruleSets map[string]*CompiledRuleSet) map[string]*CompiledRuleSet {
+	rules := make(map[string]*CompiledRuleSet, len(current)+len(ruleSets))
+	for id, ruleSet := range current {
+		rules[id] = ruleSet
+	}
+	for id, ruleSet := range ruleSets {
+		rules[id] = ruleSet
+	}
+	return rules
+}
+
+// commitLocked делает rules активными и сохраняет их в истории под ревизией
+// revision; 0 - следующая после текущей
+func (rm *RuleManager) commitLocked(
+	kind RuleSetKind,
+	rules map[string]*CompiledRuleSet,
+	revision uint64,
+	change RuleChange,
+	rolledBackTo uint64,
+) {
+	history := rm.history(kind)
+	if revision == 0 {
+		revision = history.revision + 1
+	}
+	// Сохранённая ревизия может быть не новее текущей, если Redis восстановлен
+	// из старой копии: версии после неё из истории удаляются
+	if revision <= history.revision {
+		history.truncate(revision)
+	}
+
+	history.revision = revision
+	history.versions = append(history.versions, ruleSnapshot{
+		RuleVersion: RuleVersion{
+			Revision:     revision,
+			RuleChange:   change,
+			CreatedAt:    time.Now().UTC(),
+			RolledBackTo: rolledBackTo,
+		},
+		rules: rules,
+	})
+	if len(history.versions) > ruleHistoryLimit {
+		history.versions = append([]ruleSnapshot(nil), history.versions[len(history.versions)-ruleHistoryLimit:]...)
+	}
+
+	switch kind {
+	case RuleSetDSP:
+		rm.dspRules = rules
+	case RuleSetSPP:
+		rm.sppRules = rules
+	}
+}
+
+func (rm *RuleManager) history(kind RuleSetKind) *ruleHistory {
+	if kind == RuleSetSPP {
+		return &rm.sppHistory
+	}
+	return &rm.dspHistory
+}
+
+func (rm *RuleManager) rulesLocked(kind RuleSetKind) map[string]*CompiledRuleSet {
+	if kind == RuleSetSPP {
+		return rm.sppRules
+	}
+	return rm.dspRules
+}
+
+// truncate удаляет из истории версии начиная с ревизии revision
+func (h *ruleHistory) truncate(revision uint64) {
+	for i, snapshot := range h.versions {
+		if snapshot.Revision >= revision {
+			h.versions = h.versions[:i:i]
+			return
+		}
+	}
+}
+
+func (h *ruleHistory) find(revision uint64) (ruleSnapshot, error) {
+	for _, snapshot := range h.versions {
+		if snapshot.Revision == revision {
+			return snapshot, nil
+		}
+	}
+	return ruleSnapshot{}, fmt.Errorf("%w: %d", ErrUnknownRevision, revision)
+}
+
+// diffRuleSources сравнивает списки правил по их JSON, с учётом повторов
+func diffRuleSources(from, to []RuleNode) RuleSetChange {
+	change := RuleSetChange{Added: []RuleNode{}, Removed: []RuleNode{}}
+
+	remaining := make(map[string]int, len(from))
+	for _, node := range from {
+		remaining[ruleNodeKey(node)]++
+	}
+	for _, node := range to {
+		key := ruleNodeKey(node)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		change.Added = append(change.Added, node)
+	}
+	for _, node := range from {
+		key := ruleNodeKey(node)
+		if remaining[key] > 0 {
+			remaining[key]--
+			change.Removed = append(change.Removed, node)
+		}
+	}
+
+	return change
+}
+
+func ruleNodeKey(node RuleNode) string {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return fmt.Sprintf("%#v", node)
+	}
+	return string(data)
+}
@@ -2,12 +2,13 @@ package filter
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// FileRuleLoader загружает наборы правил из файлов. Файл задаёт набор целиком
+// и под своей ревизией: её записывает реплика, загрузившая правила из Redis.
 type FileRuleLoader struct {
 	ruleManager *RuleManager
 	dspFilePath string
@@ -34,33 +35,8 @@ func (fl *FileRuleLoader) LoadDSPRules() error {
 		return err
 	}
 
-	if err := ValidateDSPConfig(&config); err != nil {
-		return fmt.Errorf("DSP config validation failed: %v", err)
-	}
-
-	for dspID, dspSettings := range config.DSPs {
-		rules := make(map[string]*FilterRule)
-		seenRules := make(map[string]bool)
-
-		for _, simpleRule := range dspSettings.Rules {
-			ruleKey := fmt.Sprintf("%s_%s", simpleRule.Field, simpleRule.Condition)
-			if seenRules[ruleKey] {
-				continue
-			}
-			seenRules[ruleKey] = true
-
-			rule, err := parseSimpleRule(simpleRule)
-			if err != nil {
-				return fmt.Errorf("Error parsing rule for DSP %s: %v", dspID, err)
-			}
-
-			rules[rule.ID] = rule
-		}
-
-		fl.ruleManager.SetDSPRules(dspID, rules)
-	}
-
-	return nil
+	_, err = fl.ruleManager.replaceRules(RuleSetDSP, &config, fileRuleChange(fl.dspFilePath))
+	return err
 }
 
 func (fl *FileRuleLoader) LoadSPPRules() error {
@@ -74,33 +50,39 @@ func (fl *FileRuleLoader) LoadSPPRules() error {
 		return err
 	}
 
-	if err := ValidateSPPConfig(&config); err != nil {
-		return fmt.Errorf("SPP config validation failed: %v", err)
-	}
-
-	for sppID, sppSettings := range config.SPPs {
-		rules := make(map[string]*FilterRule)
-		seenRules := make(map[string]bool)
+	_, err = fl.ruleManager.replaceRules(RuleSetSPP, &config, fileRuleChange(fl.sppFilePath))
+	return err
+}
 
-		for _, simpleRule := range sppSettings.Rules {
-			ruleKey := fmt.Sprintf("%s_%s", simpleRule.Field, simpleRule.Condition)
-			if seenRules[ruleKey] {
-				continue
-			}
-			seenRules[ruleKey] = true
+func (fl *FileRuleLoader) loadRules(kind RuleSetKind) error {
+	if kind == RuleSetSPP {
+		return fl.LoadSPPRules()
+	}
+	return fl.LoadDSPRules()
+}
 
-			rule, err := parseSimpleRule(simpleRule)
-			if err != nil {
-				return fmt.Errorf("Error parsing rule for SPP %s: %v", sppID, err)
-			}
+// saveRules перезаписывает файл правил набора, чтобы реплика могла стартовать
+// с последних известных правил. Файл заменяется целиком через rename.
+func (fl *FileRuleLoader) saveRules(kind RuleSetKind, config SimpleRuleConfig) error {
+	path := fl.dspFilePath
+	if kind == RuleSetSPP {
+		path = fl.sppFilePath
+	}
 
-			rules[rule.ID] = rule
-		}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
 
-		fl.ruleManager.SetSPPRules(sppID, rules)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
 	}
+	return os.Rename(tmpPath, path)
+}
 
-	return nil
+func fileRuleChange(path string) RuleChange {
+	return RuleChange{Author: "file", Comment: "loaded from " + path}
 }
 
 func (fl *FileRuleLoader) Close() error {
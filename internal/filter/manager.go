@@ -1,20 +1,36 @@
 package filter
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
 )
 
 type CompiledRuleSet struct {
+	// Дерево правил, nil - правил нет
+	root           *ruleNode
 	rules          []*FilterRule
 	requiredFields []FieldType
 	// Для bulk optimization - группировка правил по полям
 	fieldRules map[FieldType][]*FilterRule
+	// Запросы (ответы), на которых вычислялся набор правил
+	counters ruleCounters
+	// Правила в формате конфига, для истории версий
+	source []RuleNode
+	// Blocklist SPP из конфига и загруженные по нему запреты, см. ReloadBlocklists
+	blocklist *Blocklist
+	blocks    atomic.Pointer[blockSet]
 }
 
 type RuleManager struct {
 	dspRules map[string]*CompiledRuleSet
 	sppRules map[string]*CompiledRuleSet
 	mu       sync.RWMutex
+	// Правила-кандидат в режиме shadow, nil - не загружены
+	shadow atomic.Pointer[shadowRules]
+	// Ревизии и снимки правил, меняются под mu
+	dspHistory ruleHistory
+	sppHistory ruleHistory
 }
 
 func NewRuleManager() *RuleManager {
@@ -24,13 +40,75 @@ func NewRuleManager() *RuleManager {
 	}
 }
 
+// compileRules объединяет правила через AND
 func (rm *RuleManager) compileRules(rules map[string]*FilterRule) *CompiledRuleSet {
-	ruleSlice := make([]*FilterRule, 0, len(rules))
-	fieldsSet := make(map[FieldType]struct{}, len(rules))
-	fieldRules := make(map[FieldType][]*FilterRule)
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	leaves := make([]*ruleNode, 0, len(rules))
+	for _, id := range ids {
+		leaves = append(leaves, newRuleLeaf(rules[id]))
+	}
+
+	ruleSet := rm.compileRuleTree(newRuleGroup(nodeAll, leaves))
+	ruleSet.source = ruleSource(ruleSet.rules)
+	return ruleSet
+}
+
+// compileSource компилирует список правил из конфига
+func (rm *RuleManager) compileSource(nodes []RuleNode) (*CompiledRuleSet, error) {
+	root, err := parseRuleNodes(nodes)
+	if err != nil {
+		return nil, err
+	}
+	ruleSet := rm.compileRuleTree(root)
+	ruleSet.source = append([]RuleNode{}, nodes...)
+	return ruleSet, nil
+}
 
+// ruleSource восстанавливает конфиг правил, заданных без конфига, по скомпилированным
+// условиям, чтобы к ним можно было откатиться и сохранить их в хранилище
+func ruleSource(rules []*FilterRule) []RuleNode {
+	source := make([]RuleNode, 0, len(rules))
 	for _, rule := range rules {
-		ruleSlice = append(ruleSlice, rule)
+		if rule.sample != nil {
+			source = append(source, RuleNode{Sample: &SampleRule{
+				Rate: rule.sample.rate,
+				Key:  rule.sample.key,
+				Salt: rule.sample.salt,
+			}})
+			continue
+		}
+
+		simpleRule := SimpleRule{
+			Field:     rule.Field,
+			Condition: rule.Condition,
+			Value:     conditionSource(rule.Value),
+			ImpMatch:  rule.ImpMatch,
+		}
+		if rule.Value != nil {
+			simpleRule.ValueType = rule.Value.Type()
+		}
+		if rule.timeField != timeNone {
+			simpleRule.Clock = rule.clock.source()
+		}
+		source = append(source, RuleNode{SimpleRule: simpleRule})
+	}
+	return source
+}
+
+func (rm *RuleManager) compileRuleTree(root *ruleNode) *CompiledRuleSet {
+	ruleSlice := root.leaves(nil)
+	if len(ruleSlice) == 0 {
+		root = nil
+	}
+	fieldsSet := make(map[FieldType]struct{}, len(ruleSlice))
+	fieldRules := make(map[FieldType][]*FilterRule)
+
+	for _, rule := range ruleSlice {
 		fieldsSet[rule.Field] = struct{}{}
 		fieldRules[rule.Field] = append(fieldRules[rule.Field], rule)
 	}
@@ -41,6 +119,7 @@ func (rm *RuleManager) compileRules(rules map[string]*FilterRule) *CompiledRuleS
 	}
 
 	return &CompiledRuleSet{
+		root:           root,
 		rules:          ruleSlice,
 		requiredFields: requiredFields,
 		fieldRules:     fieldRules,
@@ -69,19 +148,23 @@ func (rm *RuleManager) GetFieldRulesForSPP(sppID string) map[FieldType][]*Filter
 	return nil
 }
 
-// Остальные методы остаются без изменений...
+// SetDSPRules заменяет правила DSP, каждый вызов - новая ревизия
 func (rm *RuleManager) SetDSPRules(dspID string, rules map[string]*FilterRule) {
+	ruleSet := rm.compileRules(rules)
+
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	rm.dspRules[dspID] = rm.compileRules(rules)
+	rm.mergeLocked(RuleSetDSP, map[string]*CompiledRuleSet{dspID: ruleSet}, RuleChange{Comment: "SetDSPRules " + dspID})
 }
 
 func (rm *RuleManager) SetSPPRules(sppID string, rules map[string]*FilterRule) {
+	ruleSet := rm.compileRules(rules)
+
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	rm.sppRules[sppID] = rm.compileRules(rules)
+	rm.mergeLocked(RuleSetSPP, map[string]*CompiledRuleSet{sppID: ruleSet}, RuleChange{Comment: "SetSPPRules " + sppID})
 }
 
 func (rm *RuleManager) GetCompiledRulesForDSP(dspID string) *CompiledRuleSet {
@@ -98,21 +181,48 @@ func (rm *RuleManager) GetCompiledRulesForSPP(sppID string) *CompiledRuleSet {
 	return rm.sppRules[sppID]
 }
 
+// ruleSets возвращает копии map правил, чтобы обходить их без блокировки
+func (rm *RuleManager) ruleSets() (map[string]*CompiledRuleSet, map[string]*CompiledRuleSet) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	dspRules := make(map[string]*CompiledRuleSet, len(rm.dspRules))
+	for dspID, ruleSet := range rm.dspRules {
+		dspRules[dspID] = ruleSet
+	}
+	sppRules := make(map[string]*CompiledRuleSet, len(rm.sppRules))
+	for sppID, ruleSet := range rm.sppRules {
+		sppRules[sppID] = ruleSet
+	}
+
+	return dspRules, sppRules
+}
+
 func (rm *RuleManager) ClearAllDSPRules() {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	rm.dspRules = make(map[string]*CompiledRuleSet)
+	rm.commitLocked(RuleSetDSP, make(map[string]*CompiledRuleSet), 0, RuleChange{Comment: "ClearAllDSPRules"}, 0)
 }
 
 func (rm *RuleManager) ClearAllSPPRules() {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	rm.sppRules = make(map[string]*CompiledRuleSet)
+	rm.commitLocked(RuleSetSPP, make(map[string]*CompiledRuleSet), 0, RuleChange{Comment: "ClearAllSPPRules"}, 0)
 }
 
-// Статические авто-правила (создаются один раз)
+// Авто-правила SPP, скомпилированные один раз. Счётчики общие для всех SPP.
+var autoRulesForSPP = func() *CompiledRuleSet {
+	rules := GetAutoRulesForSPP()
+	leaves := make([]*ruleNode, 0, len(rules))
+	for _, rule := range rules {
+		leaves = append(leaves, newRuleLeaf(rule))
+	}
+	return (&RuleManager{}).compileRuleTree(newRuleGroup(nodeAll, leaves))
+}()
+
+// Статические авто-правила
 func GetAutoRulesForSPP() []*FilterRule {
 	return []*FilterRule{
 		{
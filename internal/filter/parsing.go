@@ -3,8 +3,79 @@ package filter
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
 )
 
+// parseRuleNodes компилирует список правил в группу all. Одинаковые условия
+// в дереве допустимы, их ID различаются порядковым номером.
+func parseRuleNodes(nodes []RuleNode) (*ruleNode, error) {
+	children, err := parseRuleNodeList(nodes, make(map[string]int))
+	if err != nil {
+		return nil, err
+	}
+	return newRuleGroup(nodeAll, children), nil
+}
+
+func parseRuleNodeList(nodes []RuleNode, ids map[string]int) ([]*ruleNode, error) {
+	children := make([]*ruleNode, 0, len(nodes))
+	for _, node := range nodes {
+		child, err := parseRuleNode(node, ids)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+func parseRuleNode(node RuleNode, ids map[string]int) (*ruleNode, error) {
+	switch {
+	case node.All != nil:
+		children, err := parseRuleNodeList(node.All, ids)
+		if err != nil {
+			return nil, err
+		}
+		return newRuleGroup(nodeAll, children), nil
+	case node.Any != nil:
+		children, err := parseRuleNodeList(node.Any, ids)
+		if err != nil {
+			return nil, err
+		}
+		return newRuleGroup(nodeAny, children), nil
+	case node.Not != nil:
+		child, err := parseRuleNode(*node.Not, ids)
+		if err != nil {
+			return nil, err
+		}
+		return newRuleGroup(nodeNot, []*ruleNode{child}), nil
+	case node.Sample != nil:
+		rule, err := parseSampleRule(*node.Sample)
+		if err != nil {
+			return nil, err
+		}
+		ids[rule.ID]++
+		if n := ids[rule.ID]; n > 1 {
+			rule.ID = fmt.Sprintf("%s_%d", rule.ID, n)
+		}
+		return newRuleLeaf(rule), nil
+	default:
+		rule, err := parseSimpleRule(node.SimpleRule)
+		if err != nil {
+			return nil, err
+		}
+		ids[rule.ID]++
+		if n := ids[rule.ID]; n > 1 {
+			rule.ID = fmt.Sprintf("%s_%d", rule.ID, n)
+		}
+		return newRuleLeaf(rule), nil
+	}
+}
+
 func parseSimpleRule(simpleRule SimpleRule) (*FilterRule, error) {
 	if err := ValidateSimpleRule(simpleRule); err != nil {
 		return nil, err
@@ -15,6 +86,20 @@ func parseSimpleRule(simpleRule SimpleRule) (*FilterRule, error) {
 		Field:     simpleRule.Field,
 		Condition: simpleRule.Condition,
 	}
+	if err := rule.bindPaths(); err != nil {
+		return nil, err
+	}
+	if rule.timeField != timeNone {
+		if err := rule.bindTime(simpleRule.Clock); err != nil {
+			return nil, err
+		}
+	}
+	if rule.impField {
+		rule.ImpMatch = simpleRule.ImpMatch
+		if rule.ImpMatch == "" {
+			rule.ImpMatch = ImpMatchEach
+		}
+	}
 
 	if simpleRule.Condition == ConditionExists {
 		switch simpleRule.ValueType {
@@ -23,12 +108,20 @@ func parseSimpleRule(simpleRule SimpleRule) (*FilterRule, error) {
 		case ValueTypeInt:
 			rule.Value = IntCondition{cond: simpleRule.Condition, values: [2]int{}}
 		case ValueTypeFloat:
-			rule.Value = FloatCondition{cond: simpleRule.Condition, values: [2]float64{}}
+			rule.Value = FloatCondition{cond: simpleRule.Condition, values: [2]money.Micros{}}
 		}
 		return rule, nil
 	}
 
 	var err error
+	if simpleRule.Condition == ConditionCIDR {
+		rule.Value, err = parseCIDRCondition(simpleRule.Value)
+		if err != nil {
+			return nil, err
+		}
+		return rule, nil
+	}
+
 	switch simpleRule.ValueType {
 	case ValueTypeInt:
 		rule.Value, err = parseIntCondition(simpleRule.Value, simpleRule.Condition)
@@ -59,6 +152,18 @@ func parseIntCondition(value json.RawMessage, cond ConditionType) (IntCondition,
 			return intCond, fmt.Errorf("requires exactly 2 values, got %d", len(values))
 		}
 		intCond.values = [2]int{values[0], values[1]}
+	case ConditionIn, ConditionNotIn:
+		var values []int
+		if err := json.Unmarshal(value, &values); err != nil {
+			return intCond, fmt.Errorf("invalid int array: %v", err)
+		}
+		if len(values) == 0 {
+			return intCond, fmt.Errorf("requires at least 1 value")
+		}
+		intCond.set = make(map[int]struct{}, len(values))
+		for _, v := range values {
+			intCond.set[v] = struct{}{}
+		}
 	default:
 		var singleValue int
 		if err := json.Unmarshal(value, &singleValue); err != nil {
@@ -75,6 +180,15 @@ func parseStringCondition(value json.RawMessage, cond ConditionType) (StringCond
 	strCond.cond = cond
 
 	switch cond {
+	case ConditionIn, ConditionNotIn:
+		values, err := parseStringList(value)
+		if err != nil {
+			return strCond, err
+		}
+		strCond.set = make(map[string]struct{}, len(values))
+		for _, v := range values {
+			strCond.set[v] = struct{}{}
+		}
 	default:
 		var singleValue string
 		if err := json.Unmarshal(value, &singleValue); err != nil {
@@ -84,6 +198,14 @@ func parseStringCondition(value json.RawMessage, cond ConditionType) (StringCond
 			return strCond, fmt.Errorf("string value cannot be empty for condition %s", cond)
 		}
 		strCond.value = singleValue
+
+		if cond == ConditionRegex {
+			re, err := regexp.Compile(singleValue)
+			if err != nil {
+				return strCond, fmt.Errorf("invalid regex %q: %v", singleValue, err)
+			}
+			strCond.re = re
+		}
 	}
 
 	return strCond, nil
@@ -102,18 +224,101 @@ func parseFloatCondition(value json.RawMessage, cond ConditionType) (FloatCondit
 		if len(values) != 2 {
 			return floatCond, fmt.Errorf("requires exactly 2 values, got %d", len(values))
 		}
-		floatCond.values = [2]float64{values[0], values[1]}
+		floatCond.values = [2]money.Micros{money.FromFloat64(values[0]), money.FromFloat64(values[1])}
+	case ConditionIn, ConditionNotIn:
+		var values []float64
+		if err := json.Unmarshal(value, &values); err != nil {
+			return floatCond, fmt.Errorf("invalid float array: %v", err)
+		}
+		if len(values) == 0 {
+			return floatCond, fmt.Errorf("requires at least 1 value")
+		}
+		floatCond.set = make(map[money.Micros]struct{}, len(values))
+		for _, v := range values {
+			floatCond.set[money.FromFloat64(v)] = struct{}{}
+		}
 	default:
 		var singleValue float64
 		if err := json.Unmarshal(value, &singleValue); err != nil {
 			return floatCond, fmt.Errorf("invalid float value: %v", err)
 		}
-		floatCond.values = [2]float64{singleValue, 0}
+		floatCond.values = [2]money.Micros{money.FromFloat64(singleValue), 0}
 	}
 
 	return floatCond, nil
 }
 
+// parseCIDRCondition принимает подсеть или массив подсетей.
+// Адрес без длины префикса считается подсетью из одного адреса.
+func parseCIDRCondition(value json.RawMessage) (CIDRCondition, error) {
+	var cidrCond CIDRCondition
+
+	values, err := parseStringList(value)
+	if err != nil {
+		var singleValue string
+		if json.Unmarshal(value, &singleValue) != nil || singleValue == "" {
+			return cidrCond, err
+		}
+		values = []string{singleValue}
+	}
+
+	cidrCond.prefixes = make(map[netip.Prefix]struct{}, len(values))
+	bitsSet := make(map[int]struct{})
+	for _, v := range values {
+		prefix, err := parsePrefix(v)
+		if err != nil {
+			return cidrCond, err
+		}
+		cidrCond.prefixes[prefix] = struct{}{}
+		bitsSet[prefix.Bits()] = struct{}{}
+	}
+
+	cidrCond.bits = make([]int, 0, len(bitsSet))
+	for bits := range bitsSet {
+		cidrCond.bits = append(cidrCond.bits, bits)
+	}
+	// Сначала самые узкие подсети
+	sort.Sort(sort.Reverse(sort.IntSlice(cidrCond.bits)))
+
+	return cidrCond, nil
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP %q: %v", value, err)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %v", value, err)
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+func parseStringList(value json.RawMessage) ([]string, error) {
+	var values []string
+	if err := json.Unmarshal(value, &values); err != nil {
+		return nil, fmt.Errorf("invalid string array: %v", err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("requires at least 1 value")
+	}
+	for _, v := range values {
+		if v == "" {
+			return nil, fmt.Errorf("string array cannot contain empty values")
+		}
+	}
+	return values, nil
+}
+
 func generateRuleID(rule SimpleRule) string {
 	return string(rule.Field) + "_" + string(rule.Condition)
 }
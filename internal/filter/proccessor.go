@@ -1,6 +1,8 @@
 package filter
 
 import (
+	"time"
+
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 )
@@ -8,113 +10,166 @@ import (
 type OptimizedFilterProcessor struct {
 	ruleManager *RuleManager
 	// Stateless экстракторы (создаются один раз)
-	v24ReqExtractor  *StatelessV24BidRequestExtractor
-	v25ReqExtractor  *StatelessV25BidRequestExtractor
-	v24RespExtractor *StatelessV24BidResponseExtractor
-	v25RespExtractor *StatelessV25BidResponseExtractor
+	v24ReqExtractor  *protoExtractor
+	v25ReqExtractor  *protoExtractor
+	v24RespExtractor *protoExtractor
+	v25RespExtractor *protoExtractor
+	// Часы для правил по времени, в тестах подменяются
+	now func() time.Time
 }
 
 func NewOptimizedFilterProcessor(ruleManager *RuleManager) *OptimizedFilterProcessor {
 	return &OptimizedFilterProcessor{
 		ruleManager:      ruleManager,
-		v24ReqExtractor:  NewStatelessV24BidRequestExtractor(),
-		v25ReqExtractor:  NewStatelessV25BidRequestExtractor(),
-		v24RespExtractor: NewStatelessV24BidResponseExtractor(),
-		v25RespExtractor: NewStatelessV25BidResponseExtractor(),
+		v24ReqExtractor:  newProtoExtractor(rootRequestV24),
+		v25ReqExtractor:  newProtoExtractor(rootRequestV25),
+		v24RespExtractor: newProtoExtractor(rootResponseV24),
+		v25RespExtractor: newProtoExtractor(rootResponseV25),
+		now:              time.Now,
 	}
 }
 
 // ProcessRequestForDSPV24 обрабатывает BidRequest v2.4 для DSP
-func (fp *OptimizedFilterProcessor) ProcessRequestForDSPV24(dspURL string, req *ortb_V2_4.BidRequest) *FilterResult {
+func (fp *OptimizedFilterProcessor) ProcessRequestForDSPV24(dspURL, globalId string, req *ortb_V2_4.BidRequest) FilterResult {
 	if req == nil {
-		return &FilterResult{Allowed: false}
+		return FilterResult{Allowed: false}
 	}
-	return fp.processRequestForDSPOptimized(dspURL, fp.v24ReqExtractor, req)
+	return fp.processRequestForDSPOptimized(dspURL, globalId, fp.v24ReqExtractor, req)
 }
 
 // ProcessRequestForDSPV25 обрабатывает BidRequest v2.5 для DSP
-func (fp *OptimizedFilterProcessor) ProcessRequestForDSPV25(dspURL string, req *ortb_V2_5.BidRequest) *FilterResult {
+func (fp *OptimizedFilterProcessor) ProcessRequestForDSPV25(dspURL, globalId string, req *ortb_V2_5.BidRequest) FilterResult {
 	if req == nil {
-		return &FilterResult{Allowed: false}
+		return FilterResult{Allowed: false}
 	}
-	return fp.processRequestForDSPOptimized(dspURL, fp.v25ReqExtractor, req)
+	return fp.processRequestForDSPOptimized(dspURL, globalId, fp.v25ReqExtractor, req)
 }
 
 // ProcessResponseForSPPV24 обрабатывает BidResponse v2.4 для SPP
-func (fp *OptimizedFilterProcessor) ProcessResponseForSPPV24(sppURL string, resp *ortb_V2_4.BidResponse) *FilterResult {
+func (fp *OptimizedFilterProcessor) ProcessResponseForSPPV24(sppURL string, resp *ortb_V2_4.BidResponse) FilterResult {
 	if resp == nil {
-		return &FilterResult{Allowed: false}
+		return FilterResult{Allowed: false}
 	}
 	return fp.processResponseForSPPOptimized(sppURL, fp.v24RespExtractor, resp)
 }
 
 // ProcessResponseForSPPV25 обрабатывает BidResponse v2.5 для SPP
-func (fp *OptimizedFilterProcessor) ProcessResponseForSPPV25(sppURL string, resp *ortb_V2_5.BidResponse) *FilterResult {
+func (fp *OptimizedFilterProcessor) ProcessResponseForSPPV25(sppURL string, resp *ortb_V2_5.BidResponse) FilterResult {
 	if resp == nil {
-		return &FilterResult{Allowed: false}
+		return FilterResult{Allowed: false}
 	}
 	return fp.processResponseForSPPOptimized(sppURL, fp.v25RespExtractor, resp)
 }
 
-// Оптимизированный метод с bulk extraction для DSP
-func (fp *OptimizedFilterProcessor) processRequestForDSPOptimized(dspURL string, extractor BidRequestExtractor, req interface{}) *FilterResult {
+func (fp *OptimizedFilterProcessor) processRequestForDSPOptimized(dspURL, globalId string, extractor BidRequestExtractor, req interface{}) FilterResult {
 	ruleSet := fp.ruleManager.GetCompiledRulesForDSP(dspURL)
-	if ruleSet == nil || len(ruleSet.rules) == 0 {
-		return &FilterResult{Allowed: true}
+	ctx := fp.requestContext(extractor, req, globalId)
+
+	result := FilterResult{Allowed: true}
+	if ruleSet != nil && ruleSet.root != nil {
+		result = evalRequestTree(ruleSet.root, ctx, false)
+		ruleSet.counters.record(result.Allowed)
 	}
 
-	// Bulk extraction: группируем правила по полям для избежания повторного извлечения
-	fieldRules := make(map[FieldType][]*FilterRule)
-	for _, rule := range ruleSet.rules {
-		fieldRules[rule.Field] = append(fieldRules[rule.Field], rule)
+	if shadow := fp.ruleManager.shadowForDSP(dspURL); shadow != nil {
+		shadow.compareRequest(ruleSet, ctx, result)
 	}
+	return result
+}
 
-	// Проверяем правила группами по полям
-	for field, rules := range fieldRules {
-		fieldValue := extractor.ExtractFieldValue(field, req)
+func (fp *OptimizedFilterProcessor) requestContext(extractor BidRequestExtractor, req interface{}, globalId string) evalContext {
+	ctx := newEvalContext(extractor, extractor, req, fp.now)
+	ctx.globalId = globalId
+	return ctx
+}
+
+// evalRequestTree вычисляет дерево правил DSP. Если в дереве есть правила
+// each по полям imp, дерево вычисляется для каждого imp отдельно.
+// Если все imp прошли, метод не аллоцирует, как бы ни были велики списки в правилах.
+func evalRequestTree(root *ruleNode, ctx evalContext, diverged bool) FilterResult {
+	ctx.diverged = diverged
+	if !root.perImp {
+		allowed := root.eval(&ctx)
+		return FilterResult{Allowed: allowed, SampleRate: ctx.appliedSampleRate(allowed)}
+	}
+
+	impCount := ctx.imps()
+	var rejected impMarks
+	sampleRate := 0.0
+	for i := 0; i < impCount; i++ {
+		ctx.imp = i
+		ctx.sampleRate = 0
+		if !root.eval(&ctx) {
+			rejected.add(i)
+		} else {
+			sampleRate = minSampleRate(sampleRate, ctx.sampleRate)
+		}
+	}
+
+	if rejected.count == 0 {
+		return FilterResult{Allowed: impCount != 0, SampleRate: sampleRate}
+	}
+	if rejected.count == impCount {
+		return FilterResult{Allowed: false}
+	}
 
-		for _, rule := range rules {
-			if !rule.Value.Compare(fieldValue) {
-				return &FilterResult{Allowed: false}
-			}
+	selected := make([]int, 0, impCount-rejected.count)
+	for i := 0; i < impCount; i++ {
+		if !rejected.has(i) {
+			selected = append(selected, i)
 		}
 	}
 
-	return &FilterResult{Allowed: true}
+	return FilterResult{Allowed: true, Imps: selected, SampleRate: sampleRate}
 }
 
-// Оптимизированный метод с bulk extraction для SPP
-func (fp *OptimizedFilterProcessor) processResponseForSPPOptimized(sppURL string, extractor BidResponseExtractor, resp interface{}) *FilterResult {
-	ruleSet := fp.ruleManager.GetCompiledRulesForSPP(sppURL)
-	autoRules := GetAutoRulesForSPP()
+// impMarks - отмеченные imp. Первые 64 хранятся в битах, чтобы не аллоцировать
+// на обычных запросах.
+type impMarks struct {
+	bits  uint64
+	extra map[int]struct{}
+	count int
+}
 
-	if ruleSet == nil && len(autoRules) == 0 {
-		return &FilterResult{Allowed: true}
+func (m *impMarks) add(i int) {
+	if i < 64 {
+		m.bits |= 1 << uint(i)
+	} else {
+		if m.extra == nil {
+			m.extra = make(map[int]struct{})
+		}
+		m.extra[i] = struct{}{}
 	}
+	m.count++
+}
 
-	// Собираем все правила
-	allRules := make([]*FilterRule, 0)
-	if ruleSet != nil {
-		allRules = append(allRules, ruleSet.rules...)
+func (m *impMarks) has(i int) bool {
+	if i < 64 {
+		return m.bits&(1<<uint(i)) != 0
 	}
-	allRules = append(allRules, autoRules...)
+	_, ok := m.extra[i]
+	return ok
+}
 
-	// Bulk extraction: группируем правила по полям
-	fieldRules := make(map[FieldType][]*FilterRule)
-	for _, rule := range allRules {
-		fieldRules[rule.Field] = append(fieldRules[rule.Field], rule)
-	}
+// processResponseForSPPOptimized вычисляет дерево правил SPP и авто-правила
+func (fp *OptimizedFilterProcessor) processResponseForSPPOptimized(sppURL string, extractor BidResponseExtractor, resp interface{}) FilterResult {
+	ctx := newEvalContext(extractor, nil, resp, fp.now)
 
-	// Проверяем правила группами по полям
-	for field, rules := range fieldRules {
-		fieldValue := extractor.ExtractFieldValue(field, resp)
+	passed := autoRulesForSPP.root.eval(&ctx)
+	autoRulesForSPP.counters.record(passed)
+	if !passed {
+		return FilterResult{Allowed: false}
+	}
 
-		for _, rule := range rules {
-			if !rule.Value.Compare(fieldValue) {
-				return &FilterResult{Allowed: false}
-			}
-		}
+	ruleSet := fp.ruleManager.GetCompiledRulesForSPP(sppURL)
+	if ruleSet != nil && ruleSet.root != nil {
+		passed = ruleSet.root.eval(&ctx)
+		ruleSet.counters.record(passed)
 	}
 
-	return &FilterResult{Allowed: true}
+	// Авто-правила у кандидата те же, поэтому он сравнивается только после них
+	if shadow := fp.ruleManager.shadowForSPP(sppURL); shadow != nil {
+		shadow.compareResponse(ruleSet, &ctx, passed)
+	}
+	return FilterResult{Allowed: passed}
 }
@@ -0,0 +1,171 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+)
+
+// SampleKey - по чему запрос попадает в выборку
+type SampleKey string
+
+const (
+	// user.id запроса, без него - globalId
+	SampleKeyUser SampleKey = "user"
+	// globalId запроса на бирже
+	SampleKeyGlobalID SampleKey = "global_id"
+)
+
+// FieldSample - поле правила sample в статистике и explain, значение - корзина
+// запроса от 0 до sampleBuckets-1
+const FieldSample FieldType = "sample"
+
+// ConditionSample - условие правила sample: корзина меньше доли выборки
+const ConditionSample ConditionType = "sample"
+
+const sampleBuckets = 10000
+
+// SampleRule пропускает долю rate запросов. Доля считается по хешу ключа, поэтому
+// один и тот же пользователь всегда либо в выборке, либо нет. Salt позволяет
+// выбрать для DSP других пользователей при той же доле.
+type SampleRule struct {
+	Rate float64   `json:"rate"`
+	Key  SampleKey `json:"key,omitempty"`
+	Salt string    `json:"salt,omitempty"`
+}
+
+func ValidateSampleRule(sample SampleRule) error {
+	if math.IsNaN(sample.Rate) || sample.Rate <= 0 || sample.Rate > 1 {
+		return fmt.Errorf("sample rate must be in (0, 1], got %v", sample.Rate)
+	}
+	switch sample.Key {
+	case "", SampleKeyUser, SampleKeyGlobalID:
+		return nil
+	default:
+		return fmt.Errorf("unknown sample key: %s", sample.Key)
+	}
+}
+
+// ruleSample - скомпилированное правило sample
+type ruleSample struct {
+	rate float64
+	key  SampleKey
+	salt string
+}
+
+// sampleCondition выполняется для корзин меньше порога
+type sampleCondition struct {
+	threshold int
+}
+
+func (sc sampleCondition) Type() ValueType {
+	return ValueTypeInt
+}
+
+func (sc sampleCondition) Compare(fieldValue FieldValue) bool {
+	return fieldValue.Type == ValueTypeInt && fieldValue.Int < sc.threshold
+}
+
+func parseSampleRule(sample SampleRule) (*FilterRule, error) {
+	if err := ValidateSampleRule(sample); err != nil {
+		return nil, err
+	}
+	if sample.Key == "" {
+		sample.Key = SampleKeyUser
+	}
+
+	return &FilterRule{
+		ID:        fmt.Sprintf("%s_%s_%g", FieldSample, sample.Key, sample.Rate),
+		Field:     FieldSample,
+		Condition: ConditionSample,
+		Value:     sampleCondition{threshold: int(math.Round(sample.Rate * sampleBuckets))},
+		sample:    &ruleSample{rate: sample.Rate, key: sample.Key, salt: sample.Salt},
+	}, nil
+}
+
+// hasSampleRule сообщает, есть ли в списке правило sample на любой глубине
+func hasSampleRule(nodes []RuleNode) bool {
+	for _, node := range nodes {
+		if node.Sample != nil || hasSampleRule(node.All) || hasSampleRule(node.Any) {
+			return true
+		}
+		if node.Not != nil && hasSampleRule([]RuleNode{*node.Not}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Идентификатор пользователя для правил sample по ключу user
+var sampleUserID = &FilterRule{Field: "user.id"}
+
+func init() {
+	if err := sampleUserID.bindPaths(); err != nil {
+		panic(err)
+	}
+}
+
+// sampleValue возвращает корзину запроса для правила sample. Без ключа
+// запрос в выборку не попадает.
+func (ctx *evalContext) sampleValue(r *FilterRule) FieldValue {
+	key := ""
+	if r.sample.key == SampleKeyUser && ctx.impExtractor != nil {
+		key = ctx.extractor.ExtractFieldValue(sampleUserID, ctx.data).String
+	}
+	if key == "" {
+		key = ctx.globalId
+	}
+	if key == "" {
+		return FieldValue{}
+	}
+	return NewIntValue(int(sampleHash(r.sample.salt, key) % sampleBuckets))
+}
+
+// matchSample проверяет правило sample и запоминает долю выборки
+func (ctx *evalContext) matchSample(r *FilterRule) bool {
+	if !r.Value.Compare(ctx.sampleValue(r)) {
+		return false
+	}
+	if ctx.sampleRate == 0 {
+		ctx.sampleRate = r.sample.rate
+	} else {
+		ctx.sampleRate *= r.sample.rate
+	}
+	return true
+}
+
+// appliedSampleRate возвращает долю выборки для результата дерева
+func (ctx *evalContext) appliedSampleRate(allowed bool) float64 {
+	if !allowed {
+		return 0
+	}
+	return ctx.sampleRate
+}
+
+// minSampleRate объединяет доли выборки прошедших imp: запрос идёт в DSP по самой
+// узкой выборке. 0 - imp прошёл без выборки, это шире любой доли.
+func minSampleRate(current, rate float64) float64 {
+	if current == 0 || (rate != 0 && rate < current) {
+		return rate
+	}
+	return current
+}
+
+// sampleHash - FNV-1a от соли и ключа, без аллокаций на склейку строк
+func sampleHash(salt, key string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	hash := uint64(offset64)
+	for i := 0; i < len(salt); i++ {
+		hash ^= uint64(salt[i])
+		hash *= prime64
+	}
+	// Нулевой байт между солью и ключом, чтобы "ab"+"c" не совпало с "a"+"bc"
+	hash *= prime64
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= prime64
+	}
+	return hash
+}
@@ -0,0 +1,380 @@
+package filter
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// shadowRules - правила-кандидат, загруженные рядом с активными. Кандидат заменяет
+// правила только перечисленных в нём DSP и SPP, как и загрузка файла правил.
+// Он вычисляется на живом трафике, но решение принимают активные правила.
+type shadowRules struct {
+	dspRules map[string]*shadowRuleSet
+	sppRules map[string]*shadowRuleSet
+	// Начало окна, за которое накоплены расхождения, unix nanos
+	since atomic.Int64
+}
+
+// shadowRuleSet - кандидат для одной DSP или SPP и расхождения его решений с активными
+type shadowRuleSet struct {
+	rules    *CompiledRuleSet
+	compared atomic.Uint64
+	// Активные правила пропустили, кандидат отклонил бы
+	wouldBlock atomic.Uint64
+	// Активные правила отклонили, кандидат пропустил бы
+	wouldAllow atomic.Uint64
+	// Оба пропустили, но с разным набором imp
+	changedImps atomic.Uint64
+}
+
+func (s *shadowRuleSet) reset() {
+	s.compared.Store(0)
+	s.wouldBlock.Store(0)
+	s.wouldAllow.Store(0)
+	s.changedImps.Store(0)
+}
+
+// ShadowReport - расхождения решений кандидата и активных правил за окно
+type ShadowReport struct {
+	Since time.Time             `json:"since"`
+	DSPs  map[string]ShadowDiff `json:"dsps"`
+	SPPs  map[string]ShadowDiff `json:"spps"`
+	// Число реплик роутера, чьи расхождения сложены; 0 - отчёт одной реплики
+	Replicas int `json:"replicas,omitempty"`
+}
+
+type ShadowDiff struct {
+	Compared    uint64 `json:"compared"`
+	WouldBlock  uint64 `json:"would_block"`
+	WouldAllow  uint64 `json:"would_allow"`
+	ChangedImps uint64 `json:"changed_imps"`
+	// Условия кандидата, которые отклонили пропущенные активными правилами запросы
+	CandidateRules []ShadowRuleDiff `json:"candidate_rules"`
+	// Активные условия, чьи отказы кандидат бы снял
+	ActiveRules []ShadowRuleDiff `json:"active_rules"`
+}
+
+type ShadowRuleDiff struct {
+	ID        string        `json:"id"`
+	Field     FieldType     `json:"field"`
+	Condition ConditionType `json:"condition"`
+	Diverged  uint64        `json:"diverged"`
+}
+
+func (r ShadowRuleDiff) key() ruleKey {
+	return ruleKey{id: r.ID, field: r.Field, condition: r.Condition}
+}
+
+// MergeShadowReports складывает отчёты реплик роутера. Since - самое раннее начало
+// окна среди реплик: реплика, перезапущенная позже, считает расхождения с загрузки
+// кандидата на ней.
+func MergeShadowReports(replicas []ShadowReport) ShadowReport {
+	merged := ShadowReport{
+		DSPs:     make(map[string]ShadowDiff),
+		SPPs:     make(map[string]ShadowDiff),
+		Replicas: len(replicas),
+	}
+	for _, report := range replicas {
+		if merged.Since.IsZero() || report.Since.Before(merged.Since) {
+			merged.Since = report.Since
+		}
+		for dspID, diff := range report.DSPs {
+			merged.DSPs[dspID] = merged.DSPs[dspID].merge(diff)
+		}
+		for sppID, diff := range report.SPPs {
+			merged.SPPs[sppID] = merged.SPPs[sppID].merge(diff)
+		}
+	}
+	return merged
+}
+
+func (d ShadowDiff) merge(other ShadowDiff) ShadowDiff {
+	d.Compared += other.Compared
+	d.WouldBlock += other.WouldBlock
+	d.WouldAllow += other.WouldAllow
+	d.ChangedImps += other.ChangedImps
+	addDiverged := func(into *ShadowRuleDiff, from ShadowRuleDiff) {
+		into.Diverged += from.Diverged
+	}
+	if d.CandidateRules == nil {
+		d.CandidateRules = []ShadowRuleDiff{}
+	}
+	if d.ActiveRules == nil {
+		d.ActiveRules = []ShadowRuleDiff{}
+	}
+	d.CandidateRules = mergeRuleLists(d.CandidateRules, other.CandidateRules, addDiverged)
+	d.ActiveRules = mergeRuleLists(d.ActiveRules, other.ActiveRules, addDiverged)
+	return d
+}
+
+// SetShadowRules загружает кандидат вместо предыдущего. Конфиг проверяется так же,
+// как файлы правил; в нём могут быть и DSP, и SPP.
+func (rm *RuleManager) SetShadowRules(config *SimpleRuleConfig) error {
+	if err := ValidateConfig(config); err != nil {
+		return fmt.Errorf("shadow config validation failed: %v", err)
+	}
+
+	shadow := &shadowRules{
+		dspRules: make(map[string]*shadowRuleSet, len(config.DSPs)),
+		sppRules: make(map[string]*shadowRuleSet, len(config.SPPs)),
+	}
+	for dspID, dspSettings := range config.DSPs {
+		ruleSet, err := rm.compileSource(dspSettings.Rules)
+		if err != nil {
+			return fmt.Errorf("Error parsing rule for DSP %s: %v", dspID, err)
+		}
+		shadow.dspRules[dspID] = &shadowRuleSet{rules: ruleSet}
+	}
+	for sppID, sppSettings := range config.SPPs {
+		ruleSet, err := rm.compileSource(sppSettings.Rules)
+		if err != nil {
+			return fmt.Errorf("Error parsing rule for SPP %s: %v", sppID, err)
+		}
+		shadow.sppRules[sppID] = &shadowRuleSet{rules: ruleSet}
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.resetDivergedLocked(shadow)
+	shadow.since.Store(time.Now().UnixNano())
+	rm.shadow.Store(shadow)
+
+	return nil
+}
+
+// PromoteShadowRules атомарно делает кандидат активным: запросы видят либо старые,
+// либо новые правила всех DSP и SPP кандидата. Каждый затронутый набор получает
+// новую ревизию.
+func (rm *RuleManager) PromoteShadowRules(change RuleChange) error {
+	shadow, updates, err := rm.preparePromote(change)
+	if err != nil {
+		return err
+	}
+
+	return rm.promote(shadow, updates)
+}
+
+// preparePromote готовит изменения наборов, которые затрагивает кандидат
+func (rm *RuleManager) preparePromote(change RuleChange) (*shadowRules, []*ruleUpdate, error) {
+	shadow := rm.shadow.Load()
+	if shadow == nil {
+		return nil, nil, fmt.Errorf("no shadow rules loaded")
+	}
+
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	var updates []*ruleUpdate
+	for _, kind := range ruleSetKinds {
+		shadowSets := shadow.dspRules
+		if kind == RuleSetSPP {
+			shadowSets = shadow.sppRules
+		}
+		if len(shadowSets) == 0 {
+			continue
+		}
+
+		ruleSets := make(map[string]*CompiledRuleSet, len(shadowSets))
+		for id, shadowSet := range shadowSets {
+			ruleSets[id] = shadowSet.rules
+		}
+		rules := mergeRuleSets(rm.rulesLocked(kind), ruleSets)
+
+		base := rm.history(kind).revision
+		updates = append(updates, &ruleUpdate{kind: kind, base: base, revision: base + 1, rules: rules, change: change})
+	}
+
+	return shadow, updates, nil
+}
+
+// promote применяет изменения кандидата, если его не заменили после подготовки
+func (rm *RuleManager) promote(shadow *shadowRules, updates []*ruleUpdate) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.shadow.Load() != shadow {
+		return fmt.Errorf("shadow rules changed during promote")
+	}
+	if err := rm.applyLocked(updates...); err != nil {
+		return err
+	}
+	rm.shadow.Store(nil)
+
+	return nil
+}
+
+// DropShadowRules выгружает кандидат
+func (rm *RuleManager) DropShadowRules() {
+	rm.shadow.Store(nil)
+}
+
+// ShadowReport возвращает расхождения с момента загрузки кандидата или сброса
+// статистики. false - кандидат не загружен.
+func (rm *RuleManager) ShadowReport() (ShadowReport, bool) {
+	shadow := rm.shadow.Load()
+	if shadow == nil {
+		return ShadowReport{}, false
+	}
+	dspRules, sppRules := rm.ruleSets()
+
+	report := ShadowReport{
+		Since: time.Unix(0, shadow.since.Load()).UTC(),
+		DSPs:  make(map[string]ShadowDiff, len(shadow.dspRules)),
+		SPPs:  make(map[string]ShadowDiff, len(shadow.sppRules)),
+	}
+	for dspID, shadowSet := range shadow.dspRules {
+		report.DSPs[dspID] = shadowSet.diff(dspRules[dspID])
+	}
+	for sppID, shadowSet := range shadow.sppRules {
+		report.SPPs[sppID] = shadowSet.diff(sppRules[sppID])
+	}
+
+	return report, true
+}
+
+func (s *shadowRuleSet) diff(active *CompiledRuleSet) ShadowDiff {
+	return ShadowDiff{
+		Compared:       s.compared.Load(),
+		WouldBlock:     s.wouldBlock.Load(),
+		WouldAllow:     s.wouldAllow.Load(),
+		ChangedImps:    s.changedImps.Load(),
+		CandidateRules: divergedRules(s.rules),
+		ActiveRules:    divergedRules(active),
+	}
+}
+
+func divergedRules(ruleSet *CompiledRuleSet) []ShadowRuleDiff {
+	rules := []ShadowRuleDiff{}
+	if ruleSet == nil || ruleSet.root == nil {
+		return rules
+	}
+	for _, leaf := range ruleSet.root.leafNodes(nil) {
+		rules = append(rules, ShadowRuleDiff{
+			ID:        leaf.rule.ID,
+			Field:     leaf.rule.Field,
+			Condition: leaf.rule.Condition,
+			Diverged:  leaf.counters.diverged.Load(),
+		})
+	}
+	return rules
+}
+
+// resetShadowStats начинает новое окно расхождений
+func (rm *RuleManager) resetShadowStats() {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	shadow := rm.shadow.Load()
+	if shadow == nil {
+		return
+	}
+	rm.resetDivergedLocked(shadow)
+	for _, shadowSet := range shadow.dspRules {
+		shadowSet.reset()
+		shadowSet.rules.resetStats()
+	}
+	for _, shadowSet := range shadow.sppRules {
+		shadowSet.reset()
+		shadowSet.rules.resetStats()
+	}
+	shadow.since.Store(time.Now().UnixNano())
+}
+
+// resetDivergedLocked обнуляет расхождения условий кандидата и активных правил тех же DSP и SPP
+func (rm *RuleManager) resetDivergedLocked(shadow *shadowRules) {
+	for dspID, shadowSet := range shadow.dspRules {
+		shadowSet.rules.resetDiverged()
+		if active := rm.dspRules[dspID]; active != nil {
+			active.resetDiverged()
+		}
+	}
+	for sppID, shadowSet := range shadow.sppRules {
+		shadowSet.rules.resetDiverged()
+		if active := rm.sppRules[sppID]; active != nil {
+			active.resetDiverged()
+		}
+	}
+}
+
+func (rs *CompiledRuleSet) resetDiverged() {
+	if rs.root == nil {
+		return
+	}
+	for _, leaf := range rs.root.leafNodes(nil) {
+		leaf.counters.diverged.Store(0)
+	}
+}
+
+// shadowForDSP возвращает кандидат для DSP, без блокировки
+func (rm *RuleManager) shadowForDSP(dspID string) *shadowRuleSet {
+	if shadow := rm.shadow.Load(); shadow != nil {
+		return shadow.dspRules[dspID]
+	}
+	return nil
+}
+
+func (rm *RuleManager) shadowForSPP(sppID string) *shadowRuleSet {
+	if shadow := rm.shadow.Load(); shadow != nil {
+		return shadow.sppRules[sppID]
+	}
+	return nil
+}
+
+// compareRequest вычисляет кандидат на запросе и учитывает расхождение с решением
+// активных правил. При расхождении дерево, которое отклонило запрос, вычисляется
+// ещё раз, чтобы отметить отклонившие условия.
+func (s *shadowRuleSet) compareRequest(active *CompiledRuleSet, ctx evalContext, result FilterResult) {
+	shadowResult := FilterResult{Allowed: true}
+	if s.rules.root != nil {
+		shadowResult = evalRequestTree(s.rules.root, ctx, false)
+		s.rules.counters.record(shadowResult.Allowed)
+	}
+	s.compared.Add(1)
+
+	switch {
+	case result.Allowed && !shadowResult.Allowed:
+		s.wouldBlock.Add(1)
+		evalRequestTree(s.rules.root, ctx, true)
+	case !result.Allowed && shadowResult.Allowed:
+		s.wouldAllow.Add(1)
+		evalRequestTree(active.root, ctx, true)
+	case result.Allowed && !sameImps(result.Imps, shadowResult.Imps):
+		s.changedImps.Add(1)
+	}
+}
+
+func (s *shadowRuleSet) compareResponse(active *CompiledRuleSet, ctx *evalContext, allowed bool) {
+	shadowAllowed := true
+	if s.rules.root != nil {
+		shadowAllowed = s.rules.root.eval(ctx)
+		s.rules.counters.record(shadowAllowed)
+	}
+	s.compared.Add(1)
+
+	switch {
+	case allowed && !shadowAllowed:
+		s.wouldBlock.Add(1)
+		ctx.diverged = true
+		s.rules.root.eval(ctx)
+		ctx.diverged = false
+	case !allowed && shadowAllowed:
+		s.wouldAllow.Add(1)
+		ctx.diverged = true
+		active.root.eval(ctx)
+		ctx.diverged = false
+	}
+}
+
+func sameImps(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package filter
+
+import "sync/atomic"
+
+// ruleCounters - счётчики вычислений, обновляются атомарно на горячем пути
+type ruleCounters struct {
+	evaluated atomic.Uint64
+	rejected  atomic.Uint64
+	// Отказы в запросах, где решения кандидата и активных правил разошлись
+	diverged atomic.Uint64
+}
+
+func (c *ruleCounters) record(passed bool) {
+	c.evaluated.Add(1)
+	if !passed {
+		c.rejected.Add(1)
+	}
+}
+
+func (c *ruleCounters) reset() {
+	c.evaluated.Store(0)
+	c.rejected.Store(0)
+}
+
+// FilterStats - статистика фильтра с момента загрузки правил или последнего сброса
+type FilterStats struct {
+	DSPs map[string]RuleSetStats `json:"dsps"`
+	SPPs map[string]RuleSetStats `json:"spps"`
+	// Авто-правила проверяются для ответов любой SPP до её собственных правил
+	AutoRulesSPP RuleSetStats `json:"auto_rules_spp"`
+	// Число реплик роутера, чьи счётчики сложены; 0 - статистика одной реплики
+	Replicas int `json:"replicas,omitempty"`
+}
+
+// RuleSetStats - статистика правил одной DSP или SPP. Evaluated и Rejected считают
+// запросы (ответы), Rejected - не прошедшие фильтр.
+type RuleSetStats struct {
+	Evaluated uint64      `json:"evaluated"`
+	Rejected  uint64      `json:"rejected"`
+	Rules     []RuleStats `json:"rules"`
+}
+
+// RuleStats - статистика условия. Условие вычисляется, только если до него дошла
+// очередь в группе, а правила each вычисляются для каждого imp. Rejected - сколько
+// раз условие не выполнилось; внутри any и not это ещё не отказ всему запросу.
+type RuleStats struct {
+	ID        string        `json:"id"`
+	Field     FieldType     `json:"field"`
+	Condition ConditionType `json:"condition"`
+	Evaluated uint64        `json:"evaluated"`
+	Rejected  uint64        `json:"rejected"`
+}
+
+// Stats возвращает счётчики по всем DSP и SPP
+func (fp *OptimizedFilterProcessor) Stats() FilterStats {
+	dspRules, sppRules := fp.ruleManager.ruleSets()
+
+	stats := FilterStats{
+		DSPs:         make(map[string]RuleSetStats, len(dspRules)),
+		SPPs:         make(map[string]RuleSetStats, len(sppRules)),
+		AutoRulesSPP: autoRulesForSPP.stats(),
+	}
+	for dspID, ruleSet := range dspRules {
+		stats.DSPs[dspID] = ruleSet.stats()
+	}
+	for sppID, ruleSet := range sppRules {
+		stats.SPPs[sppID] = ruleSet.stats()
+	}
+
+	return stats
+}
+
+// ResetStats обнуляет все счётчики и начинает новое окно расхождений кандидата.
+// Запросы, которые вычисляются во время сброса, могут попасть в статистику частично.
+func (fp *OptimizedFilterProcessor) ResetStats() {
+	dspRules, sppRules := fp.ruleManager.ruleSets()
+
+	fp.ruleManager.resetShadowStats()
+	autoRulesForSPP.resetStats()
+	for _, ruleSet := range dspRules {
+		ruleSet.resetStats()
+	}
+	for _, ruleSet := range sppRules {
+		ruleSet.resetStats()
+	}
+}
+
+func (rs *CompiledRuleSet) stats() RuleSetStats {
+	stats := RuleSetStats{
+		Evaluated: rs.counters.evaluated.Load(),
+		Rejected:  rs.counters.rejected.Load(),
+		Rules:     make([]RuleStats, 0, len(rs.rules)),
+	}
+	if rs.root == nil {
+		return stats
+	}
+	for _, leaf := range rs.root.leafNodes(nil) {
+		stats.Rules = append(stats.Rules, RuleStats{
+			ID:        leaf.rule.ID,
+			Field:     leaf.rule.Field,
+			Condition: leaf.rule.Condition,
+			Evaluated: leaf.counters.evaluated.Load(),
+			Rejected:  leaf.counters.rejected.Load(),
+		})
+	}
+
+	return stats
+}
+
+func (rs *CompiledRuleSet) resetStats() {
+	rs.counters.reset()
+	if rs.root == nil {
+		return
+	}
+	for _, leaf := range rs.root.leafNodes(nil) {
+		leaf.counters.reset()
+	}
+}
+
+// MergeFilterStats складывает статистику реплик роутера. Условия одной DSP или SPP
+// сопоставляются по id, полю и типу условия в порядке следования, условия, которых
+// нет у части реплик (реплики на разных ревизиях), добавляются в конец.
+func MergeFilterStats(replicas []FilterStats) FilterStats {
+	merged := FilterStats{
+		DSPs:     make(map[string]RuleSetStats),
+		SPPs:     make(map[string]RuleSetStats),
+		Replicas: len(replicas),
+	}
+	for _, stats := range replicas {
+		for dspID, ruleSet := range stats.DSPs {
+			merged.DSPs[dspID] = merged.DSPs[dspID].merge(ruleSet)
+		}
+		for sppID, ruleSet := range stats.SPPs {
+			merged.SPPs[sppID] = merged.SPPs[sppID].merge(ruleSet)
+		}
+		merged.AutoRulesSPP = merged.AutoRulesSPP.merge(stats.AutoRulesSPP)
+	}
+	return merged
+}
+
+func (s RuleSetStats) merge(other RuleSetStats) RuleSetStats {
+	s.Evaluated += other.Evaluated
+	s.Rejected += other.Rejected
+	if s.Rules == nil {
+		s.Rules = make([]RuleStats, 0, len(other.Rules))
+	}
+	s.Rules = mergeRuleLists(s.Rules, other.Rules, func(into *RuleStats, from RuleStats) {
+		into.Evaluated += from.Evaluated
+		into.Rejected += from.Rejected
+	})
+	return s
+}
+
+// ruleKey - признаки условия, по которым сопоставляются счётчики реплик
+type ruleKey struct {
+	id        string
+	field     FieldType
+	condition ConditionType
+}
+
+func (r RuleStats) key() ruleKey {
+	return ruleKey{id: r.ID, field: r.Field, condition: r.Condition}
+}
+
+// mergeRuleLists добавляет счётчики from к условиям into: n-е условие с одинаковым
+// ruleKey в from складывается с n-м таким же условием в into
+func mergeRuleLists[T interface{ key() ruleKey }](into, from []T, add func(into *T, from T)) []T {
+	positions := make(map[ruleKey][]int, len(into))
+	for i := range into {
+		key := into[i].key()
+		positions[key] = append(positions[key], i)
+	}
+	for _, rule := range from {
+		key := rule.key()
+		if indices := positions[key]; len(indices) > 0 {
+			add(&into[indices[0]], rule)
+			positions[key] = indices[1:]
+			continue
+		}
+		into = append(into, rule)
+	}
+	return into
+}
@@ -0,0 +1,505 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Ключи наборов правил в Redis. Hash tag держит оба ключа в одном слоте кластера,
+// чтобы promote кандидата менял правила DSP и SPP одним скриптом.
+const (
+	ruleStoreKeyPrefix = "filter:{rules}:"
+	ruleStoreChannel   = "filter:rules:updates"
+
+	defaultRuleReconcileInterval = 30 * time.Second
+)
+
+// Источник правил реплики в RuleSyncStatus
+const (
+	RuleSourceFile  = "file"
+	RuleSourceRedis = "redis"
+)
+
+var ErrRuleStoreUnavailable = errors.New("rule store is unavailable")
+
+// storedRules - версия набора правил в Redis
+type storedRules struct {
+	RuleVersion
+	Config SimpleRuleConfig `json:"config"`
+}
+
+// saveRulesScript сохраняет наборы правил, только если их ревизии в Redis не
+// изменились с подготовки изменения, и оповещает реплики. KEYS - ключи наборов;
+// ARGV[1] - канал, далее по четыре значения на набор: ожидаемая ревизия, новая
+// ревизия, правила и уведомление.
+var saveRulesScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	local current = tonumber(redis.call('HGET', key, 'revision')) or 0
+	if current ~= tonumber(ARGV[i * 4 - 2]) then
+		return 0
+	end
+end
+for i, key in ipairs(KEYS) do
+	redis.call('HSET', key, 'revision', ARGV[i * 4 - 1], 'rules', ARGV[i * 4])
+	redis.call('PUBLISH', ARGV[1], ARGV[i * 4 + 1])
+end
+return 1
+`)
+
+// RuleSyncStatus - ревизии правил, которые обслуживает реплика
+type RuleSyncStatus struct {
+	Source string             `json:"source"`
+	DSP    RuleRevisionStatus `json:"dsp"`
+	SPP    RuleRevisionStatus `json:"spp"`
+	// Последняя успешная сверка с Redis
+	SyncedAt *time.Time `json:"synced_at,omitempty"`
+	// Ошибка последнего обращения к Redis, пусто - Redis доступен
+	Error string `json:"error,omitempty"`
+}
+
+type RuleRevisionStatus struct {
+	Revision uint64 `json:"revision"`
+	// Ревизия в Redis на момент последней сверки
+	StoredRevision uint64 `json:"stored_revision,omitempty"`
+}
+
+// SyncStatus возвращает ревизии правил реплики без общего хранилища
+func (rm *RuleManager) SyncStatus() RuleSyncStatus {
+	return RuleSyncStatus{
+		Source: RuleSourceFile,
+		DSP:    RuleRevisionStatus{Revision: rm.Revision(RuleSetDSP)},
+		SPP:    RuleRevisionStatus{Revision: rm.Revision(RuleSetSPP)},
+	}
+}
+
+// RedisRuleStore раздаёт правила DSP и SPP всем репликам роутера через Redis.
+// Redis - источник правил: в него пишут только изменения через админ API, сначала
+// в Redis под новой ревизией и только потом локально. Остальные реплики загружают
+// изменение по уведомлению pub/sub, а периодическая сверка догоняет пропущенные
+// уведомления. Каждую загруженную ревизию реплика записывает в свои файлы правил
+// и без Redis стартует с них.
+type RedisRuleStore struct {
+	ruleManager       *RuleManager
+	fileLoader        *FileRuleLoader
+	client            *redis.Client
+	reconcileInterval time.Duration
+
+	// Изменения правил через реплику и загрузка чужих ревизий идут по очереди
+	mu sync.Mutex
+
+	statusMu sync.RWMutex
+	stored   map[RuleSetKind]uint64
+	syncedAt time.Time
+	lastErr  error
+}
+
+func NewRedisRuleStore(
+	ruleManager *RuleManager,
+	fileLoader *FileRuleLoader,
+	client *redis.Client,
+	reconcileInterval time.Duration,
+) *RedisRuleStore {
+	if reconcileInterval <= 0 {
+		reconcileInterval = defaultRuleReconcileInterval
+	}
+
+	return &RedisRuleStore{
+		ruleManager:       ruleManager,
+		fileLoader:        fileLoader,
+		client:            client,
+		reconcileInterval: reconcileInterval,
+		stored:            make(map[RuleSetKind]uint64),
+	}
+}
+
+// Start загружает правила и синхронизирует их с Redis до отмены ctx. Набор правил
+// берётся из Redis, а из файла - если в Redis его ещё нет или Redis недоступен.
+// Правила из файла в Redis не сохраняются: они попадут туда с первым изменением.
+func (s *RedisRuleStore) Start(ctx context.Context) {
+	for _, kind := range ruleSetKinds {
+		if err := s.start(ctx, kind); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	go s.watch(ctx)
+}
+
+func (s *RedisRuleStore) start(ctx context.Context, kind RuleSetKind) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, readErr := s.read(ctx, kind)
+	if readErr != nil {
+		log.Printf("Warning: cannot read %s rules from Redis, falling back to file: %v", kind, readErr)
+	}
+	if stored != nil {
+		loadErr := s.loadLocked(kind, stored)
+		if loadErr == nil {
+			s.recordSync(kind, stored.Revision)
+			return nil
+		}
+		log.Printf("Warning: cannot load %s rules from Redis, falling back to file: %v", kind, loadErr)
+	}
+
+	if fileErr := s.fileLoader.loadRules(kind); fileErr != nil {
+		return fmt.Errorf("cannot load %s rules from file: %w", kind, fileErr)
+	}
+	log.Printf("%s rules revision %d loaded from file", kind, s.ruleManager.Revision(kind))
+	// Ревизия в Redis неизвестна: её сверит первая синхронизация
+	if readErr != nil {
+		return fmt.Errorf("serving %s rules from local file until Redis is available", kind)
+	}
+
+	s.recordSync(kind, 0)
+	return nil
+}
+
+// Reconcile сверяет правила реплики с Redis и загружает ревизию из Redis,
+// если она отличается от локальной
+func (s *RedisRuleStore) Reconcile(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.syncAllLocked(ctx)
+}
+
+// UpdateRules применяет правила, как RuleManager.UpdateRules, но на всех репликах.
+// Без Redis изменение отклоняется с ErrRuleStoreUnavailable, чтобы реплики не разошлись.
+func (s *RedisRuleStore) UpdateRules(
+	ctx context.Context,
+	kind RuleSetKind,
+	config *SimpleRuleConfig,
+	change RuleChange,
+) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.syncLocked(ctx, kind); err != nil {
+		return 0, err
+	}
+	update, err := s.ruleManager.prepareUpdate(kind, config, change)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.commitLocked(ctx, update)
+}
+
+// Rollback откатывает правила на всех репликах. Ревизия берётся из истории этой
+// реплики: реплика, запущенная позже ревизии, её не знает.
+func (s *RedisRuleStore) Rollback(ctx context.Context, kind RuleSetKind, revision uint64, change RuleChange) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.syncLocked(ctx, kind); err != nil {
+		return 0, err
+	}
+	update, err := s.ruleManager.prepareRollback(kind, revision, change)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.commitLocked(ctx, update)
+}
+
+// PromoteShadowRules делает активным кандидат этой реплики на всех репликах.
+// Правила DSP и SPP кандидата сохраняются в Redis одним скриптом.
+func (s *RedisRuleStore) PromoteShadowRules(ctx context.Context, change RuleChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.syncAllLocked(ctx); err != nil {
+		return err
+	}
+	shadow, updates, err := s.ruleManager.preparePromote(change)
+	if err != nil {
+		return err
+	}
+
+	if len(updates) > 0 {
+		if err := s.save(ctx, updates...); err != nil {
+			return err
+		}
+	}
+	for _, update := range updates {
+		s.recordSync(update.kind, update.revision)
+	}
+	// Как в commitLocked: сохранённые в Redis ревизии применяются и при отказе promote
+	if err := s.ruleManager.promote(shadow, updates); err != nil {
+		for _, update := range updates {
+			if err := s.rollForwardLocked(update, err); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, update := range updates {
+		s.saveFile(update)
+	}
+
+	return nil
+}
+
+// Status возвращает ревизии, которые обслуживает реплика, и состояние связи с Redis
+func (s *RedisRuleStore) Status() RuleSyncStatus {
+	status := s.ruleManager.SyncStatus()
+	status.Source = RuleSourceRedis
+
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	status.DSP.StoredRevision = s.stored[RuleSetDSP]
+	status.SPP.StoredRevision = s.stored[RuleSetSPP]
+	if !s.syncedAt.IsZero() {
+		syncedAt := s.syncedAt
+		status.SyncedAt = &syncedAt
+	}
+	if s.lastErr != nil {
+		status.Error = s.lastErr.Error()
+	}
+
+	return status
+}
+
+func (s *RedisRuleStore) watch(ctx context.Context) {
+	pubsub := s.client.Subscribe(ctx, ruleStoreChannel)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	ticker := time.NewTicker(s.reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			kind, revision, err := parseRuleNotification(msg.Payload)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+				continue
+			}
+			// Своё изменение или уже загруженное сверкой
+			if revision == s.ruleManager.Revision(kind) {
+				continue
+			}
+			if err := s.sync(ctx, kind); err != nil {
+				log.Printf("Warning: cannot load %s rules revision %d: %v", kind, revision, err)
+			}
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				log.Printf("Warning: rule store reconcile failed: %v", err)
+			}
+		}
+	}
+}
+
+func (s *RedisRuleStore) sync(ctx context.Context, kind RuleSetKind) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.syncLocked(ctx, kind)
+}
+
+func (s *RedisRuleStore) syncAllLocked(ctx context.Context) error {
+	for _, kind := range ruleSetKinds {
+		if err := s.syncLocked(ctx, kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncLocked приводит набор реплики к ревизии из Redis, даже если она меньше
+// локальной (Redis восстановлен из копии). Если в Redis набора нет, реплика
+// обслуживает свой набор, но в Redis его не сохраняет.
+func (s *RedisRuleStore) syncLocked(ctx context.Context, kind RuleSetKind) error {
+	stored, err := s.read(ctx, kind)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		s.recordSync(kind, 0)
+		return nil
+	}
+
+	if stored.Revision != s.ruleManager.Revision(kind) {
+		if err := s.loadLocked(kind, stored); err != nil {
+			return err
+		}
+	}
+
+	s.recordSync(kind, stored.Revision)
+	return nil
+}
+
+// loadLocked заменяет набор реплики ревизией из Redis
+func (s *RedisRuleStore) loadLocked(kind RuleSetKind, stored *storedRules) error {
+	stored.Config.Version = stored.Revision
+	update, err := s.ruleManager.prepareReplace(kind, &stored.Config, stored.RuleChange, stored.RolledBackTo)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ruleManager.apply(update); err != nil {
+		return err
+	}
+	log.Printf("%s rules revision %d loaded from Redis", kind, update.revision)
+	s.saveFile(update)
+
+	return nil
+}
+
+// commitLocked сохраняет изменение в Redis и применяет его. Правила уже проверены
+// и скомпилированы при подготовке, поэтому apply отказывает только по ErrStaleRevision:
+// набор реплики изменили в обход хранилища (SetDSPRules, ClearAllDSPRules). Ревизию
+// в Redis уже видят другие реплики, поэтому реплика догоняет её, см. rollForwardLocked.
+func (s *RedisRuleStore) commitLocked(ctx context.Context, update *ruleUpdate) (uint64, error) {
+	if err := s.save(ctx, update); err != nil {
+		return 0, err
+	}
+	s.recordSync(update.kind, update.revision)
+
+	if _, err := s.ruleManager.apply(update); err != nil {
+		if err := s.rollForwardLocked(update, err); err != nil {
+			return 0, err
+		}
+		return update.revision, nil
+	}
+	s.saveFile(update)
+
+	return update.revision, nil
+}
+
+// rollForwardLocked применяет сохранённую в Redis ревизию поверх текущего набора
+// реплики, когда её не удалось применить как подготовленное изменение
+func (s *RedisRuleStore) rollForwardLocked(update *ruleUpdate, applyErr error) error {
+	log.Printf("Warning: %s rules revision %d saved to Redis but not applied, rolling forward: %v", update.kind, update.revision, applyErr)
+	err := s.loadLocked(update.kind, &storedRules{
+		RuleVersion: RuleVersion{
+			Revision:     update.revision,
+			RuleChange:   update.change,
+			RolledBackTo: update.rolledBackTo,
+		},
+		Config: update.config(),
+	})
+	if err != nil {
+		return fmt.Errorf("%s rules revision %d saved to Redis but not applied: %w", update.kind, update.revision, err)
+	}
+	return nil
+}
+
+func (s *RedisRuleStore) read(ctx context.Context, kind RuleSetKind) (*storedRules, error) {
+	data, err := s.client.HGet(ctx, ruleStoreKey(kind), "rules").Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, s.unavailable(err)
+	}
+
+	var stored storedRules
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("cannot decode %s rules from Redis: %w", kind, err)
+	}
+	return &stored, nil
+}
+
+// save сохраняет изменения в Redis, если ревизии наборов в Redis не изменились
+// с последней сверки
+func (s *RedisRuleStore) save(ctx context.Context, updates ...*ruleUpdate) error {
+	keys := make([]string, 0, len(updates))
+	args := make([]interface{}, 0, 1+4*len(updates))
+	args = append(args, ruleStoreChannel)
+
+	createdAt := time.Now().UTC()
+	for _, update := range updates {
+		data, err := json.Marshal(storedRules{
+			RuleVersion: RuleVersion{
+				Revision:     update.revision,
+				RuleChange:   update.change,
+				CreatedAt:    createdAt,
+				RolledBackTo: update.rolledBackTo,
+			},
+			Config: update.config(),
+		})
+		if err != nil {
+			return err
+		}
+		keys = append(keys, ruleStoreKey(update.kind))
+		args = append(args, s.storedRevision(update.kind), update.revision, data, ruleNotification(update.kind, update.revision))
+	}
+
+	saved, err := saveRulesScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return s.unavailable(err)
+	}
+	if saved == 0 {
+		return fmt.Errorf("%w: rules in Redis were changed by another replica", ErrStaleRevision)
+	}
+	return nil
+}
+
+// saveFile записывает ревизию в файл правил. Ошибка не мешает обслуживать правила,
+// поэтому только логируется: файлы могут быть смонтированы только для чтения.
+func (s *RedisRuleStore) saveFile(update *ruleUpdate) {
+	if err := s.fileLoader.saveRules(update.kind, update.config()); err != nil {
+		log.Printf("Warning: cannot save %s rules revision %d to file: %v", update.kind, update.revision, err)
+	}
+}
+
+func (s *RedisRuleStore) unavailable(err error) error {
+	s.statusMu.Lock()
+	s.lastErr = err
+	s.statusMu.Unlock()
+
+	return fmt.Errorf("%w: %v", ErrRuleStoreUnavailable, err)
+}
+
+func (s *RedisRuleStore) storedRevision(kind RuleSetKind) uint64 {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+
+	return s.stored[kind]
+}
+
+func (s *RedisRuleStore) recordSync(kind RuleSetKind, stored uint64) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	s.stored[kind] = stored
+	s.syncedAt = time.Now().UTC()
+	s.lastErr = nil
+}
+
+func ruleStoreKey(kind RuleSetKind) string {
+	return ruleStoreKeyPrefix + string(kind)
+}
+
+func ruleNotification(kind RuleSetKind, revision uint64) string {
+	return fmt.Sprintf("%s:%d", kind, revision)
+}
+
+func parseRuleNotification(payload string) (RuleSetKind, uint64, error) {
+	kind, revision, ok := strings.Cut(payload, ":")
+	if !ok || (RuleSetKind(kind) != RuleSetDSP && RuleSetKind(kind) != RuleSetSPP) {
+		return "", 0, fmt.Errorf("malformed rule notification %q", payload)
+	}
+	parsed, err := strconv.ParseUint(revision, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed rule notification %q: %v", payload, err)
+	}
+	return RuleSetKind(kind), parsed, nil
+}
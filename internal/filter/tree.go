@@ -0,0 +1,185 @@
+package filter
+
+import "time"
+
+type nodeOp uint8
+
+const (
+	nodeRule nodeOp = iota
+	nodeAll
+	nodeAny
+	nodeNot
+)
+
+// ruleNode - скомпилированное дерево правил. Группы вычисляются с коротким
+// замыканием в порядке условий из файла.
+type ruleNode struct {
+	op       nodeOp
+	rule     *FilterRule
+	children []*ruleNode
+	// В поддереве есть правило each по полю imp, результат зависит от imp
+	perImp bool
+	// Только для условий
+	counters ruleCounters
+}
+
+func newRuleLeaf(rule *FilterRule) *ruleNode {
+	if rule.paths == ([rootCount]*fieldPath{}) && rule.timeField == timeNone && rule.sample == nil {
+		// Правила, собранные в коде, а не разобранные из конфигурации
+		_ = rule.bindPaths()
+	}
+	return &ruleNode{
+		op:     nodeRule,
+		rule:   rule,
+		perImp: rule.impField && rule.ImpMatch == ImpMatchEach,
+	}
+}
+
+func newRuleGroup(op nodeOp, children []*ruleNode) *ruleNode {
+	node := &ruleNode{op: op, children: children}
+	for _, child := range children {
+		node.perImp = node.perImp || child.perImp
+	}
+	return node
+}
+
+// leaves возвращает правила дерева в порядке обхода
+func (n *ruleNode) leaves(rules []*FilterRule) []*FilterRule {
+	if n.op == nodeRule {
+		return append(rules, n.rule)
+	}
+	for _, child := range n.children {
+		rules = child.leaves(rules)
+	}
+	return rules
+}
+
+// leafNodes возвращает условия дерева вместе со счётчиками
+func (n *ruleNode) leafNodes(nodes []*ruleNode) []*ruleNode {
+	if n.op == nodeRule {
+		return append(nodes, n)
+	}
+	for _, child := range n.children {
+		nodes = child.leafNodes(nodes)
+	}
+	return nodes
+}
+
+type fieldExtractor interface {
+	ExtractFieldValue(rule *FilterRule, data interface{}) FieldValue
+	MatchFieldValues(rule *FilterRule, data interface{}) bool
+}
+
+// evalContext - данные, на которых вычисляется дерево
+type evalContext struct {
+	extractor fieldExtractor
+	// nil для ответов DSP
+	impExtractor BidRequestExtractor
+	data         interface{}
+	// Текущий imp для правил each, -1 - дерево не зависит от imp
+	imp      int
+	impCount int
+	// Повторное вычисление при расхождении с кандидатом: условия отмечают отказы
+	// в diverged вместо обычных счётчиков
+	diverged bool
+	// Часы для полей time.*, время берётся один раз при первом условии
+	clock func() time.Time
+	at    time.Time
+	// globalId запроса для правил sample, пустой для ответов
+	globalId string
+	// Доля выборки по прошедшим правилам sample, 0 - правил не было
+	sampleRate float64
+}
+
+func newEvalContext(extractor fieldExtractor, impExtractor BidRequestExtractor, data interface{}, clock func() time.Time) evalContext {
+	return evalContext{
+		extractor:    extractor,
+		impExtractor: impExtractor,
+		data:         data,
+		clock:        clock,
+		imp:          -1,
+		impCount:     -1,
+	}
+}
+
+func (ctx *evalContext) imps() int {
+	if ctx.impCount < 0 {
+		ctx.impCount = 0
+		if ctx.impExtractor != nil {
+			ctx.impCount = ctx.impExtractor.ImpCount(ctx.data)
+		}
+	}
+	return ctx.impCount
+}
+
+func (n *ruleNode) eval(ctx *evalContext) bool {
+	switch n.op {
+	case nodeAll:
+		for _, child := range n.children {
+			if !child.eval(ctx) {
+				return false
+			}
+		}
+		return true
+	case nodeAny:
+		// Доля выборки берётся только из прошедшей ветки
+		sampleRate := ctx.sampleRate
+		for _, child := range n.children {
+			if child.eval(ctx) {
+				return true
+			}
+			ctx.sampleRate = sampleRate
+		}
+		return false
+	case nodeNot:
+		sampleRate := ctx.sampleRate
+		passed := !n.children[0].eval(ctx)
+		ctx.sampleRate = sampleRate
+		return passed
+	default:
+		passed := n.rule.match(ctx)
+		if !ctx.diverged {
+			n.counters.record(passed)
+		} else if !passed {
+			n.counters.diverged.Add(1)
+		}
+		return passed
+	}
+}
+
+func (r *FilterRule) match(ctx *evalContext) bool {
+	if r.timeField != timeNone {
+		return r.Value.Compare(ctx.timeValue(r))
+	}
+	if r.sample != nil {
+		return ctx.matchSample(r)
+	}
+	if r.listField {
+		return ctx.extractor.MatchFieldValues(r, ctx.data)
+	}
+	if ctx.impExtractor == nil || !r.impField {
+		return r.Value.Compare(ctx.extractor.ExtractFieldValue(r, ctx.data))
+	}
+
+	switch r.ImpMatch {
+	case ImpMatchAny:
+		for i := 0; i < ctx.imps(); i++ {
+			if r.Value.Compare(ctx.impExtractor.ExtractImpFieldValue(r, ctx.data, i)) {
+				return true
+			}
+		}
+		return false
+	case ImpMatchAll:
+		for i := 0; i < ctx.imps(); i++ {
+			if !r.Value.Compare(ctx.impExtractor.ExtractImpFieldValue(r, ctx.data, i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		if ctx.imp < 0 {
+			return false
+		}
+		return r.Value.Compare(ctx.impExtractor.ExtractImpFieldValue(r, ctx.data, ctx.imp))
+	}
+}
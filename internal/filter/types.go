@@ -2,8 +2,12 @@ package filter
 
 import (
 	"encoding/json"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
 )
 
+// FieldType - путь поля в сообщении ORTB через точку, см. compileFieldPath.
+// Константы - поля, с которыми работает код, и прежние короткие имена.
 type FieldType string
 
 const (
@@ -17,12 +21,37 @@ const (
 	FieldBidNurl       FieldType = "bid.nurl"
 	FieldBidBurl       FieldType = "bid.burl"
 
+	// Регион по ISO-3166-2 без кода страны и город, из запроса SSP или базы MaxMind
+	FieldDeviceRegion FieldType = "device.geo.region"
+	FieldDeviceCity   FieldType = "device.geo.city"
+
 	FieldBidPrice    FieldType = "bid.price"
 	FieldBidID       FieldType = "bid.id"
 	FieldBidAdID     FieldType = "bid.adid"
 	FieldBidImpID    FieldType = "bid.impid"
 	FieldSeatBidSeat FieldType = "seatbid.seat"
 	FieldBidArray    FieldType = "bid.array"
+	// Категории ставок в канонической таксономии, см. пакет taxonomy
+	FieldBidCat FieldType = "bid.cat"
+
+	// Время обработки запроса по часам из SimpleRule.Clock, значения int
+	FieldTimeHour FieldType = "time.hour"
+	// Минута от начала суток, 0-1439
+	FieldTimeMinute FieldType = "time.minute"
+	// День недели, 1 - понедельник, 7 - воскресенье
+	FieldTimeWeekday FieldType = "time.weekday"
+)
+
+// ImpMatch задаёт, как правило по полю imp применяется к запросу с несколькими imp
+type ImpMatch string
+
+const (
+	// Правило проверяется для каждого imp, DSP получает только прошедшие imp
+	ImpMatchEach ImpMatch = "each"
+	// Запрос проходит, если правило выполнено хотя бы для одного imp
+	ImpMatchAny ImpMatch = "any"
+	// Запрос проходит, только если правило выполнено для всех imp
+	ImpMatchAll ImpMatch = "all"
 )
 
 type ValueType string
@@ -45,6 +74,17 @@ const (
 	ConditionBetween      ConditionType = "between"
 	ConditionNotBetween   ConditionType = "not_between"
 	ConditionExists       ConditionType = "exists"
+	// Значение из списка, проверяется поиском в hash set
+	ConditionIn    ConditionType = "in"
+	ConditionNotIn ConditionType = "not_in"
+	// Только для строк
+	ConditionPrefix   ConditionType = "prefix"
+	ConditionSuffix   ConditionType = "suffix"
+	ConditionContains ConditionType = "contains"
+	// Регулярное выражение компилируется при загрузке правил
+	ConditionRegex ConditionType = "regex"
+	// IPv4 и IPv6 подсети, только для device.ip
+	ConditionCIDR ConditionType = "cidr"
 )
 
 type FieldValue struct {
@@ -52,6 +92,8 @@ type FieldValue struct {
 	Int    int
 	Float  float64
 	String string
+	// Дробные поля фильтра денежные и сравниваются в micros
+	Micros money.Micros
 }
 
 func NewIntValue(value int) FieldValue {
@@ -59,7 +101,13 @@ func NewIntValue(value int) FieldValue {
 }
 
 func NewFloatValue(value float64) FieldValue {
-	return FieldValue{Type: ValueTypeFloat, Float: value}
+	return FieldValue{Type: ValueTypeFloat, Float: value, Micros: money.FromFloat64(value)}
+}
+
+// NewMoneyValue переводит цену из протокола по её десятичному представлению,
+// поэтому float32 1.1 не окажется больше правила "greater_than 1.1"
+func NewMoneyValue(value float32) FieldValue {
+	return FieldValue{Type: ValueTypeFloat, Float: float64(value), Micros: money.FromFloat32(value)}
 }
 
 func NewStringValue(value string) FieldValue {
@@ -76,20 +124,85 @@ type FilterRule struct {
 	Field     FieldType
 	Condition ConditionType
 	Value     ConditionValue
+	// Только для полей imp
+	ImpMatch ImpMatch
+
+	// Путь поля для каждой версии ORTB, см. bindPaths
+	paths    [rootCount]*fieldPath
+	impField bool
+	// Поле repeated string вне imp: условие проверяется по всем значениям
+	listField bool
+	// Для полей time.*
+	timeField timeField
+	clock     ruleClock
+	// Для правил sample
+	sample *ruleSample
 }
 
+// Форматы правил в поле format
+const (
+	// Плоский список правил, объединённых через AND
+	ConfigVersionFlat = "1.0"
+	// Список правил, в котором есть вложенные группы all/any/not
+	ConfigVersionTree = "2.0"
+)
+
 type SimpleRuleConfig struct {
-	Version string                 `json:"version"`
+	// Ревизия правил, см. RuleManager.UpdateRules. 0 - следующая по порядку.
+	Version uint64                 `json:"version"`
+	Format  string                 `json:"format"`
 	DSPs    map[string]DSPSettings `json:"dsps"`
 	SPPs    map[string]SPPSettings `json:"spps"`
 }
 
+// UnmarshalJSON принимает и старые файлы, где в version записан формат правил
+func (c *SimpleRuleConfig) UnmarshalJSON(data []byte) error {
+	type config SimpleRuleConfig
+	var raw struct {
+		config
+		Version json.RawMessage `json:"version"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = SimpleRuleConfig(raw.config)
+
+	if len(raw.Version) == 0 || string(raw.Version) == "null" {
+		return nil
+	}
+	var legacyFormat string
+	if err := json.Unmarshal(raw.Version, &legacyFormat); err == nil {
+		if c.Format == "" {
+			c.Format = legacyFormat
+		}
+		return nil
+	}
+	return json.Unmarshal(raw.Version, &c.Version)
+}
+
+// Правила из списка объединяются через AND
 type DSPSettings struct {
-	Rules []SimpleRule `json:"rules"`
+	Rules []RuleNode `json:"rules"`
 }
 
 type SPPSettings struct {
-	Rules []SimpleRule `json:"rules"`
+	Rules     []RuleNode `json:"rules"`
+	Blocklist *Blocklist `json:"blocklist,omitempty"`
+}
+
+// RuleNode - условие либо группа условий. Группы поддерживаются с формата 2.0:
+// all - все условия группы, any - хотя бы одно, not - отрицание условия.
+// sample - выборка доли трафика, только для DSP.
+type RuleNode struct {
+	SimpleRule
+	All    []RuleNode  `json:"all,omitempty"`
+	Any    []RuleNode  `json:"any,omitempty"`
+	Not    *RuleNode   `json:"not,omitempty"`
+	Sample *SampleRule `json:"sample,omitempty"`
+}
+
+func (n RuleNode) isGroup() bool {
+	return n.All != nil || n.Any != nil || n.Not != nil
 }
 
 type SimpleRule struct {
@@ -97,18 +210,35 @@ type SimpleRule struct {
 	Condition ConditionType   `json:"condition"`
 	ValueType ValueType       `json:"value_type"`
 	Value     json.RawMessage `json:"value"`
+	// Для полей imp, по умолчанию each
+	ImpMatch ImpMatch `json:"imp_match,omitempty"`
+	// Для полей time.*, по умолчанию exchange
+	Clock Clock `json:"clock,omitempty"`
 }
 
 type FilterResult struct {
 	Allowed bool `json:"allowed"`
+	// Индексы imp запроса, прошедших правила, если прошли не все; nil - все imp
+	Imps []int `json:"imps,omitempty"`
+	// Доля трафика по правилам sample, через которые прошёл запрос; 0 - без выборки.
+	// Если imp прошли по разным выборкам - самая узкая из них.
+	SampleRate float64 `json:"sample_rate,omitempty"`
 }
 
 // BidRequestExtractor интерфейс для stateless извлечения значений
 type BidRequestExtractor interface {
-	ExtractFieldValue(field FieldType, req interface{}) FieldValue
+	ExtractFieldValue(rule *FilterRule, req interface{}) FieldValue
+	// MatchFieldValues проверяет правило по всем значениям repeated поля, как site.cat
+	MatchFieldValues(rule *FilterRule, req interface{}) bool
+	// ImpCount и ExtractImpFieldValue извлекают поля imp по отдельности
+	ImpCount(req interface{}) int
+	ExtractImpFieldValue(rule *FilterRule, req interface{}, imp int) FieldValue
+	// UserUTCOffset возвращает смещение местного времени пользователя в минутах
+	UserUTCOffset(req interface{}) (int, bool)
 }
 
 // BidResponseExtractor интерфейс для stateless извлечения значений
 type BidResponseExtractor interface {
-	ExtractFieldValue(field FieldType, resp interface{}) FieldValue
+	ExtractFieldValue(rule *FilterRule, resp interface{}) FieldValue
+	MatchFieldValues(rule *FilterRule, resp interface{}) bool
 }
@@ -3,8 +3,41 @@ package filter
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 )
 
+var numericConditions = map[ConditionType]struct{}{
+	ConditionEqual:        {},
+	ConditionNotEqual:     {},
+	ConditionGreaterThan:  {},
+	ConditionGreaterEqual: {},
+	ConditionLessThan:     {},
+	ConditionLessEqual:    {},
+	ConditionBetween:      {},
+	ConditionNotBetween:   {},
+	ConditionIn:           {},
+	ConditionNotIn:        {},
+	ConditionExists:       {},
+}
+
+// Условия, допустимые для каждого типа значения
+var valueTypeConditions = map[ValueType]map[ConditionType]struct{}{
+	ValueTypeInt:   numericConditions,
+	ValueTypeFloat: numericConditions,
+	ValueTypeString: {
+		ConditionEqual:    {},
+		ConditionNotEqual: {},
+		ConditionIn:       {},
+		ConditionNotIn:    {},
+		ConditionPrefix:   {},
+		ConditionSuffix:   {},
+		ConditionContains: {},
+		ConditionRegex:    {},
+		ConditionCIDR:     {},
+		ConditionExists:   {},
+	},
+}
+
 func ValidateSimpleRule(simpleRule SimpleRule) error {
 
 	if simpleRule.Field == "" {
@@ -17,15 +50,52 @@ func ValidateSimpleRule(simpleRule SimpleRule) error {
 		return fmt.Errorf("value_type is required")
 	}
 
-	if simpleRule.Condition == ConditionExists {
-		switch simpleRule.ValueType {
-		case ValueTypeString, ValueTypeInt, ValueTypeFloat:
-			return nil
-		default:
-			return fmt.Errorf("unknown value type for exists condition: %s", simpleRule.ValueType)
+	fieldType, err := fieldValueType(simpleRule.Field)
+	if err != nil {
+		return err
+	}
+
+	if simpleRule.Clock != "" {
+		if !isTimeField(simpleRule.Field) {
+			return fmt.Errorf("clock is only supported for time fields, got %s", simpleRule.Field)
+		}
+		if _, err := parseClock(simpleRule.Clock); err != nil {
+			return err
 		}
 	}
 
+	switch simpleRule.ImpMatch {
+	case "":
+	case ImpMatchEach, ImpMatchAny, ImpMatchAll:
+		if !IsImpField(simpleRule.Field) {
+			return fmt.Errorf("imp_match is only supported for imp fields, got %s", simpleRule.Field)
+		}
+	default:
+		return fmt.Errorf("unknown imp_match: %s", simpleRule.ImpMatch)
+	}
+
+	conditions, ok := valueTypeConditions[simpleRule.ValueType]
+	if !ok {
+		return fmt.Errorf("unknown value type: %s", simpleRule.ValueType)
+	}
+	if _, ok := conditions[simpleRule.Condition]; !ok {
+		return fmt.Errorf("condition %s is not supported for value_type %s", simpleRule.Condition, simpleRule.ValueType)
+	}
+	if simpleRule.Condition != ConditionExists && simpleRule.ValueType != fieldType {
+		return fmt.Errorf("field %s has value_type %s, got %s", simpleRule.Field, fieldType, simpleRule.ValueType)
+	}
+
+	switch simpleRule.Condition {
+	case ConditionExists:
+		return nil
+	case ConditionCIDR:
+		if simpleRule.Field != FieldDeviceIP {
+			return fmt.Errorf("cidr condition is only supported for %s, got %s", FieldDeviceIP, simpleRule.Field)
+		}
+		_, err := parseCIDRCondition(simpleRule.Value)
+		return err
+	}
+
 	switch simpleRule.ValueType {
 	case ValueTypeInt:
 		return validateIntCondition(simpleRule.Value, simpleRule.Condition)
@@ -48,6 +118,14 @@ func validateIntCondition(value json.RawMessage, cond ConditionType) error {
 		if len(values) != 2 {
 			return fmt.Errorf("requires exactly 2 values, got %d", len(values))
 		}
+	case ConditionIn, ConditionNotIn:
+		var values []int
+		if err := json.Unmarshal(value, &values); err != nil {
+			return fmt.Errorf("invalid int array: %v", err)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("requires at least 1 value")
+		}
 	default:
 		var singleValue int
 		if err := json.Unmarshal(value, &singleValue); err != nil {
@@ -59,6 +137,10 @@ func validateIntCondition(value json.RawMessage, cond ConditionType) error {
 
 func validateStringCondition(value json.RawMessage, cond ConditionType) error {
 	switch cond {
+	case ConditionIn, ConditionNotIn:
+		if _, err := parseStringList(value); err != nil {
+			return err
+		}
 	default:
 		var singleValue string
 		if err := json.Unmarshal(value, &singleValue); err != nil {
@@ -67,6 +149,11 @@ func validateStringCondition(value json.RawMessage, cond ConditionType) error {
 		if singleValue == "" {
 			return fmt.Errorf("string value cannot be empty for condition %s", cond)
 		}
+		if cond == ConditionRegex {
+			if _, err := regexp.Compile(singleValue); err != nil {
+				return fmt.Errorf("invalid regex %q: %v", singleValue, err)
+			}
+		}
 	}
 	return nil
 }
@@ -81,6 +168,14 @@ func validateFloatCondition(value json.RawMessage, cond ConditionType) error {
 		if len(values) != 2 {
 			return fmt.Errorf("requires exactly 2 values, got %d", len(values))
 		}
+	case ConditionIn, ConditionNotIn:
+		var values []float64
+		if err := json.Unmarshal(value, &values); err != nil {
+			return fmt.Errorf("invalid float array: %v", err)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("requires at least 1 value")
+		}
 	default:
 		var singleValue float64
 		if err := json.Unmarshal(value, &singleValue); err != nil {
@@ -91,8 +186,8 @@ func validateFloatCondition(value json.RawMessage, cond ConditionType) error {
 }
 
 func ValidateConfig(config *SimpleRuleConfig) error {
-	if config.Version == "" {
-		return fmt.Errorf("version is required")
+	if config.Format == "" {
+		return fmt.Errorf("format is required")
 	}
 
 	if (config.DSPs == nil || len(config.DSPs) == 0) &&
@@ -116,8 +211,8 @@ func ValidateConfig(config *SimpleRuleConfig) error {
 }
 
 func ValidateDSPConfig(config *SimpleRuleConfig) error {
-	if config.Version == "" {
-		return fmt.Errorf("version is required")
+	if config.Format == "" {
+		return fmt.Errorf("format is required")
 	}
 
 	if config.DSPs == nil || len(config.DSPs) == 0 {
@@ -129,17 +224,8 @@ func ValidateDSPConfig(config *SimpleRuleConfig) error {
 			return fmt.Errorf("DSP ID cannot be empty")
 		}
 
-		seenRules := make(map[string]bool)
-		for _, simpleRule := range dspSettings.Rules {
-			ruleKey := fmt.Sprintf("%s_%s", simpleRule.Field, simpleRule.Condition)
-			if seenRules[ruleKey] {
-				return fmt.Errorf("duplicate rule for DSP %s: %s", dspID, ruleKey)
-			}
-			seenRules[ruleKey] = true
-
-			if err := ValidateSimpleRule(simpleRule); err != nil {
-				return fmt.Errorf("invalid rule for DSP %s: %v", dspID, err)
-			}
+		if err := validateRuleList(config.Format, dspSettings.Rules); err != nil {
+			return fmt.Errorf("invalid rules for DSP %s: %v", dspID, err)
 		}
 	}
 
@@ -147,8 +233,8 @@ func ValidateDSPConfig(config *SimpleRuleConfig) error {
 }
 
 func ValidateSPPConfig(config *SimpleRuleConfig) error {
-	if config.Version == "" {
-		return fmt.Errorf("version is required")
+	if config.Format == "" {
+		return fmt.Errorf("format is required")
 	}
 
 	if config.SPPs == nil || len(config.SPPs) == 0 {
@@ -160,19 +246,105 @@ func ValidateSPPConfig(config *SimpleRuleConfig) error {
 			return fmt.Errorf("SPP ID cannot be empty")
 		}
 
+		if err := validateRuleList(config.Format, sppSettings.Rules); err != nil {
+			return fmt.Errorf("invalid rules for SPP %s: %v", sppID, err)
+		}
+		if hasSampleRule(sppSettings.Rules) {
+			return fmt.Errorf("invalid rules for SPP %s: sample rules are only supported for DSPs", sppID)
+		}
+		if sppSettings.Blocklist != nil {
+			if err := validateBlocklist(sppSettings.Blocklist); err != nil {
+				return fmt.Errorf("invalid blocklist for SPP %s: %v", sppID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Максимальная вложенность групп правил
+const maxRuleDepth = 16
+
+// validateRuleList проверяет список правил по правилам его формата: в 1.0 только
+// условия без повторов field_condition, в 2.0 - любые группы.
+func validateRuleList(format string, rules []RuleNode) error {
+	switch format {
+	case ConfigVersionFlat:
 		seenRules := make(map[string]bool)
-		for _, simpleRule := range sppSettings.Rules {
-			ruleKey := fmt.Sprintf("%s_%s", simpleRule.Field, simpleRule.Condition)
+		for _, node := range rules {
+			if node.isGroup() {
+				return fmt.Errorf("rule groups require format %s", ConfigVersionTree)
+			}
+			if node.Sample != nil {
+				if err := validateRuleNode(node, 1); err != nil {
+					return err
+				}
+				continue
+			}
+
+			ruleKey := fmt.Sprintf("%s_%s", node.Field, node.Condition)
 			if seenRules[ruleKey] {
-				return fmt.Errorf("duplicate rule for SPP %s: %s", sppID, ruleKey)
+				return fmt.Errorf("duplicate rule %s", ruleKey)
 			}
 			seenRules[ruleKey] = true
 
-			if err := ValidateSimpleRule(simpleRule); err != nil {
-				return fmt.Errorf("invalid rule for SPP %s: %v", sppID, err)
+			if err := ValidateSimpleRule(node.SimpleRule); err != nil {
+				return err
+			}
+		}
+	case ConfigVersionTree:
+		for _, node := range rules {
+			if err := validateRuleNode(node, 1); err != nil {
+				return err
 			}
 		}
+	default:
+		return fmt.Errorf("unsupported format %s", format)
 	}
 
 	return nil
 }
+
+func validateRuleNode(node RuleNode, depth int) error {
+	if depth > maxRuleDepth {
+		return fmt.Errorf("rule groups are nested deeper than %d", maxRuleDepth)
+	}
+
+	kinds := 0
+	if node.SimpleRule.Field != "" || node.SimpleRule.Condition != "" || node.SimpleRule.ValueType != "" {
+		kinds++
+	}
+	for _, isSet := range []bool{node.All != nil, node.Any != nil, node.Not != nil, node.Sample != nil} {
+		if isSet {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("rule must be exactly one of condition, all, any, not or sample")
+	}
+
+	switch {
+	case node.All != nil:
+		return validateRuleGroup("all", node.All, depth)
+	case node.Any != nil:
+		return validateRuleGroup("any", node.Any, depth)
+	case node.Not != nil:
+		return validateRuleNode(*node.Not, depth+1)
+	case node.Sample != nil:
+		return ValidateSampleRule(*node.Sample)
+	default:
+		return ValidateSimpleRule(node.SimpleRule)
+	}
+}
+
+func validateRuleGroup(name string, nodes []RuleNode, depth int) error {
+	if len(nodes) == 0 {
+		return fmt.Errorf("%s group cannot be empty", name)
+	}
+	for _, node := range nodes {
+		if err := validateRuleNode(node, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
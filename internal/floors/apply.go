@@ -0,0 +1,97 @@
+package floors
+
+import (
+	"fmt"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// impression - общие для версий протокола поля импрессии, нужные для флора.
+// bidFloor и bidFloorCur указывают на поля самой импрессии.
+type impression struct {
+	id          string
+	format      Format
+	size        string
+	bidFloor    **float32
+	bidFloorCur **string
+}
+
+// Apply_V2_4 поднимает imp.bidFloor до флора биржи и возвращает решения по каждой импрессии
+func (m *Manager) Apply_V2_4(req *ortb_V2_4.BidRequest, ssp, country string) []Decision {
+	imps := make([]impression, 0, len(req.GetImp()))
+	for _, imp := range req.GetImp() {
+		if imp == nil {
+			continue
+		}
+
+		i := impression{id: imp.GetId(), bidFloor: &imp.BidFloor, bidFloorCur: &imp.BidFloorCur}
+		switch {
+		case imp.Banner != nil:
+			i.format = FormatBanner
+			i.size = size(imp.Banner.GetW(), imp.Banner.GetH())
+		case imp.Native != nil:
+			i.format = FormatNative
+		}
+		imps = append(imps, i)
+	}
+
+	return m.apply(imps, ssp, country)
+}
+
+func (m *Manager) Apply_V2_5(req *ortb_V2_5.BidRequest, ssp, country string) []Decision {
+	imps := make([]impression, 0, len(req.GetImp()))
+	for _, imp := range req.GetImp() {
+		if imp == nil {
+			continue
+		}
+
+		i := impression{id: imp.GetId(), bidFloor: &imp.BidFloor, bidFloorCur: &imp.BidFloorCur}
+		switch {
+		case imp.Banner != nil:
+			i.format = FormatBanner
+			i.size = size(imp.Banner.GetW(), imp.Banner.GetH())
+		case imp.Native != nil:
+			i.format = FormatNative
+		}
+		imps = append(imps, i)
+	}
+
+	return m.apply(imps, ssp, country)
+}
+
+func (m *Manager) apply(imps []impression, ssp, country string) []Decision {
+	hour := m.Hour()
+	decisions := make([]Decision, 0, len(imps))
+
+	for _, imp := range imps {
+		key := Key{SSP: ssp, Country: country, Hour: hour, Format: imp.format, Size: imp.size}
+
+		var sspFloor float32
+		if *imp.bidFloor != nil {
+			sspFloor = **imp.bidFloor
+		}
+		var sspFloorCur string
+		if *imp.bidFloorCur != nil {
+			sspFloorCur = **imp.bidFloorCur
+		}
+
+		decision := m.Floor(imp.id, money.FromFloat32(sspFloor), sspFloorCur, key)
+		if decision.Source == SourceExchange {
+			bidFloor := decision.Floor.Float32()
+			*imp.bidFloor = &bidFloor
+			*imp.bidFloorCur = &decision.Cur
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions
+}
+
+func size(w, h int32) string {
+	if w <= 0 || h <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", w, h)
+}
@@ -0,0 +1,231 @@
+package floors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Manager хранит правила флоров биржи. Чтение lock-free,
+// изменения через админ API сериализуются и сохраняются в файл правил.
+type Manager struct {
+	path  string
+	rates *currency.Rates
+	now   func() time.Time
+
+	config atomic.Pointer[RuleConfig]
+	mu     sync.Mutex
+}
+
+// NewManager загружает правила из path; пустой path - правила только через админ API
+func NewManager(path string, rates *currency.Rates) (*Manager, error) {
+	m := &Manager{
+		path:  path,
+		rates: rates,
+		now:   time.Now,
+	}
+	m.config.Store(&RuleConfig{Version: "1.0"})
+
+	if path != "" {
+		if err := m.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Manager) Reload() error {
+	if m.path == "" {
+		return fmt.Errorf("floor rules file is not configured")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return err
+	}
+
+	var config RuleConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("invalid floor rules file %s: %v", m.path, err)
+	}
+	if err := normalizeConfig(&config); err != nil {
+		return fmt.Errorf("floor rules validation failed: %v", err)
+	}
+
+	m.config.Store(&config)
+	return nil
+}
+
+// Replace подменяет все правила и, если задан файл, сохраняет их в него
+func (m *Manager) Replace(config RuleConfig) error {
+	if err := normalizeConfig(&config); err != nil {
+		return fmt.Errorf("floor rules validation failed: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.path != "" {
+		if err := writeConfig(m.path, &config); err != nil {
+			return fmt.Errorf("cannot save floor rules: %v", err)
+		}
+	}
+
+	m.config.Store(&config)
+	return nil
+}
+
+func (m *Manager) Rules() RuleConfig {
+	return *m.config.Load()
+}
+
+// Lookup возвращает самое точное подходящее правило,
+// при равной точности - с наибольшим флором
+func (m *Manager) Lookup(key Key) (Rule, bool) {
+	var (
+		best      Rule
+		bestScore = -1
+		found     bool
+	)
+
+	for _, rule := range m.config.Load().Rules {
+		if !rule.matches(key) {
+			continue
+		}
+		score := rule.specificity()
+		if score > bestScore || (score == bestScore && m.higher(rule, best)) {
+			best, bestScore, found = rule, score, true
+		}
+	}
+
+	return best, found
+}
+
+// Floor считает итоговый флор импрессии: максимум из флора SSP и флора биржи.
+// Флор биржи переводится в валюту флора SSP.
+func (m *Manager) Floor(impID string, sspFloor money.Micros, sspFloorCur string, key Key) Decision {
+	decision := Decision{
+		ImpID:    impID,
+		Source:   SourceNone,
+		Floor:    sspFloor,
+		Cur:      currency.Normalize(sspFloorCur),
+		SspFloor: sspFloor,
+	}
+	if sspFloor > 0 {
+		decision.Source = SourceSSP
+	}
+
+	rule, ok := m.Lookup(key)
+	if !ok || rule.floor <= 0 {
+		return decision
+	}
+
+	// Если SSP не прислала флор, валюту задаёт правило
+	if sspFloor <= 0 && sspFloorCur == "" {
+		decision.Cur = rule.Cur
+	}
+
+	// Округление вверх не даёт флору биржи опуститься при конвертации
+	exchangeFloor, err := m.rates.Convert(rule.floor, rule.Cur, decision.Cur, money.ROUND_UP)
+	if err != nil {
+		return decision
+	}
+
+	decision.ExchangeFloor = exchangeFloor
+	decision.RuleID = rule.ID
+	if exchangeFloor > sspFloor {
+		decision.Floor = exchangeFloor
+		decision.Source = SourceExchange
+	}
+
+	return decision
+}
+
+// Hour - текущий час по UTC для подбора правил
+func (m *Manager) Hour() int {
+	return m.now().UTC().Hour()
+}
+
+func (m *Manager) higher(a, b Rule) bool {
+	if a.Cur == b.Cur {
+		return a.floor > b.floor
+	}
+	converted, err := m.rates.Convert(a.floor, a.Cur, b.Cur, money.ROUND_HALF_EVEN)
+	if err != nil {
+		return false
+	}
+	return converted > b.floor
+}
+
+func normalizeConfig(config *RuleConfig) error {
+	if config.Version == "" {
+		config.Version = "1.0"
+	}
+
+	seen := make(map[string]struct{}, len(config.Rules))
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.ID == "" {
+			return fmt.Errorf("rule #%d has no id", i)
+		}
+		if _, ok := seen[rule.ID]; ok {
+			return fmt.Errorf("duplicate rule id %s", rule.ID)
+		}
+		seen[rule.ID] = struct{}{}
+
+		if rule.Floor < 0 {
+			return fmt.Errorf("rule %s: floor must not be negative", rule.ID)
+		}
+		switch rule.Format {
+		case "", FormatBanner, FormatNative:
+		default:
+			return fmt.Errorf("rule %s: unknown format %s", rule.ID, rule.Format)
+		}
+		for _, hour := range rule.Hours {
+			if hour < 0 || hour > 23 {
+				return fmt.Errorf("rule %s: hour %d out of range", rule.ID, hour)
+			}
+		}
+
+		rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+		rule.Size = strings.ToLower(strings.TrimSpace(rule.Size))
+		rule.Cur = currency.Normalize(rule.Cur)
+		rule.floor = money.FromFloat64(rule.Floor)
+	}
+
+	return nil
+}
+
+func writeConfig(path string, config *RuleConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
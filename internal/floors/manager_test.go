@@ -0,0 +1,106 @@
+package floors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+func newTestManager(t *testing.T, rules ...Rule) *Manager {
+	m, err := NewManager("", nil)
+	require.NoError(t, err)
+	require.NoError(t, m.Replace(RuleConfig{Rules: rules}))
+	m.now = func() time.Time { return time.Date(2025, 1, 1, 19, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func TestFloor(t *testing.T) {
+	m := newTestManager(t,
+		Rule{ID: "default", Floor: 0.1},
+		Rule{ID: "us", Country: "us", Floor: 0.3},
+		Rule{ID: "us_banner_night", Country: "US", Format: FormatBanner, Hours: []int{1, 2}, Floor: 2},
+		Rule{ID: "us_banner_evening", Country: "US", Format: FormatBanner, Hours: []int{19}, Floor: 0.2},
+	)
+
+	tests := []struct {
+		name     string
+		sspFloor money.Micros
+		key      Key
+		expected Decision
+	}{
+		{
+			name:     "SSP floor is higher than exchange floor",
+			sspFloor: 500_000,
+			key:      Key{Country: "DE", Hour: 19},
+			expected: Decision{ImpID: "1", Source: SourceSSP, Floor: 500_000, Cur: "USD", SspFloor: 500_000, ExchangeFloor: 100_000, RuleID: "default"},
+		},
+		{
+			name:     "Exchange floor raises SSP floor",
+			sspFloor: 200_000,
+			key:      Key{Country: "us", Hour: 19},
+			expected: Decision{ImpID: "1", Source: SourceExchange, Floor: 300_000, Cur: "USD", SspFloor: 200_000, ExchangeFloor: 300_000, RuleID: "us"},
+		},
+		{
+			name:     "Most specific rule wins even with lower floor",
+			sspFloor: 0,
+			key:      Key{Country: "US", Format: FormatBanner, Hour: 19},
+			expected: Decision{ImpID: "1", Source: SourceExchange, Floor: 200_000, Cur: "USD", SspFloor: 0, ExchangeFloor: 200_000, RuleID: "us_banner_evening"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, m.Floor("1", tt.sspFloor, "", tt.key))
+		})
+	}
+}
+
+func TestFloorWithoutRules(t *testing.T) {
+	m := newTestManager(t)
+
+	assert.Equal(t, SourceNone, m.Floor("1", 0, "", Key{}).Source)
+	assert.Equal(t, SourceSSP, m.Floor("1", 100_000, "", Key{}).Source)
+}
+
+func TestApply(t *testing.T) {
+	m := newTestManager(t, Rule{ID: "300x250", Size: "300x250", Floor: 1})
+
+	w, h := int32(300), int32(250)
+	impID, sspFloor := "imp1", float32(0.5)
+	req := &ortb_V2_5.BidRequest{
+		Imp: []*ortb_V2_5.Imp{{
+			Id:       &impID,
+			BidFloor: &sspFloor,
+			Banner:   &ortb_V2_5.Banner{W: &w, H: &h},
+		}},
+	}
+
+	decisions := m.Apply_V2_5(req, "ssp1", "US")
+
+	require.Len(t, decisions, 1)
+	assert.Equal(t, SourceExchange, decisions[0].Source)
+	assert.Equal(t, float32(1), req.Imp[0].GetBidFloor())
+	assert.Equal(t, "USD", req.Imp[0].GetBidFloorCur())
+}
+
+func TestReplaceValidationAndPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "floors.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":"1.0","rules":[]}`), 0o644))
+
+	m, err := NewManager(path, nil)
+	require.NoError(t, err)
+
+	assert.Error(t, m.Replace(RuleConfig{Rules: []Rule{{ID: "a"}, {ID: "a"}}}))
+	assert.Error(t, m.Replace(RuleConfig{Rules: []Rule{{ID: "a", Hours: []int{24}}}}))
+	assert.Error(t, m.Replace(RuleConfig{Rules: []Rule{{ID: "a", Format: "video"}}}))
+
+	require.NoError(t, m.Replace(RuleConfig{Rules: []Rule{{ID: "a", Floor: 1}}}))
+	require.NoError(t, m.Reload())
+	assert.Len(t, m.Rules().Rules, 1)
+}
@@ -0,0 +1,110 @@
+package floors
+
+import (
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+type Format string
+
+const (
+	FormatBanner Format = "banner"
+	FormatNative Format = "native"
+)
+
+// Source - откуда взят итоговый флор импрессии
+type Source string
+
+const (
+	SourceNone     Source = "none"
+	SourceSSP      Source = "ssp"
+	SourceExchange Source = "exchange"
+)
+
+// Rule - флор биржи для сегмента трафика.
+// Пустое поле (или пустой список часов) означает "любое значение".
+type Rule struct {
+	ID      string `json:"id"`
+	SSP     string `json:"ssp,omitempty"`
+	Country string `json:"country,omitempty"`
+	// Размер баннера в формате WxH, например 300x250
+	Size   string `json:"size,omitempty"`
+	Format Format `json:"format,omitempty"`
+	// Часы суток по UTC, 0-23
+	Hours []int   `json:"hours,omitempty"`
+	Floor float64 `json:"floor"`
+	Cur   string  `json:"cur,omitempty"`
+
+	floor money.Micros
+}
+
+type RuleConfig struct {
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Key - признаки импрессии, по которым подбирается правило
+type Key struct {
+	SSP     string
+	Country string
+	Size    string
+	Format  Format
+	Hour    int
+}
+
+// Decision фиксирует выбор флора по импрессии: источник и сработавшее правило
+type Decision struct {
+	ImpID         string       `json:"impId"`
+	Source        Source       `json:"source"`
+	Floor         money.Micros `json:"floorMicros"`
+	Cur           string       `json:"cur"`
+	SspFloor      money.Micros `json:"sspFloorMicros"`
+	ExchangeFloor money.Micros `json:"exchangeFloorMicros,omitempty"`
+	RuleID        string       `json:"ruleId,omitempty"`
+}
+
+func (r *Rule) matches(key Key) bool {
+	if r.SSP != "" && r.SSP != key.SSP {
+		return false
+	}
+	if r.Country != "" && r.Country != strings.ToUpper(key.Country) {
+		return false
+	}
+	if r.Size != "" && r.Size != key.Size {
+		return false
+	}
+	if r.Format != "" && r.Format != key.Format {
+		return false
+	}
+	if len(r.Hours) > 0 {
+		for _, hour := range r.Hours {
+			if hour == key.Hour {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// specificity - число заданных в правиле признаков; побеждает самое точное правило
+func (r *Rule) specificity() int {
+	n := 0
+	if r.SSP != "" {
+		n++
+	}
+	if r.Country != "" {
+		n++
+	}
+	if r.Size != "" {
+		n++
+	}
+	if r.Format != "" {
+		n++
+	}
+	if len(r.Hours) > 0 {
+		n++
+	}
+	return n
+}
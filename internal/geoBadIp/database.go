@@ -0,0 +1,144 @@
+package geoBadIp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filewatch"
+)
+
+// Database - файл mmdb, который можно заменить без перезапуска: MaxMind выпускает
+// базы раз в неделю. Поиск идёт без глобальной блокировки, старая база закрывается
+// после того, как закончатся начатые в ней поиски.
+type Database struct {
+	path     string
+	current  atomic.Pointer[openedDatabase]
+	modTime  atomic.Int64
+	loadedAt atomic.Int64
+	// Reload из Watch и admin API не должны открывать базу одновременно
+	reloadMu sync.Mutex
+}
+
+type openedDatabase struct {
+	reader *maxminddb.Reader
+	// Поиск держит RLock, закрытие ждёт Lock
+	mu     sync.RWMutex
+	closed bool
+}
+
+// DatabaseInfo - сведения о загруженной базе для admin API
+type DatabaseInfo struct {
+	Path         string    `json:"path"`
+	DatabaseType string    `json:"databaseType"`
+	BuildEpoch   uint      `json:"buildEpoch"`
+	BuildTime    time.Time `json:"buildTime"`
+	LoadedAt     time.Time `json:"loadedAt"`
+}
+
+func OpenDatabase(path string) (*Database, error) {
+	d := &Database{path: path}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload открывает файл заново и подменяет базу. Новая база проверяется целиком
+// и должна быть того же типа, что и текущая: City не заменится на ASN.
+func (d *Database) Reload() error {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return err
+	}
+
+	reader, err := maxminddb.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("cannot open mmdb %s: %w", d.path, err)
+	}
+	if err := reader.Verify(); err != nil {
+		reader.Close()
+		return fmt.Errorf("invalid mmdb %s: %w", d.path, err)
+	}
+	old := d.current.Load()
+	if old != nil && old.reader.Metadata.DatabaseType != reader.Metadata.DatabaseType {
+		databaseType := reader.Metadata.DatabaseType
+		reader.Close()
+		return fmt.Errorf(
+			"mmdb %s changed type from %s to %s",
+			d.path,
+			old.reader.Metadata.DatabaseType,
+			databaseType,
+		)
+	}
+
+	d.current.Store(&openedDatabase{reader: reader})
+	d.modTime.Store(info.ModTime().UnixNano())
+	d.loadedAt.Store(time.Now().UnixNano())
+	if old != nil {
+		go old.close()
+	}
+	return nil
+}
+
+// Watch перечитывает базу при изменении времени модификации файла
+func (d *Database) Watch(ctx context.Context, interval time.Duration) {
+	filewatch.Watch(ctx, d.path, interval, &d.modTime, "mmdb", d.Reload)
+}
+
+// Lookup ищет ip в текущей базе. Если базу подменили между загрузкой указателя
+// и блокировкой, поиск повторяется в новой.
+func (d *Database) Lookup(ip net.IP, result any) error {
+	for {
+		db := d.current.Load()
+		db.mu.RLock()
+		if db.closed {
+			db.mu.RUnlock()
+			if d.current.Load() == db {
+				return DatabaseClosedError
+			}
+			continue
+		}
+		err := db.reader.Lookup(ip, result)
+		db.mu.RUnlock()
+		return err
+	}
+}
+
+func (d *Database) Info() DatabaseInfo {
+	metadata := d.current.Load().reader.Metadata
+	return DatabaseInfo{
+		Path:         d.path,
+		DatabaseType: metadata.DatabaseType,
+		BuildEpoch:   metadata.BuildEpoch,
+		BuildTime:    time.Unix(int64(metadata.BuildEpoch), 0).UTC(),
+		LoadedAt:     time.Unix(0, d.loadedAt.Load()).UTC(),
+	}
+}
+
+func (d *Database) Close() {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+
+	d.current.Load().close()
+}
+
+// close ждёт окончания начатых поисков и освобождает файл
+func (o *openedDatabase) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+	o.closed = true
+	o.reader.Close()
+}
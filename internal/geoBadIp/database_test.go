@@ -0,0 +1,122 @@
+package geoBadIp
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseReloadUnderLookups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestDatabase(t, path, "GeoIP2-City", 1, testCityRecord("US"))
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, uint(1), db.Info().BuildEpoch)
+	assert.Equal(t, "GeoIP2-City", db.Info().DatabaseType)
+
+	// Поиски идут всё время подмены базы и видят либо старую, либо новую
+	ctx, cancel := context.WithCancel(context.Background())
+	var lookups, failures atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				var rec GeoIPRecord
+				err := db.Lookup(net.ParseIP("8.8.8.8"), &rec)
+				if err != nil || (rec.Country.ISOCode != "US" && rec.Country.ISOCode != "DE") {
+					failures.Add(1)
+				}
+				lookups.Add(1)
+			}
+		}()
+	}
+
+	countries := []string{"DE", "US"}
+	for epoch := uint64(2); epoch <= 21; epoch++ {
+		old := db.current.Load()
+		writeTestDatabase(t, path, "GeoIP2-City", epoch, testCityRecord(countries[epoch%2]))
+		require.NoError(t, db.Reload())
+		assert.Equal(t, uint(epoch), db.Info().BuildEpoch)
+
+		// Старая база закрывается, когда закончатся начатые в ней поиски
+		assert.Eventually(t, func() bool {
+			old.mu.RLock()
+			defer old.mu.RUnlock()
+			return old.closed
+		}, time.Second, time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return lookups.Load() > 100 }, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+	assert.Zero(t, failures.Load())
+
+	var rec GeoIPRecord
+	require.NoError(t, db.Lookup(net.ParseIP("8.8.8.8"), &rec))
+	assert.Equal(t, "US", rec.Country.ISOCode)
+}
+
+func TestDatabaseReloadKeepsCurrentOnInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestDatabase(t, path, "GeoIP2-City", 1, testCityRecord("US"))
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+
+	// База другого типа и повреждённый файл не заменяют загруженную
+	writeTestDatabase(t, path, "GeoLite2-ASN", 2, map[string]any{"autonomous_system_number": uint32(1)})
+	assert.ErrorContains(t, db.Reload(), "changed type")
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0o644))
+	assert.Error(t, db.Reload())
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, db.Reload())
+
+	assert.Equal(t, uint(1), db.Info().BuildEpoch)
+	var rec GeoIPRecord
+	require.NoError(t, db.Lookup(net.ParseIP("8.8.8.8"), &rec))
+	assert.Equal(t, "US", rec.Country.ISOCode)
+
+	db.Close()
+	assert.ErrorIs(t, db.Lookup(net.ParseIP("8.8.8.8"), &rec), DatabaseClosedError)
+}
+
+func TestDatabaseWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestDatabase(t, path, "GeoIP2-City", 1, testCityRecord("US"))
+
+	db, err := OpenDatabase(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.Watch(ctx, time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Время изменения сдвигается, чтобы новая база не совпала с прежней по mtime
+	writeTestDatabase(t, path, "GeoIP2-City", 2, testCityRecord("DE"))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	assert.Eventually(t, func() bool { return db.Info().BuildEpoch == 2 }, time.Second, time.Millisecond)
+	var rec GeoIPRecord
+	require.NoError(t, db.Lookup(net.ParseIP("8.8.8.8"), &rec))
+	assert.Equal(t, "DE", rec.Country.ISOCode)
+}
@@ -22,4 +22,8 @@ var (
 	AnonymousIpError = errors.New(
 		"Anonymous IP",
 	)
+
+	DatabaseClosedError = errors.New(
+		"GeoIP database is closed",
+	)
 )
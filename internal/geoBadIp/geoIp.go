@@ -1,53 +1,219 @@
-package geoBadIp
-
-import (
-	"fmt"
-	"net"
-
-	"github.com/oschwald/maxminddb-golang"
-)
-
-type GeoIPRecord struct {
-	Country struct {
-		ISOCode string `maxminddb:"iso_code"`
-	} `maxminddb:"country"`
-}
-
-type GeoIPService struct {
-	db *maxminddb.Reader
-}
-
-func NewGeoIPService(dbPath string) (*GeoIPService, error) {
-	reader, err := maxminddb.Open(dbPath)
-	if err != nil {
-		return nil, err
-	}
-	return &GeoIPService{db: reader}, nil
-}
-
-func (g *GeoIPService) Close() {
-	if g.db != nil {
-		g.db.Close()
-	}
-}
-
-func (g *GeoIPService) GetCountryISO(ipStr string) (string, error) {
-	ip := net.ParseIP(ipStr)
-	if ip == nil {
-		return "", fmt.Errorf(
-			"%w %s",
-			BadIpFormatError,
-			ip,
-		)
-	}
-	var rec GeoIPRecord
-	if err := g.db.Lookup(ip, &rec); err != nil {
-		return "", fmt.Errorf(
-			"%w %s: %w",
-			InnerLookupIpError,
-			ip,
-			err,
-		)
-	}
-	return rec.Country.ISOCode, nil
-}
+package geoBadIp
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	// Часовые пояса из базы должны загружаться и без zoneinfo в образе
+	_ "time/tzdata"
+)
+
+// Тип подключения в терминах OpenRTB device.connectiontype
+const (
+	CONNECTION_TYPE_UNKNOWN  int32 = 0
+	CONNECTION_TYPE_ETHERNET int32 = 1
+	CONNECTION_TYPE_CELLULAR int32 = 3
+)
+
+type GeoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	// Поля ниже есть только в базах City
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Location struct {
+		TimeZone  string   `maxminddb:"time_zone"`
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+		// Радиус точности координат в километрах
+		AccuracyRadius uint16 `maxminddb:"accuracy_radius"`
+		MetroCode      uint   `maxminddb:"metro_code"`
+	} `maxminddb:"location"`
+
+	// Из базы ASN, если она подключена
+	ASN ASNRecord `maxminddb:"-"`
+	// Из базы Connection Type, если она подключена: Cable/DSL, Cellular, ...
+	ConnectionType string `maxminddb:"-"`
+}
+
+type ASNRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+type connectionTypeRecord struct {
+	ConnectionType string `maxminddb:"connection_type"`
+}
+
+// Region возвращает код первого уровня деления страны по ISO-3166-2: CA для Калифорнии
+func (r *GeoIPRecord) Region() string {
+	if len(r.Subdivisions) == 0 {
+		return ""
+	}
+	return r.Subdivisions[0].ISOCode
+}
+
+func (r *GeoIPRecord) CityName() string {
+	return r.City.Names["en"]
+}
+
+// Metro возвращает код рынка Nielsen DMA, он есть только для США
+func (r *GeoIPRecord) Metro() string {
+	if r.Location.MetroCode == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(r.Location.MetroCode), 10)
+}
+
+// Coordinates возвращает координаты и их точность в метрах
+func (r *GeoIPRecord) Coordinates() (lat float64, lon float64, accuracy int32, ok bool) {
+	if r.Location.Latitude == nil || r.Location.Longitude == nil {
+		return 0, 0, 0, false
+	}
+	return *r.Location.Latitude, *r.Location.Longitude, int32(r.Location.AccuracyRadius) * 1000, true
+}
+
+// OpenRTBConnectionType переводит тип подключения MaxMind в device.connectiontype
+func (r *GeoIPRecord) OpenRTBConnectionType() int32 {
+	switch r.ConnectionType {
+	case "Cable/DSL", "Corporate", "Dialup":
+		return CONNECTION_TYPE_ETHERNET
+	case "Cellular":
+		return CONNECTION_TYPE_CELLULAR
+	default:
+		return CONNECTION_TYPE_UNKNOWN
+	}
+}
+
+// Часовые пояса из базы, чтобы не читать zoneinfo на каждый запрос
+var timeZones sync.Map
+
+// UTCOffset возвращает смещение местного времени от UTC в минутах на момент at
+func (r *GeoIPRecord) UTCOffset(at time.Time) (int32, bool) {
+	if r.Location.TimeZone == "" {
+		return 0, false
+	}
+	location, ok := timeZones.Load(r.Location.TimeZone)
+	if !ok {
+		loaded, err := time.LoadLocation(r.Location.TimeZone)
+		if err != nil {
+			return 0, false
+		}
+		location, _ = timeZones.LoadOrStore(r.Location.TimeZone, loaded)
+	}
+	_, offset := at.In(location.(*time.Location)).Zone()
+	return int32(offset / 60), true
+}
+
+type GeoIPService struct {
+	db *Database
+	// Необязательные базы ASN и Connection Type
+	asnDb            *Database
+	connectionTypeDb *Database
+}
+
+func NewGeoIPService(dbPath string) (*GeoIPService, error) {
+	db, err := OpenDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &GeoIPService{db: db}, nil
+}
+
+// OpenASN подключает базу GeoLite2/GeoIP2 ASN
+func (g *GeoIPService) OpenASN(dbPath string) error {
+	db, err := OpenDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+	g.asnDb = db
+	return nil
+}
+
+// OpenConnectionType подключает базу GeoIP2 Connection Type
+func (g *GeoIPService) OpenConnectionType(dbPath string) error {
+	db, err := OpenDatabase(dbPath)
+	if err != nil {
+		return err
+	}
+	g.connectionTypeDb = db
+	return nil
+}
+
+// Databases возвращает подключённые базы для перечитывания и admin API
+func (g *GeoIPService) Databases() []*Database {
+	databases := []*Database{g.db}
+	for _, db := range []*Database{g.asnDb, g.connectionTypeDb} {
+		if db != nil {
+			databases = append(databases, db)
+		}
+	}
+	return databases
+}
+
+func (g *GeoIPService) Close() {
+	for _, db := range g.Databases() {
+		db.Close()
+	}
+}
+
+func (g *GeoIPService) GetCountryISO(ipStr string) (string, error) {
+	rec, err := g.Lookup(ipStr)
+	if err != nil {
+		return "", err
+	}
+	return rec.Country.ISOCode, nil
+}
+
+func (g *GeoIPService) Lookup(ipStr string) (GeoIPRecord, error) {
+	var rec GeoIPRecord
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return rec, fmt.Errorf(
+			"%w %s",
+			BadIpFormatError,
+			ip,
+		)
+	}
+	if err := g.db.Lookup(ip, &rec); err != nil {
+		return rec, fmt.Errorf(
+			"%w %s: %w",
+			InnerLookupIpError,
+			ip,
+			err,
+		)
+	}
+	if g.asnDb != nil {
+		if err := g.asnDb.Lookup(ip, &rec.ASN); err != nil {
+			return rec, fmt.Errorf(
+				"%w %s in asn database: %w",
+				InnerLookupIpError,
+				ip,
+				err,
+			)
+		}
+	}
+	if g.connectionTypeDb != nil {
+		var connection connectionTypeRecord
+		if err := g.connectionTypeDb.Lookup(ip, &connection); err != nil {
+			return rec, fmt.Errorf(
+				"%w %s in connection type database: %w",
+				InnerLookupIpError,
+				ip,
+				err,
+			)
+		}
+		rec.ConnectionType = connection.ConnectionType
+	}
+	return rec, nil
+}
@@ -0,0 +1,203 @@
+package geoBadIp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestDatabase записывает базу mmdb для IPv4, в которой record есть у адресов
+// 0.0.0.0/1, а адреса 128.0.0.0/1 не найдены
+func writeTestDatabase(t testing.TB, path, databaseType string, buildEpoch uint64, record map[string]any) {
+	t.Helper()
+
+	const nodeCount = 1
+	var tree []byte
+	// Левая запись - данные со смещением 0, правая - "не найдено"
+	for _, value := range []uint32{nodeCount + 16, nodeCount} {
+		tree = append(tree, byte(value>>16), byte(value>>8), byte(value))
+	}
+
+	var file bytes.Buffer
+	file.Write(tree)
+	file.Write(make([]byte, 16))
+	writeTestValue(&file, record)
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	writeTestValue(&file, map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               databaseType,
+		"languages":                   []any{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 buildEpoch,
+		"description":                 map[string]any{"en": "test " + databaseType},
+	})
+
+	// Подмена через rename, как при обновлении баз MaxMind
+	tmpPath := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmpPath, file.Bytes(), 0o644))
+	require.NoError(t, os.Rename(tmpPath, path))
+}
+
+// writeTestValue кодирует значение в формате данных MaxMind DB
+func writeTestValue(buf *bytes.Buffer, value any) {
+	// Типы 1-7 хранятся в управляющем байте, остальные - в следующем за ним.
+	// Размер от 29 продолжается в байте после типа.
+	control := func(typ, size int) {
+		sizeBits, extra := size, -1
+		if size >= 29 {
+			sizeBits, extra = 29, size-29
+		}
+		if typ <= 7 {
+			buf.WriteByte(byte(typ<<5 | sizeBits))
+		} else {
+			buf.WriteByte(byte(sizeBits))
+			buf.WriteByte(byte(typ - 7))
+		}
+		if extra >= 0 {
+			buf.WriteByte(byte(extra))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		control(2, len(v))
+		buf.WriteString(v)
+	case float64:
+		control(3, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		control(5, 2)
+		binary.Write(buf, binary.BigEndian, v)
+	case uint32:
+		control(6, 4)
+		binary.Write(buf, binary.BigEndian, v)
+	case uint64:
+		control(9, 8)
+		binary.Write(buf, binary.BigEndian, v)
+	case map[string]any:
+		control(7, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writeTestValue(buf, key)
+			writeTestValue(buf, v[key])
+		}
+	case []any:
+		control(11, len(v))
+		for _, item := range v {
+			writeTestValue(buf, item)
+		}
+	default:
+		panic("unsupported mmdb value")
+	}
+}
+
+func testCityRecord(country string) map[string]any {
+	return map[string]any{
+		"country":      map[string]any{"iso_code": country},
+		"subdivisions": []any{map[string]any{"iso_code": "CA"}},
+		"city":         map[string]any{"names": map[string]any{"en": "Mountain View"}},
+		"postal":       map[string]any{"code": "94043"},
+		"location": map[string]any{
+			"time_zone":       "America/Los_Angeles",
+			"latitude":        37.386,
+			"longitude":       -122.0838,
+			"accuracy_radius": uint16(20),
+			"metro_code":      uint16(807),
+		},
+	}
+}
+
+func TestGeoIPServiceLookup(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	connectionTypePath := filepath.Join(dir, "connection-type.mmdb")
+	writeTestDatabase(t, cityPath, "GeoIP2-City", 1700000000, testCityRecord("US"))
+	writeTestDatabase(t, asnPath, "GeoLite2-ASN", 1700000000, map[string]any{
+		"autonomous_system_number":       uint32(15169),
+		"autonomous_system_organization": "Google LLC",
+	})
+	writeTestDatabase(t, connectionTypePath, "GeoIP2-Connection-Type", 1700000000, map[string]any{
+		"connection_type": "Cellular",
+	})
+
+	service, err := NewGeoIPService(cityPath)
+	require.NoError(t, err)
+	defer service.Close()
+	require.NoError(t, service.OpenASN(asnPath))
+	require.NoError(t, service.OpenConnectionType(connectionTypePath))
+	assert.Len(t, service.Databases(), 3)
+
+	rec, err := service.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", rec.Country.ISOCode)
+	assert.Equal(t, "CA", rec.Region())
+	assert.Equal(t, "Mountain View", rec.CityName())
+	assert.Equal(t, "94043", rec.Postal.Code)
+	assert.Equal(t, "807", rec.Metro())
+	lat, lon, accuracy, ok := rec.Coordinates()
+	assert.True(t, ok)
+	assert.Equal(t, 37.386, lat)
+	assert.Equal(t, -122.0838, lon)
+	assert.Equal(t, int32(20_000), accuracy)
+	offset, ok := rec.UTCOffset(time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, int32(-8*60), offset)
+	assert.Equal(t, ASNRecord{Number: 15169, Organization: "Google LLC"}, rec.ASN)
+	assert.Equal(t, CONNECTION_TYPE_CELLULAR, rec.OpenRTBConnectionType())
+
+	country, err := service.GetCountryISO("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "US", country)
+
+	// Адреса нет ни в одной базе: пустая запись без ошибки
+	rec, err = service.Lookup("203.0.113.1")
+	require.NoError(t, err)
+	assert.Equal(t, GeoIPRecord{}, rec)
+	_, _, _, ok = rec.Coordinates()
+	assert.False(t, ok)
+
+	_, err = service.Lookup("not an ip")
+	assert.ErrorIs(t, err, BadIpFormatError)
+}
+
+func TestGeoIPServiceWithoutOptionalDatabases(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := filepath.Join(dir, "city.mmdb")
+	writeTestDatabase(t, cityPath, "GeoIP2-City", 1700000000, testCityRecord("DE"))
+
+	service, err := NewGeoIPService(cityPath)
+	require.NoError(t, err)
+	defer service.Close()
+
+	// Несуществующие базы ASN и Connection Type не подключаются
+	assert.Error(t, service.OpenASN(filepath.Join(dir, "missing-asn.mmdb")))
+	assert.Error(t, service.OpenConnectionType(filepath.Join(dir, "missing-connection-type.mmdb")))
+	assert.Len(t, service.Databases(), 1)
+
+	rec, err := service.Lookup("8.8.8.8")
+	require.NoError(t, err)
+	assert.Equal(t, "DE", rec.Country.ISOCode)
+	assert.Equal(t, "Mountain View", rec.CityName())
+	assert.Equal(t, ASNRecord{}, rec.ASN)
+	assert.Empty(t, rec.ConnectionType)
+	assert.Equal(t, CONNECTION_TYPE_UNKNOWN, rec.OpenRTBConnectionType())
+
+	_, err = NewGeoIPService(filepath.Join(dir, "missing-city.mmdb"))
+	assert.Error(t, err)
+}
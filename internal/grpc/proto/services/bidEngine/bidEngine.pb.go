@@ -23,18 +23,241 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type BidEngineRequest_V2_4 struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	BidRequest    *ortb_V2_4.BidRequest    `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	BidResponses  []*ortb_V2_4.BidResponse `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
-	GlobalId      string                   `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+// Ответ DSP и то, что знает о нём биржа. Метаданные биржи не кладутся
+// в сообщения OpenRTB, чтобы DSP не могла подставить их в свой ответ.
+type DspBidResponse_V2_4 struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BidResponse *ortb_V2_4.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
+	// Endpoint DSP, проставляется роутером
+	DspId string `protobuf:"bytes,2,opt,name=dspId,proto3" json:"dspId,omitempty"`
+	// Для отфильтрованных ответов - код причины отказа всех ставок ответа,
+	// 0 - ответ отклонён правилами SPP
+	FilterReason  int32 `protobuf:"varint,3,opt,name=filterReason,proto3" json:"filterReason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DspBidResponse_V2_4) Reset() {
+	*x = DspBidResponse_V2_4{}
+	mi := &file_services_bidEngine_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspBidResponse_V2_4) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspBidResponse_V2_4) ProtoMessage() {}
+
+func (x *DspBidResponse_V2_4) ProtoReflect() protoreflect.Message {
+	mi := &file_services_bidEngine_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspBidResponse_V2_4.ProtoReflect.Descriptor instead.
+func (*DspBidResponse_V2_4) Descriptor() ([]byte, []int) {
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DspBidResponse_V2_4) GetBidResponse() *ortb_V2_4.BidResponse {
+	if x != nil {
+		return x.BidResponse
+	}
+	return nil
+}
+
+func (x *DspBidResponse_V2_4) GetDspId() string {
+	if x != nil {
+		return x.DspId
+	}
+	return ""
+}
+
+func (x *DspBidResponse_V2_4) GetFilterReason() int32 {
+	if x != nil {
+		return x.FilterReason
+	}
+	return 0
+}
+
+type DspBidResponse_V2_5 struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BidResponse   *ortb_V2_5.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
+	DspId         string                 `protobuf:"bytes,2,opt,name=dspId,proto3" json:"dspId,omitempty"`
+	FilterReason  int32                  `protobuf:"varint,3,opt,name=filterReason,proto3" json:"filterReason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *DspBidResponse_V2_5) Reset() {
+	*x = DspBidResponse_V2_5{}
+	mi := &file_services_bidEngine_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DspBidResponse_V2_5) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DspBidResponse_V2_5) ProtoMessage() {}
+
+func (x *DspBidResponse_V2_5) ProtoReflect() protoreflect.Message {
+	mi := &file_services_bidEngine_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DspBidResponse_V2_5.ProtoReflect.Descriptor instead.
+func (*DspBidResponse_V2_5) Descriptor() ([]byte, []int) {
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DspBidResponse_V2_5) GetBidResponse() *ortb_V2_5.BidResponse {
+	if x != nil {
+		return x.BidResponse
+	}
+	return nil
+}
+
+func (x *DspBidResponse_V2_5) GetDspId() string {
+	if x != nil {
+		return x.DspId
+	}
+	return ""
+}
+
+func (x *DspBidResponse_V2_5) GetFilterReason() int32 {
+	if x != nil {
+		return x.FilterReason
+	}
+	return 0
+}
+
+// Выбор флора по импрессии: источник и сработавшее правило биржи
+type FloorDecision struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	ImpId string                 `protobuf:"bytes,1,opt,name=impId,proto3" json:"impId,omitempty"`
+	// none, ssp или exchange
+	Source              string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	FloorMicros         int64  `protobuf:"varint,3,opt,name=floorMicros,proto3" json:"floorMicros,omitempty"`
+	Cur                 string `protobuf:"bytes,4,opt,name=cur,proto3" json:"cur,omitempty"`
+	SspFloorMicros      int64  `protobuf:"varint,5,opt,name=sspFloorMicros,proto3" json:"sspFloorMicros,omitempty"`
+	ExchangeFloorMicros int64  `protobuf:"varint,6,opt,name=exchangeFloorMicros,proto3" json:"exchangeFloorMicros,omitempty"`
+	RuleId              string `protobuf:"bytes,7,opt,name=ruleId,proto3" json:"ruleId,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FloorDecision) Reset() {
+	*x = FloorDecision{}
+	mi := &file_services_bidEngine_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FloorDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FloorDecision) ProtoMessage() {}
+
+func (x *FloorDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_services_bidEngine_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FloorDecision.ProtoReflect.Descriptor instead.
+func (*FloorDecision) Descriptor() ([]byte, []int) {
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FloorDecision) GetImpId() string {
+	if x != nil {
+		return x.ImpId
+	}
+	return ""
+}
+
+func (x *FloorDecision) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *FloorDecision) GetFloorMicros() int64 {
+	if x != nil {
+		return x.FloorMicros
+	}
+	return 0
+}
+
+func (x *FloorDecision) GetCur() string {
+	if x != nil {
+		return x.Cur
+	}
+	return ""
+}
+
+func (x *FloorDecision) GetSspFloorMicros() int64 {
+	if x != nil {
+		return x.SspFloorMicros
+	}
+	return 0
+}
+
+func (x *FloorDecision) GetExchangeFloorMicros() int64 {
+	if x != nil {
+		return x.ExchangeFloorMicros
+	}
+	return 0
+}
+
+func (x *FloorDecision) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+type BidEngineRequest_V2_4 struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	BidRequest           *ortb_V2_4.BidRequest  `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses         []*DspBidResponse_V2_4 `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId             string                 `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FilteredBidResponses []*DspBidResponse_V2_4 `protobuf:"bytes,4,rep,name=filteredBidResponses,proto3" json:"filteredBidResponses,omitempty"`
+	SppEndpoint          string                 `protobuf:"bytes,5,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
+	// Флоры, которые SPP adapter выбрал по импрессиям запроса
+	FloorDecisions []*FloorDecision `protobuf:"bytes,6,rep,name=floorDecisions,proto3" json:"floorDecisions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *BidEngineRequest_V2_4) Reset() {
 	*x = BidEngineRequest_V2_4{}
-	mi := &file_services_bidEngine_proto_msgTypes[0]
+	mi := &file_services_bidEngine_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -46,7 +269,7 @@ func (x *BidEngineRequest_V2_4) String() string {
 func (*BidEngineRequest_V2_4) ProtoMessage() {}
 
 func (x *BidEngineRequest_V2_4) ProtoReflect() protoreflect.Message {
-	mi := &file_services_bidEngine_proto_msgTypes[0]
+	mi := &file_services_bidEngine_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,7 +282,7 @@ func (x *BidEngineRequest_V2_4) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BidEngineRequest_V2_4.ProtoReflect.Descriptor instead.
 func (*BidEngineRequest_V2_4) Descriptor() ([]byte, []int) {
-	return file_services_bidEngine_proto_rawDescGZIP(), []int{0}
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *BidEngineRequest_V2_4) GetBidRequest() *ortb_V2_4.BidRequest {
@@ -69,7 +292,7 @@ func (x *BidEngineRequest_V2_4) GetBidRequest() *ortb_V2_4.BidRequest {
 	return nil
 }
 
-func (x *BidEngineRequest_V2_4) GetBidResponses() []*ortb_V2_4.BidResponse {
+func (x *BidEngineRequest_V2_4) GetBidResponses() []*DspBidResponse_V2_4 {
 	if x != nil {
 		return x.BidResponses
 	}
@@ -83,6 +306,27 @@ func (x *BidEngineRequest_V2_4) GetGlobalId() string {
 	return ""
 }
 
+func (x *BidEngineRequest_V2_4) GetFilteredBidResponses() []*DspBidResponse_V2_4 {
+	if x != nil {
+		return x.FilteredBidResponses
+	}
+	return nil
+}
+
+func (x *BidEngineRequest_V2_4) GetSppEndpoint() string {
+	if x != nil {
+		return x.SppEndpoint
+	}
+	return ""
+}
+
+func (x *BidEngineRequest_V2_4) GetFloorDecisions() []*FloorDecision {
+	if x != nil {
+		return x.FloorDecisions
+	}
+	return nil
+}
+
 type BidEngineResponse_V2_4 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BidResponse   *ortb_V2_4.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
@@ -93,7 +337,7 @@ type BidEngineResponse_V2_4 struct {
 
 func (x *BidEngineResponse_V2_4) Reset() {
 	*x = BidEngineResponse_V2_4{}
-	mi := &file_services_bidEngine_proto_msgTypes[1]
+	mi := &file_services_bidEngine_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -105,7 +349,7 @@ func (x *BidEngineResponse_V2_4) String() string {
 func (*BidEngineResponse_V2_4) ProtoMessage() {}
 
 func (x *BidEngineResponse_V2_4) ProtoReflect() protoreflect.Message {
-	mi := &file_services_bidEngine_proto_msgTypes[1]
+	mi := &file_services_bidEngine_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -118,7 +362,7 @@ func (x *BidEngineResponse_V2_4) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BidEngineResponse_V2_4.ProtoReflect.Descriptor instead.
 func (*BidEngineResponse_V2_4) Descriptor() ([]byte, []int) {
-	return file_services_bidEngine_proto_rawDescGZIP(), []int{1}
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BidEngineResponse_V2_4) GetBidResponse() *ortb_V2_4.BidResponse {
@@ -136,17 +380,20 @@ func (x *BidEngineResponse_V2_4) GetGlobalId() string {
 }
 
 type BidEngineRequest_V2_5 struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	BidRequest    *ortb_V2_5.BidRequest    `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	BidResponses  []*ortb_V2_5.BidResponse `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
-	GlobalId      string                   `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	BidRequest           *ortb_V2_5.BidRequest  `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses         []*DspBidResponse_V2_5 `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId             string                 `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FilteredBidResponses []*DspBidResponse_V2_5 `protobuf:"bytes,4,rep,name=filteredBidResponses,proto3" json:"filteredBidResponses,omitempty"`
+	SppEndpoint          string                 `protobuf:"bytes,5,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
+	FloorDecisions       []*FloorDecision       `protobuf:"bytes,6,rep,name=floorDecisions,proto3" json:"floorDecisions,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *BidEngineRequest_V2_5) Reset() {
 	*x = BidEngineRequest_V2_5{}
-	mi := &file_services_bidEngine_proto_msgTypes[2]
+	mi := &file_services_bidEngine_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -158,7 +405,7 @@ func (x *BidEngineRequest_V2_5) String() string {
 func (*BidEngineRequest_V2_5) ProtoMessage() {}
 
 func (x *BidEngineRequest_V2_5) ProtoReflect() protoreflect.Message {
-	mi := &file_services_bidEngine_proto_msgTypes[2]
+	mi := &file_services_bidEngine_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -171,7 +418,7 @@ func (x *BidEngineRequest_V2_5) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BidEngineRequest_V2_5.ProtoReflect.Descriptor instead.
 func (*BidEngineRequest_V2_5) Descriptor() ([]byte, []int) {
-	return file_services_bidEngine_proto_rawDescGZIP(), []int{2}
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BidEngineRequest_V2_5) GetBidRequest() *ortb_V2_5.BidRequest {
@@ -181,7 +428,7 @@ func (x *BidEngineRequest_V2_5) GetBidRequest() *ortb_V2_5.BidRequest {
 	return nil
 }
 
-func (x *BidEngineRequest_V2_5) GetBidResponses() []*ortb_V2_5.BidResponse {
+func (x *BidEngineRequest_V2_5) GetBidResponses() []*DspBidResponse_V2_5 {
 	if x != nil {
 		return x.BidResponses
 	}
@@ -195,6 +442,27 @@ func (x *BidEngineRequest_V2_5) GetGlobalId() string {
 	return ""
 }
 
+func (x *BidEngineRequest_V2_5) GetFilteredBidResponses() []*DspBidResponse_V2_5 {
+	if x != nil {
+		return x.FilteredBidResponses
+	}
+	return nil
+}
+
+func (x *BidEngineRequest_V2_5) GetSppEndpoint() string {
+	if x != nil {
+		return x.SppEndpoint
+	}
+	return ""
+}
+
+func (x *BidEngineRequest_V2_5) GetFloorDecisions() []*FloorDecision {
+	if x != nil {
+		return x.FloorDecisions
+	}
+	return nil
+}
+
 type BidEngineResponse_V2_5 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BidResponse   *ortb_V2_5.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
@@ -205,7 +473,7 @@ type BidEngineResponse_V2_5 struct {
 
 func (x *BidEngineResponse_V2_5) Reset() {
 	*x = BidEngineResponse_V2_5{}
-	mi := &file_services_bidEngine_proto_msgTypes[3]
+	mi := &file_services_bidEngine_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -217,7 +485,7 @@ func (x *BidEngineResponse_V2_5) String() string {
 func (*BidEngineResponse_V2_5) ProtoMessage() {}
 
 func (x *BidEngineResponse_V2_5) ProtoReflect() protoreflect.Message {
-	mi := &file_services_bidEngine_proto_msgTypes[3]
+	mi := &file_services_bidEngine_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +498,7 @@ func (x *BidEngineResponse_V2_5) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BidEngineResponse_V2_5.ProtoReflect.Descriptor instead.
 func (*BidEngineResponse_V2_5) Descriptor() ([]byte, []int) {
-	return file_services_bidEngine_proto_rawDescGZIP(), []int{3}
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *BidEngineResponse_V2_5) GetBidResponse() *ortb_V2_5.BidResponse {
@@ -247,32 +515,148 @@ func (x *BidEngineResponse_V2_5) GetGlobalId() string {
 	return ""
 }
 
+// Billing по выигранной импрессии, приходит из burl SSP
+type BillingEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GlobalId      string                 `protobuf:"bytes,1,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	ImpId         string                 `protobuf:"bytes,2,opt,name=impId,proto3" json:"impId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BillingEvent) Reset() {
+	*x = BillingEvent{}
+	mi := &file_services_bidEngine_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillingEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillingEvent) ProtoMessage() {}
+
+func (x *BillingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_services_bidEngine_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillingEvent.ProtoReflect.Descriptor instead.
+func (*BillingEvent) Descriptor() ([]byte, []int) {
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BillingEvent) GetGlobalId() string {
+	if x != nil {
+		return x.GlobalId
+	}
+	return ""
+}
+
+func (x *BillingEvent) GetImpId() string {
+	if x != nil {
+		return x.ImpId
+	}
+	return ""
+}
+
+type BillingEventAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BillingEventAck) Reset() {
+	*x = BillingEventAck{}
+	mi := &file_services_bidEngine_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillingEventAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillingEventAck) ProtoMessage() {}
+
+func (x *BillingEventAck) ProtoReflect() protoreflect.Message {
+	mi := &file_services_bidEngine_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillingEventAck.ProtoReflect.Descriptor instead.
+func (*BillingEventAck) Descriptor() ([]byte, []int) {
+	return file_services_bidEngine_proto_rawDescGZIP(), []int{8}
+}
+
 var File_services_bidEngine_proto protoreflect.FileDescriptor
 
 const file_services_bidEngine_proto_rawDesc = "" +
 	"\n" +
-	"\x18services/bidEngine.proto\x12\tbidEngine\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\"\xa6\x01\n" +
+	"\x18services/bidEngine.proto\x12\tbidEngine\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\"\x89\x01\n" +
+	"\x13DspBidResponse_V2_4\x128\n" +
+	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_4.BidResponseR\vbidResponse\x12\x14\n" +
+	"\x05dspId\x18\x02 \x01(\tR\x05dspId\x12\"\n" +
+	"\ffilterReason\x18\x03 \x01(\x05R\ffilterReason\"\x89\x01\n" +
+	"\x13DspBidResponse_V2_5\x128\n" +
+	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_5.BidResponseR\vbidResponse\x12\x14\n" +
+	"\x05dspId\x18\x02 \x01(\tR\x05dspId\x12\"\n" +
+	"\ffilterReason\x18\x03 \x01(\x05R\ffilterReason\"\xe3\x01\n" +
+	"\rFloorDecision\x12\x14\n" +
+	"\x05impId\x18\x01 \x01(\tR\x05impId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12 \n" +
+	"\vfloorMicros\x18\x03 \x01(\x03R\vfloorMicros\x12\x10\n" +
+	"\x03cur\x18\x04 \x01(\tR\x03cur\x12&\n" +
+	"\x0esspFloorMicros\x18\x05 \x01(\x03R\x0esspFloorMicros\x120\n" +
+	"\x13exchangeFloorMicros\x18\x06 \x01(\x03R\x13exchangeFloorMicros\x12\x16\n" +
+	"\x06ruleId\x18\a \x01(\tR\x06ruleId\"\xe6\x02\n" +
 	"\x15BidEngineRequest_V2_4\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_4.BidRequestR\n" +
-	"bidRequest\x12:\n" +
-	"\fbidResponses\x18\x02 \x03(\v2\x16.ortb_V2_4.BidResponseR\fbidResponses\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"n\n" +
+	"bidRequest\x12B\n" +
+	"\fbidResponses\x18\x02 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_4R\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12R\n" +
+	"\x14filteredBidResponses\x18\x04 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_4R\x14filteredBidResponses\x12 \n" +
+	"\vsppEndpoint\x18\x05 \x01(\tR\vsppEndpoint\x12@\n" +
+	"\x0efloorDecisions\x18\x06 \x03(\v2\x18.bidEngine.FloorDecisionR\x0efloorDecisions\"n\n" +
 	"\x16BidEngineResponse_V2_4\x128\n" +
 	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_4.BidResponseR\vbidResponse\x12\x1a\n" +
-	"\bglobalId\x18\x02 \x01(\tR\bglobalId\"\xa6\x01\n" +
+	"\bglobalId\x18\x02 \x01(\tR\bglobalId\"\xe6\x02\n" +
 	"\x15BidEngineRequest_V2_5\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_5.BidRequestR\n" +
-	"bidRequest\x12:\n" +
-	"\fbidResponses\x18\x02 \x03(\v2\x16.ortb_V2_5.BidResponseR\fbidResponses\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"n\n" +
+	"bidRequest\x12B\n" +
+	"\fbidResponses\x18\x02 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_5R\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12R\n" +
+	"\x14filteredBidResponses\x18\x04 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_5R\x14filteredBidResponses\x12 \n" +
+	"\vsppEndpoint\x18\x05 \x01(\tR\vsppEndpoint\x12@\n" +
+	"\x0efloorDecisions\x18\x06 \x03(\v2\x18.bidEngine.FloorDecisionR\x0efloorDecisions\"n\n" +
 	"\x16BidEngineResponse_V2_5\x128\n" +
 	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_5.BidResponseR\vbidResponse\x12\x1a\n" +
-	"\bglobalId\x18\x02 \x01(\tR\bglobalId2\xca\x01\n" +
+	"\bglobalId\x18\x02 \x01(\tR\bglobalId\"@\n" +
+	"\fBillingEvent\x12\x1a\n" +
+	"\bglobalId\x18\x01 \x01(\tR\bglobalId\x12\x14\n" +
+	"\x05impId\x18\x02 \x01(\tR\x05impId\"\x11\n" +
+	"\x0fBillingEventAck2\x92\x02\n" +
 	"\x10BidEngineService\x12Z\n" +
 	"\x11getWinnerBid_V2_4\x12 .bidEngine.BidEngineRequest_V2_4\x1a!.bidEngine.BidEngineResponse_V2_4\"\x00\x12Z\n" +
-	"\x11getWinnerBid_V2_5\x12 .bidEngine.BidEngineRequest_V2_5\x1a!.bidEngine.BidEngineResponse_V2_5\"\x00B_Z]gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine;bidEngineGrpcb\x06proto3"
+	"\x11getWinnerBid_V2_5\x12 .bidEngine.BidEngineRequest_V2_5\x1a!.bidEngine.BidEngineResponse_V2_5\"\x00\x12F\n" +
+	"\rreportBilling\x12\x17.bidEngine.BillingEvent\x1a\x1a.bidEngine.BillingEventAck\"\x00B_Z]gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine;bidEngineGrpcb\x06proto3"
 
 var (
 	file_services_bidEngine_proto_rawDescOnce sync.Once
@@ -286,33 +670,46 @@ func file_services_bidEngine_proto_rawDescGZIP() []byte {
 	return file_services_bidEngine_proto_rawDescData
 }
 
-var file_services_bidEngine_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_services_bidEngine_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_services_bidEngine_proto_goTypes = []any{
-	(*BidEngineRequest_V2_4)(nil),  // 0: bidEngine.BidEngineRequest_V2_4
-	(*BidEngineResponse_V2_4)(nil), // 1: bidEngine.BidEngineResponse_V2_4
-	(*BidEngineRequest_V2_5)(nil),  // 2: bidEngine.BidEngineRequest_V2_5
-	(*BidEngineResponse_V2_5)(nil), // 3: bidEngine.BidEngineResponse_V2_5
-	(*ortb_V2_4.BidRequest)(nil),   // 4: ortb_V2_4.BidRequest
-	(*ortb_V2_4.BidResponse)(nil),  // 5: ortb_V2_4.BidResponse
-	(*ortb_V2_5.BidRequest)(nil),   // 6: ortb_V2_5.BidRequest
-	(*ortb_V2_5.BidResponse)(nil),  // 7: ortb_V2_5.BidResponse
+	(*DspBidResponse_V2_4)(nil),    // 0: bidEngine.DspBidResponse_V2_4
+	(*DspBidResponse_V2_5)(nil),    // 1: bidEngine.DspBidResponse_V2_5
+	(*FloorDecision)(nil),          // 2: bidEngine.FloorDecision
+	(*BidEngineRequest_V2_4)(nil),  // 3: bidEngine.BidEngineRequest_V2_4
+	(*BidEngineResponse_V2_4)(nil), // 4: bidEngine.BidEngineResponse_V2_4
+	(*BidEngineRequest_V2_5)(nil),  // 5: bidEngine.BidEngineRequest_V2_5
+	(*BidEngineResponse_V2_5)(nil), // 6: bidEngine.BidEngineResponse_V2_5
+	(*BillingEvent)(nil),           // 7: bidEngine.BillingEvent
+	(*BillingEventAck)(nil),        // 8: bidEngine.BillingEventAck
+	(*ortb_V2_4.BidResponse)(nil),  // 9: ortb_V2_4.BidResponse
+	(*ortb_V2_5.BidResponse)(nil),  // 10: ortb_V2_5.BidResponse
+	(*ortb_V2_4.BidRequest)(nil),   // 11: ortb_V2_4.BidRequest
+	(*ortb_V2_5.BidRequest)(nil),   // 12: ortb_V2_5.BidRequest
 }
 var file_services_bidEngine_proto_depIdxs = []int32{
-	4, // 0: bidEngine.BidEngineRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
-	5, // 1: bidEngine.BidEngineRequest_V2_4.bidResponses:type_name -> ortb_V2_4.BidResponse
-	5, // 2: bidEngine.BidEngineResponse_V2_4.bidResponse:type_name -> ortb_V2_4.BidResponse
-	6, // 3: bidEngine.BidEngineRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
-	7, // 4: bidEngine.BidEngineRequest_V2_5.bidResponses:type_name -> ortb_V2_5.BidResponse
-	7, // 5: bidEngine.BidEngineResponse_V2_5.bidResponse:type_name -> ortb_V2_5.BidResponse
-	0, // 6: bidEngine.BidEngineService.getWinnerBid_V2_4:input_type -> bidEngine.BidEngineRequest_V2_4
-	2, // 7: bidEngine.BidEngineService.getWinnerBid_V2_5:input_type -> bidEngine.BidEngineRequest_V2_5
-	1, // 8: bidEngine.BidEngineService.getWinnerBid_V2_4:output_type -> bidEngine.BidEngineResponse_V2_4
-	3, // 9: bidEngine.BidEngineService.getWinnerBid_V2_5:output_type -> bidEngine.BidEngineResponse_V2_5
-	8, // [8:10] is the sub-list for method output_type
-	6, // [6:8] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	9,  // 0: bidEngine.DspBidResponse_V2_4.bidResponse:type_name -> ortb_V2_4.BidResponse
+	10, // 1: bidEngine.DspBidResponse_V2_5.bidResponse:type_name -> ortb_V2_5.BidResponse
+	11, // 2: bidEngine.BidEngineRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
+	0,  // 3: bidEngine.BidEngineRequest_V2_4.bidResponses:type_name -> bidEngine.DspBidResponse_V2_4
+	0,  // 4: bidEngine.BidEngineRequest_V2_4.filteredBidResponses:type_name -> bidEngine.DspBidResponse_V2_4
+	2,  // 5: bidEngine.BidEngineRequest_V2_4.floorDecisions:type_name -> bidEngine.FloorDecision
+	9,  // 6: bidEngine.BidEngineResponse_V2_4.bidResponse:type_name -> ortb_V2_4.BidResponse
+	12, // 7: bidEngine.BidEngineRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
+	1,  // 8: bidEngine.BidEngineRequest_V2_5.bidResponses:type_name -> bidEngine.DspBidResponse_V2_5
+	1,  // 9: bidEngine.BidEngineRequest_V2_5.filteredBidResponses:type_name -> bidEngine.DspBidResponse_V2_5
+	2,  // 10: bidEngine.BidEngineRequest_V2_5.floorDecisions:type_name -> bidEngine.FloorDecision
+	10, // 11: bidEngine.BidEngineResponse_V2_5.bidResponse:type_name -> ortb_V2_5.BidResponse
+	3,  // 12: bidEngine.BidEngineService.getWinnerBid_V2_4:input_type -> bidEngine.BidEngineRequest_V2_4
+	5,  // 13: bidEngine.BidEngineService.getWinnerBid_V2_5:input_type -> bidEngine.BidEngineRequest_V2_5
+	7,  // 14: bidEngine.BidEngineService.reportBilling:input_type -> bidEngine.BillingEvent
+	4,  // 15: bidEngine.BidEngineService.getWinnerBid_V2_4:output_type -> bidEngine.BidEngineResponse_V2_4
+	6,  // 16: bidEngine.BidEngineService.getWinnerBid_V2_5:output_type -> bidEngine.BidEngineResponse_V2_5
+	8,  // 17: bidEngine.BidEngineService.reportBilling:output_type -> bidEngine.BillingEventAck
+	15, // [15:18] is the sub-list for method output_type
+	12, // [12:15] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_services_bidEngine_proto_init() }
@@ -326,7 +723,7 @@ func file_services_bidEngine_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_bidEngine_proto_rawDesc), len(file_services_bidEngine_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
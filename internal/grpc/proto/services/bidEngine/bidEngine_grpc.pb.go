@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	BidEngineService_GetWinnerBid_V2_4_FullMethodName = "/bidEngine.BidEngineService/getWinnerBid_V2_4"
 	BidEngineService_GetWinnerBid_V2_5_FullMethodName = "/bidEngine.BidEngineService/getWinnerBid_V2_5"
+	BidEngineService_ReportBilling_FullMethodName     = "/bidEngine.BidEngineService/reportBilling"
 )
 
 // BidEngineServiceClient is the client API for BidEngineService service.
@@ -29,6 +30,7 @@ const (
 type BidEngineServiceClient interface {
 	GetWinnerBid_V2_4(ctx context.Context, in *BidEngineRequest_V2_4, opts ...grpc.CallOption) (*BidEngineResponse_V2_4, error)
 	GetWinnerBid_V2_5(ctx context.Context, in *BidEngineRequest_V2_5, opts ...grpc.CallOption) (*BidEngineResponse_V2_5, error)
+	ReportBilling(ctx context.Context, in *BillingEvent, opts ...grpc.CallOption) (*BillingEventAck, error)
 }
 
 type bidEngineServiceClient struct {
@@ -59,12 +61,23 @@ func (c *bidEngineServiceClient) GetWinnerBid_V2_5(ctx context.Context, in *BidE
 	return out, nil
 }
 
+func (c *bidEngineServiceClient) ReportBilling(ctx context.Context, in *BillingEvent, opts ...grpc.CallOption) (*BillingEventAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BillingEventAck)
+	err := c.cc.Invoke(ctx, BidEngineService_ReportBilling_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BidEngineServiceServer is the server API for BidEngineService service.
 // All implementations must embed UnimplementedBidEngineServiceServer
 // for forward compatibility.
 type BidEngineServiceServer interface {
 	GetWinnerBid_V2_4(context.Context, *BidEngineRequest_V2_4) (*BidEngineResponse_V2_4, error)
 	GetWinnerBid_V2_5(context.Context, *BidEngineRequest_V2_5) (*BidEngineResponse_V2_5, error)
+	ReportBilling(context.Context, *BillingEvent) (*BillingEventAck, error)
 	mustEmbedUnimplementedBidEngineServiceServer()
 }
 
@@ -81,6 +94,9 @@ func (UnimplementedBidEngineServiceServer) GetWinnerBid_V2_4(context.Context, *B
 func (UnimplementedBidEngineServiceServer) GetWinnerBid_V2_5(context.Context, *BidEngineRequest_V2_5) (*BidEngineResponse_V2_5, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetWinnerBid_V2_5 not implemented")
 }
+func (UnimplementedBidEngineServiceServer) ReportBilling(context.Context, *BillingEvent) (*BillingEventAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportBilling not implemented")
+}
 func (UnimplementedBidEngineServiceServer) mustEmbedUnimplementedBidEngineServiceServer() {}
 func (UnimplementedBidEngineServiceServer) testEmbeddedByValue()                          {}
 
@@ -138,6 +154,24 @@ func _BidEngineService_GetWinnerBid_V2_5_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BidEngineService_ReportBilling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BillingEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BidEngineServiceServer).ReportBilling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BidEngineService_ReportBilling_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BidEngineServiceServer).ReportBilling(ctx, req.(*BillingEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BidEngineService_ServiceDesc is the grpc.ServiceDesc for BidEngineService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +187,10 @@ var BidEngineService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getWinnerBid_V2_5",
 			Handler:    _BidEngineService_GetWinnerBid_V2_5_Handler,
 		},
+		{
+			MethodName: "reportBilling",
+			Handler:    _BidEngineService_ReportBilling_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/bidEngine.proto",
@@ -7,6 +7,7 @@
 package dspRouterGrpc
 
 import (
+	bidEngine "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
 	ortb_V2_4 "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	ortb_V2_5 "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -84,12 +85,13 @@ func (x *DspRouterRequest_V2_4) GetGlobalId() string {
 }
 
 type DspRouterResponse_V2_4 struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	BidRequest    *ortb_V2_4.BidRequest    `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	BidResponses  []*ortb_V2_4.BidResponse `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
-	GlobalId      string                   `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState           `protogen:"open.v1"`
+	BidRequest           *ortb_V2_4.BidRequest            `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses         []*bidEngine.DspBidResponse_V2_4 `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId             string                           `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FilteredBidResponses []*bidEngine.DspBidResponse_V2_4 `protobuf:"bytes,4,rep,name=filteredBidResponses,proto3" json:"filteredBidResponses,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *DspRouterResponse_V2_4) Reset() {
@@ -129,7 +131,7 @@ func (x *DspRouterResponse_V2_4) GetBidRequest() *ortb_V2_4.BidRequest {
 	return nil
 }
 
-func (x *DspRouterResponse_V2_4) GetBidResponses() []*ortb_V2_4.BidResponse {
+func (x *DspRouterResponse_V2_4) GetBidResponses() []*bidEngine.DspBidResponse_V2_4 {
 	if x != nil {
 		return x.BidResponses
 	}
@@ -143,6 +145,13 @@ func (x *DspRouterResponse_V2_4) GetGlobalId() string {
 	return ""
 }
 
+func (x *DspRouterResponse_V2_4) GetFilteredBidResponses() []*bidEngine.DspBidResponse_V2_4 {
+	if x != nil {
+		return x.FilteredBidResponses
+	}
+	return nil
+}
+
 type DspRouterRequest_V2_5 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BidRequest    *ortb_V2_5.BidRequest  `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
@@ -204,12 +213,13 @@ func (x *DspRouterRequest_V2_5) GetGlobalId() string {
 }
 
 type DspRouterResponse_V2_5 struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	BidRequest    *ortb_V2_5.BidRequest    `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	BidResponses  []*ortb_V2_5.BidResponse `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
-	GlobalId      string                   `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState           `protogen:"open.v1"`
+	BidRequest           *ortb_V2_5.BidRequest            `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses         []*bidEngine.DspBidResponse_V2_5 `protobuf:"bytes,2,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId             string                           `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FilteredBidResponses []*bidEngine.DspBidResponse_V2_5 `protobuf:"bytes,4,rep,name=filteredBidResponses,proto3" json:"filteredBidResponses,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *DspRouterResponse_V2_5) Reset() {
@@ -249,7 +259,7 @@ func (x *DspRouterResponse_V2_5) GetBidRequest() *ortb_V2_5.BidRequest {
 	return nil
 }
 
-func (x *DspRouterResponse_V2_5) GetBidResponses() []*ortb_V2_5.BidResponse {
+func (x *DspRouterResponse_V2_5) GetBidResponses() []*bidEngine.DspBidResponse_V2_5 {
 	if x != nil {
 		return x.BidResponses
 	}
@@ -263,6 +273,437 @@ func (x *DspRouterResponse_V2_5) GetGlobalId() string {
 	return ""
 }
 
+func (x *DspRouterResponse_V2_5) GetFilteredBidResponses() []*bidEngine.DspBidResponse_V2_5 {
+	if x != nil {
+		return x.FilteredBidResponses
+	}
+	return nil
+}
+
+// Разбор решения фильтра: dspId - по запросу, sppId - по ответам DSP.
+// globalId - ключ выборки для правил sample, как в GetBids.
+type ExplainFilterRequest_V2_4 struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	DspId         string                   `protobuf:"bytes,1,opt,name=dspId,proto3" json:"dspId,omitempty"`
+	SppId         string                   `protobuf:"bytes,2,opt,name=sppId,proto3" json:"sppId,omitempty"`
+	BidRequest    *ortb_V2_4.BidRequest    `protobuf:"bytes,3,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses  []*ortb_V2_4.BidResponse `protobuf:"bytes,4,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId      string                   `protobuf:"bytes,5,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainFilterRequest_V2_4) Reset() {
+	*x = ExplainFilterRequest_V2_4{}
+	mi := &file_services_dspRouter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainFilterRequest_V2_4) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainFilterRequest_V2_4) ProtoMessage() {}
+
+func (x *ExplainFilterRequest_V2_4) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainFilterRequest_V2_4.ProtoReflect.Descriptor instead.
+func (*ExplainFilterRequest_V2_4) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ExplainFilterRequest_V2_4) GetDspId() string {
+	if x != nil {
+		return x.DspId
+	}
+	return ""
+}
+
+func (x *ExplainFilterRequest_V2_4) GetSppId() string {
+	if x != nil {
+		return x.SppId
+	}
+	return ""
+}
+
+func (x *ExplainFilterRequest_V2_4) GetBidRequest() *ortb_V2_4.BidRequest {
+	if x != nil {
+		return x.BidRequest
+	}
+	return nil
+}
+
+func (x *ExplainFilterRequest_V2_4) GetBidResponses() []*ortb_V2_4.BidResponse {
+	if x != nil {
+		return x.BidResponses
+	}
+	return nil
+}
+
+func (x *ExplainFilterRequest_V2_4) GetGlobalId() string {
+	if x != nil {
+		return x.GlobalId
+	}
+	return ""
+}
+
+type ExplainFilterRequest_V2_5 struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	DspId         string                   `protobuf:"bytes,1,opt,name=dspId,proto3" json:"dspId,omitempty"`
+	SppId         string                   `protobuf:"bytes,2,opt,name=sppId,proto3" json:"sppId,omitempty"`
+	BidRequest    *ortb_V2_5.BidRequest    `protobuf:"bytes,3,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	BidResponses  []*ortb_V2_5.BidResponse `protobuf:"bytes,4,rep,name=bidResponses,proto3" json:"bidResponses,omitempty"`
+	GlobalId      string                   `protobuf:"bytes,5,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainFilterRequest_V2_5) Reset() {
+	*x = ExplainFilterRequest_V2_5{}
+	mi := &file_services_dspRouter_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainFilterRequest_V2_5) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainFilterRequest_V2_5) ProtoMessage() {}
+
+func (x *ExplainFilterRequest_V2_5) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainFilterRequest_V2_5.ProtoReflect.Descriptor instead.
+func (*ExplainFilterRequest_V2_5) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExplainFilterRequest_V2_5) GetDspId() string {
+	if x != nil {
+		return x.DspId
+	}
+	return ""
+}
+
+func (x *ExplainFilterRequest_V2_5) GetSppId() string {
+	if x != nil {
+		return x.SppId
+	}
+	return ""
+}
+
+func (x *ExplainFilterRequest_V2_5) GetBidRequest() *ortb_V2_5.BidRequest {
+	if x != nil {
+		return x.BidRequest
+	}
+	return nil
+}
+
+func (x *ExplainFilterRequest_V2_5) GetBidResponses() []*ortb_V2_5.BidResponse {
+	if x != nil {
+		return x.BidResponses
+	}
+	return nil
+}
+
+func (x *ExplainFilterRequest_V2_5) GetGlobalId() string {
+	if x != nil {
+		return x.GlobalId
+	}
+	return ""
+}
+
+// reset - обнулить счётчики после чтения
+type FilterStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reset_        bool                   `protobuf:"varint,1,opt,name=reset,proto3" json:"reset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterStatsRequest) Reset() {
+	*x = FilterStatsRequest{}
+	mi := &file_services_dspRouter_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterStatsRequest) ProtoMessage() {}
+
+func (x *FilterStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterStatsRequest.ProtoReflect.Descriptor instead.
+func (*FilterStatsRequest) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FilterStatsRequest) GetReset_() bool {
+	if x != nil {
+		return x.Reset_
+	}
+	return false
+}
+
+type ShadowRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	Comment       string                 `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShadowRulesRequest) Reset() {
+	*x = ShadowRulesRequest{}
+	mi := &file_services_dspRouter_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShadowRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShadowRulesRequest) ProtoMessage() {}
+
+func (x *ShadowRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShadowRulesRequest.ProtoReflect.Descriptor instead.
+func (*ShadowRulesRequest) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ShadowRulesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ShadowRulesRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+// kind - набор правил: dsp или spp
+type RuleVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleVersionsRequest) Reset() {
+	*x = RuleVersionsRequest{}
+	mi := &file_services_dspRouter_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleVersionsRequest) ProtoMessage() {}
+
+func (x *RuleVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleVersionsRequest.ProtoReflect.Descriptor instead.
+func (*RuleVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RuleVersionsRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type RuleVersionsDiffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	From          uint64                 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            uint64                 `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleVersionsDiffRequest) Reset() {
+	*x = RuleVersionsDiffRequest{}
+	mi := &file_services_dspRouter_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleVersionsDiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleVersionsDiffRequest) ProtoMessage() {}
+
+func (x *RuleVersionsDiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleVersionsDiffRequest.ProtoReflect.Descriptor instead.
+func (*RuleVersionsDiffRequest) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RuleVersionsDiffRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RuleVersionsDiffRequest) GetFrom() uint64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *RuleVersionsDiffRequest) GetTo() uint64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+type RollbackRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Revision      uint64                 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Comment       string                 `protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackRulesRequest) Reset() {
+	*x = RollbackRulesRequest{}
+	mi := &file_services_dspRouter_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackRulesRequest) ProtoMessage() {}
+
+func (x *RollbackRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_services_dspRouter_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackRulesRequest.ProtoReflect.Descriptor instead.
+func (*RollbackRulesRequest) Descriptor() ([]byte, []int) {
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RollbackRulesRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RollbackRulesRequest) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *RollbackRulesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *RollbackRulesRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
 type GetRulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -271,7 +712,7 @@ type GetRulesRequest struct {
 
 func (x *GetRulesRequest) Reset() {
 	*x = GetRulesRequest{}
-	mi := &file_services_dspRouter_proto_msgTypes[4]
+	mi := &file_services_dspRouter_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -283,7 +724,7 @@ func (x *GetRulesRequest) String() string {
 func (*GetRulesRequest) ProtoMessage() {}
 
 func (x *GetRulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_services_dspRouter_proto_msgTypes[4]
+	mi := &file_services_dspRouter_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -296,20 +737,22 @@ func (x *GetRulesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRulesRequest.ProtoReflect.Descriptor instead.
 func (*GetRulesRequest) Descriptor() ([]byte, []int) {
-	return file_services_dspRouter_proto_rawDescGZIP(), []int{4}
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{11}
 }
 
 type UpdateRulesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Ревизия правил после изменения
+	Revision      uint64 `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateRulesResponse) Reset() {
 	*x = UpdateRulesResponse{}
-	mi := &file_services_dspRouter_proto_msgTypes[5]
+	mi := &file_services_dspRouter_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -321,7 +764,7 @@ func (x *UpdateRulesResponse) String() string {
 func (*UpdateRulesResponse) ProtoMessage() {}
 
 func (x *UpdateRulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_dspRouter_proto_msgTypes[5]
+	mi := &file_services_dspRouter_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -334,7 +777,7 @@ func (x *UpdateRulesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateRulesResponse.ProtoReflect.Descriptor instead.
 func (*UpdateRulesResponse) Descriptor() ([]byte, []int) {
-	return file_services_dspRouter_proto_rawDescGZIP(), []int{5}
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *UpdateRulesResponse) GetSuccess() bool {
@@ -351,16 +794,25 @@ func (x *UpdateRulesResponse) GetMessage() string {
 	return ""
 }
 
+func (x *UpdateRulesResponse) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
 type JsonRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JsonData      []byte                 `protobuf:"bytes,1,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+	Author        string                 `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Comment       string                 `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *JsonRequest) Reset() {
 	*x = JsonRequest{}
-	mi := &file_services_dspRouter_proto_msgTypes[6]
+	mi := &file_services_dspRouter_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -372,7 +824,7 @@ func (x *JsonRequest) String() string {
 func (*JsonRequest) ProtoMessage() {}
 
 func (x *JsonRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_services_dspRouter_proto_msgTypes[6]
+	mi := &file_services_dspRouter_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -385,7 +837,7 @@ func (x *JsonRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JsonRequest.ProtoReflect.Descriptor instead.
 func (*JsonRequest) Descriptor() ([]byte, []int) {
-	return file_services_dspRouter_proto_rawDescGZIP(), []int{6}
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *JsonRequest) GetJsonData() []byte {
@@ -395,6 +847,20 @@ func (x *JsonRequest) GetJsonData() []byte {
 	return nil
 }
 
+func (x *JsonRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *JsonRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
 type JsonResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	JsonData      []byte                 `protobuf:"bytes,1,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
@@ -404,7 +870,7 @@ type JsonResponse struct {
 
 func (x *JsonResponse) Reset() {
 	*x = JsonResponse{}
-	mi := &file_services_dspRouter_proto_msgTypes[7]
+	mi := &file_services_dspRouter_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -416,7 +882,7 @@ func (x *JsonResponse) String() string {
 func (*JsonResponse) ProtoMessage() {}
 
 func (x *JsonResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_services_dspRouter_proto_msgTypes[7]
+	mi := &file_services_dspRouter_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,7 +895,7 @@ func (x *JsonResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JsonResponse.ProtoReflect.Descriptor instead.
 func (*JsonResponse) Descriptor() ([]byte, []int) {
-	return file_services_dspRouter_proto_rawDescGZIP(), []int{7}
+	return file_services_dspRouter_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *JsonResponse) GetJsonData() []byte {
@@ -443,39 +909,76 @@ var File_services_dspRouter_proto protoreflect.FileDescriptor
 
 const file_services_dspRouter_proto_rawDesc = "" +
 	"\n" +
-	"\x18services/dspRouter.proto\x12\tdspRouter\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\"\x8c\x01\n" +
+	"\x18services/dspRouter.proto\x12\tdspRouter\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\x1a\x18services/bidEngine.proto\"\x8c\x01\n" +
 	"\x15DspRouterRequest_V2_4\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_4.BidRequestR\n" +
 	"bidRequest\x12 \n" +
 	"\vsppEndpoint\x18\x02 \x01(\tR\vsppEndpoint\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\xa7\x01\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\x83\x02\n" +
 	"\x16DspRouterResponse_V2_4\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_4.BidRequestR\n" +
-	"bidRequest\x12:\n" +
-	"\fbidResponses\x18\x02 \x03(\v2\x16.ortb_V2_4.BidResponseR\fbidResponses\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\x8c\x01\n" +
+	"bidRequest\x12B\n" +
+	"\fbidResponses\x18\x02 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_4R\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12R\n" +
+	"\x14filteredBidResponses\x18\x04 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_4R\x14filteredBidResponses\"\x8c\x01\n" +
 	"\x15DspRouterRequest_V2_5\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_5.BidRequestR\n" +
 	"bidRequest\x12 \n" +
 	"\vsppEndpoint\x18\x02 \x01(\tR\vsppEndpoint\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\xa7\x01\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\x83\x02\n" +
 	"\x16DspRouterResponse_V2_5\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_5.BidRequestR\n" +
+	"bidRequest\x12B\n" +
+	"\fbidResponses\x18\x02 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_5R\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12R\n" +
+	"\x14filteredBidResponses\x18\x04 \x03(\v2\x1e.bidEngine.DspBidResponse_V2_5R\x14filteredBidResponses\"\xd6\x01\n" +
+	"\x19ExplainFilterRequest_V2_4\x12\x14\n" +
+	"\x05dspId\x18\x01 \x01(\tR\x05dspId\x12\x14\n" +
+	"\x05sppId\x18\x02 \x01(\tR\x05sppId\x125\n" +
+	"\n" +
+	"bidRequest\x18\x03 \x01(\v2\x15.ortb_V2_4.BidRequestR\n" +
+	"bidRequest\x12:\n" +
+	"\fbidResponses\x18\x04 \x03(\v2\x16.ortb_V2_4.BidResponseR\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x05 \x01(\tR\bglobalId\"\xd6\x01\n" +
+	"\x19ExplainFilterRequest_V2_5\x12\x14\n" +
+	"\x05dspId\x18\x01 \x01(\tR\x05dspId\x12\x14\n" +
+	"\x05sppId\x18\x02 \x01(\tR\x05sppId\x125\n" +
+	"\n" +
+	"bidRequest\x18\x03 \x01(\v2\x15.ortb_V2_5.BidRequestR\n" +
 	"bidRequest\x12:\n" +
-	"\fbidResponses\x18\x02 \x03(\v2\x16.ortb_V2_5.BidResponseR\fbidResponses\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\x11\n" +
-	"\x0fGetRulesRequest\"I\n" +
+	"\fbidResponses\x18\x04 \x03(\v2\x16.ortb_V2_5.BidResponseR\fbidResponses\x12\x1a\n" +
+	"\bglobalId\x18\x05 \x01(\tR\bglobalId\"*\n" +
+	"\x12FilterStatsRequest\x12\x14\n" +
+	"\x05reset\x18\x01 \x01(\bR\x05reset\"F\n" +
+	"\x12ShadowRulesRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\x12\x18\n" +
+	"\acomment\x18\x02 \x01(\tR\acomment\")\n" +
+	"\x13RuleVersionsRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\"Q\n" +
+	"\x17RuleVersionsDiffRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x04R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x04R\x02to\"x\n" +
+	"\x14RollbackRulesRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x04R\brevision\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x18\n" +
+	"\acomment\x18\x04 \x01(\tR\acomment\"\x11\n" +
+	"\x0fGetRulesRequest\"e\n" +
 	"\x13UpdateRulesResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"*\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
+	"\brevision\x18\x03 \x01(\x04R\brevision\"\\\n" +
 	"\vJsonRequest\x12\x1b\n" +
-	"\tjson_data\x18\x01 \x01(\fR\bjsonData\"+\n" +
+	"\tjson_data\x18\x01 \x01(\fR\bjsonData\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\x12\x18\n" +
+	"\acomment\x18\x03 \x01(\tR\acomment\"+\n" +
 	"\fJsonResponse\x12\x1b\n" +
-	"\tjson_data\x18\x01 \x01(\fR\bjsonData2\xfe\x04\n" +
+	"\tjson_data\x18\x01 \x01(\fR\bjsonData2\xf7\f\n" +
 	"\x10DspRouterService\x12S\n" +
 	"\fGetBids_V2_4\x12 .dspRouter.DspRouterRequest_V2_4\x1a!.dspRouter.DspRouterResponse_V2_4\x12D\n" +
 	"\rGetRules_V2_4\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\x12G\n" +
@@ -484,7 +987,20 @@ const file_services_dspRouter_proto_rawDesc = "" +
 	"\x10UpdateRules_V2_4\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12M\n" +
 	"\x13UpdateDSPRules_V2_4\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12M\n" +
 	"\x13UpdateSPPRules_V2_4\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12S\n" +
-	"\fGetBids_V2_5\x12 .dspRouter.DspRouterRequest_V2_5\x1a!.dspRouter.DspRouterResponse_V2_5B_Z]gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter;dspRouterGrpcb\x06proto3"
+	"\fGetBids_V2_5\x12 .dspRouter.DspRouterRequest_V2_5\x1a!.dspRouter.DspRouterResponse_V2_5\x12S\n" +
+	"\x12ExplainFilter_V2_4\x12$.dspRouter.ExplainFilterRequest_V2_4\x1a\x17.dspRouter.JsonResponse\x12S\n" +
+	"\x12ExplainFilter_V2_5\x12$.dspRouter.ExplainFilterRequest_V2_5\x1a\x17.dspRouter.JsonResponse\x12H\n" +
+	"\x0eGetFilterStats\x12\x1d.dspRouter.FilterStatsRequest\x1a\x17.dspRouter.JsonResponse\x12H\n" +
+	"\x0eSetShadowRules\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12I\n" +
+	"\x0fGetShadowReport\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x17.dspRouter.JsonResponse\x12S\n" +
+	"\x12PromoteShadowRules\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12P\n" +
+	"\x0fDropShadowRules\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12K\n" +
+	"\x10ListRuleVersions\x12\x1e.dspRouter.RuleVersionsRequest\x1a\x17.dspRouter.JsonResponse\x12O\n" +
+	"\x10DiffRuleVersions\x12\".dspRouter.RuleVersionsDiffRequest\x1a\x17.dspRouter.JsonResponse\x12P\n" +
+	"\rRollbackRules\x12\x1f.dspRouter.RollbackRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\x12H\n" +
+	"\x11GetRuleSyncStatus\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\x12D\n" +
+	"\rGetBlocklists\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\x12G\n" +
+	"\x10ReloadBlocklists\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponseB_Z]gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter;dspRouterGrpcb\x06proto3"
 
 var (
 	file_services_dspRouter_proto_rawDescOnce sync.Once
@@ -498,49 +1014,90 @@ func file_services_dspRouter_proto_rawDescGZIP() []byte {
 	return file_services_dspRouter_proto_rawDescData
 }
 
-var file_services_dspRouter_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_services_dspRouter_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_services_dspRouter_proto_goTypes = []any{
-	(*DspRouterRequest_V2_4)(nil),  // 0: dspRouter.DspRouterRequest_V2_4
-	(*DspRouterResponse_V2_4)(nil), // 1: dspRouter.DspRouterResponse_V2_4
-	(*DspRouterRequest_V2_5)(nil),  // 2: dspRouter.DspRouterRequest_V2_5
-	(*DspRouterResponse_V2_5)(nil), // 3: dspRouter.DspRouterResponse_V2_5
-	(*GetRulesRequest)(nil),        // 4: dspRouter.GetRulesRequest
-	(*UpdateRulesResponse)(nil),    // 5: dspRouter.UpdateRulesResponse
-	(*JsonRequest)(nil),            // 6: dspRouter.JsonRequest
-	(*JsonResponse)(nil),           // 7: dspRouter.JsonResponse
-	(*ortb_V2_4.BidRequest)(nil),   // 8: ortb_V2_4.BidRequest
-	(*ortb_V2_4.BidResponse)(nil),  // 9: ortb_V2_4.BidResponse
-	(*ortb_V2_5.BidRequest)(nil),   // 10: ortb_V2_5.BidRequest
-	(*ortb_V2_5.BidResponse)(nil),  // 11: ortb_V2_5.BidResponse
+	(*DspRouterRequest_V2_4)(nil),         // 0: dspRouter.DspRouterRequest_V2_4
+	(*DspRouterResponse_V2_4)(nil),        // 1: dspRouter.DspRouterResponse_V2_4
+	(*DspRouterRequest_V2_5)(nil),         // 2: dspRouter.DspRouterRequest_V2_5
+	(*DspRouterResponse_V2_5)(nil),        // 3: dspRouter.DspRouterResponse_V2_5
+	(*ExplainFilterRequest_V2_4)(nil),     // 4: dspRouter.ExplainFilterRequest_V2_4
+	(*ExplainFilterRequest_V2_5)(nil),     // 5: dspRouter.ExplainFilterRequest_V2_5
+	(*FilterStatsRequest)(nil),            // 6: dspRouter.FilterStatsRequest
+	(*ShadowRulesRequest)(nil),            // 7: dspRouter.ShadowRulesRequest
+	(*RuleVersionsRequest)(nil),           // 8: dspRouter.RuleVersionsRequest
+	(*RuleVersionsDiffRequest)(nil),       // 9: dspRouter.RuleVersionsDiffRequest
+	(*RollbackRulesRequest)(nil),          // 10: dspRouter.RollbackRulesRequest
+	(*GetRulesRequest)(nil),               // 11: dspRouter.GetRulesRequest
+	(*UpdateRulesResponse)(nil),           // 12: dspRouter.UpdateRulesResponse
+	(*JsonRequest)(nil),                   // 13: dspRouter.JsonRequest
+	(*JsonResponse)(nil),                  // 14: dspRouter.JsonResponse
+	(*ortb_V2_4.BidRequest)(nil),          // 15: ortb_V2_4.BidRequest
+	(*bidEngine.DspBidResponse_V2_4)(nil), // 16: bidEngine.DspBidResponse_V2_4
+	(*ortb_V2_5.BidRequest)(nil),          // 17: ortb_V2_5.BidRequest
+	(*bidEngine.DspBidResponse_V2_5)(nil), // 18: bidEngine.DspBidResponse_V2_5
+	(*ortb_V2_4.BidResponse)(nil),         // 19: ortb_V2_4.BidResponse
+	(*ortb_V2_5.BidResponse)(nil),         // 20: ortb_V2_5.BidResponse
 }
 var file_services_dspRouter_proto_depIdxs = []int32{
-	8,  // 0: dspRouter.DspRouterRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
-	8,  // 1: dspRouter.DspRouterResponse_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
-	9,  // 2: dspRouter.DspRouterResponse_V2_4.bidResponses:type_name -> ortb_V2_4.BidResponse
-	10, // 3: dspRouter.DspRouterRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
-	10, // 4: dspRouter.DspRouterResponse_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
-	11, // 5: dspRouter.DspRouterResponse_V2_5.bidResponses:type_name -> ortb_V2_5.BidResponse
-	0,  // 6: dspRouter.DspRouterService.GetBids_V2_4:input_type -> dspRouter.DspRouterRequest_V2_4
-	4,  // 7: dspRouter.DspRouterService.GetRules_V2_4:input_type -> dspRouter.GetRulesRequest
-	4,  // 8: dspRouter.DspRouterService.GetDSPRules_V2_4:input_type -> dspRouter.GetRulesRequest
-	4,  // 9: dspRouter.DspRouterService.GetSPPRules_V2_4:input_type -> dspRouter.GetRulesRequest
-	6,  // 10: dspRouter.DspRouterService.UpdateRules_V2_4:input_type -> dspRouter.JsonRequest
-	6,  // 11: dspRouter.DspRouterService.UpdateDSPRules_V2_4:input_type -> dspRouter.JsonRequest
-	6,  // 12: dspRouter.DspRouterService.UpdateSPPRules_V2_4:input_type -> dspRouter.JsonRequest
-	2,  // 13: dspRouter.DspRouterService.GetBids_V2_5:input_type -> dspRouter.DspRouterRequest_V2_5
-	1,  // 14: dspRouter.DspRouterService.GetBids_V2_4:output_type -> dspRouter.DspRouterResponse_V2_4
-	7,  // 15: dspRouter.DspRouterService.GetRules_V2_4:output_type -> dspRouter.JsonResponse
-	7,  // 16: dspRouter.DspRouterService.GetDSPRules_V2_4:output_type -> dspRouter.JsonResponse
-	7,  // 17: dspRouter.DspRouterService.GetSPPRules_V2_4:output_type -> dspRouter.JsonResponse
-	5,  // 18: dspRouter.DspRouterService.UpdateRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
-	5,  // 19: dspRouter.DspRouterService.UpdateDSPRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
-	5,  // 20: dspRouter.DspRouterService.UpdateSPPRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
-	3,  // 21: dspRouter.DspRouterService.GetBids_V2_5:output_type -> dspRouter.DspRouterResponse_V2_5
-	14, // [14:22] is the sub-list for method output_type
-	6,  // [6:14] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	15, // 0: dspRouter.DspRouterRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
+	15, // 1: dspRouter.DspRouterResponse_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
+	16, // 2: dspRouter.DspRouterResponse_V2_4.bidResponses:type_name -> bidEngine.DspBidResponse_V2_4
+	16, // 3: dspRouter.DspRouterResponse_V2_4.filteredBidResponses:type_name -> bidEngine.DspBidResponse_V2_4
+	17, // 4: dspRouter.DspRouterRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
+	17, // 5: dspRouter.DspRouterResponse_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
+	18, // 6: dspRouter.DspRouterResponse_V2_5.bidResponses:type_name -> bidEngine.DspBidResponse_V2_5
+	18, // 7: dspRouter.DspRouterResponse_V2_5.filteredBidResponses:type_name -> bidEngine.DspBidResponse_V2_5
+	15, // 8: dspRouter.ExplainFilterRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
+	19, // 9: dspRouter.ExplainFilterRequest_V2_4.bidResponses:type_name -> ortb_V2_4.BidResponse
+	17, // 10: dspRouter.ExplainFilterRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
+	20, // 11: dspRouter.ExplainFilterRequest_V2_5.bidResponses:type_name -> ortb_V2_5.BidResponse
+	0,  // 12: dspRouter.DspRouterService.GetBids_V2_4:input_type -> dspRouter.DspRouterRequest_V2_4
+	11, // 13: dspRouter.DspRouterService.GetRules_V2_4:input_type -> dspRouter.GetRulesRequest
+	11, // 14: dspRouter.DspRouterService.GetDSPRules_V2_4:input_type -> dspRouter.GetRulesRequest
+	11, // 15: dspRouter.DspRouterService.GetSPPRules_V2_4:input_type -> dspRouter.GetRulesRequest
+	13, // 16: dspRouter.DspRouterService.UpdateRules_V2_4:input_type -> dspRouter.JsonRequest
+	13, // 17: dspRouter.DspRouterService.UpdateDSPRules_V2_4:input_type -> dspRouter.JsonRequest
+	13, // 18: dspRouter.DspRouterService.UpdateSPPRules_V2_4:input_type -> dspRouter.JsonRequest
+	2,  // 19: dspRouter.DspRouterService.GetBids_V2_5:input_type -> dspRouter.DspRouterRequest_V2_5
+	4,  // 20: dspRouter.DspRouterService.ExplainFilter_V2_4:input_type -> dspRouter.ExplainFilterRequest_V2_4
+	5,  // 21: dspRouter.DspRouterService.ExplainFilter_V2_5:input_type -> dspRouter.ExplainFilterRequest_V2_5
+	6,  // 22: dspRouter.DspRouterService.GetFilterStats:input_type -> dspRouter.FilterStatsRequest
+	13, // 23: dspRouter.DspRouterService.SetShadowRules:input_type -> dspRouter.JsonRequest
+	7,  // 24: dspRouter.DspRouterService.GetShadowReport:input_type -> dspRouter.ShadowRulesRequest
+	7,  // 25: dspRouter.DspRouterService.PromoteShadowRules:input_type -> dspRouter.ShadowRulesRequest
+	7,  // 26: dspRouter.DspRouterService.DropShadowRules:input_type -> dspRouter.ShadowRulesRequest
+	8,  // 27: dspRouter.DspRouterService.ListRuleVersions:input_type -> dspRouter.RuleVersionsRequest
+	9,  // 28: dspRouter.DspRouterService.DiffRuleVersions:input_type -> dspRouter.RuleVersionsDiffRequest
+	10, // 29: dspRouter.DspRouterService.RollbackRules:input_type -> dspRouter.RollbackRulesRequest
+	11, // 30: dspRouter.DspRouterService.GetRuleSyncStatus:input_type -> dspRouter.GetRulesRequest
+	11, // 31: dspRouter.DspRouterService.GetBlocklists:input_type -> dspRouter.GetRulesRequest
+	11, // 32: dspRouter.DspRouterService.ReloadBlocklists:input_type -> dspRouter.GetRulesRequest
+	1,  // 33: dspRouter.DspRouterService.GetBids_V2_4:output_type -> dspRouter.DspRouterResponse_V2_4
+	14, // 34: dspRouter.DspRouterService.GetRules_V2_4:output_type -> dspRouter.JsonResponse
+	14, // 35: dspRouter.DspRouterService.GetDSPRules_V2_4:output_type -> dspRouter.JsonResponse
+	14, // 36: dspRouter.DspRouterService.GetSPPRules_V2_4:output_type -> dspRouter.JsonResponse
+	12, // 37: dspRouter.DspRouterService.UpdateRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
+	12, // 38: dspRouter.DspRouterService.UpdateDSPRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
+	12, // 39: dspRouter.DspRouterService.UpdateSPPRules_V2_4:output_type -> dspRouter.UpdateRulesResponse
+	3,  // 40: dspRouter.DspRouterService.GetBids_V2_5:output_type -> dspRouter.DspRouterResponse_V2_5
+	14, // 41: dspRouter.DspRouterService.ExplainFilter_V2_4:output_type -> dspRouter.JsonResponse
+	14, // 42: dspRouter.DspRouterService.ExplainFilter_V2_5:output_type -> dspRouter.JsonResponse
+	14, // 43: dspRouter.DspRouterService.GetFilterStats:output_type -> dspRouter.JsonResponse
+	12, // 44: dspRouter.DspRouterService.SetShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	14, // 45: dspRouter.DspRouterService.GetShadowReport:output_type -> dspRouter.JsonResponse
+	12, // 46: dspRouter.DspRouterService.PromoteShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	12, // 47: dspRouter.DspRouterService.DropShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	14, // 48: dspRouter.DspRouterService.ListRuleVersions:output_type -> dspRouter.JsonResponse
+	14, // 49: dspRouter.DspRouterService.DiffRuleVersions:output_type -> dspRouter.JsonResponse
+	12, // 50: dspRouter.DspRouterService.RollbackRules:output_type -> dspRouter.UpdateRulesResponse
+	14, // 51: dspRouter.DspRouterService.GetRuleSyncStatus:output_type -> dspRouter.JsonResponse
+	14, // 52: dspRouter.DspRouterService.GetBlocklists:output_type -> dspRouter.JsonResponse
+	14, // 53: dspRouter.DspRouterService.ReloadBlocklists:output_type -> dspRouter.JsonResponse
+	33, // [33:54] is the sub-list for method output_type
+	12, // [12:33] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_services_dspRouter_proto_init() }
@@ -554,7 +1111,7 @@ func file_services_dspRouter_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_services_dspRouter_proto_rawDesc), len(file_services_dspRouter_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
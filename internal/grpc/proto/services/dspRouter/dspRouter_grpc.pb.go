@@ -27,6 +27,19 @@ const (
 	DspRouterService_UpdateDSPRules_V2_4_FullMethodName = "/dspRouter.DspRouterService/UpdateDSPRules_V2_4"
 	DspRouterService_UpdateSPPRules_V2_4_FullMethodName = "/dspRouter.DspRouterService/UpdateSPPRules_V2_4"
 	DspRouterService_GetBids_V2_5_FullMethodName        = "/dspRouter.DspRouterService/GetBids_V2_5"
+	DspRouterService_ExplainFilter_V2_4_FullMethodName  = "/dspRouter.DspRouterService/ExplainFilter_V2_4"
+	DspRouterService_ExplainFilter_V2_5_FullMethodName  = "/dspRouter.DspRouterService/ExplainFilter_V2_5"
+	DspRouterService_GetFilterStats_FullMethodName      = "/dspRouter.DspRouterService/GetFilterStats"
+	DspRouterService_SetShadowRules_FullMethodName      = "/dspRouter.DspRouterService/SetShadowRules"
+	DspRouterService_GetShadowReport_FullMethodName     = "/dspRouter.DspRouterService/GetShadowReport"
+	DspRouterService_PromoteShadowRules_FullMethodName  = "/dspRouter.DspRouterService/PromoteShadowRules"
+	DspRouterService_DropShadowRules_FullMethodName     = "/dspRouter.DspRouterService/DropShadowRules"
+	DspRouterService_ListRuleVersions_FullMethodName    = "/dspRouter.DspRouterService/ListRuleVersions"
+	DspRouterService_DiffRuleVersions_FullMethodName    = "/dspRouter.DspRouterService/DiffRuleVersions"
+	DspRouterService_RollbackRules_FullMethodName       = "/dspRouter.DspRouterService/RollbackRules"
+	DspRouterService_GetRuleSyncStatus_FullMethodName   = "/dspRouter.DspRouterService/GetRuleSyncStatus"
+	DspRouterService_GetBlocklists_FullMethodName       = "/dspRouter.DspRouterService/GetBlocklists"
+	DspRouterService_ReloadBlocklists_FullMethodName    = "/dspRouter.DspRouterService/ReloadBlocklists"
 )
 
 // DspRouterServiceClient is the client API for DspRouterService service.
@@ -41,6 +54,19 @@ type DspRouterServiceClient interface {
 	UpdateDSPRules_V2_4(ctx context.Context, in *JsonRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
 	UpdateSPPRules_V2_4(ctx context.Context, in *JsonRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
 	GetBids_V2_5(ctx context.Context, in *DspRouterRequest_V2_5, opts ...grpc.CallOption) (*DspRouterResponse_V2_5, error)
+	ExplainFilter_V2_4(ctx context.Context, in *ExplainFilterRequest_V2_4, opts ...grpc.CallOption) (*JsonResponse, error)
+	ExplainFilter_V2_5(ctx context.Context, in *ExplainFilterRequest_V2_5, opts ...grpc.CallOption) (*JsonResponse, error)
+	GetFilterStats(ctx context.Context, in *FilterStatsRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	SetShadowRules(ctx context.Context, in *JsonRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
+	GetShadowReport(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	PromoteShadowRules(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
+	DropShadowRules(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
+	ListRuleVersions(ctx context.Context, in *RuleVersionsRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	DiffRuleVersions(ctx context.Context, in *RuleVersionsDiffRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	RollbackRules(ctx context.Context, in *RollbackRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error)
+	GetRuleSyncStatus(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	GetBlocklists(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error)
+	ReloadBlocklists(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error)
 }
 
 type dspRouterServiceClient struct {
@@ -131,6 +157,136 @@ func (c *dspRouterServiceClient) GetBids_V2_5(ctx context.Context, in *DspRouter
 	return out, nil
 }
 
+func (c *dspRouterServiceClient) ExplainFilter_V2_4(ctx context.Context, in *ExplainFilterRequest_V2_4, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_ExplainFilter_V2_4_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) ExplainFilter_V2_5(ctx context.Context, in *ExplainFilterRequest_V2_5, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_ExplainFilter_V2_5_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) GetFilterStats(ctx context.Context, in *FilterStatsRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_GetFilterStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) SetShadowRules(ctx context.Context, in *JsonRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_SetShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) GetShadowReport(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_GetShadowReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) PromoteShadowRules(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_PromoteShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) DropShadowRules(ctx context.Context, in *ShadowRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_DropShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) ListRuleVersions(ctx context.Context, in *RuleVersionsRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_ListRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) DiffRuleVersions(ctx context.Context, in *RuleVersionsDiffRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_DiffRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) RollbackRules(ctx context.Context, in *RollbackRulesRequest, opts ...grpc.CallOption) (*UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_RollbackRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) GetRuleSyncStatus(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_GetRuleSyncStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) GetBlocklists(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_GetBlocklists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dspRouterServiceClient) ReloadBlocklists(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JsonResponse)
+	err := c.cc.Invoke(ctx, DspRouterService_ReloadBlocklists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DspRouterServiceServer is the server API for DspRouterService service.
 // All implementations must embed UnimplementedDspRouterServiceServer
 // for forward compatibility.
@@ -143,6 +299,19 @@ type DspRouterServiceServer interface {
 	UpdateDSPRules_V2_4(context.Context, *JsonRequest) (*UpdateRulesResponse, error)
 	UpdateSPPRules_V2_4(context.Context, *JsonRequest) (*UpdateRulesResponse, error)
 	GetBids_V2_5(context.Context, *DspRouterRequest_V2_5) (*DspRouterResponse_V2_5, error)
+	ExplainFilter_V2_4(context.Context, *ExplainFilterRequest_V2_4) (*JsonResponse, error)
+	ExplainFilter_V2_5(context.Context, *ExplainFilterRequest_V2_5) (*JsonResponse, error)
+	GetFilterStats(context.Context, *FilterStatsRequest) (*JsonResponse, error)
+	SetShadowRules(context.Context, *JsonRequest) (*UpdateRulesResponse, error)
+	GetShadowReport(context.Context, *ShadowRulesRequest) (*JsonResponse, error)
+	PromoteShadowRules(context.Context, *ShadowRulesRequest) (*UpdateRulesResponse, error)
+	DropShadowRules(context.Context, *ShadowRulesRequest) (*UpdateRulesResponse, error)
+	ListRuleVersions(context.Context, *RuleVersionsRequest) (*JsonResponse, error)
+	DiffRuleVersions(context.Context, *RuleVersionsDiffRequest) (*JsonResponse, error)
+	RollbackRules(context.Context, *RollbackRulesRequest) (*UpdateRulesResponse, error)
+	GetRuleSyncStatus(context.Context, *GetRulesRequest) (*JsonResponse, error)
+	GetBlocklists(context.Context, *GetRulesRequest) (*JsonResponse, error)
+	ReloadBlocklists(context.Context, *GetRulesRequest) (*JsonResponse, error)
 	mustEmbedUnimplementedDspRouterServiceServer()
 }
 
@@ -177,6 +346,45 @@ func (UnimplementedDspRouterServiceServer) UpdateSPPRules_V2_4(context.Context,
 func (UnimplementedDspRouterServiceServer) GetBids_V2_5(context.Context, *DspRouterRequest_V2_5) (*DspRouterResponse_V2_5, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBids_V2_5 not implemented")
 }
+func (UnimplementedDspRouterServiceServer) ExplainFilter_V2_4(context.Context, *ExplainFilterRequest_V2_4) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainFilter_V2_4 not implemented")
+}
+func (UnimplementedDspRouterServiceServer) ExplainFilter_V2_5(context.Context, *ExplainFilterRequest_V2_5) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainFilter_V2_5 not implemented")
+}
+func (UnimplementedDspRouterServiceServer) GetFilterStats(context.Context, *FilterStatsRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFilterStats not implemented")
+}
+func (UnimplementedDspRouterServiceServer) SetShadowRules(context.Context, *JsonRequest) (*UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetShadowRules not implemented")
+}
+func (UnimplementedDspRouterServiceServer) GetShadowReport(context.Context, *ShadowRulesRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShadowReport not implemented")
+}
+func (UnimplementedDspRouterServiceServer) PromoteShadowRules(context.Context, *ShadowRulesRequest) (*UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteShadowRules not implemented")
+}
+func (UnimplementedDspRouterServiceServer) DropShadowRules(context.Context, *ShadowRulesRequest) (*UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropShadowRules not implemented")
+}
+func (UnimplementedDspRouterServiceServer) ListRuleVersions(context.Context, *RuleVersionsRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRuleVersions not implemented")
+}
+func (UnimplementedDspRouterServiceServer) DiffRuleVersions(context.Context, *RuleVersionsDiffRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffRuleVersions not implemented")
+}
+func (UnimplementedDspRouterServiceServer) RollbackRules(context.Context, *RollbackRulesRequest) (*UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackRules not implemented")
+}
+func (UnimplementedDspRouterServiceServer) GetRuleSyncStatus(context.Context, *GetRulesRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRuleSyncStatus not implemented")
+}
+func (UnimplementedDspRouterServiceServer) GetBlocklists(context.Context, *GetRulesRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocklists not implemented")
+}
+func (UnimplementedDspRouterServiceServer) ReloadBlocklists(context.Context, *GetRulesRequest) (*JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadBlocklists not implemented")
+}
 func (UnimplementedDspRouterServiceServer) mustEmbedUnimplementedDspRouterServiceServer() {}
 func (UnimplementedDspRouterServiceServer) testEmbeddedByValue()                          {}
 
@@ -342,6 +550,240 @@ func _DspRouterService_GetBids_V2_5_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DspRouterService_ExplainFilter_V2_4_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainFilterRequest_V2_4)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).ExplainFilter_V2_4(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_ExplainFilter_V2_4_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).ExplainFilter_V2_4(ctx, req.(*ExplainFilterRequest_V2_4))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_ExplainFilter_V2_5_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainFilterRequest_V2_5)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).ExplainFilter_V2_5(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_ExplainFilter_V2_5_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).ExplainFilter_V2_5(ctx, req.(*ExplainFilterRequest_V2_5))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_GetFilterStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).GetFilterStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_GetFilterStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).GetFilterStats(ctx, req.(*FilterStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_SetShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JsonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).SetShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_SetShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).SetShadowRules(ctx, req.(*JsonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_GetShadowReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).GetShadowReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_GetShadowReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).GetShadowReport(ctx, req.(*ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_PromoteShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).PromoteShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_PromoteShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).PromoteShadowRules(ctx, req.(*ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_DropShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).DropShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_DropShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).DropShadowRules(ctx, req.(*ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_ListRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RuleVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).ListRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_ListRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).ListRuleVersions(ctx, req.(*RuleVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_DiffRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RuleVersionsDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).DiffRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_DiffRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).DiffRuleVersions(ctx, req.(*RuleVersionsDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_RollbackRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).RollbackRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_RollbackRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).RollbackRules(ctx, req.(*RollbackRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_GetRuleSyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).GetRuleSyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_GetRuleSyncStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).GetRuleSyncStatus(ctx, req.(*GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_GetBlocklists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).GetBlocklists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_GetBlocklists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).GetBlocklists(ctx, req.(*GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DspRouterService_ReloadBlocklists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DspRouterServiceServer).ReloadBlocklists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DspRouterService_ReloadBlocklists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DspRouterServiceServer).ReloadBlocklists(ctx, req.(*GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DspRouterService_ServiceDesc is the grpc.ServiceDesc for DspRouterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -381,6 +823,58 @@ var DspRouterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBids_V2_5",
 			Handler:    _DspRouterService_GetBids_V2_5_Handler,
 		},
+		{
+			MethodName: "ExplainFilter_V2_4",
+			Handler:    _DspRouterService_ExplainFilter_V2_4_Handler,
+		},
+		{
+			MethodName: "ExplainFilter_V2_5",
+			Handler:    _DspRouterService_ExplainFilter_V2_5_Handler,
+		},
+		{
+			MethodName: "GetFilterStats",
+			Handler:    _DspRouterService_GetFilterStats_Handler,
+		},
+		{
+			MethodName: "SetShadowRules",
+			Handler:    _DspRouterService_SetShadowRules_Handler,
+		},
+		{
+			MethodName: "GetShadowReport",
+			Handler:    _DspRouterService_GetShadowReport_Handler,
+		},
+		{
+			MethodName: "PromoteShadowRules",
+			Handler:    _DspRouterService_PromoteShadowRules_Handler,
+		},
+		{
+			MethodName: "DropShadowRules",
+			Handler:    _DspRouterService_DropShadowRules_Handler,
+		},
+		{
+			MethodName: "ListRuleVersions",
+			Handler:    _DspRouterService_ListRuleVersions_Handler,
+		},
+		{
+			MethodName: "DiffRuleVersions",
+			Handler:    _DspRouterService_DiffRuleVersions_Handler,
+		},
+		{
+			MethodName: "RollbackRules",
+			Handler:    _DspRouterService_RollbackRules_Handler,
+		},
+		{
+			MethodName: "GetRuleSyncStatus",
+			Handler:    _DspRouterService_GetRuleSyncStatus_Handler,
+		},
+		{
+			MethodName: "GetBlocklists",
+			Handler:    _DspRouterService_GetBlocklists_Handler,
+		},
+		{
+			MethodName: "ReloadBlocklists",
+			Handler:    _DspRouterService_ReloadBlocklists_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/dspRouter.proto",
@@ -7,6 +7,8 @@
 package orchestratorGrpc
 
 import (
+	bidEngine "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	dspRouter "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
 	ortb_V2_4 "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	ortb_V2_5 "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -24,12 +26,13 @@ const (
 )
 
 type OrchestratorRequest_V2_4 struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BidRequest    *ortb_V2_4.BidRequest  `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	SppEndpoint   string                 `protobuf:"bytes,2,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
-	GlobalId      string                 `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState     `protogen:"open.v1"`
+	BidRequest     *ortb_V2_4.BidRequest      `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	SppEndpoint    string                     `protobuf:"bytes,2,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
+	GlobalId       string                     `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FloorDecisions []*bidEngine.FloorDecision `protobuf:"bytes,4,rep,name=floorDecisions,proto3" json:"floorDecisions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *OrchestratorRequest_V2_4) Reset() {
@@ -83,6 +86,13 @@ func (x *OrchestratorRequest_V2_4) GetGlobalId() string {
 	return ""
 }
 
+func (x *OrchestratorRequest_V2_4) GetFloorDecisions() []*bidEngine.FloorDecision {
+	if x != nil {
+		return x.FloorDecisions
+	}
+	return nil
+}
+
 type OrchestratorResponse_V2_4 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BidResponse   *ortb_V2_4.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
@@ -136,12 +146,13 @@ func (x *OrchestratorResponse_V2_4) GetGlobalId() string {
 }
 
 type OrchestratorRequest_V2_5 struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BidRequest    *ortb_V2_5.BidRequest  `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
-	SppEndpoint   string                 `protobuf:"bytes,2,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
-	GlobalId      string                 `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState     `protogen:"open.v1"`
+	BidRequest     *ortb_V2_5.BidRequest      `protobuf:"bytes,1,opt,name=bidRequest,proto3" json:"bidRequest,omitempty"`
+	SppEndpoint    string                     `protobuf:"bytes,2,opt,name=sppEndpoint,proto3" json:"sppEndpoint,omitempty"`
+	GlobalId       string                     `protobuf:"bytes,3,opt,name=globalId,proto3" json:"globalId,omitempty"`
+	FloorDecisions []*bidEngine.FloorDecision `protobuf:"bytes,4,rep,name=floorDecisions,proto3" json:"floorDecisions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *OrchestratorRequest_V2_5) Reset() {
@@ -195,6 +206,13 @@ func (x *OrchestratorRequest_V2_5) GetGlobalId() string {
 	return ""
 }
 
+func (x *OrchestratorRequest_V2_5) GetFloorDecisions() []*bidEngine.FloorDecision {
+	if x != nil {
+		return x.FloorDecisions
+	}
+	return nil
+}
+
 type OrchestratorResponse_V2_5 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BidResponse   *ortb_V2_5.BidResponse `protobuf:"bytes,1,opt,name=bidResponse,proto3" json:"bidResponse,omitempty"`
@@ -251,28 +269,48 @@ var File_services_orchestrator_proto protoreflect.FileDescriptor
 
 const file_services_orchestrator_proto_rawDesc = "" +
 	"\n" +
-	"\x1bservices/orchestrator.proto\x12\forchestrator\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\"\x8f\x01\n" +
+	"\x1bservices/orchestrator.proto\x12\forchestrator\x1a\x1atypes/ortb_V2_4/ortb.proto\x1a\x1atypes/ortb_V2_5/ortb.proto\x1a\x18services/bidEngine.proto\x1a\x18services/dspRouter.proto\"\xd1\x01\n" +
 	"\x18OrchestratorRequest_V2_4\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_4.BidRequestR\n" +
 	"bidRequest\x12 \n" +
 	"\vsppEndpoint\x18\x02 \x01(\tR\vsppEndpoint\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"q\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12@\n" +
+	"\x0efloorDecisions\x18\x04 \x03(\v2\x18.bidEngine.FloorDecisionR\x0efloorDecisions\"q\n" +
 	"\x19OrchestratorResponse_V2_4\x128\n" +
 	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_4.BidResponseR\vbidResponse\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\x8f\x01\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"\xd1\x01\n" +
 	"\x18OrchestratorRequest_V2_5\x125\n" +
 	"\n" +
 	"bidRequest\x18\x01 \x01(\v2\x15.ortb_V2_5.BidRequestR\n" +
 	"bidRequest\x12 \n" +
 	"\vsppEndpoint\x18\x02 \x01(\tR\vsppEndpoint\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId\"q\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId\x12@\n" +
+	"\x0efloorDecisions\x18\x04 \x03(\v2\x18.bidEngine.FloorDecisionR\x0efloorDecisions\"q\n" +
 	"\x19OrchestratorResponse_V2_5\x128\n" +
 	"\vbidResponse\x18\x01 \x01(\v2\x16.ortb_V2_5.BidResponseR\vbidResponse\x12\x1a\n" +
-	"\bglobalId\x18\x03 \x01(\tR\bglobalId2\xe5\x01\n" +
+	"\bglobalId\x18\x03 \x01(\tR\bglobalId2\xe4\f\n" +
 	"\x13OrchestratorService\x12f\n" +
 	"\x11getWinnerBid_V2_4\x12&.orchestrator.OrchestratorRequest_V2_4\x1a'.orchestrator.OrchestratorResponse_V2_4\"\x00\x12f\n" +
-	"\x11getWinnerBid_V2_5\x12&.orchestrator.OrchestratorRequest_V2_5\x1a'.orchestrator.OrchestratorResponse_V2_5\"\x00BeZcgitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator;orchestratorGrpcb\x06proto3"
+	"\x11getWinnerBid_V2_5\x12&.orchestrator.OrchestratorRequest_V2_5\x1a'.orchestrator.OrchestratorResponse_V2_5\"\x00\x12F\n" +
+	"\rreportBilling\x12\x17.bidEngine.BillingEvent\x1a\x1a.bidEngine.BillingEventAck\"\x00\x12U\n" +
+	"\x12explainFilter_V2_4\x12$.dspRouter.ExplainFilterRequest_V2_4\x1a\x17.dspRouter.JsonResponse\"\x00\x12U\n" +
+	"\x12explainFilter_V2_5\x12$.dspRouter.ExplainFilterRequest_V2_5\x1a\x17.dspRouter.JsonResponse\"\x00\x12J\n" +
+	"\x0egetFilterStats\x12\x1d.dspRouter.FilterStatsRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12J\n" +
+	"\x0esetShadowRules\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12K\n" +
+	"\x0fgetShadowReport\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12U\n" +
+	"\x12promoteShadowRules\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12R\n" +
+	"\x0fdropShadowRules\x12\x1d.dspRouter.ShadowRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12D\n" +
+	"\vgetDSPRules\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12D\n" +
+	"\vgetSPPRules\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12J\n" +
+	"\x0eupdateDSPRules\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12J\n" +
+	"\x0eupdateSPPRules\x12\x16.dspRouter.JsonRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12M\n" +
+	"\x10listRuleVersions\x12\x1e.dspRouter.RuleVersionsRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12Q\n" +
+	"\x10diffRuleVersions\x12\".dspRouter.RuleVersionsDiffRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12R\n" +
+	"\rrollbackRules\x12\x1f.dspRouter.RollbackRulesRequest\x1a\x1e.dspRouter.UpdateRulesResponse\"\x00\x12J\n" +
+	"\x11getRuleSyncStatus\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12F\n" +
+	"\rgetBlocklists\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00\x12I\n" +
+	"\x10reloadBlocklists\x12\x1a.dspRouter.GetRulesRequest\x1a\x17.dspRouter.JsonResponse\"\x00BeZcgitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator;orchestratorGrpcb\x06proto3"
 
 var (
 	file_services_orchestrator_proto_rawDescOnce sync.Once
@@ -288,29 +326,81 @@ func file_services_orchestrator_proto_rawDescGZIP() []byte {
 
 var file_services_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_services_orchestrator_proto_goTypes = []any{
-	(*OrchestratorRequest_V2_4)(nil),  // 0: orchestrator.OrchestratorRequest_V2_4
-	(*OrchestratorResponse_V2_4)(nil), // 1: orchestrator.OrchestratorResponse_V2_4
-	(*OrchestratorRequest_V2_5)(nil),  // 2: orchestrator.OrchestratorRequest_V2_5
-	(*OrchestratorResponse_V2_5)(nil), // 3: orchestrator.OrchestratorResponse_V2_5
-	(*ortb_V2_4.BidRequest)(nil),      // 4: ortb_V2_4.BidRequest
-	(*ortb_V2_4.BidResponse)(nil),     // 5: ortb_V2_4.BidResponse
-	(*ortb_V2_5.BidRequest)(nil),      // 6: ortb_V2_5.BidRequest
-	(*ortb_V2_5.BidResponse)(nil),     // 7: ortb_V2_5.BidResponse
+	(*OrchestratorRequest_V2_4)(nil),            // 0: orchestrator.OrchestratorRequest_V2_4
+	(*OrchestratorResponse_V2_4)(nil),           // 1: orchestrator.OrchestratorResponse_V2_4
+	(*OrchestratorRequest_V2_5)(nil),            // 2: orchestrator.OrchestratorRequest_V2_5
+	(*OrchestratorResponse_V2_5)(nil),           // 3: orchestrator.OrchestratorResponse_V2_5
+	(*ortb_V2_4.BidRequest)(nil),                // 4: ortb_V2_4.BidRequest
+	(*bidEngine.FloorDecision)(nil),             // 5: bidEngine.FloorDecision
+	(*ortb_V2_4.BidResponse)(nil),               // 6: ortb_V2_4.BidResponse
+	(*ortb_V2_5.BidRequest)(nil),                // 7: ortb_V2_5.BidRequest
+	(*ortb_V2_5.BidResponse)(nil),               // 8: ortb_V2_5.BidResponse
+	(*bidEngine.BillingEvent)(nil),              // 9: bidEngine.BillingEvent
+	(*dspRouter.ExplainFilterRequest_V2_4)(nil), // 10: dspRouter.ExplainFilterRequest_V2_4
+	(*dspRouter.ExplainFilterRequest_V2_5)(nil), // 11: dspRouter.ExplainFilterRequest_V2_5
+	(*dspRouter.FilterStatsRequest)(nil),        // 12: dspRouter.FilterStatsRequest
+	(*dspRouter.JsonRequest)(nil),               // 13: dspRouter.JsonRequest
+	(*dspRouter.ShadowRulesRequest)(nil),        // 14: dspRouter.ShadowRulesRequest
+	(*dspRouter.GetRulesRequest)(nil),           // 15: dspRouter.GetRulesRequest
+	(*dspRouter.RuleVersionsRequest)(nil),       // 16: dspRouter.RuleVersionsRequest
+	(*dspRouter.RuleVersionsDiffRequest)(nil),   // 17: dspRouter.RuleVersionsDiffRequest
+	(*dspRouter.RollbackRulesRequest)(nil),      // 18: dspRouter.RollbackRulesRequest
+	(*bidEngine.BillingEventAck)(nil),           // 19: bidEngine.BillingEventAck
+	(*dspRouter.JsonResponse)(nil),              // 20: dspRouter.JsonResponse
+	(*dspRouter.UpdateRulesResponse)(nil),       // 21: dspRouter.UpdateRulesResponse
 }
 var file_services_orchestrator_proto_depIdxs = []int32{
-	4, // 0: orchestrator.OrchestratorRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
-	5, // 1: orchestrator.OrchestratorResponse_V2_4.bidResponse:type_name -> ortb_V2_4.BidResponse
-	6, // 2: orchestrator.OrchestratorRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
-	7, // 3: orchestrator.OrchestratorResponse_V2_5.bidResponse:type_name -> ortb_V2_5.BidResponse
-	0, // 4: orchestrator.OrchestratorService.getWinnerBid_V2_4:input_type -> orchestrator.OrchestratorRequest_V2_4
-	2, // 5: orchestrator.OrchestratorService.getWinnerBid_V2_5:input_type -> orchestrator.OrchestratorRequest_V2_5
-	1, // 6: orchestrator.OrchestratorService.getWinnerBid_V2_4:output_type -> orchestrator.OrchestratorResponse_V2_4
-	3, // 7: orchestrator.OrchestratorService.getWinnerBid_V2_5:output_type -> orchestrator.OrchestratorResponse_V2_5
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	4,  // 0: orchestrator.OrchestratorRequest_V2_4.bidRequest:type_name -> ortb_V2_4.BidRequest
+	5,  // 1: orchestrator.OrchestratorRequest_V2_4.floorDecisions:type_name -> bidEngine.FloorDecision
+	6,  // 2: orchestrator.OrchestratorResponse_V2_4.bidResponse:type_name -> ortb_V2_4.BidResponse
+	7,  // 3: orchestrator.OrchestratorRequest_V2_5.bidRequest:type_name -> ortb_V2_5.BidRequest
+	5,  // 4: orchestrator.OrchestratorRequest_V2_5.floorDecisions:type_name -> bidEngine.FloorDecision
+	8,  // 5: orchestrator.OrchestratorResponse_V2_5.bidResponse:type_name -> ortb_V2_5.BidResponse
+	0,  // 6: orchestrator.OrchestratorService.getWinnerBid_V2_4:input_type -> orchestrator.OrchestratorRequest_V2_4
+	2,  // 7: orchestrator.OrchestratorService.getWinnerBid_V2_5:input_type -> orchestrator.OrchestratorRequest_V2_5
+	9,  // 8: orchestrator.OrchestratorService.reportBilling:input_type -> bidEngine.BillingEvent
+	10, // 9: orchestrator.OrchestratorService.explainFilter_V2_4:input_type -> dspRouter.ExplainFilterRequest_V2_4
+	11, // 10: orchestrator.OrchestratorService.explainFilter_V2_5:input_type -> dspRouter.ExplainFilterRequest_V2_5
+	12, // 11: orchestrator.OrchestratorService.getFilterStats:input_type -> dspRouter.FilterStatsRequest
+	13, // 12: orchestrator.OrchestratorService.setShadowRules:input_type -> dspRouter.JsonRequest
+	14, // 13: orchestrator.OrchestratorService.getShadowReport:input_type -> dspRouter.ShadowRulesRequest
+	14, // 14: orchestrator.OrchestratorService.promoteShadowRules:input_type -> dspRouter.ShadowRulesRequest
+	14, // 15: orchestrator.OrchestratorService.dropShadowRules:input_type -> dspRouter.ShadowRulesRequest
+	15, // 16: orchestrator.OrchestratorService.getDSPRules:input_type -> dspRouter.GetRulesRequest
+	15, // 17: orchestrator.OrchestratorService.getSPPRules:input_type -> dspRouter.GetRulesRequest
+	13, // 18: orchestrator.OrchestratorService.updateDSPRules:input_type -> dspRouter.JsonRequest
+	13, // 19: orchestrator.OrchestratorService.updateSPPRules:input_type -> dspRouter.JsonRequest
+	16, // 20: orchestrator.OrchestratorService.listRuleVersions:input_type -> dspRouter.RuleVersionsRequest
+	17, // 21: orchestrator.OrchestratorService.diffRuleVersions:input_type -> dspRouter.RuleVersionsDiffRequest
+	18, // 22: orchestrator.OrchestratorService.rollbackRules:input_type -> dspRouter.RollbackRulesRequest
+	15, // 23: orchestrator.OrchestratorService.getRuleSyncStatus:input_type -> dspRouter.GetRulesRequest
+	15, // 24: orchestrator.OrchestratorService.getBlocklists:input_type -> dspRouter.GetRulesRequest
+	15, // 25: orchestrator.OrchestratorService.reloadBlocklists:input_type -> dspRouter.GetRulesRequest
+	1,  // 26: orchestrator.OrchestratorService.getWinnerBid_V2_4:output_type -> orchestrator.OrchestratorResponse_V2_4
+	3,  // 27: orchestrator.OrchestratorService.getWinnerBid_V2_5:output_type -> orchestrator.OrchestratorResponse_V2_5
+	19, // 28: orchestrator.OrchestratorService.reportBilling:output_type -> bidEngine.BillingEventAck
+	20, // 29: orchestrator.OrchestratorService.explainFilter_V2_4:output_type -> dspRouter.JsonResponse
+	20, // 30: orchestrator.OrchestratorService.explainFilter_V2_5:output_type -> dspRouter.JsonResponse
+	20, // 31: orchestrator.OrchestratorService.getFilterStats:output_type -> dspRouter.JsonResponse
+	21, // 32: orchestrator.OrchestratorService.setShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	20, // 33: orchestrator.OrchestratorService.getShadowReport:output_type -> dspRouter.JsonResponse
+	21, // 34: orchestrator.OrchestratorService.promoteShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	21, // 35: orchestrator.OrchestratorService.dropShadowRules:output_type -> dspRouter.UpdateRulesResponse
+	20, // 36: orchestrator.OrchestratorService.getDSPRules:output_type -> dspRouter.JsonResponse
+	20, // 37: orchestrator.OrchestratorService.getSPPRules:output_type -> dspRouter.JsonResponse
+	21, // 38: orchestrator.OrchestratorService.updateDSPRules:output_type -> dspRouter.UpdateRulesResponse
+	21, // 39: orchestrator.OrchestratorService.updateSPPRules:output_type -> dspRouter.UpdateRulesResponse
+	20, // 40: orchestrator.OrchestratorService.listRuleVersions:output_type -> dspRouter.JsonResponse
+	20, // 41: orchestrator.OrchestratorService.diffRuleVersions:output_type -> dspRouter.JsonResponse
+	21, // 42: orchestrator.OrchestratorService.rollbackRules:output_type -> dspRouter.UpdateRulesResponse
+	20, // 43: orchestrator.OrchestratorService.getRuleSyncStatus:output_type -> dspRouter.JsonResponse
+	20, // 44: orchestrator.OrchestratorService.getBlocklists:output_type -> dspRouter.JsonResponse
+	20, // 45: orchestrator.OrchestratorService.reloadBlocklists:output_type -> dspRouter.JsonResponse
+	26, // [26:46] is the sub-list for method output_type
+	6,  // [6:26] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_services_orchestrator_proto_init() }
@@ -8,6 +8,8 @@ package orchestratorGrpc
 
 import (
 	context "context"
+	bidEngine "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	dspRouter "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,8 +21,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrchestratorService_GetWinnerBid_V2_4_FullMethodName = "/orchestrator.OrchestratorService/getWinnerBid_V2_4"
-	OrchestratorService_GetWinnerBid_V2_5_FullMethodName = "/orchestrator.OrchestratorService/getWinnerBid_V2_5"
+	OrchestratorService_GetWinnerBid_V2_4_FullMethodName  = "/orchestrator.OrchestratorService/getWinnerBid_V2_4"
+	OrchestratorService_GetWinnerBid_V2_5_FullMethodName  = "/orchestrator.OrchestratorService/getWinnerBid_V2_5"
+	OrchestratorService_ReportBilling_FullMethodName      = "/orchestrator.OrchestratorService/reportBilling"
+	OrchestratorService_ExplainFilter_V2_4_FullMethodName = "/orchestrator.OrchestratorService/explainFilter_V2_4"
+	OrchestratorService_ExplainFilter_V2_5_FullMethodName = "/orchestrator.OrchestratorService/explainFilter_V2_5"
+	OrchestratorService_GetFilterStats_FullMethodName     = "/orchestrator.OrchestratorService/getFilterStats"
+	OrchestratorService_SetShadowRules_FullMethodName     = "/orchestrator.OrchestratorService/setShadowRules"
+	OrchestratorService_GetShadowReport_FullMethodName    = "/orchestrator.OrchestratorService/getShadowReport"
+	OrchestratorService_PromoteShadowRules_FullMethodName = "/orchestrator.OrchestratorService/promoteShadowRules"
+	OrchestratorService_DropShadowRules_FullMethodName    = "/orchestrator.OrchestratorService/dropShadowRules"
+	OrchestratorService_GetDSPRules_FullMethodName        = "/orchestrator.OrchestratorService/getDSPRules"
+	OrchestratorService_GetSPPRules_FullMethodName        = "/orchestrator.OrchestratorService/getSPPRules"
+	OrchestratorService_UpdateDSPRules_FullMethodName     = "/orchestrator.OrchestratorService/updateDSPRules"
+	OrchestratorService_UpdateSPPRules_FullMethodName     = "/orchestrator.OrchestratorService/updateSPPRules"
+	OrchestratorService_ListRuleVersions_FullMethodName   = "/orchestrator.OrchestratorService/listRuleVersions"
+	OrchestratorService_DiffRuleVersions_FullMethodName   = "/orchestrator.OrchestratorService/diffRuleVersions"
+	OrchestratorService_RollbackRules_FullMethodName      = "/orchestrator.OrchestratorService/rollbackRules"
+	OrchestratorService_GetRuleSyncStatus_FullMethodName  = "/orchestrator.OrchestratorService/getRuleSyncStatus"
+	OrchestratorService_GetBlocklists_FullMethodName      = "/orchestrator.OrchestratorService/getBlocklists"
+	OrchestratorService_ReloadBlocklists_FullMethodName   = "/orchestrator.OrchestratorService/reloadBlocklists"
 )
 
 // OrchestratorServiceClient is the client API for OrchestratorService service.
@@ -29,6 +49,24 @@ const (
 type OrchestratorServiceClient interface {
 	GetWinnerBid_V2_4(ctx context.Context, in *OrchestratorRequest_V2_4, opts ...grpc.CallOption) (*OrchestratorResponse_V2_4, error)
 	GetWinnerBid_V2_5(ctx context.Context, in *OrchestratorRequest_V2_5, opts ...grpc.CallOption) (*OrchestratorResponse_V2_5, error)
+	ReportBilling(ctx context.Context, in *bidEngine.BillingEvent, opts ...grpc.CallOption) (*bidEngine.BillingEventAck, error)
+	ExplainFilter_V2_4(ctx context.Context, in *dspRouter.ExplainFilterRequest_V2_4, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	ExplainFilter_V2_5(ctx context.Context, in *dspRouter.ExplainFilterRequest_V2_5, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	GetFilterStats(ctx context.Context, in *dspRouter.FilterStatsRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	SetShadowRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	GetShadowReport(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	PromoteShadowRules(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	DropShadowRules(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	GetDSPRules(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	GetSPPRules(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	UpdateDSPRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	UpdateSPPRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	ListRuleVersions(ctx context.Context, in *dspRouter.RuleVersionsRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	DiffRuleVersions(ctx context.Context, in *dspRouter.RuleVersionsDiffRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	RollbackRules(ctx context.Context, in *dspRouter.RollbackRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error)
+	GetRuleSyncStatus(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	GetBlocklists(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
+	ReloadBlocklists(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error)
 }
 
 type orchestratorServiceClient struct {
@@ -59,12 +97,210 @@ func (c *orchestratorServiceClient) GetWinnerBid_V2_5(ctx context.Context, in *O
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) ReportBilling(ctx context.Context, in *bidEngine.BillingEvent, opts ...grpc.CallOption) (*bidEngine.BillingEventAck, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(bidEngine.BillingEventAck)
+	err := c.cc.Invoke(ctx, OrchestratorService_ReportBilling_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ExplainFilter_V2_4(ctx context.Context, in *dspRouter.ExplainFilterRequest_V2_4, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ExplainFilter_V2_4_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ExplainFilter_V2_5(ctx context.Context, in *dspRouter.ExplainFilterRequest_V2_5, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ExplainFilter_V2_5_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetFilterStats(ctx context.Context, in *dspRouter.FilterStatsRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetFilterStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) SetShadowRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_SetShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetShadowReport(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetShadowReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) PromoteShadowRules(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_PromoteShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DropShadowRules(ctx context.Context, in *dspRouter.ShadowRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_DropShadowRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetDSPRules(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetDSPRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetSPPRules(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetSPPRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) UpdateDSPRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_UpdateDSPRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) UpdateSPPRules(ctx context.Context, in *dspRouter.JsonRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_UpdateSPPRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListRuleVersions(ctx context.Context, in *dspRouter.RuleVersionsRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DiffRuleVersions(ctx context.Context, in *dspRouter.RuleVersionsDiffRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_DiffRuleVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) RollbackRules(ctx context.Context, in *dspRouter.RollbackRulesRequest, opts ...grpc.CallOption) (*dspRouter.UpdateRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.UpdateRulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_RollbackRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetRuleSyncStatus(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetRuleSyncStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetBlocklists(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetBlocklists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ReloadBlocklists(ctx context.Context, in *dspRouter.GetRulesRequest, opts ...grpc.CallOption) (*dspRouter.JsonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(dspRouter.JsonResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ReloadBlocklists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrchestratorServiceServer is the server API for OrchestratorService service.
 // All implementations must embed UnimplementedOrchestratorServiceServer
 // for forward compatibility.
 type OrchestratorServiceServer interface {
 	GetWinnerBid_V2_4(context.Context, *OrchestratorRequest_V2_4) (*OrchestratorResponse_V2_4, error)
 	GetWinnerBid_V2_5(context.Context, *OrchestratorRequest_V2_5) (*OrchestratorResponse_V2_5, error)
+	ReportBilling(context.Context, *bidEngine.BillingEvent) (*bidEngine.BillingEventAck, error)
+	ExplainFilter_V2_4(context.Context, *dspRouter.ExplainFilterRequest_V2_4) (*dspRouter.JsonResponse, error)
+	ExplainFilter_V2_5(context.Context, *dspRouter.ExplainFilterRequest_V2_5) (*dspRouter.JsonResponse, error)
+	GetFilterStats(context.Context, *dspRouter.FilterStatsRequest) (*dspRouter.JsonResponse, error)
+	SetShadowRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error)
+	GetShadowReport(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.JsonResponse, error)
+	PromoteShadowRules(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.UpdateRulesResponse, error)
+	DropShadowRules(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.UpdateRulesResponse, error)
+	GetDSPRules(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error)
+	GetSPPRules(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error)
+	UpdateDSPRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error)
+	UpdateSPPRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error)
+	ListRuleVersions(context.Context, *dspRouter.RuleVersionsRequest) (*dspRouter.JsonResponse, error)
+	DiffRuleVersions(context.Context, *dspRouter.RuleVersionsDiffRequest) (*dspRouter.JsonResponse, error)
+	RollbackRules(context.Context, *dspRouter.RollbackRulesRequest) (*dspRouter.UpdateRulesResponse, error)
+	GetRuleSyncStatus(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error)
+	GetBlocklists(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error)
+	ReloadBlocklists(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error)
 	mustEmbedUnimplementedOrchestratorServiceServer()
 }
 
@@ -81,6 +317,60 @@ func (UnimplementedOrchestratorServiceServer) GetWinnerBid_V2_4(context.Context,
 func (UnimplementedOrchestratorServiceServer) GetWinnerBid_V2_5(context.Context, *OrchestratorRequest_V2_5) (*OrchestratorResponse_V2_5, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetWinnerBid_V2_5 not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) ReportBilling(context.Context, *bidEngine.BillingEvent) (*bidEngine.BillingEventAck, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportBilling not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ExplainFilter_V2_4(context.Context, *dspRouter.ExplainFilterRequest_V2_4) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainFilter_V2_4 not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ExplainFilter_V2_5(context.Context, *dspRouter.ExplainFilterRequest_V2_5) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainFilter_V2_5 not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetFilterStats(context.Context, *dspRouter.FilterStatsRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFilterStats not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) SetShadowRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetShadowRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetShadowReport(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShadowReport not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) PromoteShadowRules(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteShadowRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DropShadowRules(context.Context, *dspRouter.ShadowRulesRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropShadowRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetDSPRules(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDSPRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetSPPRules(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSPPRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) UpdateDSPRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDSPRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) UpdateSPPRules(context.Context, *dspRouter.JsonRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSPPRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListRuleVersions(context.Context, *dspRouter.RuleVersionsRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRuleVersions not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DiffRuleVersions(context.Context, *dspRouter.RuleVersionsDiffRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffRuleVersions not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) RollbackRules(context.Context, *dspRouter.RollbackRulesRequest) (*dspRouter.UpdateRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackRules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetRuleSyncStatus(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRuleSyncStatus not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetBlocklists(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocklists not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ReloadBlocklists(context.Context, *dspRouter.GetRulesRequest) (*dspRouter.JsonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadBlocklists not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) mustEmbedUnimplementedOrchestratorServiceServer() {}
 func (UnimplementedOrchestratorServiceServer) testEmbeddedByValue()                             {}
 
@@ -138,6 +428,330 @@ func _OrchestratorService_GetWinnerBid_V2_5_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_ReportBilling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bidEngine.BillingEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ReportBilling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ReportBilling_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ReportBilling(ctx, req.(*bidEngine.BillingEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ExplainFilter_V2_4_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.ExplainFilterRequest_V2_4)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ExplainFilter_V2_4(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ExplainFilter_V2_4_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ExplainFilter_V2_4(ctx, req.(*dspRouter.ExplainFilterRequest_V2_4))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ExplainFilter_V2_5_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.ExplainFilterRequest_V2_5)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ExplainFilter_V2_5(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ExplainFilter_V2_5_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ExplainFilter_V2_5(ctx, req.(*dspRouter.ExplainFilterRequest_V2_5))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetFilterStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.FilterStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetFilterStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetFilterStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetFilterStats(ctx, req.(*dspRouter.FilterStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_SetShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.JsonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).SetShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_SetShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).SetShadowRules(ctx, req.(*dspRouter.JsonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetShadowReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetShadowReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetShadowReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetShadowReport(ctx, req.(*dspRouter.ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_PromoteShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).PromoteShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_PromoteShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).PromoteShadowRules(ctx, req.(*dspRouter.ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DropShadowRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.ShadowRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DropShadowRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DropShadowRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DropShadowRules(ctx, req.(*dspRouter.ShadowRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetDSPRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetDSPRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetDSPRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetDSPRules(ctx, req.(*dspRouter.GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetSPPRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetSPPRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetSPPRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetSPPRules(ctx, req.(*dspRouter.GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_UpdateDSPRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.JsonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).UpdateDSPRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_UpdateDSPRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).UpdateDSPRules(ctx, req.(*dspRouter.JsonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_UpdateSPPRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.JsonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).UpdateSPPRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_UpdateSPPRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).UpdateSPPRules(ctx, req.(*dspRouter.JsonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.RuleVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListRuleVersions(ctx, req.(*dspRouter.RuleVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DiffRuleVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.RuleVersionsDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DiffRuleVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DiffRuleVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DiffRuleVersions(ctx, req.(*dspRouter.RuleVersionsDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_RollbackRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.RollbackRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).RollbackRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_RollbackRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).RollbackRules(ctx, req.(*dspRouter.RollbackRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetRuleSyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetRuleSyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetRuleSyncStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetRuleSyncStatus(ctx, req.(*dspRouter.GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetBlocklists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetBlocklists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetBlocklists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetBlocklists(ctx, req.(*dspRouter.GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ReloadBlocklists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(dspRouter.GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ReloadBlocklists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ReloadBlocklists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ReloadBlocklists(ctx, req.(*dspRouter.GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrchestratorService_ServiceDesc is the grpc.ServiceDesc for OrchestratorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +767,78 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getWinnerBid_V2_5",
 			Handler:    _OrchestratorService_GetWinnerBid_V2_5_Handler,
 		},
+		{
+			MethodName: "reportBilling",
+			Handler:    _OrchestratorService_ReportBilling_Handler,
+		},
+		{
+			MethodName: "explainFilter_V2_4",
+			Handler:    _OrchestratorService_ExplainFilter_V2_4_Handler,
+		},
+		{
+			MethodName: "explainFilter_V2_5",
+			Handler:    _OrchestratorService_ExplainFilter_V2_5_Handler,
+		},
+		{
+			MethodName: "getFilterStats",
+			Handler:    _OrchestratorService_GetFilterStats_Handler,
+		},
+		{
+			MethodName: "setShadowRules",
+			Handler:    _OrchestratorService_SetShadowRules_Handler,
+		},
+		{
+			MethodName: "getShadowReport",
+			Handler:    _OrchestratorService_GetShadowReport_Handler,
+		},
+		{
+			MethodName: "promoteShadowRules",
+			Handler:    _OrchestratorService_PromoteShadowRules_Handler,
+		},
+		{
+			MethodName: "dropShadowRules",
+			Handler:    _OrchestratorService_DropShadowRules_Handler,
+		},
+		{
+			MethodName: "getDSPRules",
+			Handler:    _OrchestratorService_GetDSPRules_Handler,
+		},
+		{
+			MethodName: "getSPPRules",
+			Handler:    _OrchestratorService_GetSPPRules_Handler,
+		},
+		{
+			MethodName: "updateDSPRules",
+			Handler:    _OrchestratorService_UpdateDSPRules_Handler,
+		},
+		{
+			MethodName: "updateSPPRules",
+			Handler:    _OrchestratorService_UpdateSPPRules_Handler,
+		},
+		{
+			MethodName: "listRuleVersions",
+			Handler:    _OrchestratorService_ListRuleVersions_Handler,
+		},
+		{
+			MethodName: "diffRuleVersions",
+			Handler:    _OrchestratorService_DiffRuleVersions_Handler,
+		},
+		{
+			MethodName: "rollbackRules",
+			Handler:    _OrchestratorService_RollbackRules_Handler,
+		},
+		{
+			MethodName: "getRuleSyncStatus",
+			Handler:    _OrchestratorService_GetRuleSyncStatus_Handler,
+		},
+		{
+			MethodName: "getBlocklists",
+			Handler:    _OrchestratorService_GetBlocklists_Handler,
+		},
+		{
+			MethodName: "reloadBlocklists",
+			Handler:    _OrchestratorService_ReloadBlocklists_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services/orchestrator.proto",
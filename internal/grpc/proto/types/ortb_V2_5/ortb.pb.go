@@ -22,11 +22,19 @@ const (
 )
 
 type BidRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
-	At            *int32                 `protobuf:"varint,2,opt,name=at,proto3,oneof" json:"at,omitempty"`
-	Imp           []*Imp                 `protobuf:"bytes,3,rep,name=imp,proto3" json:"imp,omitempty"`
-	Device        *Device                `protobuf:"bytes,4,opt,name=device,proto3,oneof" json:"device,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	At     *int32                 `protobuf:"varint,2,opt,name=at,proto3,oneof" json:"at,omitempty"`
+	Imp    []*Imp                 `protobuf:"bytes,3,rep,name=imp,proto3" json:"imp,omitempty"`
+	Device *Device                `protobuf:"bytes,4,opt,name=device,proto3,oneof" json:"device,omitempty"`
+	Cur    []string               `protobuf:"bytes,5,rep,name=cur,proto3" json:"cur,omitempty"`
+	Site   *Site                  `protobuf:"bytes,6,opt,name=site,proto3,oneof" json:"site,omitempty"`
+	App    *App                   `protobuf:"bytes,7,opt,name=app,proto3,oneof" json:"app,omitempty"`
+	User   *User                  `protobuf:"bytes,8,opt,name=user,proto3,oneof" json:"user,omitempty"`
+	// Запрещённые категории IAB, домены рекламодателей и приложения
+	Bcat          []string `protobuf:"bytes,9,rep,name=bcat,proto3" json:"bcat,omitempty"`
+	Badv          []string `protobuf:"bytes,10,rep,name=badv,proto3" json:"badv,omitempty"`
+	Bapp          []string `protobuf:"bytes,11,rep,name=bapp,proto3" json:"bapp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,12 +97,63 @@ func (x *BidRequest) GetDevice() *Device {
 	return nil
 }
 
+func (x *BidRequest) GetCur() []string {
+	if x != nil {
+		return x.Cur
+	}
+	return nil
+}
+
+func (x *BidRequest) GetSite() *Site {
+	if x != nil {
+		return x.Site
+	}
+	return nil
+}
+
+func (x *BidRequest) GetApp() *App {
+	if x != nil {
+		return x.App
+	}
+	return nil
+}
+
+func (x *BidRequest) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *BidRequest) GetBcat() []string {
+	if x != nil {
+		return x.Bcat
+	}
+	return nil
+}
+
+func (x *BidRequest) GetBadv() []string {
+	if x != nil {
+		return x.Badv
+	}
+	return nil
+}
+
+func (x *BidRequest) GetBapp() []string {
+	if x != nil {
+		return x.Bapp
+	}
+	return nil
+}
+
 type Imp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
 	BidFloor      *float32               `protobuf:"fixed32,2,opt,name=bidFloor,proto3,oneof" json:"bidFloor,omitempty"`
 	Banner        *Banner                `protobuf:"bytes,3,opt,name=banner,proto3,oneof" json:"banner,omitempty"`
 	Native        *Native                `protobuf:"bytes,4,opt,name=native,proto3,oneof" json:"native,omitempty"`
+	BidFloorCur   *string                `protobuf:"bytes,5,opt,name=bidFloorCur,proto3,oneof" json:"bidFloorCur,omitempty"`
+	Pmp           *Pmp                   `protobuf:"bytes,6,opt,name=pmp,proto3,oneof" json:"pmp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -157,6 +216,151 @@ func (x *Imp) GetNative() *Native {
 	return nil
 }
 
+func (x *Imp) GetBidFloorCur() string {
+	if x != nil && x.BidFloorCur != nil {
+		return *x.BidFloorCur
+	}
+	return ""
+}
+
+func (x *Imp) GetPmp() *Pmp {
+	if x != nil {
+		return x.Pmp
+	}
+	return nil
+}
+
+type Pmp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 1 - к импрессии допускаются только ставки по сделкам из deals
+	PrivateAuction *int32  `protobuf:"varint,1,opt,name=private_auction,json=privateAuction,proto3,oneof" json:"private_auction,omitempty"`
+	Deals          []*Deal `protobuf:"bytes,2,rep,name=deals,proto3" json:"deals,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Pmp) Reset() {
+	*x = Pmp{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pmp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pmp) ProtoMessage() {}
+
+func (x *Pmp) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pmp.ProtoReflect.Descriptor instead.
+func (*Pmp) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pmp) GetPrivateAuction() int32 {
+	if x != nil && x.PrivateAuction != nil {
+		return *x.PrivateAuction
+	}
+	return 0
+}
+
+func (x *Pmp) GetDeals() []*Deal {
+	if x != nil {
+		return x.Deals
+	}
+	return nil
+}
+
+type Deal struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	Bidfloor    *float32               `protobuf:"fixed32,2,opt,name=bidfloor,proto3,oneof" json:"bidfloor,omitempty"`
+	Bidfloorcur *string                `protobuf:"bytes,3,opt,name=bidfloorcur,proto3,oneof" json:"bidfloorcur,omitempty"`
+	// 1 - первая цена, 2 - вторая цена, 3 - фиксированная цена сделки
+	At *int32 `protobuf:"varint,4,opt,name=at,proto3,oneof" json:"at,omitempty"`
+	// Места покупателей (DSP), которым разрешена сделка; пусто - всем
+	Wseat         []string `protobuf:"bytes,5,rep,name=wseat,proto3" json:"wseat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Deal) Reset() {
+	*x = Deal{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deal) ProtoMessage() {}
+
+func (x *Deal) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deal.ProtoReflect.Descriptor instead.
+func (*Deal) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Deal) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *Deal) GetBidfloor() float32 {
+	if x != nil && x.Bidfloor != nil {
+		return *x.Bidfloor
+	}
+	return 0
+}
+
+func (x *Deal) GetBidfloorcur() string {
+	if x != nil && x.Bidfloorcur != nil {
+		return *x.Bidfloorcur
+	}
+	return ""
+}
+
+func (x *Deal) GetAt() int32 {
+	if x != nil && x.At != nil {
+		return *x.At
+	}
+	return 0
+}
+
+func (x *Deal) GetWseat() []string {
+	if x != nil {
+		return x.Wseat
+	}
+	return nil
+}
+
 type Native struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Request       *string                `protobuf:"bytes,1,opt,name=request,proto3,oneof" json:"request,omitempty"`
@@ -166,7 +370,7 @@ type Native struct {
 
 func (x *Native) Reset() {
 	*x = Native{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[2]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -178,7 +382,7 @@ func (x *Native) String() string {
 func (*Native) ProtoMessage() {}
 
 func (x *Native) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[2]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -191,7 +395,7 @@ func (x *Native) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Native.ProtoReflect.Descriptor instead.
 func (*Native) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{2}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Native) GetRequest() string {
@@ -211,7 +415,7 @@ type Banner struct {
 
 func (x *Banner) Reset() {
 	*x = Banner{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[3]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -223,7 +427,7 @@ func (x *Banner) String() string {
 func (*Banner) ProtoMessage() {}
 
 func (x *Banner) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[3]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -236,7 +440,7 @@ func (x *Banner) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Banner.ProtoReflect.Descriptor instead.
 func (*Banner) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{3}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Banner) GetW() int32 {
@@ -253,17 +457,233 @@ func (x *Banner) GetH() int32 {
 	return 0
 }
 
-type Device struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ip            *string                `protobuf:"bytes,1,opt,name=ip,proto3,oneof" json:"ip,omitempty"`
-	Geo           *Geo                   `protobuf:"bytes,2,opt,name=geo,proto3,oneof" json:"geo,omitempty"`
+type Site struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	// Категории IAB контента
+	Cat           []string `protobuf:"bytes,2,rep,name=cat,proto3" json:"cat,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *Site) Reset() {
+	*x = Site{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Site) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Site) ProtoMessage() {}
+
+func (x *Site) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Site.ProtoReflect.Descriptor instead.
+func (*Site) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Site) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *Site) GetCat() []string {
+	if x != nil {
+		return x.Cat
+	}
+	return nil
+}
+
+type App struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	// Категории IAB контента
+	Cat           []string `protobuf:"bytes,2,rep,name=cat,proto3" json:"cat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *App) Reset() {
+	*x = App{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *App) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*App) ProtoMessage() {}
+
+func (x *App) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use App.ProtoReflect.Descriptor instead.
+func (*App) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *App) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *App) GetCat() []string {
+	if x != nil {
+		return x.Cat
+	}
+	return nil
+}
+
+type User struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	Ext   *UserExt               `protobuf:"bytes,2,opt,name=ext,proto3,oneof" json:"ext,omitempty"`
+	// uid пользователя в DSP; от SSP приходит uid биржи, роутер заменяет его uid DSP
+	Buyeruid      *string `protobuf:"bytes,3,opt,name=buyeruid,proto3,oneof" json:"buyeruid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *User) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *User) GetExt() *UserExt {
+	if x != nil {
+		return x.Ext
+	}
+	return nil
+}
+
+func (x *User) GetBuyeruid() string {
+	if x != nil && x.Buyeruid != nil {
+		return *x.Buyeruid
+	}
+	return ""
+}
+
+type UserExt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Сегменты аудитории пользователя
+	Segments      []string `protobuf:"bytes,1,rep,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserExt) Reset() {
+	*x = UserExt{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserExt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserExt) ProtoMessage() {}
+
+func (x *UserExt) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserExt.ProtoReflect.Descriptor instead.
+func (*UserExt) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UserExt) GetSegments() []string {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+type Device struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ip    *string                `protobuf:"bytes,1,opt,name=ip,proto3,oneof" json:"ip,omitempty"`
+	Geo   *Geo                   `protobuf:"bytes,2,opt,name=geo,proto3,oneof" json:"geo,omitempty"`
+	Ua    *string                `protobuf:"bytes,3,opt,name=ua,proto3,oneof" json:"ua,omitempty"`
+	// 0 - неизвестно, 1 - Ethernet, 2 - WiFi, 3 - мобильная сеть, 4-7 - 2G-5G
+	Connectiontype *int32     `protobuf:"varint,4,opt,name=connectiontype,proto3,oneof" json:"connectiontype,omitempty"`
+	Ext            *DeviceExt `protobuf:"bytes,5,opt,name=ext,proto3,oneof" json:"ext,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
 func (x *Device) Reset() {
 	*x = Device{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[4]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -275,7 +695,7 @@ func (x *Device) String() string {
 func (*Device) ProtoMessage() {}
 
 func (x *Device) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[4]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -288,7 +708,7 @@ func (x *Device) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Device.ProtoReflect.Descriptor instead.
 func (*Device) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{4}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Device) GetIp() string {
@@ -305,16 +725,105 @@ func (x *Device) GetGeo() *Geo {
 	return nil
 }
 
+func (x *Device) GetUa() string {
+	if x != nil && x.Ua != nil {
+		return *x.Ua
+	}
+	return ""
+}
+
+func (x *Device) GetConnectiontype() int32 {
+	if x != nil && x.Connectiontype != nil {
+		return *x.Connectiontype
+	}
+	return 0
+}
+
+func (x *Device) GetExt() *DeviceExt {
+	if x != nil {
+		return x.Ext
+	}
+	return nil
+}
+
+type DeviceExt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Автономная система IP устройства и её владелец
+	Asn           *int64  `protobuf:"varint,1,opt,name=asn,proto3,oneof" json:"asn,omitempty"`
+	Asorg         *string `protobuf:"bytes,2,opt,name=asorg,proto3,oneof" json:"asorg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceExt) Reset() {
+	*x = DeviceExt{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceExt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceExt) ProtoMessage() {}
+
+func (x *DeviceExt) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceExt.ProtoReflect.Descriptor instead.
+func (*DeviceExt) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeviceExt) GetAsn() int64 {
+	if x != nil && x.Asn != nil {
+		return *x.Asn
+	}
+	return 0
+}
+
+func (x *DeviceExt) GetAsorg() string {
+	if x != nil && x.Asorg != nil {
+		return *x.Asorg
+	}
+	return ""
+}
+
 type Geo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Country       *string                `protobuf:"bytes,1,opt,name=country,proto3,oneof" json:"country,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Country *string                `protobuf:"bytes,1,opt,name=country,proto3,oneof" json:"country,omitempty"`
+	// Смещение местного времени пользователя от UTC в минутах
+	Utcoffset *int32   `protobuf:"varint,2,opt,name=utcoffset,proto3,oneof" json:"utcoffset,omitempty"`
+	Lat       *float64 `protobuf:"fixed64,3,opt,name=lat,proto3,oneof" json:"lat,omitempty"`
+	Lon       *float64 `protobuf:"fixed64,4,opt,name=lon,proto3,oneof" json:"lon,omitempty"`
+	// Источник координат: 1 - GPS, 2 - IP, 3 - указан пользователем
+	Type *int32 `protobuf:"varint,5,opt,name=type,proto3,oneof" json:"type,omitempty"`
+	// Точность координат в метрах
+	Accuracy *int32 `protobuf:"varint,6,opt,name=accuracy,proto3,oneof" json:"accuracy,omitempty"`
+	// Регион по ISO-3166-2 без кода страны: CA для Калифорнии
+	Region *string `protobuf:"bytes,7,opt,name=region,proto3,oneof" json:"region,omitempty"`
+	// Код рынка Nielsen DMA, только США
+	Metro         *string `protobuf:"bytes,8,opt,name=metro,proto3,oneof" json:"metro,omitempty"`
+	City          *string `protobuf:"bytes,9,opt,name=city,proto3,oneof" json:"city,omitempty"`
+	Zip           *string `protobuf:"bytes,10,opt,name=zip,proto3,oneof" json:"zip,omitempty"`
+	Ext           *GeoExt `protobuf:"bytes,11,opt,name=ext,proto3,oneof" json:"ext,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Geo) Reset() {
 	*x = Geo{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[5]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -326,7 +835,7 @@ func (x *Geo) String() string {
 func (*Geo) ProtoMessage() {}
 
 func (x *Geo) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[5]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,7 +848,7 @@ func (x *Geo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Geo.ProtoReflect.Descriptor instead.
 func (*Geo) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{5}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Geo) GetCountry() string {
@@ -349,16 +858,135 @@ func (x *Geo) GetCountry() string {
 	return ""
 }
 
+func (x *Geo) GetUtcoffset() int32 {
+	if x != nil && x.Utcoffset != nil {
+		return *x.Utcoffset
+	}
+	return 0
+}
+
+func (x *Geo) GetLat() float64 {
+	if x != nil && x.Lat != nil {
+		return *x.Lat
+	}
+	return 0
+}
+
+func (x *Geo) GetLon() float64 {
+	if x != nil && x.Lon != nil {
+		return *x.Lon
+	}
+	return 0
+}
+
+func (x *Geo) GetType() int32 {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return 0
+}
+
+func (x *Geo) GetAccuracy() int32 {
+	if x != nil && x.Accuracy != nil {
+		return *x.Accuracy
+	}
+	return 0
+}
+
+func (x *Geo) GetRegion() string {
+	if x != nil && x.Region != nil {
+		return *x.Region
+	}
+	return ""
+}
+
+func (x *Geo) GetMetro() string {
+	if x != nil && x.Metro != nil {
+		return *x.Metro
+	}
+	return ""
+}
+
+func (x *Geo) GetCity() string {
+	if x != nil && x.City != nil {
+		return *x.City
+	}
+	return ""
+}
+
+func (x *Geo) GetZip() string {
+	if x != nil && x.Zip != nil {
+		return *x.Zip
+	}
+	return ""
+}
+
+func (x *Geo) GetExt() *GeoExt {
+	if x != nil {
+		return x.Ext
+	}
+	return nil
+}
+
+type GeoExt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Часовой пояс IANA: Europe/Moscow
+	Timezone      *string `protobuf:"bytes,1,opt,name=timezone,proto3,oneof" json:"timezone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeoExt) Reset() {
+	*x = GeoExt{}
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoExt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoExt) ProtoMessage() {}
+
+func (x *GeoExt) ProtoReflect() protoreflect.Message {
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoExt.ProtoReflect.Descriptor instead.
+func (*GeoExt) Descriptor() ([]byte, []int) {
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GeoExt) GetTimezone() string {
+	if x != nil && x.Timezone != nil {
+		return *x.Timezone
+	}
+	return ""
+}
+
 type SeatBid struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Bid           []*Bid                 `protobuf:"bytes,1,rep,name=bid,proto3" json:"bid,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Bid   []*Bid                 `protobuf:"bytes,1,rep,name=bid,proto3" json:"bid,omitempty"`
+	// Идентификатор места покупателя в DSP
+	Seat *string `protobuf:"bytes,2,opt,name=seat,proto3,oneof" json:"seat,omitempty"`
+	// 1 - ставки места выигрывают только все вместе
+	Group         *int32 `protobuf:"varint,3,opt,name=group,proto3,oneof" json:"group,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SeatBid) Reset() {
 	*x = SeatBid{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[6]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +998,7 @@ func (x *SeatBid) String() string {
 func (*SeatBid) ProtoMessage() {}
 
 func (x *SeatBid) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[6]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +1011,7 @@ func (x *SeatBid) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SeatBid.ProtoReflect.Descriptor instead.
 func (*SeatBid) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{6}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *SeatBid) GetBid() []*Bid {
@@ -393,21 +1021,43 @@ func (x *SeatBid) GetBid() []*Bid {
 	return nil
 }
 
+func (x *SeatBid) GetSeat() string {
+	if x != nil && x.Seat != nil {
+		return *x.Seat
+	}
+	return ""
+}
+
+func (x *SeatBid) GetGroup() int32 {
+	if x != nil && x.Group != nil {
+		return *x.Group
+	}
+	return 0
+}
+
 type Bid struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
-	Impid         *string                `protobuf:"bytes,2,opt,name=impid,proto3,oneof" json:"impid,omitempty"`
-	Price         *float32               `protobuf:"fixed32,3,opt,name=price,proto3,oneof" json:"price,omitempty"`
-	Adid          *string                `protobuf:"bytes,4,opt,name=adid,proto3,oneof" json:"adid,omitempty"`
-	Nurl          *string                `protobuf:"bytes,5,opt,name=nurl,proto3,oneof" json:"nurl,omitempty"`
-	Burl          *string                `protobuf:"bytes,6,opt,name=burl,proto3,oneof" json:"burl,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	Impid  *string                `protobuf:"bytes,2,opt,name=impid,proto3,oneof" json:"impid,omitempty"`
+	Price  *float32               `protobuf:"fixed32,3,opt,name=price,proto3,oneof" json:"price,omitempty"`
+	Adid   *string                `protobuf:"bytes,4,opt,name=adid,proto3,oneof" json:"adid,omitempty"`
+	Nurl   *string                `protobuf:"bytes,5,opt,name=nurl,proto3,oneof" json:"nurl,omitempty"`
+	Burl   *string                `protobuf:"bytes,6,opt,name=burl,proto3,oneof" json:"burl,omitempty"`
+	Lurl   *string                `protobuf:"bytes,7,opt,name=lurl,proto3,oneof" json:"lurl,omitempty"`
+	Dealid *string                `protobuf:"bytes,8,opt,name=dealid,proto3,oneof" json:"dealid,omitempty"`
+	// Категории IAB креатива, домены рекламодателя и приложение рекламодателя
+	Cat     []string `protobuf:"bytes,9,rep,name=cat,proto3" json:"cat,omitempty"`
+	Adomain []string `protobuf:"bytes,10,rep,name=adomain,proto3" json:"adomain,omitempty"`
+	Bundle  *string  `protobuf:"bytes,11,opt,name=bundle,proto3,oneof" json:"bundle,omitempty"`
+	// Идентификатор креатива
+	Crid          *string `protobuf:"bytes,13,opt,name=crid,proto3,oneof" json:"crid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Bid) Reset() {
 	*x = Bid{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[7]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -419,7 +1069,7 @@ func (x *Bid) String() string {
 func (*Bid) ProtoMessage() {}
 
 func (x *Bid) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[7]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -432,7 +1082,7 @@ func (x *Bid) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Bid.ProtoReflect.Descriptor instead.
 func (*Bid) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{7}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Bid) GetId() string {
@@ -477,17 +1127,60 @@ func (x *Bid) GetBurl() string {
 	return ""
 }
 
+func (x *Bid) GetLurl() string {
+	if x != nil && x.Lurl != nil {
+		return *x.Lurl
+	}
+	return ""
+}
+
+func (x *Bid) GetDealid() string {
+	if x != nil && x.Dealid != nil {
+		return *x.Dealid
+	}
+	return ""
+}
+
+func (x *Bid) GetCat() []string {
+	if x != nil {
+		return x.Cat
+	}
+	return nil
+}
+
+func (x *Bid) GetAdomain() []string {
+	if x != nil {
+		return x.Adomain
+	}
+	return nil
+}
+
+func (x *Bid) GetBundle() string {
+	if x != nil && x.Bundle != nil {
+		return *x.Bundle
+	}
+	return ""
+}
+
+func (x *Bid) GetCrid() string {
+	if x != nil && x.Crid != nil {
+		return *x.Crid
+	}
+	return ""
+}
+
 type BidResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            *string                `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
-	Seatbid       *SeatBid               `protobuf:"bytes,2,opt,name=seatbid,proto3,oneof" json:"seatbid,omitempty"`
+	Seatbid       []*SeatBid             `protobuf:"bytes,2,rep,name=seatbid,proto3" json:"seatbid,omitempty"`
+	Cur           *string                `protobuf:"bytes,3,opt,name=cur,proto3,oneof" json:"cur,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BidResponse) Reset() {
 	*x = BidResponse{}
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[8]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -499,7 +1192,7 @@ func (x *BidResponse) String() string {
 func (*BidResponse) ProtoMessage() {}
 
 func (x *BidResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[8]
+	mi := &file_types_ortb_V2_5_ortb_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -512,7 +1205,7 @@ func (x *BidResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BidResponse.ProtoReflect.Descriptor instead.
 func (*BidResponse) Descriptor() ([]byte, []int) {
-	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{8}
+	return file_types_ortb_V2_5_ortb_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BidResponse) GetId() string {
@@ -522,36 +1215,72 @@ func (x *BidResponse) GetId() string {
 	return ""
 }
 
-func (x *BidResponse) GetSeatbid() *SeatBid {
+func (x *BidResponse) GetSeatbid() []*SeatBid {
 	if x != nil {
 		return x.Seatbid
 	}
 	return nil
 }
 
+func (x *BidResponse) GetCur() string {
+	if x != nil && x.Cur != nil {
+		return *x.Cur
+	}
+	return ""
+}
+
 var File_types_ortb_V2_5_ortb_proto protoreflect.FileDescriptor
 
 const file_types_ortb_V2_5_ortb_proto_rawDesc = "" +
 	"\n" +
-	"\x1atypes/ortb_V2_5/ortb.proto\x12\tortb_V2_5\"\xa1\x01\n" +
+	"\x1atypes/ortb_V2_5/ortb.proto\x12\tortb_V2_5\"\x84\x03\n" +
 	"\n" +
 	"BidRequest\x12\x13\n" +
 	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x13\n" +
 	"\x02at\x18\x02 \x01(\x05H\x01R\x02at\x88\x01\x01\x12 \n" +
 	"\x03imp\x18\x03 \x03(\v2\x0e.ortb_V2_5.ImpR\x03imp\x12.\n" +
-	"\x06device\x18\x04 \x01(\v2\x11.ortb_V2_5.DeviceH\x02R\x06device\x88\x01\x01B\x05\n" +
+	"\x06device\x18\x04 \x01(\v2\x11.ortb_V2_5.DeviceH\x02R\x06device\x88\x01\x01\x12\x10\n" +
+	"\x03cur\x18\x05 \x03(\tR\x03cur\x12(\n" +
+	"\x04site\x18\x06 \x01(\v2\x0f.ortb_V2_5.SiteH\x03R\x04site\x88\x01\x01\x12%\n" +
+	"\x03app\x18\a \x01(\v2\x0e.ortb_V2_5.AppH\x04R\x03app\x88\x01\x01\x12(\n" +
+	"\x04user\x18\b \x01(\v2\x0f.ortb_V2_5.UserH\x05R\x04user\x88\x01\x01\x12\x12\n" +
+	"\x04bcat\x18\t \x03(\tR\x04bcat\x12\x12\n" +
+	"\x04badv\x18\n" +
+	" \x03(\tR\x04badv\x12\x12\n" +
+	"\x04bapp\x18\v \x03(\tR\x04bappB\x05\n" +
 	"\x03_idB\x05\n" +
 	"\x03_atB\t\n" +
-	"\a_device\"\xc5\x01\n" +
+	"\a_deviceB\a\n" +
+	"\x05_siteB\x06\n" +
+	"\x04_appB\a\n" +
+	"\x05_user\"\xab\x02\n" +
 	"\x03Imp\x12\x13\n" +
 	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x1f\n" +
 	"\bbidFloor\x18\x02 \x01(\x02H\x01R\bbidFloor\x88\x01\x01\x12.\n" +
 	"\x06banner\x18\x03 \x01(\v2\x11.ortb_V2_5.BannerH\x02R\x06banner\x88\x01\x01\x12.\n" +
-	"\x06native\x18\x04 \x01(\v2\x11.ortb_V2_5.NativeH\x03R\x06native\x88\x01\x01B\x05\n" +
+	"\x06native\x18\x04 \x01(\v2\x11.ortb_V2_5.NativeH\x03R\x06native\x88\x01\x01\x12%\n" +
+	"\vbidFloorCur\x18\x05 \x01(\tH\x04R\vbidFloorCur\x88\x01\x01\x12%\n" +
+	"\x03pmp\x18\x06 \x01(\v2\x0e.ortb_V2_5.PmpH\x05R\x03pmp\x88\x01\x01B\x05\n" +
 	"\x03_idB\v\n" +
 	"\t_bidFloorB\t\n" +
 	"\a_bannerB\t\n" +
-	"\a_native\"3\n" +
+	"\a_nativeB\x0e\n" +
+	"\f_bidFloorCurB\x06\n" +
+	"\x04_pmp\"n\n" +
+	"\x03Pmp\x12,\n" +
+	"\x0fprivate_auction\x18\x01 \x01(\x05H\x00R\x0eprivateAuction\x88\x01\x01\x12%\n" +
+	"\x05deals\x18\x02 \x03(\v2\x0f.ortb_V2_5.DealR\x05dealsB\x12\n" +
+	"\x10_private_auction\"\xb9\x01\n" +
+	"\x04Deal\x12\x13\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x1f\n" +
+	"\bbidfloor\x18\x02 \x01(\x02H\x01R\bbidfloor\x88\x01\x01\x12%\n" +
+	"\vbidfloorcur\x18\x03 \x01(\tH\x02R\vbidfloorcur\x88\x01\x01\x12\x13\n" +
+	"\x02at\x18\x04 \x01(\x05H\x03R\x02at\x88\x01\x01\x12\x14\n" +
+	"\x05wseat\x18\x05 \x03(\tR\x05wseatB\x05\n" +
+	"\x03_idB\v\n" +
+	"\t_bidfloorB\x0e\n" +
+	"\f_bidfloorcurB\x05\n" +
+	"\x03_at\"3\n" +
 	"\x06Native\x12\x1d\n" +
 	"\arequest\x18\x01 \x01(\tH\x00R\arequest\x88\x01\x01B\n" +
 	"\n" +
@@ -560,37 +1289,106 @@ const file_types_ortb_V2_5_ortb_proto_rawDesc = "" +
 	"\x01w\x18\x01 \x01(\x05H\x00R\x01w\x88\x01\x01\x12\x11\n" +
 	"\x01h\x18\x02 \x01(\x05H\x01R\x01h\x88\x01\x01B\x04\n" +
 	"\x02_wB\x04\n" +
-	"\x02_h\"S\n" +
+	"\x02_h\"4\n" +
+	"\x04Site\x12\x13\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x10\n" +
+	"\x03cat\x18\x02 \x03(\tR\x03catB\x05\n" +
+	"\x03_id\"3\n" +
+	"\x03App\x12\x13\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x10\n" +
+	"\x03cat\x18\x02 \x03(\tR\x03catB\x05\n" +
+	"\x03_id\"\x83\x01\n" +
+	"\x04User\x12\x13\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12)\n" +
+	"\x03ext\x18\x02 \x01(\v2\x12.ortb_V2_5.UserExtH\x01R\x03ext\x88\x01\x01\x12\x1f\n" +
+	"\bbuyeruid\x18\x03 \x01(\tH\x02R\bbuyeruid\x88\x01\x01B\x05\n" +
+	"\x03_idB\x06\n" +
+	"\x04_extB\v\n" +
+	"\t_buyeruid\"%\n" +
+	"\aUserExt\x12\x1a\n" +
+	"\bsegments\x18\x01 \x03(\tR\bsegments\"\xe4\x01\n" +
 	"\x06Device\x12\x13\n" +
 	"\x02ip\x18\x01 \x01(\tH\x00R\x02ip\x88\x01\x01\x12%\n" +
-	"\x03geo\x18\x02 \x01(\v2\x0e.ortb_V2_5.GeoH\x01R\x03geo\x88\x01\x01B\x05\n" +
+	"\x03geo\x18\x02 \x01(\v2\x0e.ortb_V2_5.GeoH\x01R\x03geo\x88\x01\x01\x12\x13\n" +
+	"\x02ua\x18\x03 \x01(\tH\x02R\x02ua\x88\x01\x01\x12+\n" +
+	"\x0econnectiontype\x18\x04 \x01(\x05H\x03R\x0econnectiontype\x88\x01\x01\x12+\n" +
+	"\x03ext\x18\x05 \x01(\v2\x14.ortb_V2_5.DeviceExtH\x04R\x03ext\x88\x01\x01B\x05\n" +
 	"\x03_ipB\x06\n" +
-	"\x04_geo\"0\n" +
+	"\x04_geoB\x05\n" +
+	"\x03_uaB\x11\n" +
+	"\x0f_connectiontypeB\x06\n" +
+	"\x04_ext\"O\n" +
+	"\tDeviceExt\x12\x15\n" +
+	"\x03asn\x18\x01 \x01(\x03H\x00R\x03asn\x88\x01\x01\x12\x19\n" +
+	"\x05asorg\x18\x02 \x01(\tH\x01R\x05asorg\x88\x01\x01B\x06\n" +
+	"\x04_asnB\b\n" +
+	"\x06_asorg\"\xaf\x03\n" +
 	"\x03Geo\x12\x1d\n" +
-	"\acountry\x18\x01 \x01(\tH\x00R\acountry\x88\x01\x01B\n" +
+	"\acountry\x18\x01 \x01(\tH\x00R\acountry\x88\x01\x01\x12!\n" +
+	"\tutcoffset\x18\x02 \x01(\x05H\x01R\tutcoffset\x88\x01\x01\x12\x15\n" +
+	"\x03lat\x18\x03 \x01(\x01H\x02R\x03lat\x88\x01\x01\x12\x15\n" +
+	"\x03lon\x18\x04 \x01(\x01H\x03R\x03lon\x88\x01\x01\x12\x17\n" +
+	"\x04type\x18\x05 \x01(\x05H\x04R\x04type\x88\x01\x01\x12\x1f\n" +
+	"\baccuracy\x18\x06 \x01(\x05H\x05R\baccuracy\x88\x01\x01\x12\x1b\n" +
+	"\x06region\x18\a \x01(\tH\x06R\x06region\x88\x01\x01\x12\x19\n" +
+	"\x05metro\x18\b \x01(\tH\aR\x05metro\x88\x01\x01\x12\x17\n" +
+	"\x04city\x18\t \x01(\tH\bR\x04city\x88\x01\x01\x12\x15\n" +
+	"\x03zip\x18\n" +
+	" \x01(\tH\tR\x03zip\x88\x01\x01\x12(\n" +
+	"\x03ext\x18\v \x01(\v2\x11.ortb_V2_5.GeoExtH\n" +
+	"R\x03ext\x88\x01\x01B\n" +
+	"\n" +
+	"\b_countryB\f\n" +
 	"\n" +
-	"\b_country\"+\n" +
+	"_utcoffsetB\x06\n" +
+	"\x04_latB\x06\n" +
+	"\x04_lonB\a\n" +
+	"\x05_typeB\v\n" +
+	"\t_accuracyB\t\n" +
+	"\a_regionB\b\n" +
+	"\x06_metroB\a\n" +
+	"\x05_cityB\x06\n" +
+	"\x04_zipB\x06\n" +
+	"\x04_ext\"6\n" +
+	"\x06GeoExt\x12\x1f\n" +
+	"\btimezone\x18\x01 \x01(\tH\x00R\btimezone\x88\x01\x01B\v\n" +
+	"\t_timezone\"r\n" +
 	"\aSeatBid\x12 \n" +
-	"\x03bid\x18\x01 \x03(\v2\x0e.ortb_V2_5.BidR\x03bid\"\xd1\x01\n" +
+	"\x03bid\x18\x01 \x03(\v2\x0e.ortb_V2_5.BidR\x03bid\x12\x17\n" +
+	"\x04seat\x18\x02 \x01(\tH\x00R\x04seat\x88\x01\x01\x12\x19\n" +
+	"\x05group\x18\x03 \x01(\x05H\x01R\x05group\x88\x01\x01B\a\n" +
+	"\x05_seatB\b\n" +
+	"\x06_group\"\x91\x03\n" +
 	"\x03Bid\x12\x13\n" +
 	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12\x19\n" +
 	"\x05impid\x18\x02 \x01(\tH\x01R\x05impid\x88\x01\x01\x12\x19\n" +
 	"\x05price\x18\x03 \x01(\x02H\x02R\x05price\x88\x01\x01\x12\x17\n" +
 	"\x04adid\x18\x04 \x01(\tH\x03R\x04adid\x88\x01\x01\x12\x17\n" +
 	"\x04nurl\x18\x05 \x01(\tH\x04R\x04nurl\x88\x01\x01\x12\x17\n" +
-	"\x04burl\x18\x06 \x01(\tH\x05R\x04burl\x88\x01\x01B\x05\n" +
+	"\x04burl\x18\x06 \x01(\tH\x05R\x04burl\x88\x01\x01\x12\x17\n" +
+	"\x04lurl\x18\a \x01(\tH\x06R\x04lurl\x88\x01\x01\x12\x1b\n" +
+	"\x06dealid\x18\b \x01(\tH\aR\x06dealid\x88\x01\x01\x12\x10\n" +
+	"\x03cat\x18\t \x03(\tR\x03cat\x12\x18\n" +
+	"\aadomain\x18\n" +
+	" \x03(\tR\aadomain\x12\x1b\n" +
+	"\x06bundle\x18\v \x01(\tH\bR\x06bundle\x88\x01\x01\x12\x17\n" +
+	"\x04crid\x18\r \x01(\tH\tR\x04crid\x88\x01\x01B\x05\n" +
 	"\x03_idB\b\n" +
 	"\x06_impidB\b\n" +
 	"\x06_priceB\a\n" +
 	"\x05_adidB\a\n" +
 	"\x05_nurlB\a\n" +
-	"\x05_burl\"h\n" +
+	"\x05_burlB\a\n" +
+	"\x05_lurlB\t\n" +
+	"\a_dealidB\t\n" +
+	"\a_bundleB\a\n" +
+	"\x05_crid\"v\n" +
 	"\vBidResponse\x12\x13\n" +
-	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x121\n" +
-	"\aseatbid\x18\x02 \x01(\v2\x12.ortb_V2_5.SeatBidH\x01R\aseatbid\x88\x01\x01B\x05\n" +
-	"\x03_idB\n" +
-	"\n" +
-	"\b_seatbidBXZVgitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5;ortb_V2_5b\x06proto3"
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12,\n" +
+	"\aseatbid\x18\x02 \x03(\v2\x12.ortb_V2_5.SeatBidR\aseatbid\x12\x15\n" +
+	"\x03cur\x18\x03 \x01(\tH\x01R\x03cur\x88\x01\x01B\x05\n" +
+	"\x03_idB\x06\n" +
+	"\x04_curBXZVgitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5;ortb_V2_5b\x06proto3"
 
 var (
 	file_types_ortb_V2_5_ortb_proto_rawDescOnce sync.Once
@@ -604,31 +1402,47 @@ func file_types_ortb_V2_5_ortb_proto_rawDescGZIP() []byte {
 	return file_types_ortb_V2_5_ortb_proto_rawDescData
 }
 
-var file_types_ortb_V2_5_ortb_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_types_ortb_V2_5_ortb_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_types_ortb_V2_5_ortb_proto_goTypes = []any{
 	(*BidRequest)(nil),  // 0: ortb_V2_5.BidRequest
 	(*Imp)(nil),         // 1: ortb_V2_5.Imp
-	(*Native)(nil),      // 2: ortb_V2_5.Native
-	(*Banner)(nil),      // 3: ortb_V2_5.Banner
-	(*Device)(nil),      // 4: ortb_V2_5.Device
-	(*Geo)(nil),         // 5: ortb_V2_5.Geo
-	(*SeatBid)(nil),     // 6: ortb_V2_5.SeatBid
-	(*Bid)(nil),         // 7: ortb_V2_5.Bid
-	(*BidResponse)(nil), // 8: ortb_V2_5.BidResponse
+	(*Pmp)(nil),         // 2: ortb_V2_5.Pmp
+	(*Deal)(nil),        // 3: ortb_V2_5.Deal
+	(*Native)(nil),      // 4: ortb_V2_5.Native
+	(*Banner)(nil),      // 5: ortb_V2_5.Banner
+	(*Site)(nil),        // 6: ortb_V2_5.Site
+	(*App)(nil),         // 7: ortb_V2_5.App
+	(*User)(nil),        // 8: ortb_V2_5.User
+	(*UserExt)(nil),     // 9: ortb_V2_5.UserExt
+	(*Device)(nil),      // 10: ortb_V2_5.Device
+	(*DeviceExt)(nil),   // 11: ortb_V2_5.DeviceExt
+	(*Geo)(nil),         // 12: ortb_V2_5.Geo
+	(*GeoExt)(nil),      // 13: ortb_V2_5.GeoExt
+	(*SeatBid)(nil),     // 14: ortb_V2_5.SeatBid
+	(*Bid)(nil),         // 15: ortb_V2_5.Bid
+	(*BidResponse)(nil), // 16: ortb_V2_5.BidResponse
 }
 var file_types_ortb_V2_5_ortb_proto_depIdxs = []int32{
-	1, // 0: ortb_V2_5.BidRequest.imp:type_name -> ortb_V2_5.Imp
-	4, // 1: ortb_V2_5.BidRequest.device:type_name -> ortb_V2_5.Device
-	3, // 2: ortb_V2_5.Imp.banner:type_name -> ortb_V2_5.Banner
-	2, // 3: ortb_V2_5.Imp.native:type_name -> ortb_V2_5.Native
-	5, // 4: ortb_V2_5.Device.geo:type_name -> ortb_V2_5.Geo
-	7, // 5: ortb_V2_5.SeatBid.bid:type_name -> ortb_V2_5.Bid
-	6, // 6: ortb_V2_5.BidResponse.seatbid:type_name -> ortb_V2_5.SeatBid
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	1,  // 0: ortb_V2_5.BidRequest.imp:type_name -> ortb_V2_5.Imp
+	10, // 1: ortb_V2_5.BidRequest.device:type_name -> ortb_V2_5.Device
+	6,  // 2: ortb_V2_5.BidRequest.site:type_name -> ortb_V2_5.Site
+	7,  // 3: ortb_V2_5.BidRequest.app:type_name -> ortb_V2_5.App
+	8,  // 4: ortb_V2_5.BidRequest.user:type_name -> ortb_V2_5.User
+	5,  // 5: ortb_V2_5.Imp.banner:type_name -> ortb_V2_5.Banner
+	4,  // 6: ortb_V2_5.Imp.native:type_name -> ortb_V2_5.Native
+	2,  // 7: ortb_V2_5.Imp.pmp:type_name -> ortb_V2_5.Pmp
+	3,  // 8: ortb_V2_5.Pmp.deals:type_name -> ortb_V2_5.Deal
+	9,  // 9: ortb_V2_5.User.ext:type_name -> ortb_V2_5.UserExt
+	12, // 10: ortb_V2_5.Device.geo:type_name -> ortb_V2_5.Geo
+	11, // 11: ortb_V2_5.Device.ext:type_name -> ortb_V2_5.DeviceExt
+	13, // 12: ortb_V2_5.Geo.ext:type_name -> ortb_V2_5.GeoExt
+	15, // 13: ortb_V2_5.SeatBid.bid:type_name -> ortb_V2_5.Bid
+	14, // 14: ortb_V2_5.BidResponse.seatbid:type_name -> ortb_V2_5.SeatBid
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_types_ortb_V2_5_ortb_proto_init() }
@@ -642,15 +1456,23 @@ func file_types_ortb_V2_5_ortb_proto_init() {
 	file_types_ortb_V2_5_ortb_proto_msgTypes[3].OneofWrappers = []any{}
 	file_types_ortb_V2_5_ortb_proto_msgTypes[4].OneofWrappers = []any{}
 	file_types_ortb_V2_5_ortb_proto_msgTypes[5].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[6].OneofWrappers = []any{}
 	file_types_ortb_V2_5_ortb_proto_msgTypes[7].OneofWrappers = []any{}
 	file_types_ortb_V2_5_ortb_proto_msgTypes[8].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[10].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[11].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[12].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[13].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[14].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[15].OneofWrappers = []any{}
+	file_types_ortb_V2_5_ortb_proto_msgTypes[16].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_types_ortb_V2_5_ortb_proto_rawDesc), len(file_types_ortb_V2_5_ortb_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -10,11 +11,28 @@ const (
 	BURL = "burl"
 )
 
-func WrapURL(hostname, originalURL, globalId, isItNurlOrBurl string) string {
+const (
+	AUCTION_LOSS_MACRO  = "${AUCTION_LOSS}"
+	AUCTION_PRICE_MACRO = "${AUCTION_PRICE}"
+)
+
+func WrapURL(hostname, originalURL, globalId, impId, isItNurlOrBurl string) string {
 	if originalURL == "" {
 		return ""
 	}
 	encodedURL := url.QueryEscape(originalURL)
-	return fmt.Sprintf("https://%s/%s?id=%s&url=%s",
-		hostname, isItNurlOrBurl, globalId, encodedURL)
+	return fmt.Sprintf("https://%s/%s?id=%s&imp=%s&url=%s",
+		hostname, isItNurlOrBurl, globalId, url.QueryEscape(impId), encodedURL)
+}
+
+// FillLossURL подставляет код причины проигрыша и цену клиринга в lurl DSP.
+// Пустая clearingPrice означает, что цену раскрывать нельзя.
+func FillLossURL(lurl string, lossReason int32, clearingPrice string) string {
+	if lurl == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		AUCTION_LOSS_MACRO, fmt.Sprint(lossReason),
+		AUCTION_PRICE_MACRO, clearingPrice,
+	).Replace(lurl)
 }
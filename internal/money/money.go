@@ -0,0 +1,244 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+// Micros - денежная сумма в миллионных долях единицы валюты.
+// Вся арифметика аукциона ведётся в Micros, float32 из протокола
+// переводится сюда на входе и обратно только при формировании ответа.
+type Micros int64
+
+// Ratio - безразмерный множитель (доля маржи, курс валюты) с точностью до 1e-6
+type Ratio int64
+
+const (
+	MICROS_PER_UNIT = 1_000_000
+	// Множитель 1.0
+	RATIO_ONE Ratio = 1_000_000
+
+	// Число знаков после запятой
+	SCALE = 6
+)
+
+// Rounding - правило округления до целого числа micros.
+// Семантика как у java.math.RoundingMode: DOWN/UP - к нулю/от нуля.
+type Rounding int
+
+const (
+	// Банковское округление: половина к чётному
+	ROUND_HALF_EVEN Rounding = iota
+	// Половина от нуля - так округляются значения из протокола
+	ROUND_HALF_UP
+	// К нулю - так считается маржа, чтобы не недоплатить SSP
+	ROUND_DOWN
+	// От нуля - так переводятся флоры, чтобы не опустить их округлением
+	ROUND_UP
+)
+
+// FromFloat32 переводит цену из протокола по её кратчайшему десятичному представлению:
+// 1.1 превращается ровно в 1_100_000, а не в 1_100_000.0238...
+func FromFloat32(v float32) Micros {
+	var buf [64]byte
+	m, _ := parse(strconv.AppendFloat(buf[:0], float64(v), 'f', -1, 32))
+	return m
+}
+
+func FromFloat64(v float64) Micros {
+	var buf [64]byte
+	m, _ := parse(strconv.AppendFloat(buf[:0], v, 'f', -1, 64))
+	return m
+}
+
+// Parse разбирает десятичную строку, округляя знаки после шестого по ROUND_HALF_UP
+func Parse(s string) (Micros, error) {
+	m, ok := parse([]byte(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid money value %q", s)
+	}
+	return m, nil
+}
+
+func RatioFromFloat64(v float64) Ratio {
+	return Ratio(FromFloat64(v))
+}
+
+func (m Micros) Float32() float32 {
+	v, _ := strconv.ParseFloat(m.String(), 32)
+	return float32(v)
+}
+
+func (m Micros) Float64() float64 {
+	return float64(m) / MICROS_PER_UNIT
+}
+
+// String возвращает точное десятичное представление без лишних нулей
+func (m Micros) String() string {
+	var buf [32]byte
+	b := strconv.AppendUint(buf[:0], abs(int64(m)), 10)
+
+	for len(b) <= SCALE {
+		b = append([]byte{'0'}, b...)
+	}
+	intPart, fracPart := b[:len(b)-SCALE], b[len(b)-SCALE:]
+	for len(fracPart) > 0 && fracPart[len(fracPart)-1] == '0' {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+
+	s := string(intPart)
+	if len(fracPart) > 0 {
+		s += "." + string(fracPart)
+	}
+	if m < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func (r Ratio) Float64() float64 {
+	return float64(r) / float64(RATIO_ONE)
+}
+
+// MulRatio считает m * r
+func (m Micros) MulRatio(r Ratio, rounding Rounding) Micros {
+	return Micros(MulDiv(int64(m), int64(r), int64(RATIO_ONE), rounding))
+}
+
+// Convert переводит сумму между валютами с курсами fromRate и toRate
+// относительно общей базовой валюты: m * toRate / fromRate
+func (m Micros) Convert(fromRate, toRate Ratio, rounding Rounding) Micros {
+	return Micros(MulDiv(int64(m), int64(toRate), int64(fromRate), rounding))
+}
+
+// MulDiv точно считает a * b / c через 128-битное промежуточное значение.
+// При переполнении результат насыщается до границы int64.
+func MulDiv(a, b, c int64, rounding Rounding) int64 {
+	if c == 0 {
+		panic("money: division by zero")
+	}
+	negative := (a < 0) != (b < 0) != (c < 0)
+
+	ua, ub, uc := abs(a), abs(b), abs(c)
+	hi, lo := bits.Mul64(ua, ub)
+	if hi >= uc {
+		return saturate(negative)
+	}
+	q, rem := bits.Div64(hi, lo, uc)
+
+	if rem != 0 {
+		switch rounding {
+		case ROUND_UP:
+			q++
+		case ROUND_HALF_UP:
+			if rem >= uc-rem {
+				q++
+			}
+		case ROUND_HALF_EVEN:
+			if rem > uc-rem || (rem == uc-rem && q&1 == 1) {
+				q++
+			}
+		}
+	}
+
+	if negative {
+		if q > 1<<63 {
+			return saturate(true)
+		}
+		return int64(-q)
+	}
+	if q > math.MaxInt64 {
+		return saturate(false)
+	}
+	return int64(q)
+}
+
+func parse(b []byte) (Micros, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+
+	negative := false
+	switch b[0] {
+	case '-':
+		negative = true
+		b = b[1:]
+	case '+':
+		b = b[1:]
+	}
+
+	var (
+		value    uint64
+		scale    = -1
+		roundUp  bool
+		digits   int
+		overflow bool
+	)
+	for _, c := range b {
+		switch {
+		case c == '.' && scale < 0:
+			scale = 0
+			continue
+		case c < '0' || c > '9':
+			return 0, false
+		}
+		digits++
+
+		if scale >= SCALE {
+			// Первая отброшенная цифра решает округление
+			if scale == SCALE {
+				roundUp = c >= '5'
+			}
+			scale++
+			continue
+		}
+
+		if value > (math.MaxUint64-9)/10 {
+			overflow = true
+		}
+		value = value*10 + uint64(c-'0')
+		if scale >= 0 {
+			scale++
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+
+	if scale < 0 {
+		scale = 0
+	}
+	for ; scale < SCALE; scale++ {
+		if value > math.MaxUint64/10 {
+			overflow = true
+		}
+		value *= 10
+	}
+	if roundUp {
+		value++
+	}
+
+	if overflow || value > math.MaxInt64 {
+		return Micros(saturate(negative)), true
+	}
+	if negative {
+		return Micros(-int64(value)), true
+	}
+	return Micros(value), true
+}
+
+func abs(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+func saturate(negative bool) int64 {
+	if negative {
+		return math.MinInt64
+	}
+	return math.MaxInt64
+}
@@ -0,0 +1,143 @@
+package money
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Суммы до миллиарда единиц валюты покрывают любые реальные цены
+const maxTestMicros = 1_000_000_000 * MICROS_PER_UNIT
+
+func boundMicros(v int64) Micros {
+	return Micros(v % maxTestMicros)
+}
+
+func boundRatio(v int64) Ratio {
+	r := Ratio(v % (1000 * int64(RATIO_ONE)))
+	if r < 0 {
+		r = -r
+	}
+	return r + 1
+}
+
+// mulDivBig - эталонная реализация a * b / c на big.Rat
+func mulDivBig(a, b, c int64, rounding Rounding) int64 {
+	exact := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(a), big.NewInt(b)),
+		big.NewInt(c),
+	)
+	num, den := exact.Num(), exact.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return q.Int64()
+	}
+
+	away := false
+	twiceRem := new(big.Int).Abs(new(big.Int).Mul(rem, big.NewInt(2)))
+	half := twiceRem.Cmp(den)
+	switch rounding {
+	case ROUND_UP:
+		away = true
+	case ROUND_HALF_UP:
+		away = half >= 0
+	case ROUND_HALF_EVEN:
+		away = half > 0 || (half == 0 && q.Bit(0) == 1)
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(exact.Sign())))
+	}
+	return q.Int64()
+}
+
+func TestMulDivMatchesBigRat(t *testing.T) {
+	for _, rounding := range []Rounding{ROUND_HALF_EVEN, ROUND_HALF_UP, ROUND_DOWN, ROUND_UP} {
+		property := func(a, b int64, c int32) bool {
+			m, r := int64(boundMicros(a)), int64(boundRatio(b))
+			if c == 0 {
+				c = 1
+			}
+			return MulDiv(m, r, int64(c), rounding) == mulDivBig(m, r, int64(c), rounding)
+		}
+		assert.NoError(t, quick.Check(property, nil), "rounding %d", rounding)
+	}
+}
+
+func TestRoundingOrder(t *testing.T) {
+	property := func(a, b int64) bool {
+		m, r := boundMicros(a), boundRatio(b)
+		if m < 0 {
+			m = -m
+		}
+
+		down := m.MulRatio(r, ROUND_DOWN)
+		up := m.MulRatio(r, ROUND_UP)
+		halfEven := m.MulRatio(r, ROUND_HALF_EVEN)
+		halfUp := m.MulRatio(r, ROUND_HALF_UP)
+
+		return up-down <= 1 &&
+			down <= halfEven && halfEven <= up &&
+			down <= halfUp && halfUp <= up
+	}
+	assert.NoError(t, quick.Check(property, nil))
+}
+
+func TestStringParseRoundTrip(t *testing.T) {
+	property := func(v int64) bool {
+		m := Micros(v)
+		parsed, err := Parse(m.String())
+		return err == nil && parsed == m
+	}
+	assert.NoError(t, quick.Check(property, nil))
+}
+
+func TestFloat32RoundTrip(t *testing.T) {
+	// Цена из протокола, переведённая в micros и обратно, не меняется
+	property := func(v int32) bool {
+		price := float32(v%100_000_000) / 1000
+		return FromFloat32(price).Float32() == price
+	}
+	assert.NoError(t, quick.Check(property, nil))
+}
+
+func TestConvertRoundTripError(t *testing.T) {
+	// Перевод туда и обратно ошибается не больше чем на одно округление в каждой валюте
+	property := func(a, from, to int64) bool {
+		m, fromRate, toRate := boundMicros(a), boundRatio(from), boundRatio(to)
+		if m < 0 {
+			m = -m
+		}
+
+		back := m.Convert(fromRate, toRate, ROUND_HALF_EVEN).Convert(toRate, fromRate, ROUND_HALF_EVEN)
+		diff := math.Abs(float64(back - m))
+		tolerance := 1 + float64(fromRate)/float64(toRate)
+		return diff <= tolerance
+	}
+	assert.NoError(t, quick.Check(property, nil))
+}
+
+func TestFromFloat(t *testing.T) {
+	assert.Equal(t, Micros(1_100_000), FromFloat32(1.1))
+	assert.Equal(t, Micros(1_000_100_000), FromFloat32(1000.1))
+	assert.Equal(t, Micros(300_000), FromFloat64(0.1+0.2))
+	assert.Equal(t, Micros(1_234_568), FromFloat64(1.2345675))
+	assert.Equal(t, Micros(-500), FromFloat64(-0.0005))
+	assert.Equal(t, Micros(0), FromFloat64(math.NaN()))
+	assert.Equal(t, Micros(math.MaxInt64), FromFloat64(1e30))
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "1.5", Micros(1_500_000).String())
+	assert.Equal(t, "1", Micros(1_000_000).String())
+	assert.Equal(t, "0", Micros(0).String())
+	assert.Equal(t, "-0.0005", Micros(-500).String())
+}
+
+func TestMulDivSaturates(t *testing.T) {
+	assert.Equal(t, int64(math.MaxInt64), MulDiv(math.MaxInt64, 10, 1, ROUND_DOWN))
+	assert.Equal(t, int64(math.MinInt64), MulDiv(math.MinInt64, 10, 1, ROUND_DOWN))
+}
@@ -0,0 +1,283 @@
+package bidEngine
+
+import (
+	"context"
+	"sort"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// AuctionConfig - настройки аукциона, общие для всех версий ORTB
+type AuctionConfig struct {
+	Margins *MarginPolicy
+	// Валюта, в которой ранжируются ставки и применяется маржа
+	AuctionCurrency string
+	Rates           *currency.Rates
+	// nil, если сделки не настроены: ставки по сделкам отклоняются
+	Deals *deals.Registry
+	// nil, если шейдинг выключен
+	Shader *BidShader
+	// nil, если частотные ограничения не настроены
+	FrequencyCaps *FrequencyCapper
+}
+
+// AuctionEvents - побочные результаты аукциона, которые обрабатывает web слой
+type AuctionEvents struct {
+	LossNotices []LossNotice
+	Margins     []AppliedMargin
+	Deals       []deals.Report
+	// Выигрыши, по billing которых растут счётчики частотных ограничений
+	Impressions []CapImpression
+}
+
+// rankedBid - ставка DSP с ценой, приведённой к валюте аукциона
+type rankedBid[T any] struct {
+	bid   T
+	price money.Micros
+	cur   string
+	dsp   string
+	// Сделка ставки; nil - открытый аукцион
+	deal *deals.Deal
+	// Флор сделки или импрессии, который должна пройти ставка
+	floor money.Micros
+	// Место покупателя из seatbid.seat
+	seat string
+	// Номер seatbid с group=1 в аукционе или NO_GROUP
+	group int
+	// Счётчики частотных ограничений ставки
+	caps []capCounter
+}
+
+// Значение seatbid.group, при котором ставки места выигрывают только все вместе
+const SEATBID_GROUP = 1
+
+const NO_GROUP = -1
+
+// resolveGroups применяет семантику group=1. Ставки каждой импрессии должны быть
+// отсортированы по убыванию. groupSizes[i] - число ставок в месте i.
+// Место исключается из аукциона, если хотя бы одна его ставка проиграла ставке,
+// которая сама остаётся в итоге, или не дошла до ранжирования. Если такие места
+// кончились, а набор победителей ещё не согласован, исключается первое неудачное
+// место. Возвращает исключённые места.
+func resolveGroups[T any](impBids map[string][]rankedBid[T], groupSizes []int) map[int]bool {
+	excluded := make(map[int]bool)
+	if len(groupSizes) == 0 {
+		return excluded
+	}
+
+	wins := make([]int, len(groupSizes))
+	ranked := make([]int, len(groupSizes))
+	lost := make([]bool, len(groupSizes))
+	for {
+		clear(wins)
+		clear(ranked)
+		for _, bids := range impBids {
+			winner := winnerIndex(bids, excluded)
+			for i, bid := range bids {
+				if bid.group == NO_GROUP || excluded[bid.group] {
+					continue
+				}
+				ranked[bid.group]++
+				if i == winner {
+					wins[bid.group]++
+				}
+			}
+		}
+
+		failing := func(group int) bool {
+			return group != NO_GROUP && !excluded[group] && wins[group] < groupSizes[group]
+		}
+
+		// Проигрыш ставке неудачного места ещё может измениться, остальные - окончательны
+		clear(lost)
+		for group := range groupSizes {
+			lost[group] = failing(group) && ranked[group] < groupSizes[group]
+		}
+		for _, bids := range impBids {
+			winner := winnerIndex(bids, excluded)
+			if winner < 0 {
+				continue
+			}
+			for i, bid := range bids {
+				if i == winner || !failing(bid.group) {
+					continue
+				}
+				if bids[winner].group == bid.group || !failing(bids[winner].group) {
+					lost[bid.group] = true
+				}
+			}
+		}
+
+		first := NO_GROUP
+		changed := false
+		for group := range groupSizes {
+			if lost[group] {
+				excluded[group] = true
+				changed = true
+			}
+			if first == NO_GROUP && failing(group) {
+				first = group
+			}
+		}
+		if !changed {
+			if first == NO_GROUP {
+				break
+			}
+			excluded[first] = true
+		}
+	}
+
+	// Ставки исключённых мест уходят в конец, чтобы не выиграть
+	for impID, bids := range impBids {
+		sort.SliceStable(bids, func(i, j int) bool {
+			return !excluded[bids[i].group] && excluded[bids[j].group]
+		})
+		impBids[impID] = bids
+	}
+
+	return excluded
+}
+
+// winnerIndex - индекс первой ставки не из исключённого места или -1
+func winnerIndex[T any](bids []rankedBid[T], excluded map[int]bool) int {
+	for i, bid := range bids {
+		if !excluded[bid.group] {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectWinner возвращает индекс первой по рангу ставки, которая проходит маржу и флор,
+// и её маржу. Ставки выше неё получают BELOW_AUCTION_FLOOR. Если победителя нет,
+// остальные ставки - из исключённых по group=1 мест - получают OUTBID и индекс равен -1.
+// key - общие для импрессии признаки правила маржи, DSP и сделка берутся из ставки.
+func selectWinner[T interface{ GetLurl() string }](
+	margins *MarginPolicy,
+	key MarginKey,
+	bids []rankedBid[T],
+	excluded map[int]bool,
+	events *AuctionEvents,
+) (int, AppliedMargin) {
+	for i, candidate := range bids {
+		// Ставки исключённых мест стоят в конце
+		if excluded[candidate.group] {
+			for _, ranked := range bids[i:] {
+				events.LossNotices = appendLossNotice(events.LossNotices, ranked.bid.GetLurl(), LOSS_REASON_OUTBID, nil)
+			}
+			return -1, AppliedMargin{}
+		}
+
+		key.DSP = candidate.dsp
+		key.Deal = candidate.dealID()
+		applied, err := margins.Apply(key, candidate.price, candidate.floor)
+		if err == nil {
+			return i, applied
+		}
+		events.LossNotices = appendLossNotice(events.LossNotices, candidate.bid.GetLurl(), LOSS_REASON_BELOW_AUCTION_FLOOR, nil)
+	}
+	return -1, AppliedMargin{}
+}
+
+func (c *AuctionConfig) auctionCurrency() string {
+	return currency.Normalize(c.AuctionCurrency)
+}
+
+// responseCurrency выбирает первую из запрошенных SSP валют, для которой известен курс
+func (c *AuctionConfig) responseCurrency(requested []string) string {
+	if len(requested) == 0 {
+		return currency.DEFAULT_CURRENCY
+	}
+	for _, cur := range requested {
+		if c.Rates.Has(cur) {
+			return currency.Normalize(cur)
+		}
+	}
+	return currency.Normalize(requested[0])
+}
+
+// conversionLossReason выбирает причину проигрыша ставки, цена которой не перевелась
+// в валюту аукциона: неизвестная валюта ставки - ошибка DSP, иначе нет курса у биржи
+func (c *AuctionConfig) conversionLossReason(bidCur string) int32 {
+	if c.Rates.Has(bidCur) {
+		return LOSS_REASON_INTERNAL_ERROR
+	}
+	return LOSS_REASON_INVALID_BID_RESPONSE
+}
+
+func (c *AuctionConfig) convertOrNil(amount money.Micros, from, to string) *money.Micros {
+	converted, err := c.Rates.Convert(amount, from, to, money.ROUND_HALF_EVEN)
+	if err != nil {
+		return nil
+	}
+	return &converted
+}
+
+// shade применяет шейдинг к цене победителя в аукционах первой цены
+func (c *AuctionConfig) shade(
+	applied *AppliedMargin,
+	auctionType int32,
+	globalId string,
+	key ShadingKey,
+	bidFloor money.Micros,
+) {
+	if c.Shader == nil || auctionType != FIRST_PRICE {
+		return
+	}
+	applied.ShadingBucket = c.Shader.Bucket(globalId)
+	if applied.ShadingBucket == SHADING_BUCKET_SHADED {
+		c.Shader.Shade(applied, key, bidFloor)
+	}
+}
+
+// recordShading запоминает отправленную в SSP цену для обучения шейдинга
+func (c *AuctionConfig) recordShading(ctx context.Context, applied *AppliedMargin, globalId string, key ShadingKey) {
+	if c.Shader == nil || applied.ShadingBucket == "" {
+		return
+	}
+	c.Shader.Record(ctx, globalId, applied.ImpID, key, applied.Price)
+}
+
+// dropCappedBids убирает из аукциона ставки, исчерпавшие частотное ограничение,
+// победителем импрессии становится следующая ставка
+func dropCappedBids[T interface{ GetLurl() string }](
+	ctx context.Context,
+	f *FrequencyCapper,
+	impBids map[string][]rankedBid[T],
+	events *AuctionEvents,
+) {
+	if f == nil {
+		return
+	}
+
+	impIDs := make([]string, 0, len(impBids))
+	counters := make([][]capCounter, 0)
+	for impID, bids := range impBids {
+		impIDs = append(impIDs, impID)
+		for _, bid := range bids {
+			counters = append(counters, bid.caps)
+		}
+	}
+	capped := f.capped(ctx, counters)
+
+	next := 0
+	for _, impID := range impIDs {
+		bids := impBids[impID]
+		kept := bids[:0]
+		for _, bid := range bids {
+			if capped[next] {
+				events.LossNotices = appendLossNotice(events.LossNotices, bid.bid.GetLurl(), LOSS_REASON_FREQUENCY_CAPPED, nil)
+			} else {
+				kept = append(kept, bid)
+			}
+			next++
+		}
+		if len(kept) == 0 {
+			delete(impBids, impID)
+		} else {
+			impBids[impID] = kept
+		}
+	}
+}
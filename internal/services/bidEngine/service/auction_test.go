@@ -0,0 +1,221 @@
+package bidEngine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+	"google.golang.org/protobuf/proto"
+)
+
+func testBid(id, impID string, price float32) *ortb_V2_5.Bid {
+	return &ortb_V2_5.Bid{
+		Id:    proto.String(id),
+		Impid: proto.String(impID),
+		Price: proto.Float32(price),
+		Lurl:  proto.String("https://" + id + "/loss?reason=${AUCTION_LOSS}"),
+	}
+}
+
+func testSeatBid(seat string, group int32, bids ...*ortb_V2_5.Bid) *ortb_V2_5.SeatBid {
+	return &ortb_V2_5.SeatBid{
+		Seat:  proto.String(seat),
+		Group: proto.Int32(group),
+		Bid:   bids,
+	}
+}
+
+func testTwoImpRequest(responses ...*bidEngineGrpc.DspBidResponse_V2_5) *bidEngineGrpc.BidEngineRequest_V2_5 {
+	return &bidEngineGrpc.BidEngineRequest_V2_5{
+		BidRequest: &ortb_V2_5.BidRequest{
+			Id: proto.String("request1"),
+			Imp: []*ortb_V2_5.Imp{
+				{Id: proto.String("1")},
+				{Id: proto.String("2")},
+			},
+		},
+		BidResponses: responses,
+	}
+}
+
+func testDspResponse(dsp string, resp *ortb_V2_5.BidResponse) *bidEngineGrpc.DspBidResponse_V2_5 {
+	return &bidEngineGrpc.DspBidResponse_V2_5{BidResponse: resp, DspId: dsp}
+}
+
+// winners - id победивших ставок по местам
+func winners(resp *ortb_V2_5.BidResponse) map[string][]string {
+	result := make(map[string][]string)
+	for _, seatBid := range resp.GetSeatbid() {
+		for _, bid := range seatBid.GetBid() {
+			result[seatBid.GetSeat()] = append(result[seatBid.GetSeat()], bid.GetId())
+		}
+	}
+	return result
+}
+
+func TestAuctionKeepsSeatsOfMultipleSeatBids(t *testing.T) {
+	config := &AuctionConfig{Margins: newTestMarginPolicy(t)}
+	req := testTwoImpRequest(testDspResponse("dsp1", &ortb_V2_5.BidResponse{
+		Seatbid: []*ortb_V2_5.SeatBid{
+			testSeatBid("seat1", 0, testBid("a", "1", 2)),
+			testSeatBid("seat2", 0, testBid("b", "2", 1)),
+		},
+	}))
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	assert.Equal(t, map[string][]string{"seat1": {"a"}, "seat2": {"b"}}, winners(byDspPrice))
+	require.Len(t, events.Margins, 2)
+	for _, applied := range events.Margins {
+		assert.NotEmpty(t, applied.Seat)
+	}
+}
+
+func TestAuctionRunsIndependentlyPerImp(t *testing.T) {
+	config := &AuctionConfig{Margins: newTestMarginPolicy(t)}
+	req := testTwoImpRequest(
+		testDspResponse("dsp1", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				{Bid: []*ortb_V2_5.Bid{testBid("dsp1-2", "2", 3), testBid("dsp1-1", "1", 1)}},
+			},
+		}),
+		testDspResponse("dsp2", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				{Bid: []*ortb_V2_5.Bid{testBid("dsp2-1", "1", 2), testBid("dsp2-2", "2", 2)}},
+			},
+		}),
+	)
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	// Один победитель на imp, в порядке imp запроса
+	require.Len(t, byDspPrice.Seatbid, 1)
+	bids := byDspPrice.Seatbid[0].Bid
+	require.Len(t, bids, 2)
+	assert.Equal(t, "1", bids[0].GetImpid())
+	assert.Equal(t, "dsp2-1", bids[0].GetId())
+	assert.Equal(t, "2", bids[1].GetImpid())
+	assert.Equal(t, "dsp1-2", bids[1].GetId())
+
+	reasons := lossReasons(events)
+	assert.Equal(t, int32(LOSS_REASON_OUTBID), reasons["https://dsp1-1/loss?reason=${AUCTION_LOSS}"])
+	assert.Equal(t, int32(LOSS_REASON_OUTBID), reasons["https://dsp2-2/loss?reason=${AUCTION_LOSS}"])
+}
+
+func TestAuctionGroupWinsAllOrNothing(t *testing.T) {
+	config := &AuctionConfig{Margins: newTestMarginPolicy(t)}
+	req := testTwoImpRequest(
+		testDspResponse("dsp1", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				// Выигрывает первую импрессию, но проигрывает вторую - место снимается целиком
+				testSeatBid("group", 1, testBid("group1", "1", 5), testBid("group2", "2", 1)),
+			},
+		}),
+		testDspResponse("dsp2", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				testSeatBid("open", 0, testBid("open1", "1", 2), testBid("open2", "2", 3)),
+			},
+		}),
+	)
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	assert.Equal(t, map[string][]string{"open": {"open1", "open2"}}, winners(byDspPrice))
+	assert.Equal(t, int32(LOSS_REASON_OUTBID), lossReasons(events)["https://group1/loss?reason=${AUCTION_LOSS}"])
+}
+
+func TestAuctionGroupWinsWhenAllBidsWin(t *testing.T) {
+	config := &AuctionConfig{Margins: newTestMarginPolicy(t)}
+	req := testTwoImpRequest(
+		testDspResponse("dsp1", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				testSeatBid("group", 1, testBid("group1", "1", 5), testBid("group2", "2", 4)),
+			},
+		}),
+		testDspResponse("dsp2", &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{
+				testSeatBid("open", 0, testBid("open1", "1", 2), testBid("open2", "2", 3)),
+			},
+		}),
+	)
+
+	_, byDspPrice, _ := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	winning := winners(byDspPrice)
+	assert.ElementsMatch(t, []string{"group1", "group2"}, winning["group"])
+	assert.Empty(t, winning["open"])
+}
+
+func TestResolveGroupsCascades(t *testing.T) {
+	// Группа 1 проигрывает импрессию "b" группе 0, группа 0 проигрывает "c" открытой ставке.
+	// После снятия группы 0 группа 1 выигрывает обе свои импрессии.
+	impBids := map[string][]rankedBid[string]{
+		"a": {{bid: "g0a", group: 0}, {bid: "g1a", group: 1}},
+		"b": {{bid: "g0b", group: 0}, {bid: "g1b", group: 1}},
+		"c": {{bid: "open", group: NO_GROUP}, {bid: "g0c", group: 0}},
+	}
+
+	excluded := resolveGroups(impBids, []int{3, 2})
+
+	assert.Equal(t, map[int]bool{0: true}, excluded)
+	assert.Equal(t, "g1a", impBids["a"][0].bid)
+	assert.Equal(t, "g1b", impBids["b"][0].bid)
+	assert.Equal(t, "open", impBids["c"][0].bid)
+}
+
+func TestResolveGroupsBreaksCycle(t *testing.T) {
+	impBids := map[string][]rankedBid[string]{
+		"a": {{bid: "g0a", group: 0}, {bid: "g1a", group: 1}},
+		"b": {{bid: "g1b", group: 1}, {bid: "g0b", group: 0}},
+	}
+
+	excluded := resolveGroups(impBids, []int{2, 2})
+
+	assert.Equal(t, map[int]bool{0: true}, excluded)
+	assert.Equal(t, "g1a", impBids["a"][0].bid)
+	assert.Equal(t, "g1b", impBids["b"][0].bid)
+}
+
+func TestSelectWinnerSkipsBidsFailingMargin(t *testing.T) {
+	policy := newTestMarginPolicy(t)
+	rankedTestBid := func(id string, price, floor money.Micros, group int) rankedBid[*ortb_V2_5.Bid] {
+		return rankedBid[*ortb_V2_5.Bid]{bid: testBid(id, "1", price.Float32()), dsp: id, price: price, floor: floor, group: group}
+	}
+
+	// Первая ставка ниже своего флора, побеждает следующая по рангу
+	bids := []rankedBid[*ortb_V2_5.Bid]{
+		rankedTestBid("dsp1", 3_000_000, 5_000_000, NO_GROUP),
+		rankedTestBid("dsp2", 2_000_000, 1_000_000, NO_GROUP),
+		rankedTestBid("dsp3", 1_500_000, 1_000_000, NO_GROUP),
+	}
+	events := &AuctionEvents{}
+
+	winner, applied := selectWinner(policy, MarginKey{SSP: "ssp1"}, bids, nil, events)
+
+	assert.Equal(t, 1, winner)
+	assert.Equal(t, money.Micros(2_000_000), applied.DspPrice)
+	assert.Equal(t, money.Micros(1_400_000), applied.Price)
+	// Причину получает только отклонённая ставка, проигравших победителю отмечает аукцион
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_BELOW_AUCTION_FLOOR,
+	}, lossReasons(events))
+
+	// Без подходящей ставки остальные, из исключённых мест, проигрывают по group=1
+	bids = []rankedBid[*ortb_V2_5.Bid]{
+		rankedTestBid("dsp1", 3_000_000, 5_000_000, NO_GROUP),
+		rankedTestBid("dsp2", 2_000_000, 1_000_000, 0),
+	}
+	events = &AuctionEvents{}
+
+	winner, _ = selectWinner(policy, MarginKey{SSP: "ssp1"}, bids, map[int]bool{0: true}, events)
+
+	assert.Equal(t, -1, winner)
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_BELOW_AUCTION_FLOOR,
+		"https://dsp2/loss?reason=${AUCTION_LOSS}": LOSS_REASON_OUTBID,
+	}, lossReasons(events))
+}
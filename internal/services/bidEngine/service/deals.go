@@ -0,0 +1,149 @@
+package bidEngine
+
+import (
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Импрессия с private_auction=1 принимает только ставки по своим сделкам
+const PRIVATE_AUCTION = 1
+
+// Приоритет ставки в аукционе импрессии: сделки выше открытого аукциона
+const (
+	PRIORITY_OPEN_AUCTION = iota
+	PRIORITY_DEAL
+	PRIORITY_FIXED_PRICE_DEAL
+)
+
+// impDeal - сделка из PMP импрессии запроса SSP
+type impDeal struct {
+	floor    money.Micros
+	floorCur string
+	wseat    []string
+}
+
+type impPmp struct {
+	private bool
+	deals   map[string]impDeal
+}
+
+// checkDeal проверяет ставку по флору и сделке импрессии. Возвращает сделку из реестра
+// (nil для открытого аукциона), флор, который должна пройти ставка, в валюте
+// аукциона и код причины проигрыша, если ставка недопустима. Ставка по сделке,
+// которой нет в PMP импрессии или в реестре, участвует в открытом аукционе,
+// если он не закрыт private_auction.
+func (c *AuctionConfig) checkDeal(
+	pmp impPmp,
+	impFloor money.Micros,
+	dealID string,
+	dsp string,
+	seat string,
+	price money.Micros,
+	auctionCur string,
+) (*deals.Deal, money.Micros, int32) {
+	if requested, ok := pmp.deals[dealID]; ok {
+		if deal, ok := c.Deals.Get(dealID); ok {
+			if !deal.AllowsSeat(dsp, seat, requested.wseat) {
+				return nil, 0, LOSS_REASON_BUYER_SEAT_BLOCKED
+			}
+
+			floor, err := c.Deals.Floor(deal, requested.floor, requested.floorCur, auctionCur)
+			if err != nil {
+				return nil, 0, LOSS_REASON_INTERNAL_ERROR
+			}
+			if price < floor {
+				return nil, 0, LOSS_REASON_BELOW_DEAL_FLOOR
+			}
+
+			return deal, floor, 0
+		}
+	}
+
+	if pmp.private {
+		return nil, 0, LOSS_REASON_INVALID_DEAL_ID
+	}
+	if price < impFloor {
+		return nil, 0, LOSS_REASON_BELOW_AUCTION_FLOOR
+	}
+	return nil, impFloor, 0
+}
+
+// dealID - сделка, по которой ставка участвует в аукционе; пусто для открытого аукциона
+func (b rankedBid[T]) dealID() string {
+	if b.deal == nil {
+		return ""
+	}
+	return b.deal.ID
+}
+
+func (b rankedBid[T]) priority() int {
+	switch {
+	case b.deal == nil:
+		return PRIORITY_OPEN_AUCTION
+	case b.deal.IsFixedPrice():
+		return PRIORITY_FIXED_PRICE_DEAL
+	default:
+		return PRIORITY_DEAL
+	}
+}
+
+// outranks сравнивает ставки сначала по приоритету сделки, затем по цене
+func (b rankedBid[T]) outranks(other rankedBid[T]) bool {
+	if b.priority() != other.priority() {
+		return b.priority() > other.priority()
+	}
+	return b.price > other.price
+}
+
+// lossReason - причина проигрыша ставки loser победителю winner
+func lossReason[T any](winner, loser rankedBid[T]) int32 {
+	if winner.deal != nil && loser.deal == nil {
+		return LOSS_REASON_LOST_TO_DEAL
+	}
+	return LOSS_REASON_OUTBID
+}
+
+// auctionType - тип аукциона для ставки: у сделки он свой
+func (b rankedBid[T]) auctionType(requestAt int32) int32 {
+	if b.deal != nil {
+		return b.deal.At
+	}
+	return requestAt
+}
+
+// dealReports собирает статистику по сделкам в порядке появления ставок
+type dealReports struct {
+	reports []deals.Report
+	index   map[string]int
+}
+
+func (r *dealReports) bid(impID, dealID string, valid bool) {
+	if dealID == "" {
+		return
+	}
+	key := impID + "/" + dealID
+	i, ok := r.index[key]
+	if !ok {
+		if r.index == nil {
+			r.index = make(map[string]int)
+		}
+		i = len(r.reports)
+		r.index[key] = i
+		r.reports = append(r.reports, deals.Report{ImpID: impID, DealID: dealID})
+	}
+
+	r.reports[i].Bids++
+	if valid {
+		r.reports[i].ValidBids++
+	}
+}
+
+func (r *dealReports) won(impID, dealID string, price money.Micros, cur string) {
+	i, ok := r.index[impID+"/"+dealID]
+	if !ok {
+		return
+	}
+	r.reports[i].Won = true
+	r.reports[i].Price = &price
+	r.reports[i].Cur = cur
+}
@@ -0,0 +1,181 @@
+package bidEngine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestAuctionConfig(t *testing.T) *AuctionConfig {
+	path := filepath.Join(t.TempDir(), "deals.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"deals": [
+		{"id": "deal1", "floor": 2, "seats": ["dsp1", "dsp2"]},
+		{"id": "fixed", "floor": 3, "at": 3}
+	]}`), 0o644))
+	registry, err := deals.NewRegistry(path, nil)
+	require.NoError(t, err)
+
+	return &AuctionConfig{
+		Margins: newTestMarginPolicy(t),
+		Deals:   registry,
+	}
+}
+
+func testBidResponse(dsp string, price float32, dealID string) *bidEngineGrpc.DspBidResponse_V2_5 {
+	bid := &ortb_V2_5.Bid{
+		Id:    proto.String(dsp),
+		Impid: proto.String("1"),
+		Price: proto.Float32(price),
+		Lurl:  proto.String("https://" + dsp + "/loss?reason=${AUCTION_LOSS}"),
+	}
+	if dealID != "" {
+		bid.Dealid = proto.String(dealID)
+	}
+	return &bidEngineGrpc.DspBidResponse_V2_5{
+		BidResponse: &ortb_V2_5.BidResponse{
+			Seatbid: []*ortb_V2_5.SeatBid{{Bid: []*ortb_V2_5.Bid{bid}}},
+		},
+		DspId: dsp,
+	}
+}
+
+func testPmpRequest(private int32, responses ...*bidEngineGrpc.DspBidResponse_V2_5) *bidEngineGrpc.BidEngineRequest_V2_5 {
+	return &bidEngineGrpc.BidEngineRequest_V2_5{
+		BidRequest: &ortb_V2_5.BidRequest{
+			Id: proto.String("request1"),
+			Imp: []*ortb_V2_5.Imp{{
+				Id:       proto.String("1"),
+				BidFloor: proto.Float32(1),
+				Pmp: &ortb_V2_5.Pmp{
+					PrivateAuction: proto.Int32(private),
+					Deals: []*ortb_V2_5.Deal{
+						{Id: proto.String("deal1"), Bidfloor: proto.Float32(2.5)},
+						{Id: proto.String("fixed")},
+					},
+				},
+			}},
+		},
+		BidResponses: responses,
+	}
+}
+
+func lossReasons(events *AuctionEvents) map[string]int32 {
+	reasons := make(map[string]int32, len(events.LossNotices))
+	for _, notice := range events.LossNotices {
+		reasons[notice.Lurl] = notice.Reason
+	}
+	return reasons
+}
+
+func TestAuctionPrioritizesDeals(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	req := testPmpRequest(0,
+		testBidResponse("dsp3", 10, ""),
+		testBidResponse("dsp1", 3, "deal1"),
+		testBidResponse("dsp2", 2.6, "deal1"),
+	)
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	require.Len(t, byDspPrice.Seatbid, 1)
+	require.Len(t, byDspPrice.Seatbid[0].Bid, 1)
+	winner := byDspPrice.Seatbid[0].Bid[0]
+	assert.Equal(t, "dsp1", winner.GetId())
+	assert.Equal(t, "deal1", winner.GetDealid())
+
+	reasons := lossReasons(events)
+	assert.Equal(t, int32(LOSS_REASON_LOST_TO_DEAL), reasons["https://dsp3/loss?reason=${AUCTION_LOSS}"])
+	assert.Equal(t, int32(LOSS_REASON_OUTBID), reasons["https://dsp2/loss?reason=${AUCTION_LOSS}"])
+
+	require.Len(t, events.Deals, 1)
+	assert.Equal(t, "deal1", events.Deals[0].DealID)
+	assert.Equal(t, 2, events.Deals[0].Bids)
+	assert.True(t, events.Deals[0].Won)
+}
+
+func TestAuctionRejectsInvalidDealBids(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	req := testPmpRequest(1,
+		testBidResponse("dsp1", 2.4, "deal1"),
+		testBidResponse("dsp4", 5, "deal1"),
+		testBidResponse("dsp2", 5, "unknown"),
+		testBidResponse("dsp3", 10, ""),
+	)
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	assert.Empty(t, byDspPrice.Seatbid)
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_BELOW_DEAL_FLOOR,
+		"https://dsp4/loss?reason=${AUCTION_LOSS}": LOSS_REASON_BUYER_SEAT_BLOCKED,
+		"https://dsp2/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INVALID_DEAL_ID,
+		"https://dsp3/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INVALID_DEAL_ID,
+	}, lossReasons(events))
+
+	require.Len(t, events.Deals, 2)
+	assert.Equal(t, deals.Report{ImpID: "1", DealID: "deal1", Bids: 2}, events.Deals[0])
+}
+
+func TestAuctionUnknownDealFallsBackToOpenAuction(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	req := testPmpRequest(0,
+		testBidResponse("dsp1", 4, "unknown"),
+		testBidResponse("dsp2", 0.5, "unknown"),
+		testBidResponse("dsp3", 3, ""),
+	)
+
+	result, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	require.Len(t, byDspPrice.Seatbid, 1)
+	require.Len(t, byDspPrice.Seatbid[0].Bid, 1)
+	winner := byDspPrice.Seatbid[0].Bid[0]
+	assert.Equal(t, "dsp1", winner.GetId())
+	// Сделки нет, в ответ SSP она не попадает
+	assert.Nil(t, winner.Dealid)
+	assert.Nil(t, result.Seatbid[0].Bid[0].Dealid)
+
+	assert.Equal(t, map[string]int32{
+		"https://dsp2/loss?reason=${AUCTION_LOSS}": LOSS_REASON_BELOW_AUCTION_FLOOR,
+		"https://dsp3/loss?reason=${AUCTION_LOSS}": LOSS_REASON_OUTBID,
+	}, lossReasons(events))
+
+	require.Len(t, events.Deals, 1)
+	assert.Equal(t, deals.Report{ImpID: "1", DealID: "unknown", Bids: 2}, events.Deals[0])
+}
+
+func TestAuctionFixedPriceDeal(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	req := testPmpRequest(1,
+		testBidResponse("dsp1", 4, "deal1"),
+		testBidResponse("dsp2", 3.5, "fixed"),
+	)
+
+	_, byDspPrice, _ := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	require.Len(t, byDspPrice.Seatbid, 1)
+	require.Len(t, byDspPrice.Seatbid[0].Bid, 1)
+	assert.Equal(t, "dsp2", byDspPrice.Seatbid[0].Bid[0].GetId())
+	assert.Equal(t, float32(3), byDspPrice.Seatbid[0].Bid[0].GetPrice(), "fixed price deal pays deal floor")
+}
+
+func TestAuctionReportsFilterReason(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	blocked := testBidResponse("dsp2", 5, "")
+	blocked.FilterReason = LOSS_REASON_ADVERTISER_BLOCKED
+	req := testPmpRequest(0, testBidResponse("dsp1", 2, ""))
+	req.FilteredBidResponses = []*bidEngineGrpc.DspBidResponse_V2_5{blocked, testBidResponse("dsp3", 5, "")}
+
+	_, _, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	reasons := lossReasons(events)
+	assert.Equal(t, int32(LOSS_REASON_ADVERTISER_BLOCKED), reasons["https://dsp2/loss?reason=${AUCTION_LOSS}"])
+	assert.Equal(t, int32(LOSS_REASON_CREATIVE_FILTERED), reasons["https://dsp3/loss?reason=${AUCTION_LOSS}"])
+}
@@ -0,0 +1,381 @@
+package bidEngine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type FrequencyCapTarget string
+
+const (
+	// Показы рекламодателя, считаются по каждому домену из adomain ставки
+	CAP_BY_ADVERTISER FrequencyCapTarget = "advertiser"
+	// Показы креатива, считаются по crid ставки в пределах DSP
+	CAP_BY_CREATIVE FrequencyCapTarget = "creative"
+)
+
+const (
+	FREQUENCY_CAP_KEY_PREFIX         = "fcap:"
+	FREQUENCY_CAP_PENDING_KEY_PREFIX = "fcap_pending:"
+)
+
+// FrequencyCap - не больше Limit показов одному пользователю за окно Window.
+// Окна фиксированные и отсчитываются от начала эпохи: окно 24h - сутки по UTC.
+// Пустой SSP - ограничение для всех SSP, ограничения SSP действуют вместе с общими.
+type FrequencyCap struct {
+	ID     string             `json:"id"`
+	SSP    string             `json:"ssp,omitempty"`
+	By     FrequencyCapTarget `json:"by"`
+	Limit  int64              `json:"limit"`
+	Window string             `json:"window"`
+
+	window time.Duration
+}
+
+type FrequencyCapsConfig struct {
+	Caps []FrequencyCap `json:"caps"`
+}
+
+// CapIncrement - счётчик показов, который надо увеличить, и его время жизни
+type CapIncrement struct {
+	Key string
+	TTL time.Duration
+}
+
+// CapCounterStore хранит счётчики показов и выигрыши, ждущие billing.
+// Хранилище общее для всех реплик bid engine, по умолчанию Redis.
+type CapCounterStore interface {
+	// Counts возвращает значения счётчиков, отсутствующий счётчик равен 0
+	Counts(ctx context.Context, keys []string) ([]int64, error)
+	Increment(ctx context.Context, increments []CapIncrement) error
+	SavePending(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// TakePending возвращает и удаляет выигрыш; nil, если его нет или он устарел
+	TakePending(ctx context.Context, key string) ([]byte, error)
+}
+
+// capCounter - счётчик ограничения для пары пользователь/цель без номера окна
+type capCounter struct {
+	Cap  string `json:"cap"`
+	Base string `json:"base"`
+}
+
+// CapImpression - выигрыш импрессии ставкой с частотными ограничениями.
+// Счётчики увеличиваются только по billing.
+type CapImpression struct {
+	ImpID    string
+	counters []capCounter
+}
+
+// FrequencyCapper отбрасывает ставки, рекламодатель или креатив которых
+// пользователь уже видел Limit раз за окно. Ошибки хранилища не мешают
+// аукциону: ставки тогда не ограничиваются. nil *FrequencyCapper ничего не ограничивает.
+type FrequencyCapper struct {
+	caps   map[string]*FrequencyCap
+	global []*FrequencyCap
+	bySSP  map[string][]*FrequencyCap
+
+	store CapCounterStore
+	// Сколько аукцион ждёт счётчики
+	timeout time.Duration
+	// Сколько ждать billing по выигрышу
+	pendingTTL time.Duration
+	now        func() time.Time
+}
+
+func NewFrequencyCapper(path string, store CapCounterStore, timeout, pendingTTL time.Duration) (*FrequencyCapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config FrequencyCapsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid frequency caps file %s: %v", path, err)
+	}
+	if err := validateFrequencyCaps(&config); err != nil {
+		return nil, fmt.Errorf("frequency caps validation failed: %v", err)
+	}
+	if pendingTTL <= 0 {
+		pendingTTL = 10 * time.Minute
+	}
+
+	f := &FrequencyCapper{
+		caps:       make(map[string]*FrequencyCap, len(config.Caps)),
+		bySSP:      make(map[string][]*FrequencyCap),
+		store:      store,
+		timeout:    timeout,
+		pendingTTL: pendingTTL,
+		now:        time.Now,
+	}
+	for i := range config.Caps {
+		c := &config.Caps[i]
+		f.caps[c.ID] = c
+		if c.SSP == "" {
+			f.global = append(f.global, c)
+		} else {
+			f.bySSP[c.SSP] = append(f.bySSP[c.SSP], c)
+		}
+	}
+	return f, nil
+}
+
+func validateFrequencyCaps(config *FrequencyCapsConfig) error {
+	seen := make(map[string]struct{}, len(config.Caps))
+	for i := range config.Caps {
+		c := &config.Caps[i]
+		if c.ID == "" {
+			return fmt.Errorf("cap #%d has no id", i)
+		}
+		if _, ok := seen[c.ID]; ok {
+			return fmt.Errorf("duplicate cap id %s", c.ID)
+		}
+		seen[c.ID] = struct{}{}
+
+		if c.By != CAP_BY_ADVERTISER && c.By != CAP_BY_CREATIVE {
+			return fmt.Errorf("cap %s: unknown target %q", c.ID, c.By)
+		}
+		if c.Limit <= 0 {
+			return fmt.Errorf("cap %s: limit must be positive, got %d", c.ID, c.Limit)
+		}
+		window, err := time.ParseDuration(c.Window)
+		if err != nil {
+			return fmt.Errorf("cap %s: invalid window %q: %v", c.ID, c.Window, err)
+		}
+		if window < time.Second {
+			return fmt.Errorf("cap %s: window must be at least 1s, got %s", c.ID, c.Window)
+		}
+		c.window = window
+	}
+	return nil
+}
+
+// CapUser - ключ пользователя для частотных ограничений: user.id, а без него
+// хеш IP и User-Agent устройства. Пустой ключ - пользователь неизвестен.
+func CapUser(userID, ip, ua string) string {
+	if userID != "" {
+		return "u:" + userID
+	}
+	if ip == "" {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(ua))
+	return "d:" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// counters - счётчики всех ограничений SSP и общих ограничений для ставки
+func (f *FrequencyCapper) counters(ssp, user, dsp string, adomain []string, crid string) []capCounter {
+	if f == nil || user == "" {
+		return nil
+	}
+
+	var counters []capCounter
+	add := func(caps []*FrequencyCap) {
+		for _, c := range caps {
+			switch c.By {
+			case CAP_BY_ADVERTISER:
+				for i, domain := range adomain {
+					domain = strings.ToLower(strings.TrimSpace(domain))
+					if domain == "" || containsDomain(adomain[:i], domain) {
+						continue
+					}
+					counters = append(counters, c.counter(user, domain))
+				}
+			case CAP_BY_CREATIVE:
+				if crid != "" {
+					counters = append(counters, c.counter(user, dsp+"/"+crid))
+				}
+			}
+		}
+	}
+	add(f.global)
+	add(f.bySSP[ssp])
+	return counters
+}
+
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if strings.ToLower(strings.TrimSpace(d)) == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FrequencyCap) counter(user, target string) capCounter {
+	return capCounter{
+		Cap:  c.ID,
+		Base: FREQUENCY_CAP_KEY_PREFIX + c.ID + ":" + user + ":" + target,
+	}
+}
+
+// key - счётчик текущего окна ограничения
+func (c *FrequencyCap) key(base string, now time.Time) string {
+	return base + ":" + strconv.FormatInt(now.UnixNano()/int64(c.window), 10)
+}
+
+// capped читает счётчики всех ставок одним запросом и сообщает, какие ставки
+// исчерпали хотя бы одно ограничение
+func (f *FrequencyCapper) capped(ctx context.Context, bids [][]capCounter) []bool {
+	now := f.now()
+	keys := make([]string, 0)
+	limits := make([]int64, 0)
+	for _, counters := range bids {
+		for _, counter := range counters {
+			c := f.caps[counter.Cap]
+			keys = append(keys, c.key(counter.Base, now))
+			limits = append(limits, c.Limit)
+		}
+	}
+
+	capped := make([]bool, len(bids))
+	if len(keys) == 0 {
+		return capped
+	}
+
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+	counts, err := f.store.Counts(ctx, keys)
+	if err != nil || len(counts) != len(keys) {
+		log.Printf("Cannot read frequency cap counters, bids are not capped: %v", err)
+		return capped
+	}
+
+	next := 0
+	for i, counters := range bids {
+		for range counters {
+			if counts[next] >= limits[next] {
+				capped[i] = true
+			}
+			next++
+		}
+	}
+	return capped
+}
+
+// SavePending запоминает выигрыши до прихода billing
+func (f *FrequencyCapper) SavePending(ctx context.Context, globalId string, impressions []CapImpression) {
+	if f == nil {
+		return
+	}
+	for _, impression := range impressions {
+		data, err := json.Marshal(impression.counters)
+		if err != nil {
+			log.Printf("Cannot marshal frequency cap counters: %v", err)
+			continue
+		}
+		if err := f.store.SavePending(ctx, capPendingKey(globalId, impression.ImpID), data, f.pendingTTL); err != nil {
+			log.Printf("Cannot save frequency caps of imp %s in auction %s: %v", impression.ImpID, globalId, err)
+		}
+	}
+}
+
+// ReportBilling засчитывает показ во все счётчики выигравшей ставки. Повторный
+// billing по той же импрессии ничего не меняет. Ограничения, удалённые из файла
+// после аукциона, пропускаются.
+func (f *FrequencyCapper) ReportBilling(ctx context.Context, globalId, impId string) error {
+	if f == nil {
+		return nil
+	}
+	data, err := f.store.TakePending(ctx, capPendingKey(globalId, impId))
+	if err != nil || data == nil {
+		return err
+	}
+	var counters []capCounter
+	if err := json.Unmarshal(data, &counters); err != nil {
+		return fmt.Errorf("invalid frequency cap counters: %v", err)
+	}
+
+	now := f.now()
+	increments := make([]CapIncrement, 0, len(counters))
+	for _, counter := range counters {
+		c, ok := f.caps[counter.Cap]
+		if !ok {
+			continue
+		}
+		increments = append(increments, CapIncrement{Key: c.key(counter.Base, now), TTL: c.window})
+	}
+	if len(increments) == 0 {
+		return nil
+	}
+	return f.store.Increment(ctx, increments)
+}
+
+func capPendingKey(globalId, impId string) string {
+	return FREQUENCY_CAP_PENDING_KEY_PREFIX + globalId + ":" + impId
+}
+
+// RedisPendingStore - выигрыши, ждущие billing, в Redis. Записи удаляются по TTL,
+// если billing не пришёл. Его используют частотные ограничения и шейдинг.
+type RedisPendingStore struct {
+	client *redis.Client
+}
+
+func NewRedisPendingStore(client *redis.Client) *RedisPendingStore {
+	return &RedisPendingStore{client: client}
+}
+
+func (s *RedisPendingStore) SavePending(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+func (s *RedisPendingStore) TakePending(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// RedisCapStore - счётчики частотных ограничений в Redis
+type RedisCapStore struct {
+	RedisPendingStore
+}
+
+func NewRedisCapStore(client *redis.Client) *RedisCapStore {
+	return &RedisCapStore{RedisPendingStore{client: client}}
+}
+
+func (s *RedisCapStore) Counts(ctx context.Context, keys []string) ([]int64, error) {
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if counts[i], err = strconv.ParseInt(str, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid frequency cap counter %s: %v", keys[i], err)
+		}
+	}
+	return counts, nil
+}
+
+func (s *RedisCapStore) Increment(ctx context.Context, increments []CapIncrement) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, increment := range increments {
+			pipe.Incr(ctx, increment.Key)
+			pipe.Expire(ctx, increment.Key, increment.TTL)
+		}
+		return nil
+	})
+	return err
+}
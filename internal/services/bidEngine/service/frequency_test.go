@@ -0,0 +1,187 @@
+package bidEngine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryCapStore - хранилище счётчиков в памяти вместо Redis
+type memoryCapStore struct {
+	counts  map[string]int64
+	pending map[string][]byte
+	err     error
+}
+
+func newMemoryCapStore() *memoryCapStore {
+	return &memoryCapStore{
+		counts:  make(map[string]int64),
+		pending: make(map[string][]byte),
+	}
+}
+
+func (s *memoryCapStore) Counts(ctx context.Context, keys []string) ([]int64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	counts := make([]int64, len(keys))
+	for i, key := range keys {
+		counts[i] = s.counts[key]
+	}
+	return counts, nil
+}
+
+func (s *memoryCapStore) Increment(ctx context.Context, increments []CapIncrement) error {
+	for _, increment := range increments {
+		s.counts[increment.Key]++
+	}
+	return nil
+}
+
+func (s *memoryCapStore) SavePending(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	s.pending[key] = data
+	return nil
+}
+
+func (s *memoryCapStore) TakePending(ctx context.Context, key string) ([]byte, error) {
+	data := s.pending[key]
+	delete(s.pending, key)
+	return data, nil
+}
+
+func newTestFrequencyCapper(t *testing.T, store CapCounterStore, data string) *FrequencyCapper {
+	path := filepath.Join(t.TempDir(), "caps.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	f, err := NewFrequencyCapper(path, store, 0, 0)
+	require.NoError(t, err)
+	f.now = func() time.Time { return time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return f
+}
+
+func testCapRequest(responses ...*bidEngineGrpc.DspBidResponse_V2_5) *bidEngineGrpc.BidEngineRequest_V2_5 {
+	req := testPmpRequest(0, responses...)
+	req.SppEndpoint = "ssp1"
+	req.BidRequest.Imp[0].Pmp = nil
+	req.BidRequest.User = &ortb_V2_5.User{Id: proto.String("user1")}
+	return req
+}
+
+func testCapBidResponse(dsp string, price float32, adomain, crid string) *bidEngineGrpc.DspBidResponse_V2_5 {
+	resp := testBidResponse(dsp, price, "")
+	bid := resp.BidResponse.Seatbid[0].Bid[0]
+	bid.Adomain = []string{adomain}
+	bid.Crid = proto.String(crid)
+	return resp
+}
+
+func TestFrequencyCapsValidation(t *testing.T) {
+	for name, data := range map[string]string{
+		"no id":          `{"caps": [{"by": "advertiser", "limit": 1, "window": "1h"}]}`,
+		"duplicate id":   `{"caps": [{"id": "a", "by": "advertiser", "limit": 1, "window": "1h"}, {"id": "a", "by": "creative", "limit": 1, "window": "1h"}]}`,
+		"unknown target": `{"caps": [{"id": "a", "by": "campaign", "limit": 1, "window": "1h"}]}`,
+		"zero limit":     `{"caps": [{"id": "a", "by": "advertiser", "window": "1h"}]}`,
+		"bad window":     `{"caps": [{"id": "a", "by": "advertiser", "limit": 1, "window": "day"}]}`,
+		"short window":   `{"caps": [{"id": "a", "by": "advertiser", "limit": 1, "window": "10ms"}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "caps.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		_, err := NewFrequencyCapper(path, newMemoryCapStore(), 0, 0)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCapUser(t *testing.T) {
+	assert.Equal(t, "u:user1", CapUser("user1", "1.2.3.4", "ua"))
+	assert.Equal(t, CapUser("", "1.2.3.4", "ua"), CapUser("", "1.2.3.4", "ua"))
+	assert.NotEqual(t, CapUser("", "1.2.3.4", "ua"), CapUser("", "1.2.3.4", "other"))
+	assert.Empty(t, CapUser("", "", "ua"))
+}
+
+func TestAuctionSkipsCappedBids(t *testing.T) {
+	store := newMemoryCapStore()
+	config := newTestAuctionConfig(t)
+	config.FrequencyCaps = newTestFrequencyCapper(t, store, `{"caps": [
+		{"id": "adv", "by": "advertiser", "limit": 2, "window": "24h"},
+		{"id": "creative", "ssp": "ssp1", "by": "creative", "limit": 1, "window": "1h"},
+		{"id": "other", "ssp": "ssp2", "by": "advertiser", "limit": 1, "window": "1h"}
+	]}`)
+	newRequest := func() *bidEngineGrpc.BidEngineRequest_V2_5 {
+		return testCapRequest(
+			testCapBidResponse("dsp1", 5, "Brand.com", "c1"),
+			testCapBidResponse("dsp2", 4, "brand.com", "c2"),
+			testCapBidResponse("dsp3", 3, "other.com", "c3"),
+		)
+	}
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), newRequest(), config, "global1", "exchange")
+	assert.Equal(t, "dsp1", byDspPrice.Seatbid[0].Bid[0].GetId())
+	require.Len(t, events.Impressions, 1)
+	// Без billing показ не засчитывается
+	config.FrequencyCaps.SavePending(context.Background(), "global1", events.Impressions)
+	assert.Empty(t, store.counts)
+
+	require.NoError(t, config.FrequencyCaps.ReportBilling(context.Background(), "global1", "1"))
+	// Повторный billing не засчитывается
+	require.NoError(t, config.FrequencyCaps.ReportBilling(context.Background(), "global1", "1"))
+	assert.Len(t, store.counts, 2)
+	for key, count := range store.counts {
+		assert.Equal(t, int64(1), count, key)
+	}
+
+	// Креатив c1 исчерпал ограничение, выигрывает следующая ставка того же рекламодателя
+	_, byDspPrice, events = GetWinnerBidInternal_V_2_5(context.Background(), newRequest(), config, "global2", "exchange")
+	assert.Equal(t, "dsp2", byDspPrice.Seatbid[0].Bid[0].GetId())
+	assert.Equal(t, int32(LOSS_REASON_FREQUENCY_CAPPED), lossReasons(events)["https://dsp1/loss?reason=${AUCTION_LOSS}"])
+	config.FrequencyCaps.SavePending(context.Background(), "global2", events.Impressions)
+	require.NoError(t, config.FrequencyCaps.ReportBilling(context.Background(), "global2", "1"))
+
+	// Рекламодатель brand.com исчерпал дневное ограничение
+	_, byDspPrice, events = GetWinnerBidInternal_V_2_5(context.Background(), newRequest(), config, "global3", "exchange")
+	assert.Equal(t, "dsp3", byDspPrice.Seatbid[0].Bid[0].GetId())
+	assert.Equal(t, int32(LOSS_REASON_FREQUENCY_CAPPED), lossReasons(events)["https://dsp2/loss?reason=${AUCTION_LOSS}"])
+
+	// Другой пользователь не ограничен
+	req := newRequest()
+	req.BidRequest.User = nil
+	req.BidRequest.Device = &ortb_V2_5.Device{Ip: proto.String("1.2.3.4")}
+	_, byDspPrice, _ = GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global4", "exchange")
+	assert.Equal(t, "dsp1", byDspPrice.Seatbid[0].Bid[0].GetId())
+
+	// Ошибка хранилища не мешает аукциону
+	store.err = errors.New("redis is down")
+	_, byDspPrice, _ = GetWinnerBidInternal_V_2_5(context.Background(), newRequest(), config, "global5", "exchange")
+	assert.Equal(t, "dsp1", byDspPrice.Seatbid[0].Bid[0].GetId())
+}
+
+func TestAuctionDropsImpWhenAllBidsCapped(t *testing.T) {
+	store := newMemoryCapStore()
+	config := newTestAuctionConfig(t)
+	config.FrequencyCaps = newTestFrequencyCapper(t, store, `{"caps": [
+		{"id": "adv", "by": "advertiser", "limit": 1, "window": "1h"}
+	]}`)
+	for _, counter := range config.FrequencyCaps.counters("ssp1", "u:user1", "dsp1", []string{"brand.com"}, "") {
+		store.counts[config.FrequencyCaps.caps[counter.Cap].key(counter.Base, config.FrequencyCaps.now())] = 1
+	}
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(
+		context.Background(),
+		testCapRequest(testCapBidResponse("dsp1", 5, "brand.com", "c1")),
+		config,
+		"global1",
+		"exchange",
+	)
+	assert.Empty(t, byDspPrice.Seatbid)
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_FREQUENCY_CAPPED,
+	}, lossReasons(events))
+}
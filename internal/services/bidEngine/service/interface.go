@@ -4,8 +4,6 @@ const (
 	FIRST_PRICE  = 1
 	SECOND_PRICE = 2
 
-	EPS = 0.01
-
 	IMP_DEFAULT    = 0
 	MAX_BID_NUMBER = 0
 )
@@ -0,0 +1,188 @@
+package bidEngine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	utils "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/utils_grpc"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Коды причин проигрыша из OpenRTB 2.5 (раздел 5.25)
+const (
+	LOSS_REASON_INTERNAL_ERROR       = 1
+	LOSS_REASON_INVALID_BID_RESPONSE = 3
+	LOSS_REASON_INVALID_DEAL_ID      = 4
+	LOSS_REASON_BELOW_AUCTION_FLOOR  = 100
+	LOSS_REASON_BELOW_DEAL_FLOOR     = 101
+	LOSS_REASON_OUTBID               = 102
+	LOSS_REASON_LOST_TO_DEAL         = 103
+	LOSS_REASON_BUYER_SEAT_BLOCKED   = 104
+	LOSS_REASON_CREATIVE_FILTERED    = 200
+	LOSS_REASON_ADVERTISER_BLOCKED   = 205
+	LOSS_REASON_APP_BUNDLE_BLOCKED   = 206
+	LOSS_REASON_CATEGORY_BLOCKED     = 208
+	// Коды от 500 - причины биржи: пользователь исчерпал частотное ограничение
+	LOSS_REASON_FREQUENCY_CAPPED = 500
+)
+
+// filteredLossReason возвращает причину, проставленную роутером отфильтрованной
+// ставке (205, 206, 208), иначе LOSS_REASON_CREATIVE_FILTERED
+func filteredLossReason(filterReason int32) int32 {
+	if filterReason != 0 {
+		return filterReason
+	}
+	return LOSS_REASON_CREATIVE_FILTERED
+}
+
+type LossNotice struct {
+	Lurl   string
+	Reason int32
+	// nil, если цена клиринга неизвестна (например, победителя нет)
+	ClearingPrice *money.Micros
+}
+
+func appendLossNotice(
+	notices []LossNotice,
+	lurl string,
+	reason int32,
+	clearingPrice *money.Micros,
+) []LossNotice {
+	if lurl == "" {
+		return notices
+	}
+	return append(notices, LossNotice{
+		Lurl:          lurl,
+		Reason:        reason,
+		ClearingPrice: clearingPrice,
+	})
+}
+
+// LossNotifier асинхронно отправляет lurl проигравшим DSP
+// через ограниченный пул воркеров с повторами.
+type LossNotifier struct {
+	client      *http.Client
+	queue       chan LossNotice
+	workers     int
+	retries     int
+	retryDelay  time.Duration
+	exposePrice bool
+
+	// Контекст отправки не связан с контекстом сервера: Close отменяет его только
+	// после того, как очередь разобрана
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLossNotifier(
+	workers int,
+	queueSize int,
+	retries int,
+	timeout time.Duration,
+	exposePrice bool,
+) *LossNotifier {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LossNotifier{
+		ctx:         ctx,
+		cancel:      cancel,
+		client:      &http.Client{Timeout: timeout},
+		queue:       make(chan LossNotice, queueSize),
+		workers:     workers,
+		retries:     retries,
+		retryDelay:  50 * time.Millisecond,
+		exposePrice: exposePrice,
+	}
+}
+
+func (n *LossNotifier) Start() {
+	for i := 0; i < n.workers; i++ {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			for notice := range n.queue {
+				n.send(n.ctx, notice)
+			}
+		}()
+	}
+}
+
+// Notify не блокирует аукцион: при переполненной очереди уведомление отбрасывается
+func (n *LossNotifier) Notify(notices []LossNotice) {
+	for _, notice := range notices {
+		select {
+		case n.queue <- notice:
+		default:
+			log.Printf("loss notice queue is full, dropping lurl %s", notice.Lurl)
+		}
+	}
+}
+
+// Close дожидается отправки уже поставленных в очередь уведомлений.
+// Notify после Close вызывать нельзя.
+func (n *LossNotifier) Close() {
+	close(n.queue)
+	n.wg.Wait()
+	n.cancel()
+}
+
+func (n *LossNotifier) send(ctx context.Context, notice LossNotice) {
+	clearingPrice := ""
+	if n.exposePrice && notice.ClearingPrice != nil {
+		clearingPrice = notice.ClearingPrice.String()
+	}
+	lurl := utils.FillLossURL(notice.Lurl, notice.Reason, clearingPrice)
+
+	var lastErr error
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(n.retryDelay * time.Duration(attempt)):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		lastErr = n.get(ctx, lurl)
+		if lastErr == nil {
+			return
+		}
+	}
+
+	log.Printf("failed to send loss notice %s after %d attempts: %v", lurl, n.retries+1, lastErr)
+}
+
+func (n *LossNotifier) get(ctx context.Context, lurl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lurl, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("DSP returned %d", resp.StatusCode)
+	}
+	return nil
+}
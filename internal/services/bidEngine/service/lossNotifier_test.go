@@ -0,0 +1,248 @@
+package bidEngine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+	"google.golang.org/protobuf/proto"
+)
+
+// lossServer - DSP, принимающая lurl. status возвращает код ответа на попытку n (с 1).
+type lossServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	received []string
+	attempts atomic.Int64
+}
+
+func newLossServer(t *testing.T, status func(attempt int64) int) *lossServer {
+	s := &lossServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := s.attempts.Add(1)
+		code := status(attempt)
+		if code == http.StatusOK {
+			s.mu.Lock()
+			s.received = append(s.received, r.URL.RawQuery)
+			s.mu.Unlock()
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *lossServer) queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+func alwaysOK(int64) int { return http.StatusOK }
+
+func newTestLossNotifier(workers, queueSize, retries int, exposePrice bool) *LossNotifier {
+	n := NewLossNotifier(workers, queueSize, retries, time.Second, exposePrice)
+	n.retryDelay = time.Millisecond
+	return n
+}
+
+func TestLossNotifierFillsMacros(t *testing.T) {
+	price := money.FromFloat64(1.25)
+	for _, tt := range []struct {
+		name        string
+		exposePrice bool
+		expected    string
+	}{
+		{name: "Price exposed", exposePrice: true, expected: "reason=102&price=1.25"},
+		{name: "Price hidden", exposePrice: false, expected: "reason=102&price="},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLossServer(t, alwaysOK)
+			n := newTestLossNotifier(1, 10, 0, tt.exposePrice)
+			n.Start()
+
+			n.Notify([]LossNotice{{
+				Lurl:          server.URL + "/loss?reason=${AUCTION_LOSS}&price=${AUCTION_PRICE}",
+				Reason:        LOSS_REASON_OUTBID,
+				ClearingPrice: &price,
+			}})
+			n.Close()
+
+			assert.Equal(t, []string{tt.expected}, server.queries())
+		})
+	}
+}
+
+func TestLossNotifierRetries(t *testing.T) {
+	// Две ошибки сервера, затем успех: хватает двух повторов
+	server := newLossServer(t, func(attempt int64) int {
+		if attempt <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	n := newTestLossNotifier(1, 10, 2, false)
+	n.Start()
+	n.Notify([]LossNotice{{Lurl: server.URL + "/loss", Reason: LOSS_REASON_OUTBID}})
+	n.Close()
+
+	assert.Equal(t, int64(3), server.attempts.Load())
+	assert.Len(t, server.queries(), 1)
+
+	// Повторы кончились - уведомление теряется. Ошибки клиента не повторяются.
+	failing := newLossServer(t, func(int64) int { return http.StatusBadGateway })
+	rejecting := newLossServer(t, func(int64) int { return http.StatusNotFound })
+	n = newTestLossNotifier(1, 10, 1, false)
+	n.Start()
+	n.Notify([]LossNotice{
+		{Lurl: failing.URL + "/loss", Reason: LOSS_REASON_OUTBID},
+		{Lurl: rejecting.URL + "/loss", Reason: LOSS_REASON_OUTBID},
+	})
+	n.Close()
+
+	assert.Equal(t, int64(2), failing.attempts.Load())
+	assert.Equal(t, int64(1), rejecting.attempts.Load())
+}
+
+func TestLossNotifierWorkerPool(t *testing.T) {
+	const workers = 3
+
+	var inFlight, maxInFlight atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		<-release
+	}))
+	defer server.Close()
+
+	n := newTestLossNotifier(workers, 100, 0, false)
+	n.Start()
+	notices := make([]LossNotice, 10)
+	for i := range notices {
+		notices[i] = LossNotice{Lurl: server.URL + "/loss", Reason: LOSS_REASON_OUTBID}
+	}
+	n.Notify(notices)
+
+	require.Eventually(t, func() bool { return inFlight.Load() == workers }, time.Second, time.Millisecond)
+	close(release)
+	n.Close()
+
+	assert.Equal(t, int64(workers), maxInFlight.Load())
+}
+
+func TestLossNotifierDropsWhenQueueIsFull(t *testing.T) {
+	server := newLossServer(t, alwaysOK)
+	// Воркеры не запущены, в очередь помещается одно уведомление
+	n := newTestLossNotifier(1, 1, 0, false)
+	n.Notify([]LossNotice{
+		{Lurl: server.URL + "/loss?n=1"},
+		{Lurl: server.URL + "/loss?n=2"},
+	})
+	n.Start()
+	n.Close()
+
+	assert.Equal(t, []string{"n=1"}, server.queries())
+}
+
+func TestLossNotifierCloseDrainsQueue(t *testing.T) {
+	server := newLossServer(t, func(int64) int {
+		time.Sleep(time.Millisecond)
+		return http.StatusOK
+	})
+	n := newTestLossNotifier(2, 100, 0, false)
+	n.Start()
+
+	notices := make([]LossNotice, 20)
+	for i := range notices {
+		notices[i] = LossNotice{Lurl: server.URL + "/loss", Reason: LOSS_REASON_OUTBID}
+	}
+	n.Notify(notices)
+	n.Close()
+
+	// Close вернулся только после отправки всех поставленных уведомлений
+	assert.Len(t, server.queries(), len(notices))
+	assert.ErrorIs(t, n.ctx.Err(), context.Canceled)
+}
+
+func TestAuctionSendsClearingPriceToLosers(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	req := testPmpRequest(0,
+		testBidResponse("dsp1", 10, ""),
+		testBidResponse("dsp2", 5, ""),
+	)
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	require.Len(t, byDspPrice.Seatbid, 1)
+	require.Len(t, events.Margins, 1)
+	require.Len(t, events.LossNotices, 1)
+	loser := events.LossNotices[0]
+	require.NotNil(t, loser.ClearingPrice)
+	// Проигравший видит цену, которую платит SSP, а не ставку победителя
+	assert.Equal(t, events.Margins[0].Price, *loser.ClearingPrice)
+	assert.Equal(t, money.FromFloat64(7), *loser.ClearingPrice)
+}
+
+func newTestRates(t *testing.T) *currency.Rates {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0.9}}`), 0o644))
+	rates, err := currency.NewRates(path)
+	require.NoError(t, err)
+	return rates
+}
+
+func TestAuctionReportsConversionFailures(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	config.Rates = newTestRates(t)
+	unknownCur := testBidResponse("dsp2", 5, "")
+	unknownCur.BidResponse.Cur = proto.String("XXX")
+	req := testPmpRequest(0, testBidResponse("dsp1", 10, ""), unknownCur)
+	// Курса валюты флора нет у биржи, ставка в неизвестной валюте - ошибка DSP
+	req.BidRequest.Imp[0].BidFloorCur = proto.String("GBP")
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	assert.Empty(t, byDspPrice.Seatbid)
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INTERNAL_ERROR,
+		"https://dsp2/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INVALID_BID_RESPONSE,
+	}, lossReasons(events))
+}
+
+func TestAuctionNotifiesBidsWhenResponsePriceFails(t *testing.T) {
+	config := newTestAuctionConfig(t)
+	config.Rates = newTestRates(t)
+	req := testPmpRequest(0,
+		testBidResponse("dsp1", 10, ""),
+		testBidResponse("dsp2", 5, ""),
+	)
+	req.BidRequest.Cur = []string{"ZZZ"}
+
+	_, byDspPrice, events := GetWinnerBidInternal_V_2_5(context.Background(), req, config, "global1", "exchange")
+
+	// Цену победителя нельзя перевести в валюту SSP: imp пропускается, все ставки узнают о сбое
+	assert.Empty(t, byDspPrice.Seatbid)
+	assert.Empty(t, events.Margins)
+	assert.Equal(t, map[string]int32{
+		"https://dsp1/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INTERNAL_ERROR,
+		"https://dsp2/loss?reason=${AUCTION_LOSS}": LOSS_REASON_INTERNAL_ERROR,
+	}, lossReasons(events))
+}
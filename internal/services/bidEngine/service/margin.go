@@ -0,0 +1,273 @@
+package bidEngine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+type MarginMode string
+
+const (
+	// Доля от цены DSP
+	MARGIN_MODE_PERCENT MarginMode = "percent"
+	// Фиксированная сумма за тысячу показов в валюте аукциона
+	MARGIN_MODE_CPM MarginMode = "cpm"
+	// Доля от цены DSP, зависящая от диапазона цены
+	MARGIN_MODE_TIERED MarginMode = "tiered"
+)
+
+type MarginTier struct {
+	// Нижняя граница цены DSP в валюте аукциона, включительно
+	From    float64 `json:"from"`
+	Percent float64 `json:"percent"`
+
+	from    money.Micros
+	percent money.Ratio
+}
+
+// Margin в файле задаётся дробными числами, при загрузке они переводятся в money
+type Margin struct {
+	Mode    MarginMode   `json:"mode"`
+	Percent float64      `json:"percent,omitempty"`
+	CPM     float64      `json:"cpm,omitempty"`
+	Tiers   []MarginTier `json:"tiers,omitempty"`
+
+	percent money.Ratio
+	cpm     money.Micros
+}
+
+// MarginRule - маржа для сегмента. Пустое поле означает "любое значение".
+type MarginRule struct {
+	ID      string `json:"id"`
+	SSP     string `json:"ssp,omitempty"`
+	DSP     string `json:"dsp,omitempty"`
+	Deal    string `json:"deal,omitempty"`
+	Country string `json:"country,omitempty"`
+	Margin  Margin `json:"margin"`
+}
+
+type MarginPolicyConfig struct {
+	Version string       `json:"version"`
+	Default Margin       `json:"default"`
+	Rules   []MarginRule `json:"rules"`
+}
+
+// MarginKey - признаки аукциона, по которым выбирается маржа
+type MarginKey struct {
+	SSP     string
+	DSP     string
+	Deal    string
+	Country string
+}
+
+// AppliedMargin фиксирует маржу, применённую к победителю импрессии.
+// Всегда DspPrice == Price + Margin.
+type AppliedMargin struct {
+	ImpID    string       `json:"impId"`
+	RuleID   string       `json:"ruleId,omitempty"`
+	Mode     MarginMode   `json:"mode"`
+	DspPrice money.Micros `json:"dspPriceMicros"`
+	Price    money.Micros `json:"priceMicros"`
+	Margin   money.Micros `json:"marginMicros"`
+	Cur      string       `json:"cur"`
+	Seat     string       `json:"seat,omitempty"`
+	// true, если маржа урезана, чтобы цена не опустилась ниже флора
+	Capped bool `json:"capped,omitempty"`
+	// Часть маржи, полученная шейдингом, уже входит в Margin
+	Shading       money.Micros  `json:"shadingMicros,omitempty"`
+	ShadingBucket ShadingBucket `json:"shadingBucket,omitempty"`
+}
+
+// MarginPolicy выбирает самое точное правило, при равной точности - первое в файле
+type MarginPolicy struct {
+	config MarginPolicyConfig
+}
+
+// NewMarginPolicy без файла правил применяет ко всем аукционам defaultPercent
+func NewMarginPolicy(path string, defaultPercent float32) (*MarginPolicy, error) {
+	config := MarginPolicyConfig{
+		Version: "1.0",
+		Default: Margin{
+			Mode:    MARGIN_MODE_PERCENT,
+			Percent: money.FromFloat32(defaultPercent).Float64(),
+		},
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid margin policy file %s: %v", path, err)
+		}
+	}
+
+	if err := validateMarginPolicy(&config); err != nil {
+		return nil, fmt.Errorf("margin policy validation failed: %v", err)
+	}
+
+	return &MarginPolicy{config: config}, nil
+}
+
+func (p *MarginPolicy) Lookup(key MarginKey) (string, Margin) {
+	var (
+		bestID    string
+		best      = p.config.Default
+		bestScore = 0
+	)
+
+	for _, rule := range p.config.Rules {
+		score, ok := rule.match(key)
+		if ok && score > bestScore {
+			bestID, best, bestScore = rule.ID, rule.Margin, score
+		}
+	}
+
+	return bestID, best
+}
+
+// Apply считает цену для SSP по цене DSP. Если цена с маржой опускается ниже флора,
+// маржа урезается ровно до флора.
+func (p *MarginPolicy) Apply(key MarginKey, dspPrice, bidFloor money.Micros) (AppliedMargin, error) {
+	if bidFloor < 0 {
+		bidFloor = 0
+	}
+	if dspPrice < bidFloor {
+		return AppliedMargin{}, fmt.Errorf("DSP price %s is lower than bid floor %s", dspPrice, bidFloor)
+	}
+
+	ruleID, margin := p.Lookup(key)
+
+	amount := margin.amount(dspPrice)
+	if amount < 0 {
+		amount = 0
+	}
+	if amount > dspPrice {
+		amount = dspPrice
+	}
+
+	applied := AppliedMargin{
+		RuleID:   ruleID,
+		Mode:     margin.Mode,
+		DspPrice: dspPrice,
+		Price:    dspPrice - amount,
+		Margin:   amount,
+	}
+	if applied.Price < bidFloor {
+		applied.Price = bidFloor
+		applied.Margin = dspPrice - bidFloor
+		applied.Capped = true
+	}
+
+	return applied, nil
+}
+
+// amount округляет маржу к нулю: дробные micros остаются у SSP
+func (m Margin) amount(dspPrice money.Micros) money.Micros {
+	switch m.Mode {
+	case MARGIN_MODE_CPM:
+		return m.cpm
+	case MARGIN_MODE_TIERED:
+		// Тиры отсортированы по возрастанию From при загрузке
+		percent := money.Ratio(0)
+		for _, tier := range m.Tiers {
+			if dspPrice < tier.from {
+				break
+			}
+			percent = tier.percent
+		}
+		return dspPrice.MulRatio(percent, money.ROUND_DOWN)
+	default:
+		return dspPrice.MulRatio(m.percent, money.ROUND_DOWN)
+	}
+}
+
+func (r *MarginRule) match(key MarginKey) (int, bool) {
+	score := 0
+	for _, field := range [][2]string{
+		{r.SSP, key.SSP},
+		{r.DSP, key.DSP},
+		{r.Deal, key.Deal},
+		{r.Country, strings.ToUpper(key.Country)},
+	} {
+		if field[0] == "" {
+			continue
+		}
+		if field[0] != field[1] {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+func validateMarginPolicy(config *MarginPolicyConfig) error {
+	if err := validateMargin(&config.Default); err != nil {
+		return fmt.Errorf("default: %v", err)
+	}
+
+	seen := make(map[string]struct{}, len(config.Rules))
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.ID == "" {
+			return fmt.Errorf("rule #%d has no id", i)
+		}
+		if _, ok := seen[rule.ID]; ok {
+			return fmt.Errorf("duplicate rule id %s", rule.ID)
+		}
+		seen[rule.ID] = struct{}{}
+
+		if rule.SSP == "" && rule.DSP == "" && rule.Deal == "" && rule.Country == "" {
+			return fmt.Errorf("rule %s has no conditions, use default instead", rule.ID)
+		}
+		rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+
+		if err := validateMargin(&rule.Margin); err != nil {
+			return fmt.Errorf("rule %s: %v", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func validateMargin(margin *Margin) error {
+	switch margin.Mode {
+	case "":
+		margin.Mode = MARGIN_MODE_PERCENT
+		fallthrough
+	case MARGIN_MODE_PERCENT:
+		if margin.Percent < 0 || margin.Percent > 1 {
+			return fmt.Errorf("percent must be in [0, 1], got %v", margin.Percent)
+		}
+		margin.percent = money.RatioFromFloat64(margin.Percent)
+	case MARGIN_MODE_CPM:
+		if margin.CPM < 0 {
+			return fmt.Errorf("cpm must not be negative, got %v", margin.CPM)
+		}
+		margin.cpm = money.FromFloat64(margin.CPM)
+	case MARGIN_MODE_TIERED:
+		if len(margin.Tiers) == 0 {
+			return fmt.Errorf("tiered margin has no tiers")
+		}
+		for i := range margin.Tiers {
+			tier := &margin.Tiers[i]
+			if tier.Percent < 0 || tier.Percent > 1 {
+				return fmt.Errorf("tier percent must be in [0, 1], got %v", tier.Percent)
+			}
+			tier.from = money.FromFloat64(tier.From)
+			tier.percent = money.RatioFromFloat64(tier.Percent)
+		}
+		sort.Slice(margin.Tiers, func(i, j int) bool {
+			return margin.Tiers[i].From < margin.Tiers[j].From
+		})
+	default:
+		return fmt.Errorf("unknown margin mode %s", margin.Mode)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package bidEngine
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+func newTestMarginPolicy(t *testing.T, rules ...MarginRule) *MarginPolicy {
+	config := MarginPolicyConfig{
+		Default: Margin{Mode: MARGIN_MODE_PERCENT, Percent: 0.3},
+		Rules:   rules,
+	}
+	require.NoError(t, validateMarginPolicy(&config))
+	return &MarginPolicy{config: config}
+}
+
+func TestMarginPolicyApply(t *testing.T) {
+	policy := newTestMarginPolicy(t,
+		MarginRule{ID: "ssp_revshare", SSP: "ssp1", Margin: Margin{Mode: MARGIN_MODE_PERCENT, Percent: 0.1}},
+		MarginRule{ID: "dsp_fee", DSP: "dsp1", Margin: Margin{Mode: MARGIN_MODE_CPM, CPM: 0.5}},
+		MarginRule{ID: "ssp_dsp_deal", SSP: "ssp1", DSP: "dsp1", Deal: "deal1", Margin: Margin{Mode: MARGIN_MODE_PERCENT}},
+		MarginRule{ID: "us_tiered", Country: "us", Margin: Margin{
+			Mode:  MARGIN_MODE_TIERED,
+			Tiers: []MarginTier{{From: 5, Percent: 0.1}, {From: 0, Percent: 0.2}},
+		}},
+	)
+
+	tests := []struct {
+		name     string
+		key      MarginKey
+		dspPrice money.Micros
+		bidFloor money.Micros
+		expected AppliedMargin
+	}{
+		{
+			name:     "Default percent",
+			key:      MarginKey{SSP: "other"},
+			dspPrice: 10_000_000,
+			expected: AppliedMargin{Mode: MARGIN_MODE_PERCENT, DspPrice: 10_000_000, Price: 7_000_000, Margin: 3_000_000},
+		},
+		{
+			name:     "Fixed CPM",
+			key:      MarginKey{DSP: "dsp1"},
+			dspPrice: 2_000_000,
+			expected: AppliedMargin{RuleID: "dsp_fee", Mode: MARGIN_MODE_CPM, DspPrice: 2_000_000, Price: 1_500_000, Margin: 500_000},
+		},
+		{
+			name:     "Most specific rule wins",
+			key:      MarginKey{SSP: "ssp1", DSP: "dsp1", Deal: "deal1"},
+			dspPrice: 2_000_000,
+			expected: AppliedMargin{RuleID: "ssp_dsp_deal", Mode: MARGIN_MODE_PERCENT, DspPrice: 2_000_000, Price: 2_000_000, Margin: 0},
+		},
+		{
+			name:     "Tier by DSP price",
+			key:      MarginKey{Country: "US"},
+			dspPrice: 5_000_000,
+			expected: AppliedMargin{RuleID: "us_tiered", Mode: MARGIN_MODE_TIERED, DspPrice: 5_000_000, Price: 4_500_000, Margin: 500_000},
+		},
+		{
+			name:     "Margin is capped exactly at bid floor",
+			key:      MarginKey{},
+			dspPrice: 10_000_000,
+			bidFloor: 9_500_000,
+			expected: AppliedMargin{Mode: MARGIN_MODE_PERCENT, DspPrice: 10_000_000, Price: 9_500_000, Margin: 500_000, Capped: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, err := policy.Apply(tt.key, tt.dspPrice, tt.bidFloor)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, applied)
+		})
+	}
+}
+
+func TestMarginPolicyRejectsBidBelowFloor(t *testing.T) {
+	policy := newTestMarginPolicy(t)
+
+	_, err := policy.Apply(MarginKey{}, 1_000_000, 2_000_000)
+	assert.Error(t, err)
+}
+
+func TestMarginPolicyValidation(t *testing.T) {
+	invalid := []MarginPolicyConfig{
+		{Default: Margin{Percent: 1.5}},
+		{Rules: []MarginRule{{ID: "no_conditions"}}},
+		{Rules: []MarginRule{{ID: "a", SSP: "s", Margin: Margin{Mode: "unknown"}}}},
+		{Rules: []MarginRule{{ID: "a", SSP: "s", Margin: Margin{Mode: MARGIN_MODE_TIERED}}}},
+	}
+
+	for _, config := range invalid {
+		assert.Error(t, validateMarginPolicy(&config))
+	}
+}
+
+func TestMarginPolicyConservesMoney(t *testing.T) {
+	policy := newTestMarginPolicy(t,
+		MarginRule{ID: "cpm", DSP: "cpm", Margin: Margin{Mode: MARGIN_MODE_CPM, CPM: 0.123457}},
+		MarginRule{ID: "tiered", DSP: "tiered", Margin: Margin{
+			Mode:  MARGIN_MODE_TIERED,
+			Tiers: []MarginTier{{From: 0, Percent: 0.333333}, {From: 1, Percent: 0.177777}},
+		}},
+	)
+
+	// Цена SSP плюс маржа всегда ровно равна цене DSP, а цена SSP не ниже флора
+	property := func(dspPrice, bidFloor uint32, dsp uint8) bool {
+		key := MarginKey{DSP: []string{"", "cpm", "tiered"}[dsp%3]}
+		price, floor := money.Micros(dspPrice), money.Micros(bidFloor)
+		if floor > price {
+			price, floor = floor, price
+		}
+
+		applied, err := policy.Apply(key, price, floor)
+		return err == nil &&
+			applied.Price+applied.Margin == applied.DspPrice &&
+			applied.Price >= floor &&
+			applied.Margin >= 0
+	}
+	assert.NoError(t, quick.Check(property, nil))
+}
@@ -0,0 +1,241 @@
+package bidEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+type ShadingBucket string
+
+const (
+	// Контрольная группа: цена уходит в SSP без шейдинга
+	SHADING_BUCKET_CONTROL ShadingBucket = "control"
+	SHADING_BUCKET_SHADED  ShadingBucket = "shaded"
+)
+
+const SHADING_PENDING_KEY_PREFIX = "shading_pending:"
+
+type ShadingConfig struct {
+	// Доля аукционов в процентах (0-100), попадающих в бакет с шейдингом
+	TestPercent int
+	// Максимальная доля цены, на которую шейдинг может её снизить
+	MaxShade money.Ratio
+	// Вероятность выигрыша, при которой цена считается достаточной
+	TargetWinRate float64
+	// Минимум ставок в ценовом интервале, чтобы доверять его win rate
+	MinSamples int64
+	// Ширина ценового интервала распределения в валюте аукциона
+	BucketWidth money.Micros
+	// Число интервалов, цены выше попадают в последний
+	Buckets int
+	// После стольких ставок по ключу статистика уменьшается вдвое, чтобы модель старела
+	MaxSamples int64
+	// Сколько ждать billing по отправленной ставке
+	PendingTTL time.Duration
+}
+
+// ShadingKey - разрез, в котором строится распределение цен выигрыша
+type ShadingKey struct {
+	SSP  string
+	Size string
+}
+
+// winCurve - число отправленных ставок и выигрышей по ценовым интервалам
+type winCurve struct {
+	bids  []int64
+	wins  []int64
+	total int64
+}
+
+// ShadingPendingStore хранит отправленные в SSP цены до прихода billing.
+// Хранилище общее для всех реплик bid engine, по умолчанию Redis: billing
+// может прийти не в ту реплику, которая провела аукцион.
+type ShadingPendingStore interface {
+	SavePending(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// TakePending возвращает и удаляет ставку; nil, если её нет или она устарела
+	TakePending(ctx context.Context, key string) ([]byte, error)
+}
+
+// pendingBid - ставка, ждущая billing: её разрез и ценовой интервал
+type pendingBid struct {
+	SSP    string `json:"ssp"`
+	Size   string `json:"size"`
+	Bucket int    `json:"bucket"`
+}
+
+// BidShader учится на отправленных в SSP ценах и billing событиях
+// и снижает цену первой цены аукциона до оценки минимальной выигрывающей.
+// Распределения цен у каждой реплики свои: при равномерной балансировке
+// выигрыши, засчитанные другой репликой, дают ту же оценку win rate.
+type BidShader struct {
+	config ShadingConfig
+	store  ShadingPendingStore
+
+	mu     sync.Mutex
+	curves map[ShadingKey]*winCurve
+}
+
+func NewBidShader(config ShadingConfig, store ShadingPendingStore) (*BidShader, error) {
+	if config.TestPercent < 0 || config.TestPercent > 100 {
+		return nil, fmt.Errorf("shading test percent must be in [0, 100], got %d", config.TestPercent)
+	}
+	if config.MaxShade < 0 || config.MaxShade > money.RATIO_ONE {
+		return nil, fmt.Errorf("max shade must be in [0, 1], got %v", config.MaxShade.Float64())
+	}
+	if config.TargetWinRate <= 0 || config.TargetWinRate > 1 {
+		return nil, fmt.Errorf("target win rate must be in (0, 1], got %v", config.TargetWinRate)
+	}
+	if config.BucketWidth <= 0 || config.Buckets <= 0 {
+		return nil, fmt.Errorf("shading buckets must be positive")
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = 1
+	}
+	if config.PendingTTL <= 0 {
+		config.PendingTTL = 10 * time.Minute
+	}
+
+	return &BidShader{
+		config: config,
+		store:  store,
+		curves: make(map[ShadingKey]*winCurve),
+	}, nil
+}
+
+// Bucket детерминированно относит аукцион к A/B бакету по globalId
+func (s *BidShader) Bucket(globalId string) ShadingBucket {
+	h := fnv.New32a()
+	h.Write([]byte(globalId))
+	if int(h.Sum32()%100) < s.config.TestPercent {
+		return SHADING_BUCKET_SHADED
+	}
+	return SHADING_BUCKET_CONTROL
+}
+
+// Shade снижает цену для SSP до оценки минимальной выигрывающей, но не ниже флора
+// и не больше чем на MaxShade. Снятая с цены сумма уходит в маржу.
+func (s *BidShader) Shade(applied *AppliedMargin, key ShadingKey, bidFloor money.Micros) {
+	s.mu.Lock()
+	estimate, ok := s.curves[key].minWinningPrice(&s.config)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	shaded := max(
+		estimate,
+		bidFloor,
+		applied.Price-applied.Price.MulRatio(s.config.MaxShade, money.ROUND_DOWN),
+	)
+	if shaded >= applied.Price {
+		return
+	}
+
+	shading := applied.Price - shaded
+	applied.Price = shaded
+	applied.Margin += shading
+	applied.Shading = shading
+}
+
+// Record учитывает цену, отправленную в SSP, и запоминает её до прихода billing.
+// Ставка без billing исчезает из хранилища через PendingTTL.
+func (s *BidShader) Record(ctx context.Context, globalId, impId string, key ShadingKey, price money.Micros) {
+	bucket := int(price / s.config.BucketWidth)
+	if bucket >= s.config.Buckets {
+		bucket = s.config.Buckets - 1
+	}
+
+	s.mu.Lock()
+
+	curve := s.curves[key]
+	if curve == nil {
+		curve = &winCurve{
+			bids: make([]int64, s.config.Buckets),
+			wins: make([]int64, s.config.Buckets),
+		}
+		s.curves[key] = curve
+	}
+	curve.bids[bucket]++
+	curve.total++
+	if s.config.MaxSamples > 0 && curve.total > s.config.MaxSamples {
+		curve.decay()
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(pendingBid{SSP: key.SSP, Size: key.Size, Bucket: bucket})
+	if err != nil {
+		log.Printf("Cannot marshal shading bid: %v", err)
+		return
+	}
+	if err := s.store.SavePending(ctx, pendingKey(globalId, impId), data, s.config.PendingTTL); err != nil {
+		log.Printf("Cannot save shading bid of imp %s in auction %s: %v", impId, globalId, err)
+	}
+}
+
+// ReportBilling засчитывает выигрыш ставки; false, если ставка неизвестна или устарела.
+// Повторный billing по той же импрессии ничего не меняет.
+func (s *BidShader) ReportBilling(ctx context.Context, globalId, impId string) (bool, error) {
+	data, err := s.store.TakePending(ctx, pendingKey(globalId, impId))
+	if err != nil || data == nil {
+		return false, err
+	}
+	var bid pendingBid
+	if err := json.Unmarshal(data, &bid); err != nil {
+		return false, fmt.Errorf("invalid shading bid: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Ставку могла записать другая реплика, в распределении этой выигрышей не больше ставок
+	curve := s.curves[ShadingKey{SSP: bid.SSP, Size: bid.Size}]
+	if curve == nil || bid.Bucket < 0 || bid.Bucket >= len(curve.wins) || curve.wins[bid.Bucket] >= curve.bids[bid.Bucket] {
+		return false, nil
+	}
+	curve.wins[bid.Bucket]++
+	return true, nil
+}
+
+// minWinningPrice - верхняя граница первого интервала, где win rate достиг целевого
+func (c *winCurve) minWinningPrice(config *ShadingConfig) (money.Micros, bool) {
+	if c == nil {
+		return 0, false
+	}
+	for i := range c.bids {
+		if c.bids[i] < config.MinSamples {
+			continue
+		}
+		if float64(c.wins[i])/float64(c.bids[i]) >= config.TargetWinRate {
+			return money.Micros(i+1) * config.BucketWidth, true
+		}
+	}
+	return 0, false
+}
+
+func (c *winCurve) decay() {
+	c.total = 0
+	for i := range c.bids {
+		c.bids[i] /= 2
+		c.wins[i] /= 2
+		c.total += c.bids[i]
+	}
+}
+
+func pendingKey(globalId, impId string) string {
+	return SHADING_PENDING_KEY_PREFIX + globalId + ":" + impId
+}
+
+// ShadingSize - размер баннера для ключа шейдинга
+func ShadingSize(w, h int32) string {
+	if w <= 0 || h <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", w, h)
+}
@@ -0,0 +1,162 @@
+package bidEngine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+func newTestBidShader(t *testing.T, store ShadingPendingStore) *BidShader {
+	if store == nil {
+		store = newMemoryCapStore()
+	}
+	shader, err := NewBidShader(ShadingConfig{
+		TestPercent:   100,
+		MaxShade:      money.RatioFromFloat64(0.5),
+		TargetWinRate: 0.8,
+		MinSamples:    10,
+		BucketWidth:   100_000,
+		Buckets:       100,
+		PendingTTL:    time.Minute,
+	}, store)
+	require.NoError(t, err)
+	return shader
+}
+
+// train отправляет count ставок по цене price, из которых wins выигрывают
+func train(shader *BidShader, key ShadingKey, price money.Micros, count, wins int) {
+	for i := 0; i < count; i++ {
+		globalId := fmt.Sprintf("%d-%d", price, i)
+		shader.Record(context.Background(), globalId, "1", key, price)
+		if i < wins {
+			shader.ReportBilling(context.Background(), globalId, "1")
+		}
+	}
+}
+
+func TestBidShaderShade(t *testing.T) {
+	key := ShadingKey{SSP: "ssp1", Size: "300x250"}
+
+	tests := []struct {
+		name     string
+		key      ShadingKey
+		bidFloor money.Micros
+		expected AppliedMargin
+	}{
+		{
+			name:     "Shade to estimated winning price",
+			key:      key,
+			expected: AppliedMargin{DspPrice: 1_000_000, Price: 700_000, Margin: 300_000, Shading: 100_000},
+		},
+		{
+			name:     "Floor limits shading",
+			key:      key,
+			bidFloor: 750_000,
+			expected: AppliedMargin{DspPrice: 1_000_000, Price: 750_000, Margin: 250_000, Shading: 50_000},
+		},
+		{
+			name:     "No statistics for key",
+			key:      ShadingKey{SSP: "ssp2", Size: "300x250"},
+			expected: AppliedMargin{DspPrice: 1_000_000, Price: 800_000, Margin: 200_000},
+		},
+	}
+
+	shader := newTestBidShader(t, nil)
+	// В интервале [0.6, 0.7) выигрывает 90% ставок, ниже - 10%
+	train(shader, key, 650_000, 20, 18)
+	train(shader, key, 550_000, 20, 2)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := AppliedMargin{DspPrice: 1_000_000, Price: 800_000, Margin: 200_000}
+			shader.Shade(&applied, tt.key, tt.bidFloor)
+
+			assert.Equal(t, tt.expected, applied)
+			assert.Equal(t, applied.DspPrice, applied.Price+applied.Margin)
+		})
+	}
+}
+
+func TestBidShaderMaxShade(t *testing.T) {
+	key := ShadingKey{SSP: "ssp1"}
+	shader := newTestBidShader(t, nil)
+	train(shader, key, 50_000, 20, 20)
+
+	applied := AppliedMargin{DspPrice: 1_000_000, Price: 1_000_000}
+	shader.Shade(&applied, key, 0)
+
+	assert.Equal(t, money.Micros(500_000), applied.Price)
+	assert.Equal(t, money.Micros(500_000), applied.Shading)
+}
+
+func TestBidShaderReportBilling(t *testing.T) {
+	ctx := context.Background()
+	key := ShadingKey{SSP: "ssp1"}
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	store := NewRedisPendingStore(client)
+	shader := newTestBidShader(t, store)
+
+	billed := func(globalId string) bool {
+		ok, err := shader.ReportBilling(ctx, globalId, "1")
+		require.NoError(t, err)
+		return ok
+	}
+
+	shader.Record(ctx, "auction1", "1", key, 100_000)
+	assert.True(t, billed("auction1"))
+	assert.False(t, billed("auction1"), "billing counted twice")
+	assert.False(t, billed("unknown"))
+
+	shader.Record(ctx, "auction2", "1", key, 100_000)
+	server.FastForward(2 * time.Minute)
+	assert.False(t, billed("auction2"), "expired bid counted")
+
+	// Billing пришёл в другую реплику: ставка берётся из общего хранилища
+	other := newTestBidShader(t, store)
+	shader.Record(ctx, "auction3", "1", key, 100_000)
+	other.Record(ctx, "auction4", "1", key, 100_000)
+	ok, err := other.ReportBilling(ctx, "auction3", "1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), other.curves[key].wins[1])
+}
+
+func TestBidShaderBucket(t *testing.T) {
+	shader := newTestBidShader(t, nil)
+	shader.config.TestPercent = 50
+
+	shaded := 0
+	for i := 0; i < 10_000; i++ {
+		globalId := fmt.Sprint(i)
+		bucket := shader.Bucket(globalId)
+		assert.Equal(t, bucket, shader.Bucket(globalId), "bucket is not deterministic")
+		if bucket == SHADING_BUCKET_SHADED {
+			shaded++
+		}
+	}
+	assert.InDelta(t, 5_000, shaded, 300)
+}
+
+func TestAuctionConfigShadesOnlyFirstPrice(t *testing.T) {
+	key := ShadingKey{SSP: "ssp1"}
+	shader := newTestBidShader(t, nil)
+	train(shader, key, 50_000, 20, 20)
+	config := &AuctionConfig{Shader: shader}
+
+	applied := AppliedMargin{DspPrice: 1_000_000, Price: 1_000_000}
+	config.shade(&applied, SECOND_PRICE, "auction1", key, 0)
+	assert.Equal(t, money.Micros(1_000_000), applied.Price)
+
+	config.shade(&applied, FIRST_PRICE, "auction1", key, 0)
+	assert.Equal(t, SHADING_BUCKET_SHADED, applied.ShadingBucket)
+	assert.Equal(t, money.Micros(500_000), applied.Price)
+}
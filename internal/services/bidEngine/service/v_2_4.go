@@ -2,124 +2,299 @@ package bidEngine
 
 import (
 	"context"
-	"fmt"
+	"log"
 	"sort"
 
-	"gitlab.com/twinbid-exchange/RTB-exchange/internal/constants"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
 	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
 	pb "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	utils "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/utils_grpc"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
 )
 
 func GetWinnerBidInternal_V_2_4(
 	ctx context.Context,
 	req *bidEngineGrpc.BidEngineRequest_V2_4,
-	profitPercent float32,
+	auctionConfig *AuctionConfig,
 	globalId string,
 	hostname string,
-) (*pb.BidResponse, *pb.BidResponse) {
+) (*pb.BidResponse, *pb.BidResponse, *AuctionEvents) {
+	events := &AuctionEvents{
+		LossNotices: make([]LossNotice, 0),
+	}
+	for _, filtered := range req.FilteredBidResponses {
+		for _, seatBid := range filtered.GetBidResponse().GetSeatbid() {
+			for _, bid := range seatBid.GetBid() {
+				events.LossNotices = appendLossNotice(events.LossNotices, bid.GetLurl(), filteredLossReason(filtered.GetFilterReason()), nil)
+			}
+		}
+	}
+
 	if len(req.BidResponses) == 0 {
 		return &pb.BidResponse{
-				Id: req.BidRequest.Id,
-				Seatbid: &pb.SeatBid{
-					Bid: []*pb.Bid{},
-				},
-			}, &pb.BidResponse{
-				Id: req.BidRequest.Id,
-				Seatbid: &pb.SeatBid{
-					Bid: []*pb.Bid{},
-				},
-			}
+			Id:      req.BidRequest.Id,
+			Seatbid: []*pb.SeatBid{},
+		}, &pb.BidResponse{
+			Id:      req.BidRequest.Id,
+			Seatbid: []*pb.SeatBid{},
+		}, events
 	}
 
-	impBids := make(map[string][]*pb.Bid)
-	for _, bidResponse := range req.BidResponses {
-		if bidResponse == nil || bidResponse.Seatbid == nil {
+	auctionCur := auctionConfig.auctionCurrency()
+	responseCur := auctionConfig.responseCurrency(req.BidRequest.GetCur())
+
+	// Цены из протокола переводятся в micros здесь и обратно только в ответе
+	impFloors := make(map[string]money.Micros, len(req.BidRequest.Imp))
+	impSizes := make(map[string]string, len(req.BidRequest.Imp))
+	impPmps := make(map[string]impPmp, len(req.BidRequest.Imp))
+	// Аукцион по каждому imp независимый, победители идут в порядке imp запроса
+	impOrder := make([]string, 0, len(req.BidRequest.Imp))
+	for _, imp := range req.BidRequest.Imp {
+		if _, ok := impSizes[imp.GetId()]; ok {
+			log.Printf("Duplicate imp id %s in bid request %s", imp.GetId(), req.BidRequest.GetId())
 			continue
 		}
-		for _, bid := range bidResponse.Seatbid.Bid {
-			if bid == nil {
+		impOrder = append(impOrder, imp.GetId())
+		impSizes[imp.GetId()] = ShadingSize(imp.GetBanner().GetW(), imp.GetBanner().GetH())
+		impPmps[imp.GetId()] = pmp_V_2_4(imp)
+		bidFloor := money.FromFloat32(imp.GetBidFloor())
+		if bidFloor > 0 {
+			converted, err := auctionConfig.Rates.Convert(bidFloor, imp.GetBidFloorCur(), auctionCur, money.ROUND_UP)
+			if err != nil {
+				log.Printf("Cannot convert bid floor of imp %s: %v", imp.GetId(), err)
 				continue
 			}
-			impID := bid.GetImpid()
-			if impID == "" {
-				continue
+			bidFloor = converted
+		}
+		impFloors[imp.GetId()] = bidFloor
+	}
+
+	capUser := CapUser(
+		req.BidRequest.GetUser().GetId(),
+		req.BidRequest.GetDevice().GetIp(),
+		req.BidRequest.GetDevice().GetUa(),
+	)
+	impBids := make(map[string][]rankedBid[*pb.Bid])
+	// Число ставок в каждом seatbid с group=1
+	groupSizes := make([]int, 0)
+	var reports dealReports
+	for _, dspResponse := range req.BidResponses {
+		bidResponse := dspResponse.GetBidResponse()
+		bidCur := currency.Normalize(bidResponse.GetCur())
+		for _, seatBid := range bidResponse.GetSeatbid() {
+			group := NO_GROUP
+			if seatBid.GetGroup() == SEATBID_GROUP {
+				group = len(groupSizes)
+				groupSizes = append(groupSizes, len(seatBid.GetBid()))
+			}
+
+			for _, bid := range seatBid.GetBid() {
+				if bid == nil {
+					continue
+				}
+				impID := bid.GetImpid()
+				// impSizes есть у каждого imp запроса, impFloors - только у imp с переведённым флором
+				if _, ok := impSizes[impID]; !ok || impID == "" || bid.GetPrice() <= 0 {
+					events.LossNotices = appendLossNotice(events.LossNotices, bid.GetLurl(), LOSS_REASON_INVALID_BID_RESPONSE, nil)
+					continue
+				}
+				price, err := auctionConfig.Rates.Convert(money.FromFloat32(bid.GetPrice()), bidCur, auctionCur, money.ROUND_DOWN)
+				if err != nil {
+					events.LossNotices = appendLossNotice(events.LossNotices, bid.GetLurl(), auctionConfig.conversionLossReason(bidCur), nil)
+					continue
+				}
+				if _, ok := impFloors[impID]; !ok {
+					events.LossNotices = appendLossNotice(events.LossNotices, bid.GetLurl(), LOSS_REASON_INTERNAL_ERROR, nil)
+					continue
+				}
+
+				deal, floor, reason := auctionConfig.checkDeal(
+					impPmps[impID],
+					impFloors[impID],
+					bid.GetDealid(),
+					dspResponse.GetDspId(),
+					seatBid.GetSeat(),
+					price,
+					auctionCur,
+				)
+				reports.bid(impID, bid.GetDealid(), reason == 0 && deal != nil)
+				if reason != 0 {
+					events.LossNotices = appendLossNotice(events.LossNotices, bid.GetLurl(), reason, nil)
+					continue
+				}
+				// По сделке с фиксированной ценой DSP платит флор сделки
+				if deal != nil && deal.IsFixedPrice() {
+					price = floor
+				}
+
+				impBids[impID] = append(impBids[impID], rankedBid[*pb.Bid]{
+					bid:   bid,
+					price: price,
+					cur:   bidCur,
+					dsp:   dspResponse.GetDspId(),
+					deal:  deal,
+					floor: floor,
+					seat:  seatBid.GetSeat(),
+					group: group,
+					caps: auctionConfig.FrequencyCaps.counters(
+						req.SppEndpoint,
+						capUser,
+						dspResponse.GetDspId(),
+						bid.GetAdomain(),
+						bid.GetCrid(),
+					),
+				})
 			}
-			impBids[impID] = append(impBids[impID], bid)
 		}
 	}
 
+	dropCappedBids(ctx, auctionConfig.FrequencyCaps, impBids, events)
+
 	if len(impBids) == 0 {
+		events.Deals = reports.reports
 		return &pb.BidResponse{
-				Id:      req.BidRequest.Id,
-				Seatbid: &pb.SeatBid{Bid: []*pb.Bid{}},
-			}, &pb.BidResponse{
-				Id: req.BidRequest.Id,
-				Seatbid: &pb.SeatBid{
-					Bid: []*pb.Bid{},
-				},
-			}
+			Id:      req.BidRequest.Id,
+			Seatbid: []*pb.SeatBid{},
+		}, &pb.BidResponse{
+			Id:      req.BidRequest.Id,
+			Seatbid: []*pb.SeatBid{},
+		}, events
 	}
 
-	seatBid := &pb.SeatBid{}
-	seatBidByDspPrice := &pb.SeatBid{}
-
-	for impID, bids := range impBids {
+	for _, bids := range impBids {
 		sort.Slice(bids, func(i, j int) bool {
-			return bids[i].GetPrice() > bids[j].GetPrice()
+			return bids[i].outranks(bids[j])
 		})
+	}
+	excludedGroups := resolveGroups(impBids, groupSizes)
 
-		winningBid := bids[0]
+	seatBids := make([]*pb.SeatBid, 0)
+	seatBidsByDspPrice := make([]*pb.SeatBid, 0)
 
-		var bidFloor float32 = 0
-		for _, imp := range req.BidRequest.Imp {
-			if imp.GetId() == impID {
-				bidFloor = imp.GetBidFloor()
-				break
-			}
+	for _, impID := range impOrder {
+		bids, ok := impBids[impID]
+		if !ok {
+			continue
 		}
-
-		finalPrice, _, err := applyPriceConstraintsAndPercent(
-			winningBid.GetPrice(),
-			bidFloor,
-			profitPercent,
+		winner, applied := selectWinner(
+			auctionConfig.Margins,
+			MarginKey{SSP: req.SppEndpoint, Country: req.BidRequest.GetDevice().GetGeo().GetCountry()},
+			bids,
+			excludedGroups,
+			events,
 		)
+		if winner < 0 {
+			continue
+		}
+		winningBid := bids[winner]
+		bidFloor := winningBid.floor
+
+		shadingKey := ShadingKey{SSP: req.SppEndpoint, Size: impSizes[impID]}
+		auctionConfig.shade(&applied, winningBid.auctionType(req.BidRequest.GetAt()), globalId, shadingKey, bidFloor)
+
+		finalPrice, err := auctionConfig.Rates.Convert(applied.Price, auctionCur, responseCur, money.ROUND_HALF_EVEN)
 		if err != nil {
+			log.Printf("Cannot convert price of imp %s to %s: %v", impID, responseCur, err)
+			// Imp не попадает в ответ: победитель и оставшиеся ставки узнают о сбое биржи
+			for _, ranked := range bids[winner:] {
+				events.LossNotices = appendLossNotice(events.LossNotices, ranked.bid.GetLurl(), LOSS_REASON_INTERNAL_ERROR, nil)
+			}
 			continue
 		}
 
-		wrappedNurl := utils.WrapURL(hostname, winningBid.GetNurl(), globalId, utils.NURL)
-		wrappedBurl := utils.WrapURL(hostname, winningBid.GetBurl(), globalId, utils.BURL)
+		applied.ImpID = impID
+		applied.Cur = auctionCur
+		applied.Seat = winningBid.seat
+		events.Margins = append(events.Margins, applied)
+		auctionConfig.recordShading(ctx, &applied, globalId, shadingKey)
+		reports.won(impID, winningBid.dealID(), winningBid.price, auctionCur)
+		if len(winningBid.caps) > 0 {
+			events.Impressions = append(events.Impressions, CapImpression{ImpID: impID, counters: winningBid.caps})
+		}
+
+		for _, ranked := range bids[winner+1:] {
+			reason := lossReason(winningBid, ranked)
+			// Цена клиринга - то, что платит SSP после маржи и шейдинга, а не ставка победителя
+			clearingPrice := auctionConfig.convertOrNil(applied.Price, auctionCur, ranked.cur)
+			events.LossNotices = appendLossNotice(events.LossNotices, ranked.bid.GetLurl(), reason, clearingPrice)
+		}
+
+		// Ставка с неизвестной сделкой выиграла открытый аукцион, SSP сделку не видит
+		var dealID *string
+		if winningBid.deal != nil {
+			dealID = winningBid.bid.Dealid
+		}
+
+		wrappedNurl := utils.WrapURL(hostname, winningBid.bid.GetNurl(), globalId, impID, utils.NURL)
+		wrappedBurl := utils.WrapURL(hostname, winningBid.bid.GetBurl(), globalId, impID, utils.BURL)
+		finalPriceValue := finalPrice.Float32()
 		finalBid := &pb.Bid{
-			Id:    winningBid.Id,
-			Impid: winningBid.Impid,
-			Price: &finalPrice,
-			Adid:  winningBid.Adid,
-			Nurl:  &wrappedNurl,
-			Burl:  &wrappedBurl,
+			Id:     winningBid.bid.Id,
+			Impid:  winningBid.bid.Impid,
+			Price:  &finalPriceValue,
+			Adid:   winningBid.bid.Adid,
+			Nurl:   &wrappedNurl,
+			Burl:   &wrappedBurl,
+			Dealid: dealID,
 		}
 
+		dspPrice := winningBid.price.Float32()
 		bidByDspPrice := &pb.Bid{
-			Id:    winningBid.Id,
-			Impid: winningBid.Impid,
-			Price: winningBid.Price,
+			Id:     winningBid.bid.Id,
+			Impid:  winningBid.bid.Impid,
+			Price:  &dspPrice,
+			Dealid: dealID,
 		}
 
-		seatBid.Bid = append(seatBid.Bid, finalBid)
-		seatBidByDspPrice.Bid = append(seatBidByDspPrice.Bid, bidByDspPrice)
+		seatBids = appendToSeat_V_2_4(seatBids, winningBid.seat, finalBid)
+		seatBidsByDspPrice = appendToSeat_V_2_4(seatBidsByDspPrice, winningBid.seat, bidByDspPrice)
 	}
 
 	bidResponse := &pb.BidResponse{
 		Id:      req.BidRequest.Id,
-		Seatbid: seatBid,
+		Seatbid: seatBids,
+		Cur:     &responseCur,
 	}
 
 	bidResponseByDspPrice := &pb.BidResponse{
 		Id:      req.BidRequest.Id,
-		Seatbid: seatBidByDspPrice,
+		Seatbid: seatBidsByDspPrice,
+		Cur:     &auctionCur,
 	}
 
-	return bidResponse, bidResponseByDspPrice
+	events.Deals = reports.reports
+	return bidResponse, bidResponseByDspPrice, events
+}
+
+// appendToSeat_V_2_4 кладёт ставку победителя в seatbid его места
+func appendToSeat_V_2_4(seatBids []*pb.SeatBid, seat string, bid *pb.Bid) []*pb.SeatBid {
+	for _, seatBid := range seatBids {
+		if seatBid.GetSeat() == seat {
+			seatBid.Bid = append(seatBid.Bid, bid)
+			return seatBids
+		}
+	}
+
+	seatBid := &pb.SeatBid{Bid: []*pb.Bid{bid}}
+	if seat != "" {
+		seatBid.Seat = &seat
+	}
+	return append(seatBids, seatBid)
+}
+
+func pmp_V_2_4(imp *pb.Imp) impPmp {
+	pmp := impPmp{
+		private: imp.GetPmp().GetPrivateAuction() == PRIVATE_AUCTION,
+		deals:   make(map[string]impDeal, len(imp.GetPmp().GetDeals())),
+	}
+	for _, deal := range imp.GetPmp().GetDeals() {
+		pmp.deals[deal.GetId()] = impDeal{
+			floor:    money.FromFloat32(deal.GetBidfloor()),
+			floorCur: deal.GetBidfloorcur(),
+			wseat:    deal.GetWseat(),
+		}
+	}
+	return pmp
 }
 
 func determineAuctionPrice(
@@ -135,47 +310,3 @@ func determineAuctionPrice(
 		return maxPrice
 	}
 }
-
-func applyPriceConstraintsAndPercent(dspPrice, bidFloor, profitPercent float32) (
-	finalDspPrice float32,
-	finalProfitPercent float32,
-	err error,
-) {
-	if bidFloor == constants.NEGATIVE_BIDFLOOR {
-		bidFloor = constants.ZERO_BIDFLOOR
-	}
-
-	if dspPrice < bidFloor {
-		return 0, 0, fmt.Errorf("DSP Price is lower thand bid floor")
-	}
-
-	finalDspPrice = dspPrice - dspPrice*profitPercent
-	finalProfitPercent = profitPercent
-
-	if finalDspPrice < bidFloor {
-		finalDspPrice, finalProfitPercent = findGoodPriceViaPercent(
-			dspPrice,
-			bidFloor,
-			profitPercent,
-		)
-	}
-
-	return finalDspPrice, finalProfitPercent, nil
-}
-
-func findGoodPriceViaPercent(
-	DspPrice, bidFloor, profitPercent float32,
-) (
-	finalDspPrice float32,
-	finalProfitPercent float32,
-) {
-	finalDspPrice = -1
-	for finalProfitPercent = profitPercent; finalProfitPercent >= 0; finalProfitPercent = finalProfitPercent - EPS {
-		finalDspPrice = DspPrice - DspPrice*finalProfitPercent
-		if finalDspPrice >= bidFloor {
-			break
-		}
-	}
-
-	return finalDspPrice, finalProfitPercent
-}
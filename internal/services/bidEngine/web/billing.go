@@ -0,0 +1,28 @@
+package bidEngineWeb
+
+import (
+	"context"
+	"log"
+
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+)
+
+// ReportBilling засчитывает выигрыш ставки в статистику шейдинга и в счётчики
+// частотных ограничений
+func (s *Server) ReportBilling(
+	ctx context.Context,
+	req *bidEngineGrpc.BillingEvent,
+) (*bidEngineGrpc.BillingEventAck, error) {
+	if s.auctionConfig == nil {
+		return &bidEngineGrpc.BillingEventAck{}, nil
+	}
+	if s.auctionConfig.Shader != nil {
+		if _, err := s.auctionConfig.Shader.ReportBilling(ctx, req.GetGlobalId(), req.GetImpId()); err != nil {
+			log.Printf("Cannot count billing of imp %s in auction %s for bid shading: %v", req.GetImpId(), req.GetGlobalId(), err)
+		}
+	}
+	if err := s.auctionConfig.FrequencyCaps.ReportBilling(ctx, req.GetGlobalId(), req.GetImpId()); err != nil {
+		log.Printf("Cannot count billing of imp %s in auction %s for frequency caps: %v", req.GetImpId(), req.GetGlobalId(), err)
+	}
+	return &bidEngineGrpc.BillingEventAck{}, nil
+}
@@ -14,58 +14,62 @@ import (
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 	utils "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/utils_grpc"
+	bidEngine "gitlab.com/twinbid-exchange/RTB-exchange/internal/services/bidEngine/service"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type Server struct {
-	ProfitPercent float32
+	auctionConfig *bidEngine.AuctionConfig
 	redisClient   *redis.Client
 	timeout       time.Duration
 	hostname      string
+	lossNotifier  *bidEngine.LossNotifier
 
 	GetWinnerBidInternal_V_2_4 func(
 		ctx context.Context,
 		req *bidEngineGrpc.BidEngineRequest_V2_4,
-		profitPercent float32,
+		auctionConfig *bidEngine.AuctionConfig,
 		globalId string,
 		hostname string,
-	) (*ortb_V2_4.BidResponse, *ortb_V2_4.BidResponse)
+	) (*ortb_V2_4.BidResponse, *ortb_V2_4.BidResponse, *bidEngine.AuctionEvents)
 
 	GetWinnerBidInternal_V_2_5 func(
 		ctx context.Context,
 		req *bidEngineGrpc.BidEngineRequest_V2_5,
-		profitPercent float32,
+		auctionConfig *bidEngine.AuctionConfig,
 		globalId string,
 		hostname string,
-	) (*ortb_V2_5.BidResponse, *ortb_V2_5.BidResponse)
+	) (*ortb_V2_5.BidResponse, *ortb_V2_5.BidResponse, *bidEngine.AuctionEvents)
 
 	pb.BidEngineServiceServer
 }
 
 func NewServer(
-	ProfitPercent float32,
+	auctionConfig *bidEngine.AuctionConfig,
 	redisClient *redis.Client,
 	hostname string,
+	lossNotifier *bidEngine.LossNotifier,
 	GetWinnerBidInternal_V_2_4 func(
 		ctx context.Context,
 		req *bidEngineGrpc.BidEngineRequest_V2_4,
-		profitPercent float32,
+		auctionConfig *bidEngine.AuctionConfig,
 		globalId string,
 		hostname string,
-	) (*ortb_V2_4.BidResponse, *ortb_V2_4.BidResponse),
+	) (*ortb_V2_4.BidResponse, *ortb_V2_4.BidResponse, *bidEngine.AuctionEvents),
 	GetWinnerBidInternal_V_2_5 func(
 		ctx context.Context,
 		req *bidEngineGrpc.BidEngineRequest_V2_5,
-		profitPercent float32,
+		auctionConfig *bidEngine.AuctionConfig,
 		globalId string,
 		hostname string,
-	) (*ortb_V2_5.BidResponse, *ortb_V2_5.BidResponse),
+	) (*ortb_V2_5.BidResponse, *ortb_V2_5.BidResponse, *bidEngine.AuctionEvents),
 ) *Server {
 	return &Server{
-		ProfitPercent:              ProfitPercent,
+		auctionConfig:              auctionConfig,
 		redisClient:                redisClient,
 		hostname:                   hostname,
+		lossNotifier:               lossNotifier,
 		GetWinnerBidInternal_V_2_4: GetWinnerBidInternal_V_2_4,
 		GetWinnerBidInternal_V_2_5: GetWinnerBidInternal_V_2_5,
 	}
@@ -81,42 +85,96 @@ func (s *Server) GetWinnerBid_V2_4(
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetWinnerBid_V2_5: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 
 			grpcCode := codes.Internal
 
 			resp = nil
-			funcErr = status.Errorf(grpcCode, err.Error())
+			funcErr = status.Error(grpcCode, err.Error())
 		}
 	}()
 
-	bidResponse, bidResponseByDspPrice := s.GetWinnerBidInternal_V_2_4(
+	bidResponse, bidResponseByDspPrice, events := s.GetWinnerBidInternal_V_2_4(
 		ctx,
 		req,
-		s.ProfitPercent,
+		s.auctionConfig,
 		req.GlobalId,
 		s.hostname,
 	)
+	s.handleAuctionEvents(ctx, req.GlobalId, req.GetFloorDecisions(), events)
 
 	data, err := json.Marshal(bidResponse)
 	if err != nil {
-		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %v", err)
 	}
 
 	if err := utils.WriteJsonToRedis(ctx, s.redisClient, req.GlobalId, constants.BID_RESPONSE_WINNER_COLUMN, data); err != nil {
-		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER in GetWinnerBidInternal: %v", err)
 	}
 
 	dataByDspPrice, err := json.Marshal(bidResponseByDspPrice)
 	if err != nil {
-		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %v", err)
 	}
 
 	if err := utils.WriteJsonToRedis(ctx, s.redisClient, req.GlobalId, constants.BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN, dataByDspPrice); err != nil {
-		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN in GetWinnerBidInternal: %v", err)
 	}
 
 	return &bidEngineGrpc.BidEngineResponse_V2_4{
 		BidResponse: bidResponse,
 	}, nil
 }
+
+func (s *Server) handleAuctionEvents(
+	ctx context.Context,
+	globalId string,
+	floorDecisions []*bidEngineGrpc.FloorDecision,
+	events *bidEngine.AuctionEvents,
+) {
+	// Флоры выбраны до аукциона и пишутся, даже если событий аукциона нет
+	if len(floorDecisions) > 0 {
+		data, err := json.Marshal(floorDecisions)
+		if err != nil {
+			fmt.Printf("failed to marshal floor decisions: %v", err)
+		}
+
+		if err := utils.WriteJsonToRedis(ctx, s.redisClient, globalId, constants.FLOOR_SOURCE_COLUMN, data); err != nil {
+			fmt.Printf("failed to WriteJsonToRedis FLOOR_SOURCE_COLUMN: %v", err)
+		}
+	}
+
+	if events == nil {
+		return
+	}
+
+	if s.lossNotifier != nil && len(events.LossNotices) > 0 {
+		s.lossNotifier.Notify(events.LossNotices)
+	}
+
+	if len(events.Margins) > 0 {
+		data, err := json.Marshal(events.Margins)
+		if err != nil {
+			fmt.Printf("failed to marshal applied margins: %v", err)
+		}
+
+		if err := utils.WriteJsonToRedis(ctx, s.redisClient, globalId, constants.MARGIN_COLUMN, data); err != nil {
+			fmt.Printf("failed to WriteJsonToRedis MARGIN_COLUMN: %v", err)
+		}
+	}
+
+	if len(events.Deals) > 0 {
+		data, err := json.Marshal(events.Deals)
+		if err != nil {
+			fmt.Printf("failed to marshal deal reports: %v", err)
+		}
+
+		if err := utils.WriteJsonToRedis(ctx, s.redisClient, globalId, constants.DEALS_COLUMN, data); err != nil {
+			fmt.Printf("failed to WriteJsonToRedis DEALS_COLUMN: %v", err)
+		}
+	}
+
+	if len(events.Impressions) > 0 {
+		s.auctionConfig.FrequencyCaps.SavePending(ctx, globalId, events.Impressions)
+	}
+}
@@ -28,38 +28,39 @@ func (s *Server) GetWinnerBid_V2_5(
 
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetWinnerBid_V2_5: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 
 			grpcCode := codes.Internal
 
 			resp = nil
-			funcErr = status.Errorf(grpcCode, err.Error())
+			funcErr = status.Error(grpcCode, err.Error())
 		}
 	}()
-	bidResponse, bidResponseByDspPrice := s.GetWinnerBidInternal_V_2_5(
+	bidResponse, bidResponseByDspPrice, events := s.GetWinnerBidInternal_V_2_5(
 		ctx,
 		req,
-		s.ProfitPercent,
+		s.auctionConfig,
 		req.GlobalId,
 		s.hostname,
 	)
+	s.handleAuctionEvents(ctx, req.GlobalId, req.GetFloorDecisions(), events)
 
 	data, err := json.Marshal(bidResponse)
 	if err != nil {
-		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %v", err)
 	}
 
 	if err := utils.WriteJsonToRedis(ctx, s.redisClient, req.GlobalId, constants.BID_RESPONSE_WINNER_COLUMN, data); err != nil {
-		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER in GetWinnerBidInternal: %v", err)
 	}
 
 	dataByDspPrice, err := json.Marshal(bidResponseByDspPrice)
 	if err != nil {
-		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to marshal JSON in GetWinnerBidInternal: %v", err)
 	}
 
 	if err := utils.WriteJsonToRedis(ctx, s.redisClient, req.GlobalId, constants.BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN, dataByDspPrice); err != nil {
-		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN in GetWinnerBidInternal: %w", err)
+		fmt.Printf("failed to WriteJsonToRedis Bid BID_RESPONSE_WINNER_BY_DSP_PRICE_COLUMN in GetWinnerBidInternal: %v", err)
 	}
 
 	return &bidEngineGrpc.BidEngineResponse_V2_5{
@@ -74,7 +74,10 @@ func hasData(record types.StatisticsRecord) bool {
 		record.BID_RESPONSES != "" ||
 		record.BID_RESPONSE_WINNER != "" ||
 		record.BID_RESPONSE_WINNER_BY_DSP_PRICE != "" ||
-		record.SUCCESS != ""
+		record.SUCCESS != "" ||
+		record.FLOOR_SOURCE != "" ||
+		record.MARGIN != "" ||
+		record.DEALS != ""
 }
 
 // Возвращает список непустых полей для логирования
@@ -98,6 +101,15 @@ func getNonEmptyFields(record types.StatisticsRecord) string {
 	if record.SUCCESS != "" {
 		fields = append(fields, "SUCCESS")
 	}
+	if record.FLOOR_SOURCE != "" {
+		fields = append(fields, "FLOOR_SOURCE")
+	}
+	if record.MARGIN != "" {
+		fields = append(fields, "MARGIN")
+	}
+	if record.DEALS != "" {
+		fields = append(fields, "DEALS")
+	}
 	return strings.Join(fields, ", ")
 }
 
@@ -170,6 +182,24 @@ func buildDynamicQuery(record types.StatisticsRecord) ([]string, []string, []int
 		values = append(values, record.SUCCESS)
 	}
 
+	if record.FLOOR_SOURCE != "" {
+		columns = append(columns, "floor_source")
+		placeholders = append(placeholders, "?")
+		values = append(values, record.FLOOR_SOURCE)
+	}
+
+	if record.MARGIN != "" {
+		columns = append(columns, "margin")
+		placeholders = append(placeholders, "?")
+		values = append(values, record.MARGIN)
+	}
+
+	if record.DEALS != "" {
+		columns = append(columns, "deals")
+		placeholders = append(placeholders, "?")
+		values = append(values, record.DEALS)
+	}
+
 	return columns, placeholders, values
 }
 
@@ -182,12 +212,35 @@ func CreateTable(chDB *sql.DB, tableName string) error {
 			bid_responses String,
 			bid_response_winner String,
 			bid_response_winner_by_dsp_price String,
-			success String
+			success String,
+			floor_source String,
+			margin String,
+			deals String
 		) ENGINE = MergeTree()
 		ORDER BY uuid
 		SETTINGS index_granularity = 8192
 	`, tableName))
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Колонки, добавленные после создания таблицы
+	for _, column := range addedColumns {
+		if _, err := chDB.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s",
+			tableName,
+			column,
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var addedColumns = []string{
+	"floor_source String",
+	"margin String",
+	"deals String",
 }
 
 // Остальные функции без изменений...
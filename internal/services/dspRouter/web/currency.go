@@ -0,0 +1,255 @@
+package dspRouterWeb
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/taxonomy"
+	"google.golang.org/protobuf/proto"
+)
+
+// Для DSP без настроенной валюты запрос уходит как есть
+const noDspCurrency = ""
+
+// payloadKey - DSP с одинаковым ключом получают один и тот же запрос
+type payloadKey struct {
+	cur string
+	// В запросе есть PMP, deals - доступные DSP сделки
+	pmp   bool
+	deals string
+	// Индексы imp, прошедших фильтр DSP; пусто - все imp
+	imps string
+	// Таксономия категорий DSP; пусто - каноническая
+	taxonomy taxonomy.Taxonomy
+	// uid пользователя в DSP; пусто - пользователь с DSP не синхронизирован
+	buyeruid string
+}
+
+// bidRequestPayloads_V2_4 лениво сериализует запрос для каждой DSP:
+// флоры переводятся в валюту DSP, cur ограничивается ею же,
+// из PMP остаются только сделки, к которым DSP допущена,
+// из imp остаются только прошедшие фильтр DSP,
+// категории переводятся в таксономию DSP,
+// а в user.buyeruid подставляется uid пользователя в DSP.
+// nil означает, что запрос для DSP собрать не удалось или отправлять нечего.
+func (s *Server) bidRequestPayloads_V2_4(
+	bidRequest *ortb_V2_4.BidRequest,
+	original []byte,
+	endpoints []string,
+	offered map[string]*offeredImps,
+	buyerUIDs map[string]string,
+) map[string]func() []byte {
+	withPmp := hasPmp_V2_4(bidRequest)
+	cache := make(map[payloadKey]func() []byte)
+	payloads := make(map[string]func() []byte, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		key := payloadKey{
+			cur:      s.dspCurrency(endpoint),
+			taxonomy: s.dspTaxonomies[endpoint],
+			buyeruid: buyerUIDs[endpoint],
+		}
+		var allowed map[string]struct{}
+		if withPmp {
+			allowed, key.deals = s.allowedDeals_V2_4(bidRequest, endpoint)
+			key.pmp = true
+		}
+		imps := offered[endpoint]
+		if imps != nil {
+			key.imps = imps.key
+		}
+
+		payload, ok := cache[key]
+		if !ok {
+			payload = s.bidRequestPayload_V2_4(bidRequest, original, key, allowed, imps)
+			cache[key] = payload
+		}
+		payloads[endpoint] = payload
+	}
+
+	return payloads
+}
+
+func (s *Server) bidRequestPayload_V2_4(
+	bidRequest *ortb_V2_4.BidRequest,
+	original []byte,
+	key payloadKey,
+	allowed map[string]struct{},
+	imps *offeredImps,
+) func() []byte {
+	if key == (payloadKey{cur: noDspCurrency}) {
+		return func() []byte { return original }
+	}
+
+	return sync.OnceValue(func() []byte {
+		converted := proto.Clone(bidRequest).(*ortb_V2_4.BidRequest)
+		if key.pmp && !s.filterDeals_V2_4(converted, key.cur, allowed) {
+			return nil
+		}
+		if imps != nil && !keepImps_V2_4(converted, imps) {
+			return nil
+		}
+		if key.cur != noDspCurrency && !s.convertFloors_V2_4(converted, key.cur) {
+			return nil
+		}
+		if key.taxonomy != "" {
+			s.translateCategories_V2_4(converted, key.taxonomy)
+		}
+		if key.buyeruid != "" {
+			setBuyerUID_V2_4(converted, key.buyeruid)
+		}
+
+		data, err := json.Marshal(converted)
+		if err != nil {
+			log.Printf("Cannot marshal bid request for DSP: %v", err)
+			return nil
+		}
+		return data
+	})
+}
+
+func (s *Server) convertFloors_V2_4(bidRequest *ortb_V2_4.BidRequest, cur string) bool {
+	bidRequest.Cur = []string{cur}
+	for _, imp := range bidRequest.Imp {
+		if imp == nil || imp.GetBidFloor() <= 0 {
+			continue
+		}
+		// Флор округляется вверх, чтобы DSP не увидела его ниже исходного
+		bidFloor, err := s.rates.Convert(
+			money.FromFloat32(imp.GetBidFloor()),
+			imp.GetBidFloorCur(),
+			cur,
+			money.ROUND_UP,
+		)
+		if err != nil {
+			log.Printf("Cannot convert bid floor to %s: %v", cur, err)
+			return false
+		}
+		converted := bidFloor.Float32()
+		imp.BidFloor = &converted
+		imp.BidFloorCur = &cur
+	}
+	return true
+}
+
+func (s *Server) bidRequestPayloads_V2_5(
+	bidRequest *ortb_V2_5.BidRequest,
+	original []byte,
+	endpoints []string,
+	offered map[string]*offeredImps,
+	buyerUIDs map[string]string,
+) map[string]func() []byte {
+	withPmp := hasPmp_V2_5(bidRequest)
+	cache := make(map[payloadKey]func() []byte)
+	payloads := make(map[string]func() []byte, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		key := payloadKey{
+			cur:      s.dspCurrency(endpoint),
+			taxonomy: s.dspTaxonomies[endpoint],
+			buyeruid: buyerUIDs[endpoint],
+		}
+		var allowed map[string]struct{}
+		if withPmp {
+			allowed, key.deals = s.allowedDeals_V2_5(bidRequest, endpoint)
+			key.pmp = true
+		}
+		imps := offered[endpoint]
+		if imps != nil {
+			key.imps = imps.key
+		}
+
+		payload, ok := cache[key]
+		if !ok {
+			payload = s.bidRequestPayload_V2_5(bidRequest, original, key, allowed, imps)
+			cache[key] = payload
+		}
+		payloads[endpoint] = payload
+	}
+
+	return payloads
+}
+
+func (s *Server) bidRequestPayload_V2_5(
+	bidRequest *ortb_V2_5.BidRequest,
+	original []byte,
+	key payloadKey,
+	allowed map[string]struct{},
+	imps *offeredImps,
+) func() []byte {
+	if key == (payloadKey{cur: noDspCurrency}) {
+		return func() []byte { return original }
+	}
+
+	return sync.OnceValue(func() []byte {
+		converted := proto.Clone(bidRequest).(*ortb_V2_5.BidRequest)
+		if key.pmp && !s.filterDeals_V2_5(converted, key.cur, allowed) {
+			return nil
+		}
+		if imps != nil && !keepImps_V2_5(converted, imps) {
+			return nil
+		}
+		if key.cur != noDspCurrency && !s.convertFloors_V2_5(converted, key.cur) {
+			return nil
+		}
+		if key.taxonomy != "" {
+			s.translateCategories_V2_5(converted, key.taxonomy)
+		}
+		if key.buyeruid != "" {
+			setBuyerUID_V2_5(converted, key.buyeruid)
+		}
+
+		data, err := jsoniter.Marshal(converted)
+		if err != nil {
+			log.Printf("Cannot marshal bid request for DSP: %v", err)
+			return nil
+		}
+		return data
+	})
+}
+
+func (s *Server) convertFloors_V2_5(bidRequest *ortb_V2_5.BidRequest, cur string) bool {
+	bidRequest.Cur = []string{cur}
+	for _, imp := range bidRequest.Imp {
+		if imp == nil || imp.GetBidFloor() <= 0 {
+			continue
+		}
+		// Флор округляется вверх, чтобы DSP не увидела его ниже исходного
+		bidFloor, err := s.rates.Convert(
+			money.FromFloat32(imp.GetBidFloor()),
+			imp.GetBidFloorCur(),
+			cur,
+			money.ROUND_UP,
+		)
+		if err != nil {
+			log.Printf("Cannot convert bid floor to %s: %v", cur, err)
+			return false
+		}
+		converted := bidFloor.Float32()
+		imp.BidFloor = &converted
+		imp.BidFloorCur = &cur
+	}
+	return true
+}
+
+// dspCurrency возвращает валюту DSP или noDspCurrency, если она не настроена
+func (s *Server) dspCurrency(endpoint string) string {
+	if cur, ok := s.dspCurrencies[endpoint]; ok {
+		return cur
+	}
+	return noDspCurrency
+}
+
+func normalizeDspCurrencies(dspCurrencies map[string]string) map[string]string {
+	normalized := make(map[string]string, len(dspCurrencies))
+	for endpoint, cur := range dspCurrencies {
+		normalized[endpoint] = currency.Normalize(cur)
+	}
+	return normalized
+}
@@ -0,0 +1,178 @@
+package dspRouterWeb
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/money"
+)
+
+// Импрессия с private_auction=1 уходит только DSP, допущенным хотя бы к одной её сделке
+const PRIVATE_AUCTION = 1
+
+func hasPmp_V2_4(bidRequest *ortb_V2_4.BidRequest) bool {
+	for _, imp := range bidRequest.Imp {
+		if imp.GetPmp() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedDeals_V2_4 возвращает сделки запроса, к которым допущена DSP,
+// и их список в виде ключа для кеша запросов
+func (s *Server) allowedDeals_V2_4(bidRequest *ortb_V2_4.BidRequest, endpoint string) (map[string]struct{}, string) {
+	allowed := make(map[string]struct{})
+	refs := make([]string, 0)
+	for i, imp := range bidRequest.Imp {
+		for _, deal := range imp.GetPmp().GetDeals() {
+			if _, ok := s.deals.Allowed(deal.GetId(), endpoint); !ok {
+				continue
+			}
+			ref := dealRef(i, deal.GetId())
+			allowed[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	}
+	return allowed, strings.Join(refs, ",")
+}
+
+// filterDeals_V2_4 оставляет в PMP только допущенные сделки с условиями из реестра
+// и убирает приватные импрессии без сделок. false - отправлять DSP нечего.
+func (s *Server) filterDeals_V2_4(bidRequest *ortb_V2_4.BidRequest, cur string, allowed map[string]struct{}) bool {
+	imps := bidRequest.Imp[:0]
+	for i, imp := range bidRequest.Imp {
+		pmp := imp.GetPmp()
+		if pmp == nil {
+			imps = append(imps, imp)
+			continue
+		}
+
+		dealCur := cur
+		if dealCur == noDspCurrency {
+			dealCur = currency.Normalize(imp.GetBidFloorCur())
+		}
+
+		filtered := pmp.Deals[:0]
+		for _, deal := range pmp.Deals {
+			if _, ok := allowed[dealRef(i, deal.GetId())]; !ok {
+				continue
+			}
+			registered, _ := s.deals.Get(deal.GetId())
+			bidFloor, err := s.deals.Floor(
+				registered,
+				money.FromFloat32(deal.GetBidfloor()),
+				deal.GetBidfloorcur(),
+				dealCur,
+			)
+			if err != nil {
+				log.Printf("Cannot convert floor of deal %s to %s: %v", deal.GetId(), dealCur, err)
+				continue
+			}
+			converted := bidFloor.Float32()
+			deal.Bidfloor = &converted
+			deal.Bidfloorcur = &dealCur
+			at := registered.At
+			deal.At = &at
+			filtered = append(filtered, deal)
+		}
+		pmp.Deals = filtered
+
+		if len(filtered) == 0 {
+			if pmp.GetPrivateAuction() == PRIVATE_AUCTION {
+				continue
+			}
+			imp.Pmp = nil
+		}
+		imps = append(imps, imp)
+	}
+	bidRequest.Imp = imps
+
+	return len(imps) > 0
+}
+
+func hasPmp_V2_5(bidRequest *ortb_V2_5.BidRequest) bool {
+	for _, imp := range bidRequest.Imp {
+		if imp.GetPmp() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) allowedDeals_V2_5(bidRequest *ortb_V2_5.BidRequest, endpoint string) (map[string]struct{}, string) {
+	allowed := make(map[string]struct{})
+	refs := make([]string, 0)
+	for i, imp := range bidRequest.Imp {
+		for _, deal := range imp.GetPmp().GetDeals() {
+			if _, ok := s.deals.Allowed(deal.GetId(), endpoint); !ok {
+				continue
+			}
+			ref := dealRef(i, deal.GetId())
+			allowed[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	}
+	return allowed, strings.Join(refs, ",")
+}
+
+func (s *Server) filterDeals_V2_5(bidRequest *ortb_V2_5.BidRequest, cur string, allowed map[string]struct{}) bool {
+	imps := bidRequest.Imp[:0]
+	for i, imp := range bidRequest.Imp {
+		pmp := imp.GetPmp()
+		if pmp == nil {
+			imps = append(imps, imp)
+			continue
+		}
+
+		dealCur := cur
+		if dealCur == noDspCurrency {
+			dealCur = currency.Normalize(imp.GetBidFloorCur())
+		}
+
+		filtered := pmp.Deals[:0]
+		for _, deal := range pmp.Deals {
+			if _, ok := allowed[dealRef(i, deal.GetId())]; !ok {
+				continue
+			}
+			registered, _ := s.deals.Get(deal.GetId())
+			bidFloor, err := s.deals.Floor(
+				registered,
+				money.FromFloat32(deal.GetBidfloor()),
+				deal.GetBidfloorcur(),
+				dealCur,
+			)
+			if err != nil {
+				log.Printf("Cannot convert floor of deal %s to %s: %v", deal.GetId(), dealCur, err)
+				continue
+			}
+			converted := bidFloor.Float32()
+			deal.Bidfloor = &converted
+			deal.Bidfloorcur = &dealCur
+			at := registered.At
+			deal.At = &at
+			filtered = append(filtered, deal)
+		}
+		pmp.Deals = filtered
+
+		if len(filtered) == 0 {
+			if pmp.GetPrivateAuction() == PRIVATE_AUCTION {
+				continue
+			}
+			imp.Pmp = nil
+		}
+		imps = append(imps, imp)
+	}
+	bidRequest.Imp = imps
+
+	return len(imps) > 0
+}
+
+// dealRef ссылается на сделку конкретной импрессии запроса
+func dealRef(impIndex int, dealID string) string {
+	return strconv.Itoa(impIndex) + "/" + dealID
+}
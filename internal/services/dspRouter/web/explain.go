@@ -0,0 +1,71 @@
+package dspRouterWeb
+
+import (
+	"context"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExplainFilter_V2_4 разбирает решение фильтра: для DSP по запросу, для SPP по каждому
+// из ответов DSP. Возвращает JSON массив filter.Explanation.
+func (s *Server) ExplainFilter_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.ExplainFilterRequest_V2_4,
+) (*dspRouterGrpc.JsonResponse, error) {
+	if err := checkExplainTarget(req.GetDspId(), req.GetSppId()); err != nil {
+		return nil, err
+	}
+
+	var explanations []filter.Explanation
+	if req.GetDspId() != "" {
+		if req.GetBidRequest() == nil {
+			return nil, status.Error(codes.InvalidArgument, "bidRequest is required to explain DSP rules")
+		}
+		explanations = append(explanations, s.processor.ExplainRequestForDSPV24(req.GetDspId(), req.GetGlobalId(), req.GetBidRequest()))
+	} else {
+		if len(req.GetBidResponses()) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "bidResponses are required to explain SPP rules")
+		}
+		for _, bidResponse := range req.GetBidResponses() {
+			explanations = append(explanations, s.processor.ExplainResponseForSPPV24(req.GetSppId(), bidResponse))
+		}
+	}
+
+	return jsonResponse(explanations)
+}
+
+func (s *Server) ExplainFilter_V2_5(
+	ctx context.Context,
+	req *dspRouterGrpc.ExplainFilterRequest_V2_5,
+) (*dspRouterGrpc.JsonResponse, error) {
+	if err := checkExplainTarget(req.GetDspId(), req.GetSppId()); err != nil {
+		return nil, err
+	}
+
+	var explanations []filter.Explanation
+	if req.GetDspId() != "" {
+		if req.GetBidRequest() == nil {
+			return nil, status.Error(codes.InvalidArgument, "bidRequest is required to explain DSP rules")
+		}
+		explanations = append(explanations, s.processor.ExplainRequestForDSPV25(req.GetDspId(), req.GetGlobalId(), req.GetBidRequest()))
+	} else {
+		if len(req.GetBidResponses()) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "bidResponses are required to explain SPP rules")
+		}
+		for _, bidResponse := range req.GetBidResponses() {
+			explanations = append(explanations, s.processor.ExplainResponseForSPPV25(req.GetSppId(), bidResponse))
+		}
+	}
+
+	return jsonResponse(explanations)
+}
+
+func checkExplainTarget(dspId, sppId string) error {
+	if (dspId == "") == (sppId == "") {
+		return status.Error(codes.InvalidArgument, "exactly one of dspId and sppId is required")
+	}
+	return nil
+}
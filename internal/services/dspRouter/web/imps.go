@@ -0,0 +1,145 @@
+package dspRouterWeb
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// offeredImps - imp запроса, прошедшие фильтр DSP, когда прошли не все
+type offeredImps struct {
+	ids map[string]struct{}
+	// Индексы imp в виде ключа для кеша запросов
+	key string
+}
+
+func newOfferedImps(indexes []int, impID func(i int) string) *offeredImps {
+	offered := &offeredImps{ids: make(map[string]struct{}, len(indexes))}
+	refs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		offered.ids[impID(i)] = struct{}{}
+		refs = append(refs, strconv.Itoa(i))
+	}
+	offered.key = strings.Join(refs, ",")
+	return offered
+}
+
+// eligibleDsps_V2_4 возвращает DSP, прошедшие фильтр, и для каждой из них imp,
+// которые ей можно отправить. DSP без записи в map получает все imp.
+// sampleRates - доля выборки запроса для DSP, прошедших правила sample.
+func (s *Server) eligibleDsps_V2_4(
+	bidRequest *ortb_V2_4.BidRequest,
+	globalId string,
+	endpoints []string,
+) (eligible []string, offered map[string]*offeredImps, sampleRates map[string]float64) {
+	eligible = make([]string, 0, len(endpoints))
+	offered = make(map[string]*offeredImps)
+
+	for _, endpoint := range endpoints {
+		result := s.processor.ProcessRequestForDSPV24(endpoint, globalId, bidRequest)
+		if !result.Allowed {
+			continue
+		}
+		eligible = append(eligible, endpoint)
+		if result.SampleRate != 0 {
+			if sampleRates == nil {
+				sampleRates = make(map[string]float64)
+			}
+			sampleRates[endpoint] = result.SampleRate
+		}
+		if result.Imps != nil && len(result.Imps) < len(bidRequest.Imp) {
+			offered[endpoint] = newOfferedImps(result.Imps, func(i int) string {
+				return bidRequest.Imp[i].GetId()
+			})
+		}
+	}
+
+	return eligible, offered, sampleRates
+}
+
+// keepImps_V2_4 оставляет в запросе только предложенные DSP imp. false - отправлять нечего.
+func keepImps_V2_4(bidRequest *ortb_V2_4.BidRequest, offered *offeredImps) bool {
+	imps := bidRequest.Imp[:0]
+	for _, imp := range bidRequest.Imp {
+		if _, ok := offered.ids[imp.GetId()]; ok {
+			imps = append(imps, imp)
+		}
+	}
+	bidRequest.Imp = imps
+
+	return len(imps) > 0
+}
+
+// dropUnofferedBids_V2_4 убирает ставки на imp, которые DSP не отправлялись
+func dropUnofferedBids_V2_4(bidResponse *ortb_V2_4.BidResponse, endpoint string, offered *offeredImps) {
+	for _, seatBid := range bidResponse.GetSeatbid() {
+		bids := seatBid.Bid[:0]
+		for _, bid := range seatBid.Bid {
+			if _, ok := offered.ids[bid.GetImpid()]; !ok {
+				log.Printf("DSP %s bid on imp %s which was not offered to it", endpoint, bid.GetImpid())
+				continue
+			}
+			bids = append(bids, bid)
+		}
+		seatBid.Bid = bids
+	}
+}
+
+func (s *Server) eligibleDsps_V2_5(
+	bidRequest *ortb_V2_5.BidRequest,
+	globalId string,
+	endpoints []string,
+) (eligible []string, offered map[string]*offeredImps, sampleRates map[string]float64) {
+	eligible = make([]string, 0, len(endpoints))
+	offered = make(map[string]*offeredImps)
+
+	for _, endpoint := range endpoints {
+		result := s.processor.ProcessRequestForDSPV25(endpoint, globalId, bidRequest)
+		if !result.Allowed {
+			continue
+		}
+		eligible = append(eligible, endpoint)
+		if result.SampleRate != 0 {
+			if sampleRates == nil {
+				sampleRates = make(map[string]float64)
+			}
+			sampleRates[endpoint] = result.SampleRate
+		}
+		if result.Imps != nil && len(result.Imps) < len(bidRequest.Imp) {
+			offered[endpoint] = newOfferedImps(result.Imps, func(i int) string {
+				return bidRequest.Imp[i].GetId()
+			})
+		}
+	}
+
+	return eligible, offered, sampleRates
+}
+
+func keepImps_V2_5(bidRequest *ortb_V2_5.BidRequest, offered *offeredImps) bool {
+	imps := bidRequest.Imp[:0]
+	for _, imp := range bidRequest.Imp {
+		if _, ok := offered.ids[imp.GetId()]; ok {
+			imps = append(imps, imp)
+		}
+	}
+	bidRequest.Imp = imps
+
+	return len(imps) > 0
+}
+
+func dropUnofferedBids_V2_5(bidResponse *ortb_V2_5.BidResponse, endpoint string, offered *offeredImps) {
+	for _, seatBid := range bidResponse.GetSeatbid() {
+		bids := seatBid.Bid[:0]
+		for _, bid := range seatBid.Bid {
+			if _, ok := offered.ids[bid.GetImpid()]; !ok {
+				log.Printf("DSP %s bid on imp %s which was not offered to it", endpoint, bid.GetImpid())
+				continue
+			}
+			bids = append(bids, bid)
+		}
+		seatBid.Bid = bids
+	}
+}
@@ -0,0 +1,190 @@
+package dspRouterWeb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetDSPRules_V2_4 возвращает текущие правила DSP с их ревизией в формате конфига
+func (s *Server) GetDSPRules_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	return jsonResponse(s.ruleManager.Rules(filter.RuleSetDSP))
+}
+
+func (s *Server) GetSPPRules_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	return jsonResponse(s.ruleManager.Rules(filter.RuleSetSPP))
+}
+
+// UpdateDSPRules_V2_4 заменяет правила DSP конфигом целиком. Конфиг с ревизией,
+// отличной от текущей, отклоняется с codes.Aborted: его основа устарела.
+func (s *Server) UpdateDSPRules_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	return s.updateRules(ctx, filter.RuleSetDSP, req)
+}
+
+func (s *Server) UpdateSPPRules_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	return s.updateRules(ctx, filter.RuleSetSPP, req)
+}
+
+func (s *Server) ListRuleVersions(
+	ctx context.Context,
+	req *dspRouterGrpc.RuleVersionsRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	kind, err := ruleSetKind(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(s.ruleManager.Versions(kind))
+}
+
+func (s *Server) DiffRuleVersions(
+	ctx context.Context,
+	req *dspRouterGrpc.RuleVersionsDiffRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	kind, err := ruleSetKind(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := s.ruleManager.DiffVersions(kind, req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, ruleUpdateError(err)
+	}
+	return jsonResponse(diff)
+}
+
+// RollbackRules возвращает правила сохранённой ревизии, откат получает новую ревизию
+func (s *Server) RollbackRules(
+	ctx context.Context,
+	req *dspRouterGrpc.RollbackRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	kind, err := ruleSetKind(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	change := filter.RuleChange{Author: req.GetAuthor(), Comment: req.GetComment()}
+	var revision uint64
+	if s.ruleStore != nil {
+		revision, err = s.ruleStore.Rollback(ctx, kind, req.GetRevision(), change)
+	} else {
+		revision, err = s.ruleManager.Rollback(kind, req.GetRevision(), change)
+	}
+	if err != nil {
+		return nil, ruleUpdateError(err)
+	}
+	log.Printf("%s rules rolled back to revision %d by %q, new revision %d", kind, req.GetRevision(), change.Author, revision)
+
+	return &dspRouterGrpc.UpdateRulesResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("%s rules rolled back to revision %d", kind, req.GetRevision()),
+		Revision: revision,
+	}, nil
+}
+
+// GetRuleSyncStatus возвращает ревизии правил, которые обслуживает эта реплика,
+// в виде JSON filter.RuleSyncStatus
+func (s *Server) GetRuleSyncStatus(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	if s.ruleStore != nil {
+		return jsonResponse(s.ruleStore.Status())
+	}
+	return jsonResponse(s.ruleManager.SyncStatus())
+}
+
+// GetBlocklists возвращает blocklist SPP этой реплики в виде JSON []filter.BlocklistStatus
+func (s *Server) GetBlocklists(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	return jsonResponse(s.ruleManager.Blocklists())
+}
+
+// ReloadBlocklists перечитывает файлы blocklist SPP на этой реплике. Если какой-то
+// файл не прочитан, у его SPP остаётся прежний список, а вызов возвращает ошибку.
+func (s *Server) ReloadBlocklists(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	statuses, err := s.ruleManager.ReloadBlocklists()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot reload blocklists: %v", err)
+	}
+	return jsonResponse(statuses)
+}
+
+func (s *Server) updateRules(ctx context.Context, kind filter.RuleSetKind, req *dspRouterGrpc.JsonRequest) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	var config filter.SimpleRuleConfig
+	if err := json.Unmarshal(req.GetJsonData(), &config); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode %s rules: %v", kind, err)
+	}
+
+	change := filter.RuleChange{Author: req.GetAuthor(), Comment: req.GetComment()}
+	var revision uint64
+	var err error
+	if s.ruleStore != nil {
+		revision, err = s.ruleStore.UpdateRules(ctx, kind, &config, change)
+	} else {
+		revision, err = s.ruleManager.UpdateRules(kind, &config, change)
+	}
+	if err != nil {
+		return nil, ruleUpdateError(err)
+	}
+	log.Printf("%s rules updated via admin API by %q, revision %d", kind, change.Author, revision)
+
+	return &dspRouterGrpc.UpdateRulesResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("%s rules updated", kind),
+		Revision: revision,
+	}, nil
+}
+
+func ruleSetKind(kind string) (filter.RuleSetKind, error) {
+	switch filter.RuleSetKind(kind) {
+	case filter.RuleSetDSP, filter.RuleSetSPP:
+		return filter.RuleSetKind(kind), nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "unknown rule set %q, expected %s or %s", kind, filter.RuleSetDSP, filter.RuleSetSPP)
+	}
+}
+
+func ruleUpdateError(err error) error {
+	switch {
+	case errors.Is(err, filter.ErrStaleRevision):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, filter.ErrUnknownRevision):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, filter.ErrRuleStoreUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+func jsonResponse(v interface{}) (*dspRouterGrpc.JsonResponse, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &dspRouterGrpc.JsonResponse{JsonData: data}, nil
+}
@@ -0,0 +1,80 @@
+package dspRouterWeb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetShadowRules загружает правила-кандидат в режиме shadow. Тело - конфиг в формате
+// файлов правил, в нём могут быть и DSP, и SPP.
+func (s *Server) SetShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	var config filter.SimpleRuleConfig
+	if err := json.Unmarshal(req.GetJsonData(), &config); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode shadow rules: %v", err)
+	}
+
+	if err := s.ruleManager.SetShadowRules(&config); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("Shadow rules loaded via admin API, %d DSPs, %d SPPs", len(config.DSPs), len(config.SPPs))
+
+	return &dspRouterGrpc.UpdateRulesResponse{Success: true, Message: "shadow rules loaded"}, nil
+}
+
+// GetShadowReport возвращает расхождения решений кандидата с активными правилами
+// в виде JSON filter.ShadowReport
+func (s *Server) GetShadowReport(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	report, ok := s.ruleManager.ShadowReport()
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no shadow rules loaded")
+	}
+
+	return jsonResponse(report)
+}
+
+func (s *Server) PromoteShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	change := filter.RuleChange{Author: req.GetAuthor(), Comment: req.GetComment()}
+	var err error
+	if s.ruleStore != nil {
+		err = s.ruleStore.PromoteShadowRules(ctx, change)
+	} else {
+		err = s.ruleManager.PromoteShadowRules(change)
+	}
+	switch {
+	case errors.Is(err, filter.ErrRuleStoreUnavailable):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, filter.ErrStaleRevision):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	log.Println("Shadow rules promoted via admin API")
+
+	return &dspRouterGrpc.UpdateRulesResponse{Success: true, Message: "shadow rules promoted"}, nil
+}
+
+func (s *Server) DropShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	s.ruleManager.DropShadowRules()
+	log.Println("Shadow rules dropped via admin API")
+
+	return &dspRouterGrpc.UpdateRulesResponse{Success: true, Message: "shadow rules dropped"}, nil
+}
@@ -0,0 +1,23 @@
+package dspRouterWeb
+
+import (
+	"context"
+	"log"
+
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+)
+
+// GetFilterStats возвращает счётчики правил фильтра в виде JSON filter.FilterStats.
+// С reset счётчики обнуляются сразу после чтения.
+func (s *Server) GetFilterStats(
+	ctx context.Context,
+	req *dspRouterGrpc.FilterStatsRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	stats := s.processor.Stats()
+	if req.GetReset_() {
+		s.processor.ResetStats()
+		log.Println("Filter stats reset via admin API")
+	}
+
+	return jsonResponse(stats)
+}
@@ -0,0 +1,86 @@
+package dspRouterWeb
+
+import (
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/taxonomy"
+)
+
+// Категории внутри роутера - в канонической таксономии: на них работают правила
+// фильтра и blocklist. Запрос SSP и ответы DSP приводятся к ней при получении,
+// а запрос для DSP переводится в её таксономию вместе с остальным payload.
+
+func (s *Server) normalizeCategories_V2_4(bidRequest *ortb_V2_4.BidRequest, sppEndpoint string) {
+	from := s.sspTaxonomy(sppEndpoint)
+	if site := bidRequest.GetSite(); site != nil {
+		site.Cat = s.taxonomies.Normalize(from, site.Cat)
+	}
+	if app := bidRequest.GetApp(); app != nil {
+		app.Cat = s.taxonomies.Normalize(from, app.Cat)
+	}
+	bidRequest.Bcat = s.taxonomies.Normalize(from, bidRequest.Bcat)
+}
+
+func (s *Server) translateCategories_V2_4(bidRequest *ortb_V2_4.BidRequest, to taxonomy.Taxonomy) {
+	if site := bidRequest.GetSite(); site != nil {
+		site.Cat = s.taxonomies.Translate(to, site.Cat)
+	}
+	if app := bidRequest.GetApp(); app != nil {
+		app.Cat = s.taxonomies.Translate(to, app.Cat)
+	}
+	bidRequest.Bcat = s.taxonomies.Translate(to, bidRequest.Bcat)
+}
+
+func (s *Server) normalizeBidCategories_V2_4(bidResponse *ortb_V2_4.BidResponse, dspEndpoint string) {
+	from := s.dspTaxonomy(dspEndpoint)
+	for _, seatBid := range bidResponse.GetSeatbid() {
+		for _, bid := range seatBid.GetBid() {
+			bid.Cat = s.taxonomies.Normalize(from, bid.Cat)
+		}
+	}
+}
+
+func (s *Server) normalizeCategories_V2_5(bidRequest *ortb_V2_5.BidRequest, sppEndpoint string) {
+	from := s.sspTaxonomy(sppEndpoint)
+	if site := bidRequest.GetSite(); site != nil {
+		site.Cat = s.taxonomies.Normalize(from, site.Cat)
+	}
+	if app := bidRequest.GetApp(); app != nil {
+		app.Cat = s.taxonomies.Normalize(from, app.Cat)
+	}
+	bidRequest.Bcat = s.taxonomies.Normalize(from, bidRequest.Bcat)
+}
+
+func (s *Server) translateCategories_V2_5(bidRequest *ortb_V2_5.BidRequest, to taxonomy.Taxonomy) {
+	if site := bidRequest.GetSite(); site != nil {
+		site.Cat = s.taxonomies.Translate(to, site.Cat)
+	}
+	if app := bidRequest.GetApp(); app != nil {
+		app.Cat = s.taxonomies.Translate(to, app.Cat)
+	}
+	bidRequest.Bcat = s.taxonomies.Translate(to, bidRequest.Bcat)
+}
+
+func (s *Server) normalizeBidCategories_V2_5(bidResponse *ortb_V2_5.BidResponse, dspEndpoint string) {
+	from := s.dspTaxonomy(dspEndpoint)
+	for _, seatBid := range bidResponse.GetSeatbid() {
+		for _, bid := range seatBid.GetBid() {
+			bid.Cat = s.taxonomies.Normalize(from, bid.Cat)
+		}
+	}
+}
+
+// sspTaxonomy и dspTaxonomy возвращают настроенную таксономию или каноническую
+func (s *Server) sspTaxonomy(endpoint string) taxonomy.Taxonomy {
+	if t, ok := s.sspTaxonomies[endpoint]; ok {
+		return t
+	}
+	return taxonomy.Canonical
+}
+
+func (s *Server) dspTaxonomy(endpoint string) taxonomy.Taxonomy {
+	if t, ok := s.dspTaxonomies[endpoint]; ok {
+		return t
+	}
+	return taxonomy.Canonical
+}
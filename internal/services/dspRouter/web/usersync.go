@@ -0,0 +1,44 @@
+package dspRouterWeb
+
+import (
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// SSP присылает в user.buyeruid uid биржи. DSP он ничего не говорит, поэтому
+// роутер убирает его из запроса и подставляет каждой DSP её собственный uid
+// пользователя из синхронизации.
+
+func takeExchangeUID_V2_4(bidRequest *ortb_V2_4.BidRequest) string {
+	user := bidRequest.GetUser()
+	if user == nil || user.Buyeruid == nil {
+		return ""
+	}
+	uid := user.GetBuyeruid()
+	user.Buyeruid = nil
+	return uid
+}
+
+func setBuyerUID_V2_4(bidRequest *ortb_V2_4.BidRequest, buyeruid string) {
+	if bidRequest.User == nil {
+		bidRequest.User = &ortb_V2_4.User{}
+	}
+	bidRequest.User.Buyeruid = &buyeruid
+}
+
+func takeExchangeUID_V2_5(bidRequest *ortb_V2_5.BidRequest) string {
+	user := bidRequest.GetUser()
+	if user == nil || user.Buyeruid == nil {
+		return ""
+	}
+	uid := user.GetBuyeruid()
+	user.Buyeruid = nil
+	return uid
+}
+
+func setBuyerUID_V2_5(bidRequest *ortb_V2_5.BidRequest, buyeruid string) {
+	if bidRequest.User == nil {
+		bidRequest.User = &ortb_V2_5.User{}
+	}
+	bidRequest.User.Buyeruid = &buyeruid
+}
@@ -14,10 +14,15 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/constants"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/currency"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/deals"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
 	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	utils "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/utils_grpc"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/taxonomy"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/usersync"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -26,12 +31,17 @@ type DspMetaData struct {
 	DspEndpoint string
 	Code        int
 	ErrMsg      string
+	// Доля трафика по правилам sample DSP, 0 - без выборки. Если imp прошли
+	// по разным выборкам - самая узкая, см. filter.FilterResult.SampleRate
+	SampleRate float64 `json:",omitempty"`
 }
 
 type Server struct {
 	ruleManager *filter.RuleManager
 	fileLoader  *filter.FileRuleLoader
-	processor   *filter.OptimizedFilterProcessor
+	// Раздача правил через Redis, nil - правила меняются только на этой реплике
+	ruleStore *filter.RedisRuleStore
+	processor *filter.OptimizedFilterProcessor
 
 	dspConfigPath string
 	sppConfigPath string
@@ -41,6 +51,18 @@ type Server struct {
 
 	redisClient *redis.Client
 
+	dspCurrencies map[string]string
+	rates         *currency.Rates
+	deals         *deals.Registry
+
+	// Таксономии категорий SSP и DSP, не заданные - каноническая
+	taxonomies    *taxonomy.Mapper
+	sspTaxonomies map[string]taxonomy.Taxonomy
+	dspTaxonomies map[string]taxonomy.Taxonomy
+
+	// nil, если синхронизация пользователей выключена
+	userSync *usersync.Resolver
+
 	client_v_2_4 *http.Client
 	client_v_2_5 *http.Client
 	timeout      time.Duration
@@ -83,23 +105,48 @@ func NewFastHTTPClient() *http.Client {
 	}
 }
 
-func NewServer(
-	ruleManager *filter.RuleManager,
-	fileLoader *filter.FileRuleLoader,
-	processor *filter.OptimizedFilterProcessor,
-	dspConfigPath string,
-	sppConfigPath string,
-	dspEndpoints_v_2_4,
-	dspEndpoints_v_2_5 []string,
-	redisClient *redis.Client,
-	timeout time.Duration,
-	maxParallelRequests int,
-	debug bool,
-	resp *http.Response,
-) *Server {
+// ServerConfig - зависимости и настройки Server. Необязательные поля можно не
+// заполнять: nil отключает соответствующую функцию.
+type ServerConfig struct {
+	RuleManager *filter.RuleManager
+	FileLoader  *filter.FileRuleLoader
+	// Раздача правил через Redis, nil - правила меняются только на этой реплике
+	RuleStore *filter.RedisRuleStore
+	Processor *filter.OptimizedFilterProcessor
+
+	DspConfigPath string
+	SppConfigPath string
+
+	DspEndpoints_v_2_4 []string
+	DspEndpoints_v_2_5 []string
+
+	RedisClient *redis.Client
+
+	// Валюта DSP по endpoint
+	DspCurrencies map[string]string
+	Rates         *currency.Rates
+	Deals         *deals.Registry
+
+	Taxonomies    *taxonomy.Mapper
+	SspTaxonomies map[string]taxonomy.Taxonomy
+	DspTaxonomies map[string]taxonomy.Taxonomy
+
+	UserSync *usersync.Resolver
+
+	// Таймаут запроса к DSP, 0 - 5 секунд
+	Timeout time.Duration
+	// Параллельных запросов на DSP, 0 - 64
+	MaxParallelRequests int
+	Debug               bool
+	Resp                *http.Response
+}
+
+func NewServer(config ServerConfig) *Server {
+	timeout := config.Timeout
 	if timeout <= 0 {
 		timeout = 5 * time.Second
 	}
+	maxParallelRequests := config.MaxParallelRequests
 	if maxParallelRequests <= 0 {
 		maxParallelRequests = 64
 	}
@@ -110,10 +157,10 @@ func NewServer(
 	// Глобальный лимит исходящих: консервативно отталкиваемся от
 	// количества DSP и локального лимита; при желании — вынеси в конфиг.
 	outboundLimit := maxParallelRequests * 2
-	if l := len(dspEndpoints_v_2_4); l > 0 && l*maxParallelRequests > outboundLimit {
+	if l := len(config.DspEndpoints_v_2_4); l > 0 && l*maxParallelRequests > outboundLimit {
 		outboundLimit = l * maxParallelRequests
 	}
-	if l := len(dspEndpoints_v_2_5); l > 0 && l*maxParallelRequests > outboundLimit {
+	if l := len(config.DspEndpoints_v_2_5); l > 0 && l*maxParallelRequests > outboundLimit {
 		outboundLimit = l * maxParallelRequests
 	}
 	if outboundLimit < 256 {
@@ -121,14 +168,22 @@ func NewServer(
 	}
 
 	return &Server{
-		ruleManager:        ruleManager,
-		fileLoader:         fileLoader,
-		processor:          processor,
-		dspConfigPath:      dspConfigPath,
-		sppConfigPath:      sppConfigPath,
-		dspEndpoints_v_2_4: dspEndpoints_v_2_4,
-		dspEndpoints_v_2_5: dspEndpoints_v_2_5,
-		redisClient:        redisClient,
+		ruleManager:        config.RuleManager,
+		fileLoader:         config.FileLoader,
+		ruleStore:          config.RuleStore,
+		processor:          config.Processor,
+		dspConfigPath:      config.DspConfigPath,
+		sppConfigPath:      config.SppConfigPath,
+		dspEndpoints_v_2_4: config.DspEndpoints_v_2_4,
+		dspEndpoints_v_2_5: config.DspEndpoints_v_2_5,
+		redisClient:        config.RedisClient,
+		dspCurrencies:      normalizeDspCurrencies(config.DspCurrencies),
+		rates:              config.Rates,
+		deals:              config.Deals,
+		taxonomies:         config.Taxonomies,
+		sspTaxonomies:      config.SspTaxonomies,
+		dspTaxonomies:      config.DspTaxonomies,
+		userSync:           config.UserSync,
 		client_v_2_4:       client_v_2_4,
 		client_v_2_5:       client_v_2_5,
 		timeout:            timeout,
@@ -142,7 +197,7 @@ func NewServer(
 				return &DspMetaData{}
 			},
 		},
-		resp: resp,
+		resp: config.Resp,
 	}
 }
 
@@ -156,48 +211,78 @@ func (s *Server) GetBids_V2_4(
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetBids_V2_4: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 			resp = nil
-			funcErr = status.Errorf(codes.Internal, err.Error())
+			funcErr = status.Error(codes.Internal, err.Error())
 		}
 	}()
 
 	// Предварительная сериализация JSON
+	s.normalizeCategories_V2_4(req.BidRequest, req.SppEndpoint)
+	buyerUIDs := s.userSync.BuyerUIDs(reqCtx, takeExchangeUID_V2_4(req.BidRequest))
 	jsonData, err := json.Marshal(req.BidRequest)
 	if err != nil {
 		return nil, fmt.Errorf("Can not marshal in GetBids_V2_4: %w", err)
 	}
 
+	endpoints, offered, sampleRates := s.eligibleDsps_V2_4(req.BidRequest, req.GlobalId, s.dspEndpoints_v_2_4)
+	payloads := s.bidRequestPayloads_V2_4(req.BidRequest, jsonData, endpoints, offered, buyerUIDs)
+
 	var (
 		wg sync.WaitGroup
 	)
 
-	responsesCh := make(chan *ortb_V2_4.BidResponse, len(s.dspEndpoints_v_2_4))
+	responsesCh := make(chan *bidEngineGrpc.DspBidResponse_V2_4, len(s.dspEndpoints_v_2_4))
+	// От DSP могут прийти ответы со ставками, нарушившими каждый из трёх запретов, и отфильтрованный ответ
+	filteredCh := make(chan *bidEngineGrpc.DspBidResponse_V2_4, 4*len(s.dspEndpoints_v_2_4))
 	dspMetaDataCh := make(chan *DspMetaData, len(s.dspEndpoints_v_2_4))
 
-	for _, endpoint := range s.dspEndpoints_v_2_4 {
+	for _, endpoint := range endpoints {
 		wg.Add(1)
 		go func(endpoint string) {
 			defer wg.Done()
 
-			// Быстрая фильтрация DSP
-			if !s.processor.ProcessRequestForDSPV24(endpoint, req.BidRequest).Allowed {
+			dspCur := s.dspCurrency(endpoint)
+			payload := payloads[endpoint]()
+			if payload == nil {
 				return
 			}
 
 			// HTTP запрос к DSP
-			dspResp, code, errMsg := s.getBidsFromDSPbyHTTP_V_2_4_Optimized(reqCtx, jsonData, endpoint)
+			dspResp, code, errMsg := s.getBidsFromDSPbyHTTP_V_2_4_Optimized(reqCtx, payload, endpoint)
+			if dspResp != nil {
+				if dspResp.Cur == nil && dspCur != noDspCurrency {
+					dspResp.Cur = &dspCur
+				}
+				if imps := offered[endpoint]; imps != nil {
+					dropUnofferedBids_V2_4(dspResp, endpoint, imps)
+				}
+				s.normalizeBidCategories_V2_4(dspResp, endpoint)
+			}
 
 			// Отправляем метаданные
 			meta := s.metaPool.Get().(*DspMetaData)
 			meta.DspEndpoint = endpoint
 			meta.Code = code
 			meta.ErrMsg = errMsg
+			meta.SampleRate = sampleRates[endpoint]
 			dspMetaDataCh <- meta
 
 			// Фильтрация ответа SPP
-			if dspResp != nil && s.processor.ProcessResponseForSPPV24(req.SppEndpoint, dspResp).Allowed {
-				responsesCh <- dspResp
+			if dspResp != nil {
+				for _, blocked := range s.processor.BlockBidsV24(req.SppEndpoint, req.BidRequest, dspResp) {
+					filteredCh <- &bidEngineGrpc.DspBidResponse_V2_4{
+						BidResponse:  blocked.Response,
+						DspId:        endpoint,
+						FilterReason: blocked.Reason,
+					}
+				}
+				wrapped := &bidEngineGrpc.DspBidResponse_V2_4{BidResponse: dspResp, DspId: endpoint}
+				if s.processor.ProcessResponseForSPPV24(req.SppEndpoint, dspResp).Allowed {
+					responsesCh <- wrapped
+				} else {
+					filteredCh <- wrapped
+				}
 			}
 		}(endpoint)
 	}
@@ -206,14 +291,16 @@ func (s *Server) GetBids_V2_4(
 	go func() {
 		wg.Wait()
 		close(responsesCh)
+		close(filteredCh)
 		close(dspMetaDataCh)
 	}()
 
 	// Собираем результаты
-	responses := make([]*ortb_V2_4.BidResponse, 0, len(s.dspEndpoints_v_2_4))
+	responses := make([]*bidEngineGrpc.DspBidResponse_V2_4, 0, len(s.dspEndpoints_v_2_4))
+	filteredResponses := make([]*bidEngineGrpc.DspBidResponse_V2_4, 0)
 	dspMetaData := make([]DspMetaData, 0, len(s.dspEndpoints_v_2_4))
 
-	for responsesCh != nil || dspMetaDataCh != nil {
+	for responsesCh != nil || filteredCh != nil || dspMetaDataCh != nil {
 		select {
 		case r, ok := <-responsesCh:
 			if !ok {
@@ -221,6 +308,12 @@ func (s *Server) GetBids_V2_4(
 			} else {
 				responses = append(responses, r)
 			}
+		case r, ok := <-filteredCh:
+			if !ok {
+				filteredCh = nil
+			} else {
+				filteredResponses = append(filteredResponses, r)
+			}
 		case meta, ok := <-dspMetaDataCh:
 			if !ok {
 				dspMetaDataCh = nil
@@ -229,6 +322,7 @@ func (s *Server) GetBids_V2_4(
 					DspEndpoint: meta.DspEndpoint,
 					Code:        meta.Code,
 					ErrMsg:      meta.ErrMsg,
+					SampleRate:  meta.SampleRate,
 				})
 				s.metaPool.Put(meta)
 			}
@@ -239,9 +333,10 @@ func (s *Server) GetBids_V2_4(
 	go s.writeMetadataToRedis(ctx, req.GlobalId, dspMetaData)
 
 	return &dspRouterGrpc.DspRouterResponse_V2_4{
-		BidRequest:   req.BidRequest,
-		BidResponses: responses,
-		GlobalId:     req.GlobalId,
+		BidRequest:           req.BidRequest,
+		BidResponses:         responses,
+		GlobalId:             req.GlobalId,
+		FilteredBidResponses: filteredResponses,
 	}, nil
 }
 
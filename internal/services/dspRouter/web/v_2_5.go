@@ -10,6 +10,7 @@ import (
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
 	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
 	"google.golang.org/grpc/codes"
@@ -27,43 +28,75 @@ func (s *Server) GetBids_V2_5(
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetBids_V2_5: %v", r)
 			resp = nil
-			funcErr = status.Errorf(codes.Internal, err.Error())
+			funcErr = status.Error(codes.Internal, err.Error())
 		}
 	}()
 
+	s.normalizeCategories_V2_5(req.BidRequest, req.SppEndpoint)
+	buyerUIDs := s.userSync.BuyerUIDs(reqCtx, takeExchangeUID_V2_5(req.BidRequest))
 	jsonData, err := jsoniter.Marshal(req.BidRequest)
 	if err != nil {
 		return nil, fmt.Errorf("Can not marshal in GetBids_V_2_5: %w", err)
 	}
 
+	endpoints, offered, sampleRates := s.eligibleDsps_V2_5(req.BidRequest, req.GlobalId, s.dspEndpoints_v_2_5)
+	payloads := s.bidRequestPayloads_V2_5(req.BidRequest, jsonData, endpoints, offered, buyerUIDs)
+
 	var (
 		wg sync.WaitGroup
 	)
 
-	responsesCh := make(chan *ortb_V2_5.BidResponse, len(s.dspEndpoints_v_2_5))
+	responsesCh := make(chan *bidEngineGrpc.DspBidResponse_V2_5, len(s.dspEndpoints_v_2_5))
+	// От DSP могут прийти ответы со ставками, нарушившими каждый из трёх запретов, и отфильтрованный ответ
+	filteredCh := make(chan *bidEngineGrpc.DspBidResponse_V2_5, 4*len(s.dspEndpoints_v_2_5))
 	dspMetaDataCh := make(chan *DspMetaData, len(s.dspEndpoints_v_2_5))
 
 	// Запускаем все DSP параллельно
-	for _, endpoint := range s.dspEndpoints_v_2_5 {
-		if !s.processor.ProcessRequestForDSPV25(endpoint, req.BidRequest).Allowed {
-			continue
-		}
+	for _, endpoint := range endpoints {
 		wg.Add(1)
 		go func(endpoint string) {
 			defer wg.Done()
 
-			dspResp, code, errMsg := s.getBidsFromDSPbyHTTP_V_2_5_Optimized(reqCtx, jsonData, endpoint)
+			dspCur := s.dspCurrency(endpoint)
+			payload := payloads[endpoint]()
+			if payload == nil {
+				return
+			}
+
+			dspResp, code, errMsg := s.getBidsFromDSPbyHTTP_V_2_5_Optimized(reqCtx, payload, endpoint)
+			if dspResp != nil {
+				if dspResp.Cur == nil && dspCur != noDspCurrency {
+					dspResp.Cur = &dspCur
+				}
+				if imps := offered[endpoint]; imps != nil {
+					dropUnofferedBids_V2_5(dspResp, endpoint, imps)
+				}
+				s.normalizeBidCategories_V2_5(dspResp, endpoint)
+			}
 
 			// Отправляем метаданные
 			meta := s.metaPool.Get().(*DspMetaData)
 			meta.DspEndpoint = endpoint
 			meta.Code = code
 			meta.ErrMsg = errMsg
+			meta.SampleRate = sampleRates[endpoint]
 			dspMetaDataCh <- meta
 
 			// Фильтрация ответа SPP
-			if dspResp != nil && s.processor.ProcessResponseForSPPV25(req.SppEndpoint, dspResp).Allowed {
-				responsesCh <- dspResp
+			if dspResp != nil {
+				for _, blocked := range s.processor.BlockBidsV25(req.SppEndpoint, req.BidRequest, dspResp) {
+					filteredCh <- &bidEngineGrpc.DspBidResponse_V2_5{
+						BidResponse:  blocked.Response,
+						DspId:        endpoint,
+						FilterReason: blocked.Reason,
+					}
+				}
+				wrapped := &bidEngineGrpc.DspBidResponse_V2_5{BidResponse: dspResp, DspId: endpoint}
+				if s.processor.ProcessResponseForSPPV25(req.SppEndpoint, dspResp).Allowed {
+					responsesCh <- wrapped
+				} else {
+					filteredCh <- wrapped
+				}
 			}
 		}(endpoint)
 	}
@@ -72,14 +105,16 @@ func (s *Server) GetBids_V2_5(
 	go func() {
 		wg.Wait()
 		close(responsesCh)
+		close(filteredCh)
 		close(dspMetaDataCh)
 	}()
 
 	// Собираем результаты
-	responses := make([]*ortb_V2_5.BidResponse, 0, len(s.dspEndpoints_v_2_5))
+	responses := make([]*bidEngineGrpc.DspBidResponse_V2_5, 0, len(s.dspEndpoints_v_2_5))
+	filteredResponses := make([]*bidEngineGrpc.DspBidResponse_V2_5, 0)
 	dspMetaData := make([]DspMetaData, 0, len(s.dspEndpoints_v_2_5))
 
-	for responsesCh != nil || dspMetaDataCh != nil {
+	for responsesCh != nil || filteredCh != nil || dspMetaDataCh != nil {
 		select {
 		case r, ok := <-responsesCh:
 			if !ok {
@@ -87,6 +122,12 @@ func (s *Server) GetBids_V2_5(
 			} else {
 				responses = append(responses, r)
 			}
+		case r, ok := <-filteredCh:
+			if !ok {
+				filteredCh = nil
+			} else {
+				filteredResponses = append(filteredResponses, r)
+			}
 		case meta, ok := <-dspMetaDataCh:
 			if !ok {
 				dspMetaDataCh = nil
@@ -95,6 +136,7 @@ func (s *Server) GetBids_V2_5(
 					DspEndpoint: meta.DspEndpoint,
 					Code:        meta.Code,
 					ErrMsg:      meta.ErrMsg,
+					SampleRate:  meta.SampleRate,
 				})
 				s.metaPool.Put(meta)
 			}
@@ -105,9 +147,10 @@ func (s *Server) GetBids_V2_5(
 	go s.writeMetadataToRedis(ctx, req.GlobalId, dspMetaData)
 
 	return &dspRouterGrpc.DspRouterResponse_V2_5{
-		BidRequest:   req.BidRequest,
-		BidResponses: responses,
-		GlobalId:     req.GlobalId,
+		BidRequest:           req.BidRequest,
+		BidResponses:         responses,
+		GlobalId:             req.GlobalId,
+		FilteredBidResponses: filteredResponses,
 	}, nil
 }
 
@@ -128,7 +171,7 @@ func (s *Server) getBidsFromDSPbyHTTP_V_2_5_Optimized(ctx context.Context, jsonD
 
 	t := time.Now()
 	resp, err := s.client_v_2_5.Do(req)
-	log.Println("%v", time.Since(t))
+	log.Printf("%v", time.Since(t))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Request failed: %v", err).Error()
 	}
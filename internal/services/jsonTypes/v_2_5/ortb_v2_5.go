@@ -33,12 +33,14 @@ type Geo struct {
 }
 
 type BidResponse struct {
-	Id      *string  `json:"id"`
-	SeatBid *SeatBid `json:"seatbid,omitempty"`
+	Id      *string   `json:"id"`
+	SeatBid []SeatBid `json:"seatbid,omitempty"`
 }
 
 type SeatBid struct {
-	Bid []Bid `json:"bid"`
+	Bid   []Bid   `json:"bid"`
+	Seat  *string `json:"seat,omitempty"`
+	Group *int32  `json:"group,omitempty"`
 }
 
 type Bid struct {
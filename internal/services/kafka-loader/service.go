@@ -85,6 +85,21 @@ func ProcessBatch(ctx context.Context, redisClient *redis.Client, kafkaWriter *k
 			fieldsToDelete[key] = append(fieldsToDelete[key], constants.RESULT_COLUMN)
 		}
 
+		if floorSource, exists := data[constants.FLOOR_SOURCE_COLUMN]; exists {
+			record.FLOOR_SOURCE = floorSource
+			fieldsToDelete[key] = append(fieldsToDelete[key], constants.FLOOR_SOURCE_COLUMN)
+		}
+
+		if margin, exists := data[constants.MARGIN_COLUMN]; exists {
+			record.MARGIN = margin
+			fieldsToDelete[key] = append(fieldsToDelete[key], constants.MARGIN_COLUMN)
+		}
+
+		if deals, exists := data[constants.DEALS_COLUMN]; exists {
+			record.DEALS = deals
+			fieldsToDelete[key] = append(fieldsToDelete[key], constants.DEALS_COLUMN)
+		}
+
 		// Проверяем есть ли данные в записи
 		if hasData(record) {
 			jsonData, err := json.Marshal(record)
@@ -127,7 +142,10 @@ func hasData(record types.StatisticsRecord) bool {
 		record.BID_RESPONSES != "" ||
 		record.BID_RESPONSE_WINNER != "" ||
 		record.BID_RESPONSE_WINNER_BY_DSP_PRICE != "" ||
-		record.SUCCESS != ""
+		record.SUCCESS != "" ||
+		record.FLOOR_SOURCE != "" ||
+		record.MARGIN != "" ||
+		record.DEALS != ""
 }
 
 func EnsureTopicExists(broker string, topic string) error {
@@ -9,6 +9,7 @@ import (
 	"github.com/ggicci/httpin"
 	"github.com/unrolled/render"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"google.golang.org/protobuf/proto"
 )
 
 var rnr = render.New(render.Options{
@@ -37,19 +38,48 @@ func postBid_V2_5(
 
 	input := r.Context().Value(httpin.Input).(*postBidRequest_V2_5)
 
-	var statusCode int
-
-	if len(input.Payload.Imp) > 0 {
-		resp.Seatbid.Bid[0].Impid = input.Payload.Imp[0].Id
-		statusCode = http.StatusOK
-	} else {
-		resp.Seatbid.Bid = []*ortb_V2_5.Bid{}
+	statusCode := http.StatusOK
+	bidResponse := bidOnEveryImp(resp, input.Payload.GetImp())
+	if len(bidResponse.Seatbid) == 0 {
 		statusCode = http.StatusNoContent
 	}
 
 	if err := rnr.JSON(w, statusCode, postBidResponse_V2_5{
-		BidResponse: resp,
+		BidResponse: bidResponse,
 	}); err != nil {
 		log.Printf("[%s] Cannot make HTTP response back: %v\n", resp.GetId(), err)
 	}
 }
+
+// bidOnEveryImp отвечает ставками шаблона на каждый imp запроса.
+// Шаблон общий для всех запросов, поэтому ответ собирается из копий.
+func bidOnEveryImp(template *ortb_V2_5.BidResponse, imps []*ortb_V2_5.Imp) *ortb_V2_5.BidResponse {
+	bidResponse := &ortb_V2_5.BidResponse{
+		Id:      template.Id,
+		Cur:     template.Cur,
+		Seatbid: []*ortb_V2_5.SeatBid{},
+	}
+	if len(imps) == 0 {
+		return bidResponse
+	}
+
+	for _, templateSeatBid := range template.GetSeatbid() {
+		seatBid := &ortb_V2_5.SeatBid{
+			Seat:  templateSeatBid.Seat,
+			Group: templateSeatBid.Group,
+			Bid:   make([]*ortb_V2_5.Bid, 0, len(imps)*len(templateSeatBid.GetBid())),
+		}
+		for _, templateBid := range templateSeatBid.GetBid() {
+			for _, imp := range imps {
+				bid := proto.Clone(templateBid).(*ortb_V2_5.Bid)
+				// id ставки должен быть уникален в пределах ответа
+				bidID := templateBid.GetId() + "-" + imp.GetId()
+				bid.Id = &bidID
+				bid.Impid = imp.Id
+				seatBid.Bid = append(seatBid.Bid, bid)
+			}
+		}
+		bidResponse.Seatbid = append(bidResponse.Seatbid, seatBid)
+	}
+	return bidResponse
+}
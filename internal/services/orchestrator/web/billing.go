@@ -0,0 +1,18 @@
+package orchestratorWeb
+
+import (
+	"context"
+
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+)
+
+// ReportBilling пересылает billing событие из SSP адаптера в bid engine
+func (s *Server) ReportBilling(
+	ctx context.Context,
+	req *bidEngineGrpc.BillingEvent,
+) (*bidEngineGrpc.BillingEventAck, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getWinnerBidTimeout)
+	defer cancel()
+
+	return s.bidEngineGrpcClient.ReportBilling(reqCtx, req)
+}
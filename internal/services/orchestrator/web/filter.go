@@ -0,0 +1,314 @@
+package orchestratorWeb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filter"
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExplainFilter_V2_4 пересылает запрос разбора фильтра из SSP адаптера в DSP router
+func (s *Server) ExplainFilter_V2_4(
+	ctx context.Context,
+	req *dspRouterGrpc.ExplainFilterRequest_V2_4,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.ExplainFilter_V2_4(reqCtx, req)
+}
+
+func (s *Server) ExplainFilter_V2_5(
+	ctx context.Context,
+	req *dspRouterGrpc.ExplainFilterRequest_V2_5,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.ExplainFilter_V2_5(reqCtx, req)
+}
+
+// GetFilterStats собирает статистику фильтра со всех реплик DSP router и складывает её.
+// reset обнуляет счётчики на каждой реплике.
+func (s *Server) GetFilterStats(
+	ctx context.Context,
+	req *dspRouterGrpc.FilterStatsRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	replicas, closeReplicas, err := s.routerReplicas(reqCtx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer closeReplicas()
+
+	results := callReplicas(reqCtx, replicas, func(ctx context.Context, client dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.JsonResponse, error) {
+		return client.GetFilterStats(ctx, req)
+	})
+	stats := make([]filter.FilterStats, 0, len(results))
+	for _, result := range results {
+		if result.err != nil {
+			return nil, replicaError(result.address, result.err)
+		}
+		var replicaStats filter.FilterStats
+		if err := json.Unmarshal(result.value.GetJsonData(), &replicaStats); err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot decode filter stats of router replica %s: %v", result.address, err)
+		}
+		stats = append(stats, replicaStats)
+	}
+
+	return jsonResponse(filter.MergeFilterStats(stats))
+}
+
+// SetShadowRules загружает правила-кандидат на все реплики DSP router
+func (s *Server) SetShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.updateReplicas(reqCtx, func(ctx context.Context, client dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.UpdateRulesResponse, error) {
+		return client.SetShadowRules(ctx, req)
+	})
+}
+
+// GetShadowReport складывает расхождения кандидата со всех реплик, на которых он загружен
+func (s *Server) GetShadowReport(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	replicas, closeReplicas, err := s.routerReplicas(reqCtx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer closeReplicas()
+
+	results := callReplicas(reqCtx, replicas, func(ctx context.Context, client dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.JsonResponse, error) {
+		return client.GetShadowReport(ctx, req)
+	})
+	reports := make([]filter.ShadowReport, 0, len(results))
+	for _, result := range results {
+		// Реплика без кандидата, например перезапущенная после его загрузки
+		if status.Code(result.err) == codes.NotFound {
+			log.Printf("Router replica %s has no shadow rules", result.address)
+			continue
+		}
+		if result.err != nil {
+			return nil, replicaError(result.address, result.err)
+		}
+		var report filter.ShadowReport
+		if err := json.Unmarshal(result.value.GetJsonData(), &report); err != nil {
+			return nil, status.Errorf(codes.Internal, "cannot decode shadow report of router replica %s: %v", result.address, err)
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) == 0 {
+		return nil, status.Error(codes.NotFound, "no shadow rules loaded")
+	}
+
+	return jsonResponse(filter.MergeShadowReports(reports))
+}
+
+// PromoteShadowRules делает кандидат активным на одной реплике: она сохраняет правила
+// в общее хранилище, откуда их загружают остальные. С остальных реплик кандидат снимается.
+func (s *Server) PromoteShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	replicas, closeReplicas, err := s.routerReplicas(reqCtx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer closeReplicas()
+	if len(replicas) == 0 {
+		return nil, status.Error(codes.Unavailable, "no router replicas found")
+	}
+
+	promoted, err := replicas[0].client.PromoteShadowRules(reqCtx, req)
+	if err != nil {
+		return nil, replicaError(replicas[0].address, err)
+	}
+
+	results := callReplicas(reqCtx, replicas[1:], func(ctx context.Context, client dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.UpdateRulesResponse, error) {
+		return client.DropShadowRules(ctx, &dspRouterGrpc.ShadowRulesRequest{})
+	})
+	for _, result := range results {
+		if result.err != nil {
+			promoted.Message = fmt.Sprintf("%s; cannot drop shadow rules on router replica %s: %v", promoted.GetMessage(), result.address, result.err)
+		}
+	}
+
+	return promoted, nil
+}
+
+// DropShadowRules выгружает кандидат со всех реплик DSP router
+func (s *Server) DropShadowRules(
+	ctx context.Context,
+	req *dspRouterGrpc.ShadowRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.updateReplicas(reqCtx, func(ctx context.Context, client dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.UpdateRulesResponse, error) {
+		return client.DropShadowRules(ctx, req)
+	})
+}
+
+// updateReplicas выполняет изменение на всех репликах. Ошибка любой реплики возвращается
+// с её адресом, остальные реплики изменение уже применили.
+func (s *Server) updateReplicas(
+	ctx context.Context,
+	call func(context.Context, dspRouterGrpc.DspRouterServiceClient) (*dspRouterGrpc.UpdateRulesResponse, error),
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	replicas, closeReplicas, err := s.routerReplicas(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer closeReplicas()
+
+	results := callReplicas(ctx, replicas, call)
+	for _, result := range results {
+		if result.err != nil {
+			return nil, replicaError(result.address, result.err)
+		}
+	}
+	if len(results) == 0 {
+		return nil, status.Error(codes.Unavailable, "no router replicas found")
+	}
+
+	response := results[0].value
+	if len(results) > 1 {
+		response.Message = fmt.Sprintf("%s on %d router replicas", response.GetMessage(), len(results))
+	}
+	return response, nil
+}
+
+// replicaError добавляет к ошибке реплики её адрес, сохраняя код gRPC
+func replicaError(address string, err error) error {
+	st := status.Convert(err)
+	return status.Errorf(st.Code(), "router replica %s: %s", address, st.Message())
+}
+
+func jsonResponse(v interface{}) (*dspRouterGrpc.JsonResponse, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &dspRouterGrpc.JsonResponse{JsonData: data}, nil
+}
+
+// GetDSPRules пересылает запрос текущих правил DSP в DSP router
+func (s *Server) GetDSPRules(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.GetDSPRules_V2_4(reqCtx, req)
+}
+
+func (s *Server) GetSPPRules(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.GetSPPRules_V2_4(reqCtx, req)
+}
+
+func (s *Server) UpdateDSPRules(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.UpdateDSPRules_V2_4(reqCtx, req)
+}
+
+func (s *Server) UpdateSPPRules(
+	ctx context.Context,
+	req *dspRouterGrpc.JsonRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.UpdateSPPRules_V2_4(reqCtx, req)
+}
+
+func (s *Server) ListRuleVersions(
+	ctx context.Context,
+	req *dspRouterGrpc.RuleVersionsRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.ListRuleVersions(reqCtx, req)
+}
+
+func (s *Server) DiffRuleVersions(
+	ctx context.Context,
+	req *dspRouterGrpc.RuleVersionsDiffRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.DiffRuleVersions(reqCtx, req)
+}
+
+func (s *Server) RollbackRules(
+	ctx context.Context,
+	req *dspRouterGrpc.RollbackRulesRequest,
+) (*dspRouterGrpc.UpdateRulesResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.RollbackRules(reqCtx, req)
+}
+
+// GetRuleSyncStatus возвращает ревизии правил реплики DSP router, на которую попал запрос
+func (s *Server) GetRuleSyncStatus(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.GetRuleSyncStatus(reqCtx, req)
+}
+
+func (s *Server) GetBlocklists(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.GetBlocklists(reqCtx, req)
+}
+
+// ReloadBlocklists перечитывает файлы blocklist SPP на реплике DSP router, на которую попал запрос
+func (s *Server) ReloadBlocklists(
+	ctx context.Context,
+	req *dspRouterGrpc.GetRulesRequest,
+) (*dspRouterGrpc.JsonResponse, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
+	defer cancel()
+
+	return s.dspRouterGrpcClient.ReloadBlocklists(reqCtx, req)
+}
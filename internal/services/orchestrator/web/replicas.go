@@ -0,0 +1,111 @@
+package orchestratorWeb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+
+	dspRouterGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// RouterReplicas находит все реплики DSP router. Счётчики фильтра и правила-кандидат
+// у каждой реплики свои, поэтому админские вызовы к ним идут на все реплики,
+// а не через балансировщик.
+type RouterReplicas struct {
+	// host:port, host которого резолвится в адреса всех реплик (headless service)
+	address  string
+	resolver *net.Resolver
+}
+
+// NewRouterReplicas возвращает nil для пустого адреса: тогда роутер считается
+// одной репликой за адресом URI_OF_DSP_ROUTER
+func NewRouterReplicas(address string) *RouterReplicas {
+	if address == "" {
+		return nil
+	}
+	return &RouterReplicas{address: address, resolver: net.DefaultResolver}
+}
+
+// routerReplica - соединение с одной репликой
+type routerReplica struct {
+	address string
+	client  dspRouterGrpc.DspRouterServiceClient
+}
+
+// replicaResult - ответ реплики на вызов
+type replicaResult[T any] struct {
+	address string
+	value   T
+	err     error
+}
+
+// connect резолвит адреса реплик и открывает соединения с ними, по порядку адресов
+func (r *RouterReplicas) connect(ctx context.Context) ([]routerReplica, func(), error) {
+	host, port, err := net.SplitHostPort(r.address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid router replicas address %s: %w", r.address, err)
+	}
+	ips, err := r.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve router replicas %s: %w", host, err)
+	}
+	sort.Strings(ips)
+
+	replicas := make([]routerReplica, 0, len(ips))
+	conns := make([]*grpc.ClientConn, 0, len(ips))
+	closeConns := func() {
+		for _, conn := range conns {
+			if err := conn.Close(); err != nil {
+				log.Printf("Cannot close router replica connection: %v", err)
+			}
+		}
+	}
+	for _, ip := range ips {
+		address := net.JoinHostPort(ip, port)
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			closeConns()
+			return nil, nil, fmt.Errorf("cannot connect to router replica %s: %w", address, err)
+		}
+		conns = append(conns, conn)
+		replicas = append(replicas, routerReplica{address: address, client: dspRouterGrpc.NewDspRouterServiceClient(conn)})
+	}
+
+	return replicas, closeConns, nil
+}
+
+// routerReplicas возвращает реплики роутера. Без RouterReplicas это одна реплика
+// за балансировщиком.
+func (s *Server) routerReplicas(ctx context.Context) ([]routerReplica, func(), error) {
+	if s.routerReplicaSet == nil {
+		return []routerReplica{{address: "dsp router", client: s.dspRouterGrpcClient}}, func() {}, nil
+	}
+	return s.routerReplicaSet.connect(ctx)
+}
+
+// callReplicas вызывает call на всех репликах параллельно, результаты в порядке реплик
+func callReplicas[T any](
+	ctx context.Context,
+	replicas []routerReplica,
+	call func(context.Context, dspRouterGrpc.DspRouterServiceClient) (T, error),
+) []replicaResult[T] {
+	results := make([]replicaResult[T], len(replicas))
+
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := call(ctx, replica.client)
+			results[i] = replicaResult[T]{address: replica.address, value: value, err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -18,6 +18,8 @@ import (
 type Server struct {
 	bidEngineGrpcClient bidEngineGrpc.BidEngineServiceClient
 	dspRouterGrpcClient dspRouterGrpc.DspRouterServiceClient
+	// nil - роутер одной репликой за dspRouterGrpcClient
+	routerReplicaSet *RouterReplicas
 
 	redisClient *redis.Client
 
@@ -30,6 +32,7 @@ type Server struct {
 func NewServer(
 	bidEngineGrpcClient bidEngineGrpc.BidEngineServiceClient,
 	dspRouterGrpcClient dspRouterGrpc.DspRouterServiceClient,
+	routerReplicas *RouterReplicas,
 	redisClient *redis.Client,
 	getBidsTimeout,
 	getWinnerBidTimeout time.Duration,
@@ -37,6 +40,7 @@ func NewServer(
 	return &Server{
 		bidEngineGrpcClient: bidEngineGrpcClient,
 		dspRouterGrpcClient: dspRouterGrpcClient,
+		routerReplicaSet:    routerReplicas,
 		redisClient:         redisClient,
 		getBidsTimeout:      getBidsTimeout,
 		getWinnerBidTimeout: getWinnerBidTimeout,
@@ -53,12 +57,12 @@ func (s *Server) GetWinnerBid_V2_4(
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetWinnerBid_V2_4: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 
 			grpcCode := codes.Internal
 
 			resp = nil
-			funcErr = status.Errorf(grpcCode, err.Error())
+			funcErr = status.Error(grpcCode, err.Error())
 		}
 	}()
 	getBidsReqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
@@ -82,16 +86,14 @@ func (s *Server) GetWinnerBid_V2_4(
 			newErr = fmt.Errorf("Can not get bids from router in  GetWinnerBid because got error: %w", st.Err())
 		}
 
-		return nil, status.Errorf(grpcCode, newErr.Error())
+		return nil, status.Error(grpcCode, newErr.Error())
 	}
 
-	if len(bids.BidResponses) == 0 {
+	if len(bids.BidResponses) == 0 && len(bids.FilteredBidResponses) == 0 {
 		return &orchestratorGrpc.OrchestratorResponse_V2_4{
 			BidResponse: &ortb_V2_4.BidResponse{
-				Id: req.BidRequest.Id,
-				Seatbid: &ortb_V2_4.SeatBid{
-					Bid: []*ortb_V2_4.Bid{},
-				},
+				Id:      req.BidRequest.Id,
+				Seatbid: []*ortb_V2_4.SeatBid{},
 			},
 		}, nil
 	}
@@ -102,9 +104,12 @@ func (s *Server) GetWinnerBid_V2_4(
 	winner, err := s.bidEngineGrpcClient.GetWinnerBid_V2_4(
 		getWinnerBidReqCtx,
 		&bidEngineGrpc.BidEngineRequest_V2_4{
-			BidRequest:   bids.BidRequest,
-			BidResponses: bids.BidResponses,
-			GlobalId:     bids.GlobalId,
+			BidRequest:           bids.BidRequest,
+			BidResponses:         bids.BidResponses,
+			GlobalId:             bids.GlobalId,
+			FilteredBidResponses: bids.FilteredBidResponses,
+			SppEndpoint:          req.SppEndpoint,
+			FloorDecisions:       req.FloorDecisions,
 		},
 	)
 	if err != nil {
@@ -118,7 +123,7 @@ func (s *Server) GetWinnerBid_V2_4(
 			newErr = fmt.Errorf("Can not GetWinnerBid_V2_4 from bidEngine in GetWinnerBid because got error: %w", st.Err())
 		}
 
-		return nil, status.Errorf(grpcCode, newErr.Error())
+		return nil, status.Error(grpcCode, newErr.Error())
 	}
 
 	return &orchestratorGrpc.OrchestratorResponse_V2_4{
@@ -27,12 +27,12 @@ func (s *Server) GetWinnerBid_V2_5(
 
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in GetWinnerBid_V2_5: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 
 			grpcCode := codes.Internal
 
 			resp = nil
-			funcErr = status.Errorf(grpcCode, err.Error())
+			funcErr = status.Error(grpcCode, err.Error())
 		}
 	}()
 	getBidsReqCtx, cancel := context.WithTimeout(ctx, s.getBidsTimeout)
@@ -57,16 +57,14 @@ func (s *Server) GetWinnerBid_V2_5(
 			newErr = fmt.Errorf("Can not get bids from router in  GetWinnerBid because got error: %w", st.Err())
 		}
 
-		return nil, status.Errorf(grpcCode, newErr.Error())
+		return nil, status.Error(grpcCode, newErr.Error())
 	}
 
-	if len(bids.BidResponses) == 0 {
+	if len(bids.BidResponses) == 0 && len(bids.FilteredBidResponses) == 0 {
 		return &orchestratorGrpc.OrchestratorResponse_V2_5{
 			BidResponse: &ortb_V2_5.BidResponse{
-				Id: req.BidRequest.Id,
-				Seatbid: &ortb_V2_5.SeatBid{
-					Bid: []*ortb_V2_5.Bid{},
-				},
+				Id:      req.BidRequest.Id,
+				Seatbid: []*ortb_V2_5.SeatBid{},
 			},
 		}, nil
 	}
@@ -77,9 +75,12 @@ func (s *Server) GetWinnerBid_V2_5(
 	winner, err := s.bidEngineGrpcClient.GetWinnerBid_V2_5(
 		getWinnerBidReqCtx,
 		&bidEngineGrpc.BidEngineRequest_V2_5{
-			BidRequest:   bids.BidRequest,
-			BidResponses: bids.BidResponses,
-			GlobalId:     bids.GlobalId,
+			BidRequest:           bids.BidRequest,
+			BidResponses:         bids.BidResponses,
+			GlobalId:             bids.GlobalId,
+			FilteredBidResponses: bids.FilteredBidResponses,
+			SppEndpoint:          req.SppEndpoint,
+			FloorDecisions:       req.FloorDecisions,
 		},
 	)
 	if err != nil {
@@ -93,7 +94,7 @@ func (s *Server) GetWinnerBid_V2_5(
 			newErr = fmt.Errorf("Can not GetWinnerBid_V2_5 from bidEngine in GetWinnerBid because got error: %w", st.Err())
 		}
 
-		return nil, status.Errorf(grpcCode, newErr.Error())
+		return nil, status.Error(grpcCode, newErr.Error())
 	}
 
 	return &orchestratorGrpc.OrchestratorResponse_V2_5{
@@ -11,6 +11,8 @@ import (
 	"github.com/ggicci/httpin"
 	"github.com/redis/go-redis/v9"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/constants"
+	bidEngineProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+	orchestratorProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator"
 	utils "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/utils_grpc"
 )
 
@@ -30,7 +32,7 @@ func getNurl(
 		client := &http.Client{Timeout: timeout}
 		resp, err := client.Get(decodedURL)
 		if err != nil {
-			log.Printf("Failed to proxy win notice to DSP %s, globalID: %s, error: %w",
+			log.Printf("Failed to proxy win notice to DSP %s, globalID: %s, error: %v",
 				decodedURL,
 				input.GlobalId,
 				err,
@@ -53,10 +55,15 @@ func getBurl(
 	w http.ResponseWriter,
 	r *http.Request,
 	redisClient *redis.Client,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
 	timeout time.Duration,
 ) {
 	input := r.Context().Value(httpin.Input).(*burlRequest)
 
+	if input.GlobalId != "" {
+		go reportBilling(ctx, orchestratorClient, input.GlobalId, input.ImpId, timeout)
+	}
+
 	decodedURL, err := url.QueryUnescape(input.DspURL)
 	if err != nil {
 		log.Printf("Failed to decode original URL: %v", err)
@@ -66,7 +73,7 @@ func getBurl(
 		resp, err := client.Get(decodedURL)
 		if err != nil {
 			log.Printf(
-				"Failed to proxy billable event to DSP %s, globalID: %s, error: %w",
+				"Failed to proxy billable event to DSP %s, globalID: %s, error: %v",
 				decodedURL,
 				input.GlobalId,
 				err,
@@ -82,12 +89,31 @@ func getBurl(
 	}
 
 	if err := utils.WriteStringToRedis(ctx, redisClient, input.GlobalId, constants.RESULT_COLUMN, constants.SUCCESS); err != nil {
-		fmt.Printf("failed to WriteStringToRedis SUCCESS in getBurl: %w", err)
+		fmt.Printf("failed to WriteStringToRedis SUCCESS in getBurl: %v", err)
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// reportBilling сообщает bid engine о выигрыше для обучения шейдинга
+func reportBilling(
+	ctx context.Context,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	globalId string,
+	impId string,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if _, err := orchestratorClient.ReportBilling(reqCtx, &bidEngineProto.BillingEvent{
+		GlobalId: globalId,
+		ImpId:    impId,
+	}); err != nil {
+		log.Printf("Failed to report billing event, globalID: %s, error: %v", globalId, err)
+	}
+}
+
 func getHealth(
 	w http.ResponseWriter,
 ) {
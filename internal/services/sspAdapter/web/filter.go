@@ -0,0 +1,346 @@
+package sppAdapterWeb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ggicci/httpin"
+	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	dspRouterProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/dspRouter"
+	orchestratorProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator"
+	"google.golang.org/grpc/status"
+)
+
+// postFilterExplain_V2_4 разбирает решение фильтра DSP router. Тело - JSON
+// ExplainFilterRequest_V2_4: dspId и bidRequest, либо sppId и bidResponses.
+func postFilterExplain_V2_4(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	var req dspRouterProto.ExplainFilterRequest_V2_4
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.ExplainFilter_V2_4(reqCtx, &req)
+	writeFilterJson(w, res, err)
+}
+
+func postFilterExplain_V2_5(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	var req dspRouterProto.ExplainFilterRequest_V2_5
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.ExplainFilter_V2_5(reqCtx, &req)
+	writeFilterJson(w, res, err)
+}
+
+// getFilterStats возвращает счётчики правил фильтра DSP router
+func getFilterStats(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	reset bool,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.GetFilterStats(reqCtx, &dspRouterProto.FilterStatsRequest{Reset_: reset})
+	writeFilterJson(w, res, err)
+}
+
+// putShadowRules загружает правила-кандидат в режиме shadow. Тело - конфиг в формате файлов правил.
+func putShadowRules(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.SetShadowRules(reqCtx, &dspRouterProto.JsonRequest{JsonData: data})
+	writeRulesUpdate(w, res, err)
+}
+
+// getShadowReport возвращает расхождения решений кандидата с активными правилами
+func getShadowReport(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.GetShadowReport(reqCtx, &dspRouterProto.ShadowRulesRequest{})
+	writeFilterJson(w, res, err)
+}
+
+func postShadowRulesPromote(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*ruleChangeRequest)
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.PromoteShadowRules(reqCtx, &dspRouterProto.ShadowRulesRequest{
+		Author:  input.Author,
+		Comment: input.Comment,
+	})
+	writeRulesUpdate(w, res, err)
+}
+
+func deleteShadowRules(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.DropShadowRules(reqCtx, &dspRouterProto.ShadowRulesRequest{})
+	writeRulesUpdate(w, res, err)
+}
+
+// getFilterRules возвращает текущие правила набора с их ревизией
+func getFilterRules(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*rulesRequest)
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var res *dspRouterProto.JsonResponse
+	var err error
+	switch input.Kind {
+	case ruleSetDSP:
+		res, err = orchestratorClient.GetDSPRules(reqCtx, &dspRouterProto.GetRulesRequest{})
+	case ruleSetSPP:
+		res, err = orchestratorClient.GetSPPRules(reqCtx, &dspRouterProto.GetRulesRequest{})
+	default:
+		http.Error(w, unknownRuleSetError(input.Kind), http.StatusNotFound)
+		return
+	}
+	writeFilterJson(w, res, err)
+}
+
+// putFilterRules заменяет правила набора телом запроса целиком. Если в теле указана
+// ревизия version, она должна совпадать с текущей, иначе ответ 409.
+func putFilterRules(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*rulesRequest)
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &dspRouterProto.JsonRequest{JsonData: data, Author: input.Author, Comment: input.Comment}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var res *dspRouterProto.UpdateRulesResponse
+	switch input.Kind {
+	case ruleSetDSP:
+		res, err = orchestratorClient.UpdateDSPRules(reqCtx, req)
+	case ruleSetSPP:
+		res, err = orchestratorClient.UpdateSPPRules(reqCtx, req)
+	default:
+		http.Error(w, unknownRuleSetError(input.Kind), http.StatusNotFound)
+		return
+	}
+	writeRulesUpdate(w, res, err)
+}
+
+// getRuleSyncStatus возвращает ревизии правил, которые обслуживает реплика DSP router
+func getRuleSyncStatus(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.GetRuleSyncStatus(reqCtx, &dspRouterProto.GetRulesRequest{})
+	writeFilterJson(w, res, err)
+}
+
+// getBlocklists возвращает загруженные blocklist SPP
+func getBlocklists(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.GetBlocklists(reqCtx, &dspRouterProto.GetRulesRequest{})
+	writeFilterJson(w, res, err)
+}
+
+// postBlocklistsReload перечитывает файлы blocklist SPP
+func postBlocklistsReload(
+	ctx context.Context,
+	w http.ResponseWriter,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.ReloadBlocklists(reqCtx, &dspRouterProto.GetRulesRequest{})
+	writeFilterJson(w, res, err)
+}
+
+func getRuleVersions(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*rulesRequest)
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.ListRuleVersions(reqCtx, &dspRouterProto.RuleVersionsRequest{Kind: input.Kind})
+	writeFilterJson(w, res, err)
+}
+
+func getRuleVersionsDiff(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*ruleVersionsDiffRequest)
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.DiffRuleVersions(reqCtx, &dspRouterProto.RuleVersionsDiffRequest{
+		Kind: input.Kind,
+		From: input.From,
+		To:   input.To,
+	})
+	writeFilterJson(w, res, err)
+}
+
+func postRulesRollback(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	timeout time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*rollbackRulesRequest)
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := orchestratorClient.RollbackRules(reqCtx, &dspRouterProto.RollbackRulesRequest{
+		Kind:     input.Kind,
+		Revision: input.Revision,
+		Author:   input.Author,
+		Comment:  input.Comment,
+	})
+	writeRulesUpdate(w, res, err)
+}
+
+// Наборы правил фильтра в пути админских URL
+const (
+	ruleSetDSP = "dsp"
+	ruleSetSPP = "spp"
+)
+
+func unknownRuleSetError(kind string) string {
+	return fmt.Sprintf("unknown rule set %q, expected %s or %s", kind, ruleSetDSP, ruleSetSPP)
+}
+
+// writeRouterError переводит gRPC статус ответа DSP router в HTTP код
+func writeRouterError(w http.ResponseWriter, err error) {
+	httpCode := http.StatusInternalServerError
+	if st, ok := status.FromError(err); ok {
+		httpCode = grpcRuntime.HTTPStatusFromCode(st.Code())
+	}
+
+	log.Printf("DSP router admin call failed: %v", err)
+	http.Error(w, err.Error(), httpCode)
+}
+
+func writeRulesUpdate(w http.ResponseWriter, res *dspRouterProto.UpdateRulesResponse, err error) {
+	if err != nil {
+		writeRouterError(w, err)
+		return
+	}
+
+	if err := rnr.JSON(w, http.StatusOK, res); err != nil {
+		log.Printf("Cannot make HTTP response back: %v\n", err)
+	}
+}
+
+func writeFilterJson(w http.ResponseWriter, res *dspRouterProto.JsonResponse, err error) {
+	if err != nil {
+		writeRouterError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(res.GetJsonData()); err != nil {
+		log.Printf("Cannot make HTTP response back: %v\n", err)
+	}
+}
@@ -0,0 +1,77 @@
+package sppAdapterWeb
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/floors"
+	bidEngineGrpc "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/bidEngine"
+)
+
+// floorDecisionsProto переводит выбор флоров в сообщения для статистики bid engine
+func floorDecisionsProto(decisions []floors.Decision) []*bidEngineGrpc.FloorDecision {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	result := make([]*bidEngineGrpc.FloorDecision, 0, len(decisions))
+	for _, decision := range decisions {
+		result = append(result, &bidEngineGrpc.FloorDecision{
+			ImpId:               decision.ImpID,
+			Source:              string(decision.Source),
+			FloorMicros:         int64(decision.Floor),
+			Cur:                 decision.Cur,
+			SspFloorMicros:      int64(decision.SspFloor),
+			ExchangeFloorMicros: int64(decision.ExchangeFloor),
+			RuleId:              decision.RuleID,
+		})
+	}
+	return result
+}
+
+func getFloorRules(
+	w http.ResponseWriter,
+	floorManager *floors.Manager,
+) {
+	if err := rnr.JSON(w, http.StatusOK, floorManager.Rules()); err != nil {
+		log.Printf("Cannot make HTTP response back: %v\n", err)
+	}
+}
+
+func putFloorRules(
+	w http.ResponseWriter,
+	r *http.Request,
+	floorManager *floors.Manager,
+) {
+	var config floors.RuleConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := floorManager.Replace(config); err != nil {
+		log.Printf("Cannot replace floor rules: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Floor rules replaced via admin API, %d rules", len(config.Rules))
+
+	getFloorRules(w, floorManager)
+}
+
+// postFloorRulesReload перечитывает правила из FLOOR_RULES_PATH, правки через PUT заменяются
+func postFloorRulesReload(
+	w http.ResponseWriter,
+	floorManager *floors.Manager,
+	floorRulesPath string,
+) {
+	if err := floorManager.Reload(); err != nil {
+		log.Printf("Cannot reload floor rules: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Floor rules reloaded from %s", floorRulesPath)
+
+	getFloorRules(w, floorManager)
+}
@@ -0,0 +1,178 @@
+package sppAdapterWeb
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/geoBadIp"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+)
+
+// Источник координат device.geo.type: определены по IP
+const GEO_TYPE_IP int32 = 2
+
+// fillField записывает значение из базы, если SSP не передал поле или включена перезапись
+func fillField[T any](field **T, value T, overwrite bool) {
+	if *field == nil || overwrite {
+		*field = &value
+	}
+}
+
+// enrichDevice_V2_4 дополняет device данными базы MaxMind. Поля, которые передал SSP,
+// заменяются только при overwrite; поля, которых нет в базе, не трогаются.
+func enrichDevice_V2_4(device *ortb_V2_4.Device, rec *geoBadIp.GeoIPRecord, overwrite bool, now time.Time) {
+	if device.Geo == nil {
+		device.Geo = &ortb_V2_4.Geo{}
+	}
+	geo := device.Geo
+
+	if rec.Country.ISOCode != "" {
+		fillField(&geo.Country, rec.Country.ISOCode, overwrite)
+	}
+	if region := rec.Region(); region != "" {
+		fillField(&geo.Region, region, overwrite)
+	}
+	if city := rec.CityName(); city != "" {
+		fillField(&geo.City, city, overwrite)
+	}
+	if metro := rec.Metro(); metro != "" {
+		fillField(&geo.Metro, metro, overwrite)
+	}
+	if rec.Postal.Code != "" {
+		fillField(&geo.Zip, rec.Postal.Code, overwrite)
+	}
+	// Местное время пользователя для правил по времени
+	if offset, ok := rec.UTCOffset(now); ok {
+		fillField(&geo.Utcoffset, offset, overwrite)
+	}
+	if rec.Location.TimeZone != "" {
+		if geo.Ext == nil {
+			geo.Ext = &ortb_V2_4.GeoExt{}
+		}
+		fillField(&geo.Ext.Timezone, rec.Location.TimeZone, overwrite)
+	}
+	// Координаты, их источник и точность заменяются вместе
+	if lat, lon, accuracy, ok := rec.Coordinates(); ok && (overwrite || geo.Lat == nil && geo.Lon == nil) {
+		geo.Lat = &lat
+		geo.Lon = &lon
+		geo.Type = ptr(GEO_TYPE_IP)
+		geo.Accuracy = nil
+		if accuracy > 0 {
+			geo.Accuracy = &accuracy
+		}
+	}
+
+	if connectionType := rec.OpenRTBConnectionType(); connectionType != geoBadIp.CONNECTION_TYPE_UNKNOWN {
+		fillField(&device.Connectiontype, connectionType, overwrite)
+	}
+	if rec.ASN.Number != 0 {
+		if device.Ext == nil {
+			device.Ext = &ortb_V2_4.DeviceExt{}
+		}
+		fillField(&device.Ext.Asn, int64(rec.ASN.Number), overwrite)
+		if rec.ASN.Organization != "" {
+			fillField(&device.Ext.Asorg, rec.ASN.Organization, overwrite)
+		}
+	}
+}
+
+// enrichDevice_V2_5 дополняет device данными базы MaxMind. Поля, которые передал SSP,
+// заменяются только при overwrite; поля, которых нет в базе, не трогаются.
+func enrichDevice_V2_5(device *ortb_V2_5.Device, rec *geoBadIp.GeoIPRecord, overwrite bool, now time.Time) {
+	if device.Geo == nil {
+		device.Geo = &ortb_V2_5.Geo{}
+	}
+	geo := device.Geo
+
+	if rec.Country.ISOCode != "" {
+		fillField(&geo.Country, rec.Country.ISOCode, overwrite)
+	}
+	if region := rec.Region(); region != "" {
+		fillField(&geo.Region, region, overwrite)
+	}
+	if city := rec.CityName(); city != "" {
+		fillField(&geo.City, city, overwrite)
+	}
+	if metro := rec.Metro(); metro != "" {
+		fillField(&geo.Metro, metro, overwrite)
+	}
+	if rec.Postal.Code != "" {
+		fillField(&geo.Zip, rec.Postal.Code, overwrite)
+	}
+	// Местное время пользователя для правил по времени
+	if offset, ok := rec.UTCOffset(now); ok {
+		fillField(&geo.Utcoffset, offset, overwrite)
+	}
+	if rec.Location.TimeZone != "" {
+		if geo.Ext == nil {
+			geo.Ext = &ortb_V2_5.GeoExt{}
+		}
+		fillField(&geo.Ext.Timezone, rec.Location.TimeZone, overwrite)
+	}
+	// Координаты, их источник и точность заменяются вместе
+	if lat, lon, accuracy, ok := rec.Coordinates(); ok && (overwrite || geo.Lat == nil && geo.Lon == nil) {
+		geo.Lat = &lat
+		geo.Lon = &lon
+		geo.Type = ptr(GEO_TYPE_IP)
+		geo.Accuracy = nil
+		if accuracy > 0 {
+			geo.Accuracy = &accuracy
+		}
+	}
+
+	if connectionType := rec.OpenRTBConnectionType(); connectionType != geoBadIp.CONNECTION_TYPE_UNKNOWN {
+		fillField(&device.Connectiontype, connectionType, overwrite)
+	}
+	if rec.ASN.Number != 0 {
+		if device.Ext == nil {
+			device.Ext = &ortb_V2_5.DeviceExt{}
+		}
+		fillField(&device.Ext.Asn, int64(rec.ASN.Number), overwrite)
+		if rec.ASN.Organization != "" {
+			fillField(&device.Ext.Asorg, rec.ASN.Organization, overwrite)
+		}
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// getGeoDatabases показывает загруженные базы MaxMind и время их сборки
+func getGeoDatabases(
+	w http.ResponseWriter,
+	geoDatabases []*geoBadIp.Database,
+) {
+	infos := make([]geoBadIp.DatabaseInfo, 0, len(geoDatabases))
+	for _, db := range geoDatabases {
+		infos = append(infos, db.Info())
+	}
+	if err := rnr.JSON(w, http.StatusOK, infos); err != nil {
+		log.Printf("Cannot make HTTP response back: %v\n", err)
+	}
+}
+
+// postGeoDatabasesReload перечитывает базы, не дожидаясь Watch.
+// Неудачная база остаётся прежней, остальные обновляются.
+func postGeoDatabasesReload(
+	w http.ResponseWriter,
+	geoDatabases []*geoBadIp.Database,
+) {
+	var errs []error
+	for _, db := range geoDatabases {
+		if err := db.Reload(); err != nil {
+			log.Printf("Cannot reload mmdb: %v", err)
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Println("GeoIP databases reloaded from files")
+
+	getGeoDatabases(w, geoDatabases)
+}
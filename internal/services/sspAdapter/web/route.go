@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"time"
 
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/floors"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/geoBadIp"
 	orchestratorProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_5"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/usersync"
 
 	"github.com/ggicci/httpin"
 	"github.com/ggicci/httpin/integration"
@@ -23,6 +26,31 @@ const (
 	GetBurlUrl = "/burl"
 
 	GetHealthUrl = "/health"
+
+	GetUserSyncUrl = "/usersync"
+	GetSetUidUrl   = "/setuid"
+
+	FloorRulesUrl       = "/admin/floors"
+	FloorRulesReloadUrl = "/admin/floors/reload"
+
+	GeoIpDatabasesUrl       = "/admin/geoip"
+	GeoIpDatabasesReloadUrl = "/admin/geoip/reload"
+
+	FilterExplain_V_2_4_URL = "/admin/filter/explain_v_2_4"
+	FilterExplain_V_2_5_URL = "/admin/filter/explain_v_2_5"
+	FilterStatsUrl          = "/admin/filter/stats"
+	FilterStatsResetUrl     = "/admin/filter/stats/reset"
+	FilterShadowUrl         = "/admin/filter/shadow"
+	FilterShadowPromoteUrl  = "/admin/filter/shadow/promote"
+
+	FilterRulesUrl             = "/admin/filter/rules/{kind}"
+	FilterRuleVersionsUrl      = "/admin/filter/rules/{kind}/versions"
+	FilterRuleVersionsDiffUrl  = "/admin/filter/rules/{kind}/diff"
+	FilterRuleVersionsRollback = "/admin/filter/rules/{kind}/rollback"
+	FilterRulesStatusUrl       = "/admin/filter/rules/status"
+
+	FilterBlocklistsUrl       = "/admin/filter/blocklists"
+	FilterBlocklistsReloadUrl = "/admin/filter/blocklists/reload"
 )
 
 type postBidRequest_V2_4 struct {
@@ -48,16 +76,56 @@ type nurlRequest struct {
 
 type burlRequest struct {
 	GlobalId string `in:"query=id"`
+	ImpId    string `in:"query=imp"`
 	DspURL   string `in:"query=url"`
 }
 
+// redirect - адрес возврата SSP с макросом ${UID}
+type userSyncRequest struct {
+	Redirect string `in:"query=redirect"`
+}
+
+// uid - uid пользователя в DSP, next - адрес возврата SSP, переданный по цепочке
+type setUidRequest struct {
+	Dsp  string `in:"query=dsp"`
+	Uid  string `in:"query=uid"`
+	Next string `in:"query=next"`
+}
+
+type ruleChangeRequest struct {
+	Author  string `in:"query=author"`
+	Comment string `in:"query=comment"`
+}
+
+// kind - набор правил фильтра: dsp или spp
+type rulesRequest struct {
+	Kind string `in:"path=kind"`
+	ruleChangeRequest
+}
+
+type ruleVersionsDiffRequest struct {
+	Kind string `in:"path=kind"`
+	From uint64 `in:"query=from"`
+	To   uint64 `in:"query=to"`
+}
+
+type rollbackRulesRequest struct {
+	Kind     string `in:"path=kind"`
+	Revision uint64 `in:"query=revision"`
+	ruleChangeRequest
+}
+
 func InitRoutes(
 	ctx context.Context,
 	httpRouter *chi.Mux,
 	redisClient *redis.Client,
 	isBadIp func(ipStr string) (bool, error),
-	getCountryISO func(ipStr string) (string, error),
+	lookupGeo func(ipStr string) (geoBadIp.GeoIPRecord, error),
 	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	floorManager *floors.Manager,
+	userSyncer *usersync.Syncer,
+	userSyncTTL time.Duration,
+	geoOverwrite bool,
 	bidRequestTimeout,
 	nurlTimeout,
 	burlTimeout time.Duration,
@@ -67,24 +135,158 @@ func InitRoutes(
 	httpRouter.With(
 		httpin.NewInput(postBidRequest_V2_4{}),
 	).Post(PostBid_V_2_4_URL, func(w http.ResponseWriter, r *http.Request) {
-		postBid_V2_4(ctx, w, r, redisClient, isBadIp, getCountryISO, orchestratorClient, bidRequestTimeout)
+		postBid_V2_4(ctx, w, r, redisClient, isBadIp, lookupGeo, orchestratorClient, floorManager, geoOverwrite, bidRequestTimeout)
 	})
 
 	httpRouter.With(
 		httpin.NewInput(postBidRequest_V2_5{}),
 	).Post(PostBid_V_2_5_URL, func(w http.ResponseWriter, r *http.Request) {
-		postBid_V2_5(ctx, w, r, redisClient, isBadIp, getCountryISO, orchestratorClient, bidRequestTimeout)
+		postBid_V2_5(ctx, w, r, redisClient, isBadIp, lookupGeo, orchestratorClient, floorManager, geoOverwrite, bidRequestTimeout)
 	})
 
-	httpRouter.Get(GetNurlUrl, func(w http.ResponseWriter, r *http.Request) {
+	httpRouter.With(
+		httpin.NewInput(nurlRequest{}),
+	).Get(GetNurlUrl, func(w http.ResponseWriter, r *http.Request) {
 		getNurl(ctx, w, r, nurlTimeout)
 	})
 
-	httpRouter.Get(GetBurlUrl, func(w http.ResponseWriter, r *http.Request) {
-		getBurl(ctx, w, r, redisClient, burlTimeout)
+	httpRouter.With(
+		httpin.NewInput(burlRequest{}),
+	).Get(GetBurlUrl, func(w http.ResponseWriter, r *http.Request) {
+		getBurl(ctx, w, r, redisClient, orchestratorClient, burlTimeout)
 	})
 
 	httpRouter.Get(GetHealthUrl, func(w http.ResponseWriter, r *http.Request) {
 		getHealth(w)
 	})
+
+	if userSyncer != nil {
+		httpRouter.With(
+			httpin.NewInput(userSyncRequest{}),
+		).Get(GetUserSyncUrl, func(w http.ResponseWriter, r *http.Request) {
+			getUserSync(w, r, userSyncer, userSyncTTL)
+		})
+
+		httpRouter.With(
+			httpin.NewInput(setUidRequest{}),
+		).Get(GetSetUidUrl, func(w http.ResponseWriter, r *http.Request) {
+			getSetUid(w, r, userSyncer)
+		})
+	}
+}
+
+// InitAdminRoutes регистрирует админ API. Его роутер слушает отдельный адрес,
+// который не публикуется наружу вместе с эндпоинтами для SSP.
+func InitAdminRoutes(
+	ctx context.Context,
+	adminRouter *chi.Mux,
+	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	floorManager *floors.Manager,
+	floorRulesPath string,
+	geoDatabases []*geoBadIp.Database,
+	requestTimeout time.Duration,
+) {
+	integration.UseGochiURLParam("path", chi.URLParam)
+
+	adminRouter.Post(FilterExplain_V_2_4_URL, func(w http.ResponseWriter, r *http.Request) {
+		postFilterExplain_V2_4(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Post(FilterExplain_V_2_5_URL, func(w http.ResponseWriter, r *http.Request) {
+		postFilterExplain_V2_5(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Get(FilterStatsUrl, func(w http.ResponseWriter, r *http.Request) {
+		getFilterStats(ctx, w, orchestratorClient, false, requestTimeout)
+	})
+
+	// Возвращает счётчики до сброса
+	adminRouter.Post(FilterStatsResetUrl, func(w http.ResponseWriter, r *http.Request) {
+		getFilterStats(ctx, w, orchestratorClient, true, requestTimeout)
+	})
+
+	adminRouter.Put(FilterShadowUrl, func(w http.ResponseWriter, r *http.Request) {
+		putShadowRules(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Get(FilterShadowUrl, func(w http.ResponseWriter, r *http.Request) {
+		getShadowReport(ctx, w, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Delete(FilterShadowUrl, func(w http.ResponseWriter, r *http.Request) {
+		deleteShadowRules(ctx, w, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(ruleChangeRequest{}),
+	).Post(FilterShadowPromoteUrl, func(w http.ResponseWriter, r *http.Request) {
+		postShadowRulesPromote(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Get(FilterRulesStatusUrl, func(w http.ResponseWriter, r *http.Request) {
+		getRuleSyncStatus(ctx, w, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Get(FilterBlocklistsUrl, func(w http.ResponseWriter, r *http.Request) {
+		getBlocklists(ctx, w, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Post(FilterBlocklistsReloadUrl, func(w http.ResponseWriter, r *http.Request) {
+		postBlocklistsReload(ctx, w, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(rulesRequest{}),
+	).Get(FilterRulesUrl, func(w http.ResponseWriter, r *http.Request) {
+		getFilterRules(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(rulesRequest{}),
+	).Put(FilterRulesUrl, func(w http.ResponseWriter, r *http.Request) {
+		putFilterRules(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(rulesRequest{}),
+	).Get(FilterRuleVersionsUrl, func(w http.ResponseWriter, r *http.Request) {
+		getRuleVersions(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(ruleVersionsDiffRequest{}),
+	).Get(FilterRuleVersionsDiffUrl, func(w http.ResponseWriter, r *http.Request) {
+		getRuleVersionsDiff(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.With(
+		httpin.NewInput(rollbackRulesRequest{}),
+	).Post(FilterRuleVersionsRollback, func(w http.ResponseWriter, r *http.Request) {
+		postRulesRollback(ctx, w, r, orchestratorClient, requestTimeout)
+	})
+
+	adminRouter.Get(GeoIpDatabasesUrl, func(w http.ResponseWriter, r *http.Request) {
+		getGeoDatabases(w, geoDatabases)
+	})
+
+	adminRouter.Post(GeoIpDatabasesReloadUrl, func(w http.ResponseWriter, r *http.Request) {
+		postGeoDatabasesReload(w, geoDatabases)
+	})
+
+	if floorManager != nil {
+		adminRouter.Get(FloorRulesUrl, func(w http.ResponseWriter, r *http.Request) {
+			getFloorRules(w, floorManager)
+		})
+
+		adminRouter.Put(FloorRulesUrl, func(w http.ResponseWriter, r *http.Request) {
+			putFloorRules(w, r, floorManager)
+		})
+
+		// Без файла правила живут только в памяти, перечитывать нечего
+		if floorRulesPath != "" {
+			adminRouter.Post(FloorRulesReloadUrl, func(w http.ResponseWriter, r *http.Request) {
+				postFloorRulesReload(w, floorManager, floorRulesPath)
+			})
+		}
+	}
 }
@@ -0,0 +1,97 @@
+package sppAdapterWeb
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ggicci/httpin"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/usersync"
+)
+
+// Прозрачный GIF 1x1, которым заканчивается цепочка синхронизации без адреса возврата
+var transparentPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// getUserSync начинает синхронизацию пользователя с DSP. uid биржи живёт в cookie,
+// новый пользователь получает его здесь. SSP узнаёт uid биржи через адрес
+// возврата redirect и присылает его в user.buyeruid.
+func getUserSync(
+	w http.ResponseWriter,
+	r *http.Request,
+	syncer *usersync.Syncer,
+	ttl time.Duration,
+) {
+	input := r.Context().Value(httpin.Input).(*userSyncRequest)
+
+	uid := exchangeUID(r)
+	if uid == "" {
+		uid = usersync.NewUID()
+	}
+	http.SetCookie(w, uidCookie(uid, ttl))
+
+	writeUserSyncStep(w, r, syncer.Start(r.Context(), uid, input.Redirect))
+}
+
+// getSetUid принимает uid пользователя от DSP и ведёт браузер к следующей DSP
+func getSetUid(
+	w http.ResponseWriter,
+	r *http.Request,
+	syncer *usersync.Syncer,
+) {
+	input := r.Context().Value(httpin.Input).(*setUidRequest)
+
+	uid := exchangeUID(r)
+	if uid == "" {
+		// Cookie биржи не дошла: uid DSP не к чему привязать
+		writeUserSyncStep(w, r, "")
+		return
+	}
+
+	step, err := syncer.SetUID(r.Context(), uid, input.Dsp, input.Uid, input.Next)
+	if err != nil {
+		log.Printf("Failed to set uid of dsp %s: %v", input.Dsp, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	writeUserSyncStep(w, r, step)
+}
+
+// exchangeUID возвращает uid биржи из cookie; пусто, если cookie нет или она подделана
+func exchangeUID(r *http.Request) string {
+	cookie, err := r.Cookie(usersync.UID_COOKIE)
+	if err != nil || !usersync.ValidUID(cookie.Value) {
+		return ""
+	}
+	return cookie.Value
+}
+
+func uidCookie(uid string, ttl time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     usersync.UID_COOKIE,
+		Value:    uid,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		// Синхронизация идёт из iframe и редиректов чужих доменов
+		SameSite: http.SameSiteNoneMode,
+	}
+}
+
+// writeUserSyncStep перенаправляет браузер на следующий шаг цепочки,
+// пустой шаг - цепочка закончена
+func writeUserSyncStep(w http.ResponseWriter, r *http.Request, step string) {
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	if step != "" {
+		http.Redirect(w, r, step, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.WriteHeader(http.StatusOK)
+	w.Write(transparentPixel)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/unrolled/render"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/constants"
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/floors"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/geoBadIp"
 	orchestratorProto "gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/services/orchestrator"
 	"gitlab.com/twinbid-exchange/RTB-exchange/internal/grpc/proto/types/ortb_V2_4"
@@ -32,14 +33,16 @@ func postBid_V2_4(
 	r *http.Request,
 	redisClient *redis.Client,
 	isBadIp func(ipStr string) (bool, error),
-	getCountryISO func(ipStr string) (string, error),
+	lookupGeo func(ipStr string) (geoBadIp.GeoIPRecord, error),
 	orchestratorClient orchestratorProto.OrchestratorServiceClient,
+	floorManager *floors.Manager,
+	geoOverwrite bool,
 	timeout time.Duration,
 ) {
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("Recovered from panic in postBid_V2_4: %v", r)
-			log.Printf(err.Error())
+			log.Print(err.Error())
 			http.Error(w, "", http.StatusInternalServerError)
 		}
 	}()
@@ -49,7 +52,7 @@ func postBid_V2_4(
 		err := fmt.Errorf(
 			"There is no device object",
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -59,7 +62,7 @@ func postBid_V2_4(
 		err := fmt.Errorf(
 			"There is no device ip",
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -70,7 +73,7 @@ func postBid_V2_4(
 			"There an server error while isBadIp: %w",
 			err,
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	} else if err != nil && bad == true {
@@ -78,55 +81,57 @@ func postBid_V2_4(
 			"Ip is bad: %w",
 			err,
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	countryISO, err := getCountryISO(*deviceIp)
-	if errors.As(err, geoBadIp.BadIpFormatError) {
+	geo, err := lookupGeo(*deviceIp)
+	if errors.Is(err, geoBadIp.BadIpFormatError) {
 		err := fmt.Errorf(
 			"Bad format: %w",
 			err,
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
-	} else if errors.As(err, geoBadIp.InnerLookupIpError) {
+	} else if errors.Is(err, geoBadIp.InnerLookupIpError) {
 		err := fmt.Errorf(
-			"There an server error while getCountryISO: %w",
+			"There an server error while lookupGeo: %w",
 			err,
 		)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Гео из базы дополняет то, что передал SSP, или заменяет его при geoOverwrite
+	enrichDevice_V2_4(input.Payload.Device, &geo, geoOverwrite, time.Now())
+	countryISO := input.Payload.Device.Geo.GetCountry()
+
 	globalId := uuid.New().String()
 
-	bidReqData, err := json.Marshal(input.Payload)
-	if err != nil {
-		fmt.Printf("failed to marshal JSON in postBid_V2_4: %w", err)
+	if err := utils.WriteStringToRedis(ctx, redisClient, globalId, constants.GEO_COLUMN, countryISO); err != nil {
+		fmt.Printf("failed to WriteStringToRedis Geo in postBid_V2_4: %v", err)
 	}
 
-	if err := utils.WriteJsonToRedis(ctx, redisClient, globalId, constants.BID_REQUEST_COLUMN, bidReqData); err != nil {
-		fmt.Printf("failed to WriteJsonToRedis Bid Request in postBid_V2_4: %w", err)
+	if err := utils.WriteStringToRedis(ctx, redisClient, globalId, constants.RESULT_COLUMN, constants.UNSUCCESS); err != nil {
+		fmt.Printf("failed to WriteStringToRedis SUCCESS in postBid_V2_4: %v", err)
 	}
 
-	if err := utils.WriteStringToRedis(ctx, redisClient, globalId, constants.GEO_COLUMN, countryISO); err != nil {
-		fmt.Printf("failed to WriteStringToRedis Geo in postBid_V2_4: %w", err)
+	var floorDecisions []floors.Decision
+	if floorManager != nil {
+		floorDecisions = floorManager.Apply_V2_4(input.Payload, r.Host, countryISO)
 	}
 
-	if err := utils.WriteStringToRedis(ctx, redisClient, globalId, constants.RESULT_COLUMN, constants.UNSUCCESS); err != nil {
-		fmt.Printf("failed to WriteStringToRedis SUCCESS in postBid_V2_4: %w", err)
+	// Запрос сохраняется с флорами, которые ушли в DSP
+	bidReqData, err := json.Marshal(input.Payload)
+	if err != nil {
+		fmt.Printf("failed to marshal JSON in postBid_V2_4: %v", err)
 	}
 
-	if input.Payload.Device.Geo == nil {
-		input.Payload.Device.Geo = &ortb_V2_4.Geo{
-			Country: &countryISO,
-		}
-	} else {
-		input.Payload.Device.Geo.Country = &countryISO
+	if err := utils.WriteJsonToRedis(ctx, redisClient, globalId, constants.BID_REQUEST_COLUMN, bidReqData); err != nil {
+		fmt.Printf("failed to WriteJsonToRedis Bid Request in postBid_V2_4: %v", err)
 	}
 
 	reqCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -135,9 +140,10 @@ func postBid_V2_4(
 	res, err := orchestratorClient.GetWinnerBid_V2_4(
 		reqCtx,
 		&orchestratorProto.OrchestratorRequest_V2_4{
-			BidRequest:  input.Payload,
-			SppEndpoint: r.Host,
-			GlobalId:    globalId,
+			BidRequest:     input.Payload,
+			SppEndpoint:    r.Host,
+			GlobalId:       globalId,
+			FloorDecisions: floorDecisionsProto(floorDecisions),
 		},
 	)
 	if err != nil {
@@ -151,11 +157,11 @@ func postBid_V2_4(
 		}
 
 		http.Error(w, httpErr.Error(), httpCode)
-		log.Printf(err.Error())
+		log.Print(err.Error())
 		return
 	}
 	statusCode := http.StatusOK
-	if len(res.BidResponse.Seatbid.Bid) == 0 {
+	if !hasBids_V2_4(res.BidResponse) {
 		statusCode = http.StatusNoContent
 	}
 
@@ -165,3 +171,12 @@ func postBid_V2_4(
 		log.Printf("Cannot make HTTP response back: %v\n", err)
 	}
 }
+
+func hasBids_V2_4(bidResponse *ortb_V2_4.BidResponse) bool {
+	for _, seatBid := range bidResponse.GetSeatbid() {
+		if len(seatBid.GetBid()) > 0 {
+			return true
+		}
+	}
+	return false
+}
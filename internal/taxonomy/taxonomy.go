@@ -0,0 +1,302 @@
+package taxonomy
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filewatch"
+)
+
+// Taxonomy - таксономия категорий контента. Каноническая - IAB Content Taxonomy
+// 1.0 (IAB1-2): в ней cat и bcat определены в OpenRTB 2.5, в ней пишутся правила
+// фильтра и blocklist. Категории остальных таксономий переводятся через таблицы.
+type Taxonomy string
+
+const (
+	// IAB Content Taxonomy 1.0
+	IAB1 Taxonomy = "iab1"
+	// IAB Content Taxonomy 2.x, уникальные ID категорий
+	IAB2 Taxonomy = "iab2"
+
+	Canonical = IAB1
+)
+
+//go:embed data/iab1_iab2.csv
+var iab2Table string
+
+// MappingConfig - дополнительные таблицы: для каждой таксономии код категории и
+// канонические категории, которым он соответствует. Так задаются собственные
+// коды SSP и DSP и строки IAB 2.x, которых нет во встроенной таблице.
+type MappingConfig struct {
+	Taxonomies map[Taxonomy]map[string][]string `json:"taxonomies"`
+}
+
+// table - соответствие кодов таксономии каноническим категориям в обе стороны
+type table struct {
+	toCanonical   map[string][]string
+	fromCanonical map[string][]string
+}
+
+func newTable() *table {
+	return &table{
+		toCanonical:   make(map[string][]string),
+		fromCanonical: make(map[string][]string),
+	}
+}
+
+func (t *table) add(code, canonical string) {
+	t.toCanonical[code] = appendUnique(t.toCanonical[code], canonical)
+	t.fromCanonical[canonical] = appendUnique(t.fromCanonical[canonical], code)
+}
+
+// Mapper переводит категории между таксономиями. Чтение lock-free, файл
+// дополнительных таблиц перечитывается при изменении. nil *Mapper категории
+// не переводит.
+type Mapper struct {
+	path    string
+	tables  atomic.Pointer[map[Taxonomy]*table]
+	modTime atomic.Int64
+}
+
+// NewMapper загружает встроенные таблицы и таблицы из файла; пустой путь -
+// только встроенные
+func NewMapper(path string) (*Mapper, error) {
+	m := &Mapper{path: path}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Mapper) Reload() error {
+	tables, err := bundledTables()
+	if err != nil {
+		return err
+	}
+
+	if m.path != "" {
+		info, err := os.Stat(m.path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(m.path)
+		if err != nil {
+			return err
+		}
+		var config MappingConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return fmt.Errorf("invalid taxonomy mappings file %s: %v", m.path, err)
+		}
+		if err := addMappings(tables, config); err != nil {
+			return fmt.Errorf("taxonomy mappings validation failed: %v", err)
+		}
+		m.modTime.Store(info.ModTime().UnixNano())
+	}
+
+	m.tables.Store(&tables)
+	return nil
+}
+
+// Watch перечитывает файл таблиц при изменении времени модификации
+func (m *Mapper) Watch(ctx context.Context, interval time.Duration) {
+	if m.path == "" {
+		return
+	}
+	filewatch.Watch(ctx, m.path, interval, &m.modTime, "taxonomy mappings", m.Reload)
+}
+
+// Known сообщает, что категории таксономии можно переводить
+func (m *Mapper) Known(taxonomy Taxonomy) bool {
+	if taxonomy == Canonical {
+		return true
+	}
+	if m == nil {
+		return false
+	}
+	_, ok := (*m.tables.Load())[taxonomy]
+	return ok
+}
+
+// Normalize переводит категории таксономии from в канонические. Категории без
+// соответствия отбрасываются, повторы убираются.
+func (m *Mapper) Normalize(from Taxonomy, cats []string) []string {
+	if len(cats) == 0 {
+		return cats
+	}
+	if from == Canonical || m == nil {
+		return canonicalCodes(cats)
+	}
+	table := (*m.tables.Load())[from]
+	if table == nil {
+		return cats
+	}
+
+	normalized := make([]string, 0, len(cats))
+	for _, cat := range cats {
+		for _, canonical := range table.toCanonical[strings.TrimSpace(cat)] {
+			normalized = appendUnique(normalized, canonical)
+		}
+	}
+	return normalized
+}
+
+// Translate переводит канонические категории в таксономию to. Подкатегория без
+// своего соответствия переводится по родителю: IAB7-3 как IAB7.
+func (m *Mapper) Translate(to Taxonomy, cats []string) []string {
+	if len(cats) == 0 || to == Canonical || m == nil {
+		return cats
+	}
+	table := (*m.tables.Load())[to]
+	if table == nil {
+		return cats
+	}
+
+	translated := make([]string, 0, len(cats))
+	for _, cat := range cats {
+		codes, ok := table.fromCanonical[cat]
+		if !ok {
+			codes = table.fromCanonical[Parent(cat)]
+		}
+		for _, code := range codes {
+			translated = appendUnique(translated, code)
+		}
+	}
+	return translated
+}
+
+// Parent возвращает родителя канонической категории: IAB7 для IAB7-3. У категории
+// первого уровня родитель - она сама.
+func Parent(cat string) string {
+	if dash := strings.IndexByte(cat, '-'); dash > 0 {
+		return cat[:dash]
+	}
+	return cat
+}
+
+// CanonicalCode приводит категорию IAB 1.0 к виду IAB7-3
+func CanonicalCode(cat string) string {
+	cat = strings.TrimSpace(cat)
+	if len(cat) >= 3 && strings.EqualFold(cat[:3], "IAB") && cat[:3] != "IAB" {
+		return "IAB" + cat[3:]
+	}
+	return cat
+}
+
+func validCanonical(cat string) bool {
+	rest, ok := strings.CutPrefix(cat, "IAB")
+	if !ok {
+		return false
+	}
+	tier1, tier2, sub := strings.Cut(rest, "-")
+	return isNumber(tier1) && (!sub || isNumber(tier2))
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func canonicalCodes(cats []string) []string {
+	normalized := make([]string, 0, len(cats))
+	for _, cat := range cats {
+		if cat = CanonicalCode(cat); cat != "" {
+			normalized = appendUnique(normalized, cat)
+		}
+	}
+	return normalized
+}
+
+func bundledTables() (map[Taxonomy]*table, error) {
+	rows, err := csv.NewReader(strings.NewReader(stripComments(iab2Table))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundled IAB 2.x table: %v", err)
+	}
+
+	iab2 := newTable()
+	// Первая строка - заголовок
+	for _, row := range rows[1:] {
+		if !validCanonical(row[0]) {
+			return nil, fmt.Errorf("invalid bundled IAB 2.x table: bad category %s", row[0])
+		}
+		iab2.add(row[1], row[0])
+	}
+	return map[Taxonomy]*table{IAB2: iab2}, nil
+}
+
+func addMappings(tables map[Taxonomy]*table, config MappingConfig) error {
+	for taxonomy, codes := range config.Taxonomies {
+		if taxonomy == "" || taxonomy == Canonical {
+			return fmt.Errorf("cannot map taxonomy %q", taxonomy)
+		}
+		t := tables[taxonomy]
+		if t == nil {
+			t = newTable()
+			tables[taxonomy] = t
+		}
+		for code, cats := range codes {
+			code = strings.TrimSpace(code)
+			if code == "" {
+				return fmt.Errorf("taxonomy %s has an empty code", taxonomy)
+			}
+			for _, cat := range cats {
+				cat = CanonicalCode(cat)
+				if !validCanonical(cat) {
+					return fmt.Errorf("taxonomy %s code %s maps to invalid IAB 1.0 category %q", taxonomy, code, cat)
+				}
+				t.add(code, cat)
+			}
+		}
+	}
+	return nil
+}
+
+func stripComments(data string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// ParseEndpoints проверяет таксономии SSP или DSP из конфига (endpoint=iab2).
+// Endpoint без настройки работает в канонической таксономии.
+func ParseEndpoints(m *Mapper, configured map[string]string) (map[string]Taxonomy, error) {
+	taxonomies := make(map[string]Taxonomy, len(configured))
+	for endpoint, name := range configured {
+		taxonomy := Taxonomy(strings.ToLower(strings.TrimSpace(name)))
+		if !m.Known(taxonomy) {
+			return nil, fmt.Errorf("unknown taxonomy %s for %s", name, endpoint)
+		}
+		if taxonomy != Canonical {
+			taxonomies[endpoint] = taxonomy
+		}
+	}
+	return taxonomies, nil
+}
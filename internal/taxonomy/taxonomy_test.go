@@ -0,0 +1,62 @@
+package taxonomy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMapper(t *testing.T, data string) (*Mapper, string) {
+	path := filepath.Join(t.TempDir(), "taxonomies.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	m, err := NewMapper(path)
+	require.NoError(t, err)
+	return m, path
+}
+
+func TestMapperBundledTable(t *testing.T) {
+	m, err := NewMapper("")
+	require.NoError(t, err)
+
+	assert.True(t, m.Known(IAB1))
+	assert.True(t, m.Known(IAB2))
+	assert.False(t, m.Known("acme"))
+
+	assert.Equal(t, []string{"IAB17", "IAB1-5"}, m.Normalize(IAB2, []string{"483", "324", "483", "unknown"}))
+	// 379 - и новости, и политика
+	assert.Equal(t, []string{"IAB11", "IAB12"}, m.Normalize(IAB2, []string{"379"}))
+	assert.Equal(t, []string{"IAB7-3", "IAB1"}, m.Normalize(IAB1, []string{"iab7-3", " IAB1 ", "IAB7-3"}))
+
+	assert.Equal(t, []string{"223", "324"}, m.Translate(IAB2, []string{"IAB7-3", "IAB1-5", "IAB7"}))
+	assert.Empty(t, m.Translate(IAB2, []string{"IAB26"}))
+	assert.Equal(t, []string{"IAB7-3"}, m.Translate(IAB1, []string{"IAB7-3"}))
+}
+
+func TestMapperCustomTaxonomies(t *testing.T) {
+	m, path := newTestMapper(t, `{"taxonomies": {
+		"acme": {"sport": ["IAB17"], "cars": ["iab2"]},
+		"iab2": {"9999": ["IAB26"]}
+	}}`)
+
+	assert.True(t, m.Known("acme"))
+	assert.Equal(t, []string{"IAB17", "IAB2"}, m.Normalize("acme", []string{"sport", "cars"}))
+	assert.Equal(t, []string{"sport"}, m.Translate("acme", []string{"IAB17-12"}))
+	// Файл дополняет встроенную таблицу
+	assert.Equal(t, []string{"9999", "1"}, m.Translate(IAB2, []string{"IAB26", "IAB2"}))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"taxonomies": {"acme": {"sport": ["sports"]}}}`), 0o644))
+	assert.ErrorContains(t, m.Reload(), "invalid IAB 1.0 category")
+	// Неудачная перезагрузка не меняет таблицы
+	assert.Equal(t, []string{"IAB17"}, m.Normalize("acme", []string{"sport"}))
+
+	_, err := NewMapper(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	var nilMapper *Mapper
+	assert.Equal(t, []string{"x"}, nilMapper.Translate(IAB2, []string{"x"}))
+	assert.False(t, nilMapper.Known(IAB2))
+}
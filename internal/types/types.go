@@ -7,4 +7,7 @@ type StatisticsRecord struct {
 	BID_RESPONSE_WINNER              string `json:"BID_RESPONSE_WINNER"`
 	BID_RESPONSE_WINNER_BY_DSP_PRICE string `json:"BID_RESPONSE_WINNER_BY_DSP_PRICE"`
 	SUCCESS                          string `json:"SUCCESS"`
+	FLOOR_SOURCE                     string `json:"FLOOR_SOURCE"`
+	MARGIN                           string `json:"MARGIN"`
+	DEALS                            string `json:"DEALS"`
 }
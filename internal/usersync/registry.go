@@ -0,0 +1,160 @@
+package usersync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/twinbid-exchange/RTB-exchange/internal/filewatch"
+)
+
+// Registry - DSP, с которыми биржа синхронизирует пользователей. Чтение lock-free,
+// файл перечитывается при изменении. nil *Registry - синхронизация выключена.
+type Registry struct {
+	path    string
+	config  atomic.Pointer[registryConfig]
+	modTime atomic.Int64
+}
+
+// registryConfig - проверенный файл синхронизации с индексами
+type registryConfig struct {
+	dsps        []*DSP
+	byID        map[string]*DSP
+	redirectSet map[string]struct{}
+}
+
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Registry) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("invalid user sync file %s: %v", r.path, err)
+	}
+	compiled, err := compileConfig(config)
+	if err != nil {
+		return fmt.Errorf("user sync validation failed: %v", err)
+	}
+
+	r.config.Store(compiled)
+	r.modTime.Store(info.ModTime().UnixNano())
+	return nil
+}
+
+// Watch перечитывает файл синхронизации при изменении времени модификации
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	filewatch.Watch(ctx, r.path, interval, &r.modTime, "user sync", r.Reload)
+}
+
+func (r *Registry) Get(id string) (*DSP, bool) {
+	if r == nil {
+		return nil, false
+	}
+	dsp, ok := r.config.Load().byID[id]
+	return dsp, ok
+}
+
+// Next возвращает первую DSP после after в порядке файла, с которой пользователь
+// ещё не синхронизирован. Пустой after - с начала списка.
+func (r *Registry) Next(after string, synced map[string]string) (*DSP, bool) {
+	if r == nil {
+		return nil, false
+	}
+	dsps := r.config.Load().dsps
+	start := 0
+	if after != "" {
+		for i, dsp := range dsps {
+			if dsp.ID == after {
+				start = i + 1
+				break
+			}
+		}
+	}
+	for _, dsp := range dsps[start:] {
+		if _, ok := synced[dsp.ID]; !ok {
+			return dsp, true
+		}
+	}
+	return nil, false
+}
+
+// AllowsRedirect сообщает, что после синхронизации можно вернуть браузер по адресу:
+// только https и только на хосты из redirectHosts
+func (r *Registry) AllowsRedirect(redirect string) bool {
+	if r == nil || redirect == "" {
+		return false
+	}
+	u, err := url.Parse(redirect)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	_, ok := r.config.Load().redirectSet[strings.ToLower(u.Hostname())]
+	return ok
+}
+
+func compileConfig(config Config) (*registryConfig, error) {
+	compiled := &registryConfig{
+		dsps:        make([]*DSP, 0, len(config.DSPs)),
+		byID:        make(map[string]*DSP, len(config.DSPs)),
+		redirectSet: make(map[string]struct{}, len(config.RedirectHosts)),
+	}
+	endpoints := make(map[string]string)
+
+	for i := range config.DSPs {
+		dsp := config.DSPs[i]
+		if dsp.ID == "" {
+			return nil, fmt.Errorf("dsp #%d has no id", i)
+		}
+		if _, ok := compiled.byID[dsp.ID]; ok {
+			return nil, fmt.Errorf("duplicate dsp id %s", dsp.ID)
+		}
+		if !strings.Contains(dsp.SyncURL, REDIRECT_MACRO) {
+			return nil, fmt.Errorf("dsp %s: sync url must contain %s", dsp.ID, REDIRECT_MACRO)
+		}
+		if _, err := url.Parse(dsp.SyncURL); err != nil {
+			return nil, fmt.Errorf("dsp %s: invalid sync url: %v", dsp.ID, err)
+		}
+		if dsp.UIDMacro == "" {
+			dsp.UIDMacro = DEFAULT_DSP_UID_MACRO
+		}
+		for _, endpoint := range dsp.Endpoints {
+			if other, ok := endpoints[endpoint]; ok {
+				return nil, fmt.Errorf("endpoint %s belongs to dsp %s and %s", endpoint, other, dsp.ID)
+			}
+			endpoints[endpoint] = dsp.ID
+		}
+
+		compiled.dsps = append(compiled.dsps, &dsp)
+		compiled.byID[dsp.ID] = &dsp
+	}
+
+	for _, host := range config.RedirectHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			return nil, fmt.Errorf("empty redirect host")
+		}
+		compiled.redirectSet[host] = struct{}{}
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,44 @@
+package usersync
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const KEY_PREFIX = "usersync:"
+
+// Store хранит соответствие uid биржи и uid DSP
+type Store interface {
+	Save(ctx context.Context, uid string, dsp string, dspUID string) error
+	// Lookup возвращает uid пользователя по ID DSP; пустой результат - пользователь не синхронизирован
+	Lookup(ctx context.Context, uid string) (map[string]string, error)
+}
+
+// RedisStore держит uid DSP в хеше пользователя. Каждая синхронизация продлевает
+// жизнь всего хеша на ttl.
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (s *RedisStore) Save(ctx context.Context, uid string, dsp string, dspUID string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, KEY_PREFIX+uid, dsp, dspUID)
+		pipe.Expire(ctx, KEY_PREFIX+uid, s.ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Lookup(ctx context.Context, uid string) (map[string]string, error) {
+	return s.client.HGetAll(ctx, KEY_PREFIX+uid).Result()
+}
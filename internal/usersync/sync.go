@@ -0,0 +1,125 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+var ErrUnknownDSP = errors.New("unknown user sync dsp")
+
+// Syncer ведёт браузер по цепочке синхронизации: /usersync -> DSP -> /setuid ->
+// следующая DSP -> ... -> адрес возврата SSP. DSP, с которыми пользователь уже
+// синхронизирован, пропускаются.
+type Syncer struct {
+	registry *Registry
+	store    Store
+	// Адрес /setuid биржи, на который DSP возвращают браузер
+	setUIDURL string
+}
+
+func NewSyncer(registry *Registry, store Store, setUIDURL string) *Syncer {
+	return &Syncer{
+		registry:  registry,
+		store:     store,
+		setUIDURL: setUIDURL,
+	}
+}
+
+// Start начинает цепочку для пользователя uid. redirect - адрес возврата SSP,
+// ${UID} в нём заменяется на uid биржи. Возвращает адрес следующего шага;
+// пустой адрес - цепочка закончена, браузеру отдаётся пиксель.
+func (s *Syncer) Start(ctx context.Context, uid string, redirect string) string {
+	next := ""
+	if s.registry.AllowsRedirect(redirect) {
+		next = strings.ReplaceAll(redirect, UID_MACRO, uid)
+	}
+	return s.nextStep(ctx, uid, "", next)
+}
+
+// SetUID сохраняет uid пользователя в DSP и продолжает цепочку после неё.
+// Пустой dspUID (пользователь отказался от синхронизации в DSP) не сохраняется.
+func (s *Syncer) SetUID(ctx context.Context, uid string, dsp string, dspUID string, next string) (string, error) {
+	if _, ok := s.registry.Get(dsp); !ok {
+		return "", ErrUnknownDSP
+	}
+	if !s.registry.AllowsRedirect(next) {
+		next = ""
+	}
+
+	switch {
+	case dspUID == "":
+	case len(dspUID) > MAX_DSP_UID_LENGTH:
+		log.Printf("User sync: uid of dsp %s is too long: %d", dsp, len(dspUID))
+	default:
+		if err := s.store.Save(ctx, uid, dsp, dspUID); err != nil {
+			log.Printf("Cannot save uid of dsp %s: %v", dsp, err)
+		}
+	}
+
+	return s.nextStep(ctx, uid, dsp, next), nil
+}
+
+func (s *Syncer) nextStep(ctx context.Context, uid string, after string, next string) string {
+	synced, err := s.store.Lookup(ctx, uid)
+	if err != nil {
+		log.Printf("Cannot read user sync of %s: %v", uid, err)
+	}
+	if dsp, ok := s.registry.Next(after, synced); ok {
+		return dsp.URL(s.setUIDURL, next)
+	}
+	return next
+}
+
+// Resolver находит uid пользователя в DSP для запросов роутера.
+// nil *Resolver - синхронизация выключена.
+type Resolver struct {
+	registry *Registry
+	store    Store
+	// Сколько запрос ждёт хранилище, после этого уходит без buyeruid
+	timeout time.Duration
+}
+
+func NewResolver(registry *Registry, store Store, timeout time.Duration) *Resolver {
+	return &Resolver{
+		registry: registry,
+		store:    store,
+		timeout:  timeout,
+	}
+}
+
+// BuyerUIDs возвращает uid пользователя по endpoint DSP; nil, если uid биржи
+// не выдан биржей или пользователь ни с кем не синхронизирован
+func (r *Resolver) BuyerUIDs(ctx context.Context, uid string) map[string]string {
+	if r == nil || !ValidUID(uid) {
+		return nil
+	}
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	synced, err := r.store.Lookup(ctx, uid)
+	if err != nil {
+		log.Printf("Cannot read user sync of %s: %v", uid, err)
+		return nil
+	}
+
+	var buyerUIDs map[string]string
+	for id, dspUID := range synced {
+		dsp, ok := r.registry.Get(id)
+		if !ok {
+			continue
+		}
+		if buyerUIDs == nil {
+			buyerUIDs = make(map[string]string, len(synced))
+		}
+		for _, endpoint := range dsp.Endpoints {
+			buyerUIDs[endpoint] = dspUID
+		}
+	}
+	return buyerUIDs
+}
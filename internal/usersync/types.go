@@ -0,0 +1,67 @@
+package usersync
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+const (
+	// Макрос в URL синхронизации DSP: вместо него подставляется адрес /setuid биржи
+	REDIRECT_MACRO = "${REDIRECT}"
+	// Макрос в адресе возврата SSP: вместо него подставляется uid биржи
+	UID_MACRO = "${UID}"
+	// Макрос по умолчанию, вместо которого DSP подставляет свой uid
+	DEFAULT_DSP_UID_MACRO = "$UID"
+
+	// Cookie с uid биржи
+	UID_COOKIE = "uid"
+
+	// Длина uid DSP, больше которой значение не сохраняется
+	MAX_DSP_UID_LENGTH = 256
+)
+
+// DSP - партнёр синхронизации. ID используется в /setuid?dsp=, Endpoints - адреса
+// DSP в роутере, в запросы к ним подставляется user.buyeruid.
+type DSP struct {
+	ID        string   `json:"id"`
+	Endpoints []string `json:"endpoints"`
+	// URL синхронизации DSP с макросом ${REDIRECT}
+	SyncURL string `json:"syncUrl"`
+	// Макрос, вместо которого DSP подставит свой uid в адрес возврата
+	UIDMacro string `json:"uidMacro,omitempty"`
+}
+
+type Config struct {
+	DSPs []DSP `json:"dsps"`
+	// Хосты SSP, на которые можно вернуть браузер после синхронизации
+	RedirectHosts []string `json:"redirectHosts"`
+}
+
+// URL - адрес синхронизации DSP. DSP вернёт браузер на setUIDURL со своим uid,
+// next передаётся по цепочке до последней DSP.
+func (d *DSP) URL(setUIDURL string, next string) string {
+	redirect := setUIDURL + "?dsp=" + url.QueryEscape(d.ID)
+	if next != "" {
+		redirect += "&next=" + url.QueryEscape(next)
+	}
+	redirect += "&uid=" + d.UIDMacro
+	return strings.Replace(d.SyncURL, REDIRECT_MACRO, url.QueryEscape(redirect), 1)
+}
+
+// NewUID создаёт uid биржи
+func NewUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidUID проверяет, что uid из cookie или запроса SSP выдан биржей
+func ValidUID(uid string) bool {
+	if len(uid) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(uid)
+	return err == nil
+}
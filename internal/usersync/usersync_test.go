@@ -0,0 +1,149 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUID = "0123456789abcdef0123456789abcdef"
+
+// memoryStore - соответствия uid в памяти вместо Redis
+type memoryStore struct {
+	uids map[string]map[string]string
+	err  error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{uids: make(map[string]map[string]string)}
+}
+
+func (s *memoryStore) Save(ctx context.Context, uid string, dsp string, dspUID string) error {
+	if s.uids[uid] == nil {
+		s.uids[uid] = make(map[string]string)
+	}
+	s.uids[uid][dsp] = dspUID
+	return nil
+}
+
+func (s *memoryStore) Lookup(ctx context.Context, uid string) (map[string]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.uids[uid], nil
+}
+
+func newTestRegistry(t *testing.T, data string) *Registry {
+	path := filepath.Join(t.TempDir(), "usersync.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	r, err := NewRegistry(path)
+	require.NoError(t, err)
+	return r
+}
+
+const testConfig = `{
+	"dsps": [
+		{"id": "dsp1", "endpoints": ["http://dsp1/bid", "http://dsp1/bid_v_2_4"], "syncUrl": "https://dsp1.com/sync?r=${REDIRECT}"},
+		{"id": "dsp2", "endpoints": ["http://dsp2/bid"], "syncUrl": "https://dsp2.com/sync?redir=${REDIRECT}", "uidMacro": "[UID]"}
+	],
+	"redirectHosts": ["ssp.com"]
+}`
+
+func TestRegistryValidation(t *testing.T) {
+	for name, data := range map[string]string{
+		"no id":              `{"dsps": [{"syncUrl": "https://dsp1.com/sync?r=${REDIRECT}"}]}`,
+		"duplicate id":       `{"dsps": [{"id": "a", "syncUrl": "https://a/${REDIRECT}"}, {"id": "a", "syncUrl": "https://a/${REDIRECT}"}]}`,
+		"no redirect macro":  `{"dsps": [{"id": "a", "syncUrl": "https://a/sync"}]}`,
+		"duplicate endpoint": `{"dsps": [{"id": "a", "endpoints": ["e"], "syncUrl": "https://a/${REDIRECT}"}, {"id": "b", "endpoints": ["e"], "syncUrl": "https://b/${REDIRECT}"}]}`,
+		"empty redirect":     `{"redirectHosts": [" "]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "usersync.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		_, err := NewRegistry(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRegistryNextAndRedirects(t *testing.T) {
+	r := newTestRegistry(t, testConfig)
+
+	dsp, ok := r.Next("", nil)
+	require.True(t, ok)
+	assert.Equal(t, "dsp1", dsp.ID)
+	dsp, ok = r.Next("", map[string]string{"dsp1": "x"})
+	require.True(t, ok)
+	assert.Equal(t, "dsp2", dsp.ID)
+	_, ok = r.Next("dsp2", nil)
+	assert.False(t, ok)
+
+	assert.True(t, r.AllowsRedirect("https://SSP.com/setuid?buyeruid=${UID}"))
+	assert.False(t, r.AllowsRedirect("http://ssp.com/setuid"))
+	assert.False(t, r.AllowsRedirect("https://evil.com/?ssp.com"))
+
+	var nilRegistry *Registry
+	_, ok = nilRegistry.Next("", nil)
+	assert.False(t, ok)
+	assert.False(t, nilRegistry.AllowsRedirect("https://ssp.com/"))
+}
+
+func TestSyncerChain(t *testing.T) {
+	store := newMemoryStore()
+	syncer := NewSyncer(newTestRegistry(t, testConfig), store, "https://exchange.com/setuid")
+
+	step := syncer.Start(context.Background(), testUID, "https://ssp.com/setuid?buyeruid=${UID}")
+	u, err := url.Parse(step)
+	require.NoError(t, err)
+	assert.Equal(t, "dsp1.com", u.Host)
+	assert.Equal(t,
+		"https://exchange.com/setuid?dsp=dsp1&next="+url.QueryEscape("https://ssp.com/setuid?buyeruid="+testUID)+"&uid=$UID",
+		u.Query().Get("r"),
+	)
+
+	// DSP возвращает браузер, цепочка идёт к следующей DSP со своим макросом
+	step, err = syncer.SetUID(context.Background(), testUID, "dsp1", "d1-user", "https://ssp.com/setuid?buyeruid="+testUID)
+	require.NoError(t, err)
+	u, err = url.Parse(step)
+	require.NoError(t, err)
+	assert.Equal(t, "dsp2.com", u.Host)
+	assert.Contains(t, u.Query().Get("redir"), "&uid=[UID]")
+
+	// Пользователь отказался от синхронизации во второй DSP, цепочка возвращается в SSP
+	step, err = syncer.SetUID(context.Background(), testUID, "dsp2", "", "https://ssp.com/setuid?buyeruid="+testUID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://ssp.com/setuid?buyeruid="+testUID, step)
+	assert.Equal(t, map[string]string{"dsp1": "d1-user"}, store.uids[testUID])
+
+	// Синхронизированная DSP пропускается, адрес возврата вне списка игнорируется
+	step = syncer.Start(context.Background(), testUID, "https://evil.com/")
+	assert.Contains(t, step, "https://dsp2.com/sync")
+	assert.NotContains(t, step, "evil")
+
+	_, err = syncer.SetUID(context.Background(), testUID, "unknown", "x", "")
+	assert.ErrorIs(t, err, ErrUnknownDSP)
+}
+
+func TestResolverBuyerUIDs(t *testing.T) {
+	store := newMemoryStore()
+	store.uids[testUID] = map[string]string{"dsp1": "d1-user", "removed": "x"}
+	resolver := NewResolver(newTestRegistry(t, testConfig), store, 0)
+
+	assert.Equal(t, map[string]string{
+		"http://dsp1/bid":       "d1-user",
+		"http://dsp1/bid_v_2_4": "d1-user",
+	}, resolver.BuyerUIDs(context.Background(), testUID))
+	assert.Nil(t, resolver.BuyerUIDs(context.Background(), "not-an-exchange-uid"))
+	assert.Nil(t, resolver.BuyerUIDs(context.Background(), NewUID()))
+
+	store.err = errors.New("redis is down")
+	assert.Nil(t, resolver.BuyerUIDs(context.Background(), testUID))
+
+	var nilResolver *Resolver
+	assert.Nil(t, nilResolver.BuyerUIDs(context.Background(), testUID))
+}